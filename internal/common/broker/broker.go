@@ -0,0 +1,104 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrBrokerClosed Publisher 或 Subscriber 已经关闭
+	ErrBrokerClosed = errors.New("broker closed")
+	// ErrConfirmTimeout 在 rabbitmq.confirm-timeout 内没有收到 broker 的确认，消息可能已经投递也可能已经丢失
+	ErrConfirmTimeout = errors.New("publish confirm timeout")
+)
+
+// NackError broker 拒绝了发布的消息，消息没有被投递
+type NackError struct {
+	Exchange string
+	Key      string
+}
+
+func (e NackError) Error() string {
+	return fmt.Sprintf("message to exchange=%q key=%q nacked by broker", e.Exchange, e.Key)
+}
+
+// ReturnError Mandatory 的消息没有可以投递的队列，被 broker 退回
+type ReturnError struct {
+	Exchange  string
+	Key       string
+	ReplyCode uint16
+	ReplyText string
+}
+
+func (e ReturnError) Error() string {
+	return fmt.Sprintf("message to exchange=%q key=%q returned by broker: %d %s", e.Exchange, e.Key, e.ReplyCode, e.ReplyText)
+}
+
+// Publisher 发布消息，由 RabbitMQ 和 MemoryBroker 实现。
+// Publish 在 broker 确认后返回，broker 拒绝时返回 NackError，Mandatory 的消息被退回时返回 ReturnError
+type Publisher interface {
+	Publish(ctx context.Context, p *Publishing) error
+}
+
+// Subscriber 订阅队列，返回的 channel 在 ctx 结束或连接关闭时关闭
+type Subscriber interface {
+	Subscribe(ctx context.Context, s Subscription) (<-chan *Delivery, error)
+}
+
+// Publishing 发布的消息，Exchange 为空时使用默认 exchange 将消息直接投递到名为 Key 的队列
+type Publishing struct {
+	Exchange string
+	Key      string
+	// DeclareQueue 发布前声明名为 Key 的持久化队列，避免消费者启动前发布的消息因没有队列而丢失
+	DeclareQueue bool
+	// Delay 大于 0 时消息在 Delay 后才投递到名为 Key 的队列，只能与空的 Exchange 一起使用
+	Delay time.Duration
+	// Mandatory 没有队列接收时返回 ReturnError，否则消息被静默丢弃
+	Mandatory bool
+	Headers   map[string]any
+	Body      []byte
+}
+
+// Subscription 订阅的队列。
+// Queue 为空时为每个实例声明独占的临时队列，每个实例都会收到 Exchange 的所有消息；
+// 否则声明持久化队列，订阅同一队列的实例竞争消费
+type Subscription struct {
+	Queue string
+	// Exchange 队列绑定的 exchange，为空时不绑定，只消费直接投递到队列的消息
+	Exchange string
+	// AutoDelete 最后一个消费者取消订阅后删除队列
+	AutoDelete bool
+	// Prefetch 未确认的消息数量上限，0 表示不限制
+	Prefetch int
+}
+
+// Delivery 订阅收到的消息，处理后必须 Ack 或 Nack
+type Delivery struct {
+	MessageID string
+	// Queue 收到消息的队列
+	Queue       string
+	Exchange    string
+	RoutingKey  string
+	Redelivered bool
+	Headers     map[string]any
+	Body        []byte
+
+	acknowledger acknowledger
+}
+
+type acknowledger interface {
+	Ack() error
+	Nack(requeue bool) error
+}
+
+// Ack 确认消息已处理
+func (d *Delivery) Ack() error {
+	return d.acknowledger.Ack()
+}
+
+// Nack 拒绝消息，requeue 为 true 时消息重新投递，否则被丢弃
+func (d *Delivery) Nack(requeue bool) error {
+	return d.acknowledger.Nack(requeue)
+}
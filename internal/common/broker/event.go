@@ -7,15 +7,37 @@ import (
 	"fmt"
 
 	"github.com/furutachiKurea/gorder/common/logging"
-	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/rs/zerolog/log"
 )
 
 const (
-	EventOrderCreated = "order.created"
-	EventOrderPaid    = "order.paid"
+	EventOrderCreated            = "order.created"
+	EventOrderPaid               = "order.paid"
+	EventStockBackorderAllocated = "stock.backorder_allocated"
+	EventOrderPaymentFailed      = "order.payment_failed"
+	EventOrderPaymentExpired     = "order.payment_expired"
+	EventKitchenSLABreached      = "kitchen.sla_breached"
+	EventKitchenETAUpdated       = "kitchen.eta_updated"
+	EventKitchenLoadUpdated      = "kitchen.load_updated"
+	EventKitchenTicketStarted    = "kitchen.ticket_started"
+	EventKitchenTicketReady      = "kitchen.ticket_ready"
+	EventKitchenTicketFailed     = "kitchen.ticket_failed"
 )
 
+// fanoutExchanges 连接时声明的 fanout exchange
+var fanoutExchanges = []string{
+	EventOrderPaid,
+	EventStockBackorderAllocated,
+	EventOrderPaymentFailed,
+	EventOrderPaymentExpired,
+	EventKitchenSLABreached,
+	EventKitchenETAUpdated,
+	EventKitchenLoadUpdated,
+	EventKitchenTicketStarted,
+	EventKitchenTicketReady,
+	EventKitchenTicketFailed,
+}
+
 type RoutingType string
 
 const (
@@ -24,11 +46,13 @@ const (
 )
 
 type PublishEventReq struct {
-	Channel  *amqp.Channel
-	Routing  RoutingType
-	Queue    string
-	Exchange string
-	Body     any
+	Publisher Publisher
+	Routing   RoutingType
+	Queue     string
+	Exchange  string
+	Body      any
+	// Mandatory fan-out 事件没有队列绑定时返回 ReturnError，用于必须有服务处理的事件，direct 事件总是 Mandatory
+	Mandatory bool
 }
 
 func PublishEvent(ctx context.Context, req *PublishEventReq) (err error) {
@@ -51,21 +75,18 @@ func PublishEvent(ctx context.Context, req *PublishEventReq) (err error) {
 }
 
 func directQueue(ctx context.Context, req *PublishEventReq) error {
-	_, err := req.Channel.QueueDeclare(req.Queue, true, false, false, false, nil)
-	if err != nil {
-		return err
-	}
-
 	jsonBody, err := json.Marshal(req.Body)
 	if err != nil {
 		return fmt.Errorf("marshalling body in publish event: %w", err)
 	}
 
-	return doPublish(ctx, req.Channel, req.Exchange, req.Queue, false, false, amqp.Publishing{
-		ContentType:  "application/json",
-		DeliveryMode: amqp.Persistent,
-		Body:         jsonBody,
+	return doPublish(ctx, req.Publisher, &Publishing{
+		Exchange:     req.Exchange,
+		Key:          req.Queue,
+		DeclareQueue: true,
+		Mandatory:    true,
 		Headers:      InjectRabbitMQHeaders(ctx),
+		Body:         jsonBody,
 	})
 }
 
@@ -75,33 +96,26 @@ func fanOutQueue(ctx context.Context, req *PublishEventReq) error {
 		return fmt.Errorf("marshalling body in publish event: %w", err)
 	}
 
-	return doPublish(ctx, req.Channel, req.Exchange, "", false, false, amqp.Publishing{
-		ContentType:  "application/json",
-		DeliveryMode: amqp.Persistent,
-		Body:         jsonBody,
-		Headers:      InjectRabbitMQHeaders(ctx),
+	return doPublish(ctx, req.Publisher, &Publishing{
+		Exchange:  req.Exchange,
+		Mandatory: req.Mandatory,
+		Headers:   InjectRabbitMQHeaders(ctx),
+		Body:      jsonBody,
 	})
-
 }
 
 // doPublish -
-func doPublish(
-	ctx context.Context,
-	ch *amqp.Channel,
-	exchange, key string,
-	mandatory, immediate bool,
-	msg amqp.Publishing,
-) error {
-	if err := ch.PublishWithContext(ctx, exchange, key, mandatory, immediate, msg); err != nil {
-		log.Warn().Ctx(ctx).Msgf("_publish_event_failed||exchange=%s||key=%s||msg=%v", exchange, key, msg)
+func doPublish(ctx context.Context, publisher Publisher, p *Publishing) error {
+	if err := publisher.Publish(ctx, p); err != nil {
+		log.Warn().Ctx(ctx).Msgf("_publish_event_failed||exchange=%s||key=%s||msg=%v", p.Exchange, p.Key, p)
 		return fmt.Errorf("publish event: %w", err)
 	}
 	return nil
 }
 
 func checkParam(r *PublishEventReq) error {
-	if r.Channel == nil {
-		return errors.New("nil channel")
+	if r.Publisher == nil {
+		return errors.New("nil publisher")
 	}
 	return nil
 }
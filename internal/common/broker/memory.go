@@ -0,0 +1,354 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// MemoryBroker 进程内的 Publisher 和 Subscriber，用于在没有 RabbitMQ 时构建和测试应用，
+// 声明了与 Connect 相同的 exchange 和 DLQ，行为与 RabbitMQ 保持一致：
+//   - 支持 fanout、direct exchange 以及通过默认 exchange 直接投递到队列，
+//     没有队列接收的消息被丢弃，Mandatory 的消息返回 ReturnError
+//   - 消费者未确认的消息数量达到 prefetch 后不再投递
+//   - Nack 时 requeue 的消息标记为 Redelivered 后放回队首，否则被丢弃；取消订阅时未确认的消息重新投递
+//   - Delay 的消息在延迟结束后投递，HandlerRetry 移入 DLQ 的消息可以通过订阅 DLQ 读取
+type MemoryBroker struct {
+	mu        sync.Mutex
+	exchanges map[string]*memoryExchange
+	queues    map[string]*memoryQueue
+	// seq 用于生成临时队列名和投递标签
+	seq    uint64
+	closed bool
+	done   chan struct{}
+}
+
+type memoryExchange struct {
+	kind     string
+	bindings []memoryBinding
+}
+
+type memoryBinding struct {
+	queue string
+	key   string
+}
+
+type memoryQueue struct {
+	name       string
+	autoDelete bool
+	ready      []*memoryMessage
+	consumers  int
+	// changed 在队列有新消息或有消息被确认时关闭并替换，用于唤醒等待的消费者
+	changed chan struct{}
+}
+
+type memoryMessage struct {
+	exchange    string
+	key         string
+	headers     map[string]any
+	body        []byte
+	redelivered bool
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	b := &MemoryBroker{
+		exchanges: map[string]*memoryExchange{
+			EventOrderCreated: {kind: amqp.ExchangeDirect},
+			DLX:               {kind: amqp.ExchangeFanout},
+		},
+		queues: make(map[string]*memoryQueue),
+		done:   make(chan struct{}),
+	}
+	for _, exchange := range fanoutExchanges {
+		b.exchanges[exchange] = &memoryExchange{kind: amqp.ExchangeFanout}
+	}
+
+	b.declareQueue("share_queue", false)
+	b.exchanges[DLX].bindings = append(b.exchanges[DLX].bindings, memoryBinding{queue: "share_queue"})
+	b.declareQueue(DLQ, false)
+	return b
+}
+
+// Close 关闭 broker，所有订阅的 channel 被关闭，之后的发布和订阅返回 ErrBrokerClosed
+func (b *MemoryBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.closed {
+		b.closed = true
+		close(b.done)
+	}
+	return nil
+}
+
+func (b *MemoryBroker) Publish(_ context.Context, p *Publishing) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrBrokerClosed
+	}
+
+	if p.DeclareQueue {
+		b.declareQueue(p.Key, false)
+	}
+
+	queues, err := b.route(p.Exchange, p.Key)
+	if err != nil {
+		return err
+	}
+	if len(queues) == 0 && p.Mandatory {
+		return ReturnError{Exchange: p.Exchange, Key: p.Key, ReplyCode: amqp.NoRoute, ReplyText: "NO_ROUTE"}
+	}
+
+	if p.Delay > 0 {
+		if p.Exchange != "" {
+			return fmt.Errorf("delayed publishing to exchange %s is not supported", p.Exchange)
+		}
+		msg := &memoryMessage{key: p.Key, headers: maps.Clone(p.Headers), body: p.Body}
+		time.AfterFunc(p.Delay, func() { b.deliverDelayed(msg) })
+		return nil
+	}
+
+	for _, q := range queues {
+		q.ready = append(q.ready, &memoryMessage{
+			exchange: p.Exchange,
+			key:      p.Key,
+			headers:  maps.Clone(p.Headers),
+			body:     p.Body,
+		})
+		q.notify()
+	}
+	return nil
+}
+
+// deliverDelayed 延迟结束后将消息投递到名为 msg.key 的队列，队列已经被删除时丢弃
+func (b *MemoryBroker) deliverDelayed(msg *memoryMessage) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if q, ok := b.queues[msg.key]; ok && !b.closed {
+		q.ready = append(q.ready, msg)
+		q.notify()
+	}
+}
+
+func (b *MemoryBroker) Subscribe(ctx context.Context, s Subscription) (<-chan *Delivery, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrBrokerClosed
+	}
+
+	name, autoDelete := s.Queue, s.AutoDelete
+	if name == "" {
+		b.seq++
+		name, autoDelete = fmt.Sprintf("amq.gen-%d", b.seq), true
+	}
+
+	q := b.declareQueue(name, autoDelete)
+	if s.Exchange != "" {
+		if err := b.bind(name, s.Exchange); err != nil {
+			return nil, err
+		}
+	}
+	q.consumers++
+
+	c := &memoryConsumer{b: b, q: q, prefetch: s.Prefetch, unacked: make(map[uint64]*memoryMessage)}
+	out := make(chan *Delivery)
+	go c.run(ctx, out)
+	return out, nil
+}
+
+// declareQueue 声明队列，队列已经存在时直接返回
+func (b *MemoryBroker) declareQueue(name string, autoDelete bool) *memoryQueue {
+	if q, ok := b.queues[name]; ok {
+		return q
+	}
+
+	q := &memoryQueue{name: name, autoDelete: autoDelete, changed: make(chan struct{})}
+	b.queues[name] = q
+	return q
+}
+
+func (b *MemoryBroker) bind(queue, exchange string) error {
+	ex, ok := b.exchanges[exchange]
+	if !ok {
+		return fmt.Errorf("exchange %s not found", exchange)
+	}
+
+	for _, binding := range ex.bindings {
+		if binding.queue == queue {
+			return nil
+		}
+	}
+	ex.bindings = append(ex.bindings, memoryBinding{queue: queue, key: queue})
+	return nil
+}
+
+// deleteQueue 删除队列以及队列的绑定
+func (b *MemoryBroker) deleteQueue(q *memoryQueue) {
+	delete(b.queues, q.name)
+	for _, ex := range b.exchanges {
+		bindings := ex.bindings[:0]
+		for _, binding := range ex.bindings {
+			if binding.queue != q.name {
+				bindings = append(bindings, binding)
+			}
+		}
+		ex.bindings = bindings
+	}
+}
+
+// route 返回接收消息的队列，exchange 为空时投递到名为 key 的队列
+func (b *MemoryBroker) route(exchange, key string) ([]*memoryQueue, error) {
+	if exchange == "" {
+		if q, ok := b.queues[key]; ok {
+			return []*memoryQueue{q}, nil
+		}
+		return nil, nil
+	}
+
+	ex, ok := b.exchanges[exchange]
+	if !ok {
+		return nil, fmt.Errorf("exchange %s not found", exchange)
+	}
+
+	var queues []*memoryQueue
+	for _, binding := range ex.bindings {
+		if ex.kind == amqp.ExchangeDirect && binding.key != key {
+			continue
+		}
+		if q, ok := b.queues[binding.queue]; ok {
+			queues = append(queues, q)
+		}
+	}
+	return queues, nil
+}
+
+func (q *memoryQueue) notify() {
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
+
+// requeue 将消息标记为重新投递后放回队首
+func (q *memoryQueue) requeue(msg *memoryMessage) {
+	msg.redelivered = true
+	q.ready = append([]*memoryMessage{msg}, q.ready...)
+	q.notify()
+}
+
+// memoryConsumer 队列的一个消费者，unacked 为已投递未确认的消息，key 为投递标签
+type memoryConsumer struct {
+	b        *MemoryBroker
+	q        *memoryQueue
+	prefetch int
+	unacked  map[uint64]*memoryMessage
+}
+
+func (c *memoryConsumer) run(ctx context.Context, out chan<- *Delivery) {
+	defer close(out)
+	defer c.cancel()
+
+	for {
+		c.b.mu.Lock()
+		d := c.next()
+		changed := c.q.changed
+		c.b.mu.Unlock()
+
+		if d == nil {
+			select {
+			case <-changed:
+				continue
+			case <-ctx.Done():
+				return
+			case <-c.b.done:
+				return
+			}
+		}
+
+		select {
+		case out <- d:
+		case <-ctx.Done():
+			return
+		case <-c.b.done:
+			return
+		}
+	}
+}
+
+// next 取出队首的消息，队列为空或未确认的消息达到 prefetch 时返回 nil
+func (c *memoryConsumer) next() *Delivery {
+	if len(c.q.ready) == 0 || (c.prefetch > 0 && len(c.unacked) >= c.prefetch) {
+		return nil
+	}
+
+	msg := c.q.ready[0]
+	c.q.ready = c.q.ready[1:]
+	c.b.seq++
+	tag := c.b.seq
+	c.unacked[tag] = msg
+
+	return &Delivery{
+		Queue:        c.q.name,
+		Exchange:     msg.exchange,
+		RoutingKey:   msg.key,
+		Redelivered:  msg.redelivered,
+		Headers:      maps.Clone(msg.headers),
+		Body:         msg.body,
+		acknowledger: memoryAcknowledger{c: c, tag: tag},
+	}
+}
+
+// cancel 取消订阅，未确认的消息重新投递，自动删除的队列在最后一个消费者取消后删除
+func (c *memoryConsumer) cancel() {
+	c.b.mu.Lock()
+	defer c.b.mu.Unlock()
+
+	for tag, msg := range c.unacked {
+		delete(c.unacked, tag)
+		c.q.requeue(msg)
+	}
+
+	c.q.consumers--
+	if c.q.consumers == 0 && c.q.autoDelete {
+		c.b.deleteQueue(c.q)
+	}
+}
+
+// settle 确认或拒绝投递标签为 tag 的消息
+func (c *memoryConsumer) settle(tag uint64, requeue bool) error {
+	c.b.mu.Lock()
+	defer c.b.mu.Unlock()
+
+	msg, ok := c.unacked[tag]
+	if !ok {
+		return fmt.Errorf("unknown delivery tag %d", tag)
+	}
+
+	delete(c.unacked, tag)
+	if requeue {
+		c.q.requeue(msg)
+		return nil
+	}
+	c.q.notify()
+	return nil
+}
+
+type memoryAcknowledger struct {
+	c   *memoryConsumer
+	tag uint64
+}
+
+func (a memoryAcknowledger) Ack() error {
+	return a.c.settle(a.tag, false)
+}
+
+func (a memoryAcknowledger) Nack(requeue bool) error {
+	return a.c.settle(a.tag, requeue)
+}
@@ -0,0 +1,216 @@
+package broker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testQueue = "test.queue"
+
+// receive 在 timeout 内读取一条消息，超时时测试失败
+func receive(t *testing.T, msgs <-chan *Delivery, timeout time.Duration) *Delivery {
+	t.Helper()
+
+	select {
+	case d, ok := <-msgs:
+		require.True(t, ok, "subscription closed")
+		return d
+	case <-time.After(timeout):
+		require.FailNow(t, "no message received", "waited %s", timeout)
+		return nil
+	}
+}
+
+// assertNoMessage 在 wait 内没有收到消息
+func assertNoMessage(t *testing.T, msgs <-chan *Delivery, wait time.Duration) {
+	t.Helper()
+
+	select {
+	case d := <-msgs:
+		assert.Failf(t, "unexpected message", "body=%s", d.Body)
+	case <-time.After(wait):
+	}
+}
+
+func subscribeTest(t *testing.T, b *MemoryBroker, s Subscription) <-chan *Delivery {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	msgs, err := b.Subscribe(ctx, s)
+	require.NoError(t, err)
+	return msgs
+}
+
+func TestMemoryBroker_FanOut(t *testing.T) {
+	b := NewMemoryBroker()
+	defer b.Close()
+
+	// 两个实例的临时队列都收到消息，共享队列的两个消费者只有一个收到
+	broadcast1 := subscribeTest(t, b, Subscription{Exchange: EventOrderPaid})
+	broadcast2 := subscribeTest(t, b, Subscription{Exchange: EventOrderPaid})
+	shared1 := subscribeTest(t, b, Subscription{Queue: testQueue, Exchange: EventOrderPaid})
+	shared2 := subscribeTest(t, b, Subscription{Queue: testQueue, Exchange: EventOrderPaid})
+
+	require.NoError(t, b.Publish(context.Background(), &Publishing{Exchange: EventOrderPaid, Body: []byte("paid")}))
+
+	for _, msgs := range []<-chan *Delivery{broadcast1, broadcast2} {
+		d := receive(t, msgs, time.Second)
+		assert.Equal(t, "paid", string(d.Body))
+		assert.Equal(t, EventOrderPaid, d.Exchange)
+		require.NoError(t, d.Ack())
+	}
+
+	var got int
+	for _, msgs := range []<-chan *Delivery{shared1, shared2} {
+		select {
+		case d := <-msgs:
+			got++
+			assert.Equal(t, testQueue, d.Queue)
+			require.NoError(t, d.Ack())
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	assert.Equal(t, 1, got)
+}
+
+func TestMemoryBroker_DirectQueue(t *testing.T) {
+	b := NewMemoryBroker()
+	defer b.Close()
+
+	// 消费者启动前声明队列，消息不会丢失
+	require.NoError(t, b.Publish(context.Background(), &Publishing{Key: testQueue, DeclareQueue: true, Body: []byte("1")}))
+
+	msgs := subscribeTest(t, b, Subscription{Queue: testQueue})
+	d := receive(t, msgs, time.Second)
+	assert.Equal(t, "1", string(d.Body))
+	assert.Equal(t, testQueue, d.RoutingKey)
+	require.NoError(t, d.Ack())
+}
+
+func TestMemoryBroker_Nack(t *testing.T) {
+	b := NewMemoryBroker()
+	defer b.Close()
+
+	msgs := subscribeTest(t, b, Subscription{Queue: testQueue})
+	require.NoError(t, b.Publish(context.Background(), &Publishing{Key: testQueue, Body: []byte("1")}))
+
+	d := receive(t, msgs, time.Second)
+	assert.False(t, d.Redelivered)
+	require.NoError(t, d.Nack(true))
+
+	d = receive(t, msgs, time.Second)
+	assert.True(t, d.Redelivered)
+	assert.Equal(t, "1", string(d.Body))
+	require.NoError(t, d.Nack(false))
+
+	assertNoMessage(t, msgs, 50*time.Millisecond)
+	assert.Error(t, d.Ack(), "settling a message twice should fail")
+}
+
+func TestMemoryBroker_Prefetch(t *testing.T) {
+	b := NewMemoryBroker()
+	defer b.Close()
+
+	msgs := subscribeTest(t, b, Subscription{Queue: testQueue, Prefetch: 1})
+	for _, body := range []string{"1", "2"} {
+		require.NoError(t, b.Publish(context.Background(), &Publishing{Key: testQueue, Body: []byte(body)}))
+	}
+
+	first := receive(t, msgs, time.Second)
+	assertNoMessage(t, msgs, 50*time.Millisecond)
+
+	require.NoError(t, first.Ack())
+	second := receive(t, msgs, time.Second)
+	assert.Equal(t, "2", string(second.Body))
+	require.NoError(t, second.Ack())
+}
+
+func TestMemoryBroker_CancelRequeuesUnacked(t *testing.T) {
+	b := NewMemoryBroker()
+	defer b.Close()
+
+	// second 先收到一条消息且未确认，之后的消息都投递给 first
+	second := subscribeTest(t, b, Subscription{Queue: testQueue, Prefetch: 1})
+	require.NoError(t, b.Publish(context.Background(), &Publishing{Key: testQueue, Body: []byte("hold")}))
+	held := receive(t, second, time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first, err := b.Subscribe(ctx, Subscription{Queue: testQueue})
+	require.NoError(t, err)
+	require.NoError(t, b.Publish(context.Background(), &Publishing{Key: testQueue, Body: []byte("1")}))
+	d := receive(t, first, time.Second)
+	assert.Equal(t, "1", string(d.Body))
+
+	cancel()
+	for range first {
+	}
+
+	require.NoError(t, held.Ack())
+	d = receive(t, second, time.Second)
+	assert.True(t, d.Redelivered)
+	assert.Equal(t, "1", string(d.Body))
+	require.NoError(t, d.Ack())
+}
+
+func TestMemoryBroker_Mandatory(t *testing.T) {
+	b := NewMemoryBroker()
+	defer b.Close()
+
+	err := b.Publish(context.Background(), &Publishing{Exchange: EventOrderPaid, Mandatory: true, Body: []byte("1")})
+	var returnErr ReturnError
+	require.ErrorAs(t, err, &returnErr)
+	assert.Equal(t, EventOrderPaid, returnErr.Exchange)
+
+	// 非 Mandatory 的消息被静默丢弃
+	assert.NoError(t, b.Publish(context.Background(), &Publishing{Exchange: EventOrderPaid, Body: []byte("1")}))
+
+	subscribeTest(t, b, Subscription{Queue: testQueue, Exchange: EventOrderPaid})
+	assert.NoError(t, b.Publish(context.Background(), &Publishing{Exchange: EventOrderPaid, Mandatory: true, Body: []byte("1")}))
+}
+
+func TestMemoryBroker_Delay(t *testing.T) {
+	b := NewMemoryBroker()
+	defer b.Close()
+
+	msgs := subscribeTest(t, b, Subscription{Queue: testQueue})
+	start := time.Now()
+	require.NoError(t, b.Publish(context.Background(), &Publishing{
+		Key:     testQueue,
+		Delay:   100 * time.Millisecond,
+		Headers: map[string]any{"k": "v"},
+		Body:    []byte("later"),
+	}))
+
+	d := receive(t, msgs, time.Second)
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	assert.Equal(t, "later", string(d.Body))
+	assert.Equal(t, "v", d.Headers["k"])
+	require.NoError(t, d.Ack())
+
+	err := b.Publish(context.Background(), &Publishing{Exchange: EventOrderPaid, Delay: time.Second})
+	assert.Error(t, err, "delay with exchange should be rejected")
+}
+
+func TestMemoryBroker_Close(t *testing.T) {
+	b := NewMemoryBroker()
+
+	msgs := subscribeTest(t, b, Subscription{Queue: testQueue})
+	require.NoError(t, b.Close())
+
+	select {
+	case _, ok := <-msgs:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		require.FailNow(t, "subscription not closed")
+	}
+
+	assert.ErrorIs(t, b.Publish(context.Background(), &Publishing{Key: testQueue}), ErrBrokerClosed)
+	_, err := b.Subscribe(context.Background(), Subscription{Queue: testQueue})
+	assert.ErrorIs(t, err, ErrBrokerClosed)
+}
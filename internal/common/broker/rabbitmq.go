@@ -4,10 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"sync"
 	"time"
 
 	_ "github.com/furutachiKurea/gorder/common/config"
-	"github.com/furutachiKurea/gorder/common/logging"
+	"github.com/furutachiKurea/gorder/common/decorator"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/rs/zerolog/log"
@@ -16,47 +18,222 @@ import (
 )
 
 const (
-	DLX                = "dlx"
-	DLQ                = "dlq"
-	amqpRetryHeaderKey = "x-retry-count"
+	DLX = "dlx"
+	DLQ = "dlq"
+	// DelayedExchange 启用 rabbitmq.delayed-message-exchange 时用于延迟投递的 x-delayed-message exchange，
+	// 需要 RabbitMQ 安装 rabbitmq_delayed_message_exchange 插件
+	DelayedExchange = "delayed"
 )
 
-var (
-	maxRetryCount int64 = viper.GetInt64("rabbitmq.max-retry")
+const (
+	defaultReconnectMinBackoff = 500 * time.Millisecond
+	defaultReconnectMaxBackoff = 30 * time.Second
+	defaultConfirmTimeout      = 5 * time.Second
 )
 
-// Connect 连接到 RabbitMQ 并创建 Exchange
-func Connect(user, password, host, port string) (ch *amqp.Channel, closeCoon func() error) {
-	addr := fmt.Sprintf("amqp://%s:%s@%s:%s/", user, password, host, port)
-	coon, err := amqp.Dial(addr)
-	if err != nil {
-		log.Fatal().Err(err).Msg("failed to connect to RabbitMQ")
+// ErrNotConnected 与 RabbitMQ 的连接已断开，正在重连
+var ErrNotConnected = errors.New("rabbitmq not connected")
+
+// RabbitMQ 基于 RabbitMQ 的 Publisher 和 Subscriber，每个服务共用一个自动重连的连接：
+//   - 连接断开后按指数退避重连，重连后重新声明 exchange 和 DLQ
+//   - 发布从 channel 池中取出 confirm 模式的 channel，等待 broker 确认后返回，连接断开期间发布立即返回 ErrNotConnected
+//   - 每个订阅使用独立的 channel，重连后重新订阅，Subscribe 返回的 channel 在重连期间保持打开
+type RabbitMQ struct {
+	addr           string
+	minBackoff     time.Duration
+	maxBackoff     time.Duration
+	confirmTimeout time.Duration
+	// delayedExchange 使用 DelayedExchange 延迟投递，否则使用 TTL 队列
+	delayedExchange bool
+	metricsClient   decorator.MetricsClient
+
+	mu      sync.Mutex
+	conn    *amqp.Connection
+	lastErr error
+	// generation 每次建立连接后加一，用于识别旧连接上的 channel
+	generation uint64
+	// connected 在连接建立后关闭，连接断开时替换，用于等待重连
+	connected   chan struct{}
+	notifyClose chan *amqp.Error
+	// pool 发布使用的 channel
+	pool chan *publishChannel
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// Connect 连接到 RabbitMQ 并创建 Exchange，
+// 连接失败或断开时在后台按 rabbitmq.reconnect-min-backoff 到 rabbitmq.reconnect-max-backoff 指数退避重连
+func Connect(user, password, host, port string, metricsClient decorator.MetricsClient) (mq *RabbitMQ, closeCoon func() error) {
+	if metricsClient == nil {
+		panic("metricsClient is nil")
+	}
+
+	mq = &RabbitMQ{
+		addr:            fmt.Sprintf("amqp://%s:%s@%s:%s/", user, password, host, port),
+		minBackoff:      viper.GetDuration("rabbitmq.reconnect-min-backoff"),
+		maxBackoff:      viper.GetDuration("rabbitmq.reconnect-max-backoff"),
+		confirmTimeout:  viper.GetDuration("rabbitmq.confirm-timeout"),
+		delayedExchange: viper.GetBool("rabbitmq.delayed-message-exchange"),
+		metricsClient:   metricsClient,
+		connected:       make(chan struct{}),
+		pool:            make(chan *publishChannel, max(viper.GetInt("rabbitmq.publish-channels"), 1)),
+		closed:          make(chan struct{}),
+	}
+	if mq.minBackoff <= 0 {
+		mq.minBackoff = defaultReconnectMinBackoff
+	}
+	if mq.maxBackoff < mq.minBackoff {
+		mq.maxBackoff = max(defaultReconnectMaxBackoff, mq.minBackoff)
+	}
+	if mq.confirmTimeout <= 0 {
+		mq.confirmTimeout = defaultConfirmTimeout
 	}
 
-	ch, err = coon.Channel()
+	if err := mq.connect(); err != nil {
+		log.Error().Err(err).Msg("failed to connect to RabbitMQ, reconnecting in background")
+	}
+	go mq.maintain()
+
+	return mq, mq.Close
+}
+
+// connect 建立连接，声明 exchange 并创建发布使用的 channel
+func (r *RabbitMQ) connect() (err error) {
+	defer func() {
+		if err != nil {
+			r.mu.Lock()
+			r.lastErr = err
+			r.mu.Unlock()
+		}
+	}()
+
+	conn, err := amqp.Dial(r.addr)
 	if err != nil {
-		log.Fatal().Err(err).Msg("failed to get open RabbitMQ channel")
+		return fmt.Errorf("dial: %w", err)
+	}
+
+	r.mu.Lock()
+	generation := r.generation + 1
+	r.mu.Unlock()
+
+	channels := make([]*publishChannel, 0, cap(r.pool))
+	for range cap(r.pool) {
+		ch, err := newPublishChannel(conn, generation)
+		if err != nil {
+			_ = conn.Close()
+			return err
+		}
+		channels = append(channels, ch)
+	}
+
+	if err = declareTopology(channels[0].ch, r.delayedExchange); err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.conn, r.lastErr, r.generation = conn, nil, generation
+	r.notifyClose = conn.NotifyClose(make(chan *amqp.Error, 1))
+	// 持有锁时不能阻塞，池中残留的 channel 在取出时按 generation 丢弃
+	for _, ch := range channels {
+		select {
+		case r.pool <- ch:
+		default:
+			_ = ch.ch.Close()
+		}
+	}
+	close(r.connected)
+	return nil
+}
+
+// disconnected 连接断开后清空 channel 池，等待重连
+func (r *RabbitMQ) disconnected(cause error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.conn, r.lastErr = nil, cause
+	r.connected = make(chan struct{})
+	for {
+		select {
+		case <-r.pool:
+		default:
+			return
+		}
+	}
+}
+
+// maintain 监听连接断开并按指数退避重连，直到 Close
+func (r *RabbitMQ) maintain() {
+	backoff := r.minBackoff
+	for {
+		r.mu.Lock()
+		conn, notifyClose := r.conn, r.notifyClose
+		r.mu.Unlock()
+
+		if conn != nil {
+			select {
+			case <-r.closed:
+				return
+			case amqpErr := <-notifyClose:
+				var cause error = ErrNotConnected
+				if amqpErr != nil {
+					cause = amqpErr
+				}
+				log.Warn().Err(cause).Msg("RabbitMQ connection lost, reconnecting")
+				r.disconnected(cause)
+				backoff = r.minBackoff
+			}
+		}
+
+		select {
+		case <-r.closed:
+			return
+		case <-time.After(backoff):
+		}
+
+		if err := r.connect(); err != nil {
+			log.Warn().Err(err).Dur("backoff", backoff).Msg("failed to reconnect to RabbitMQ")
+			backoff = min(backoff*2, r.maxBackoff)
+			continue
+		}
+		log.Info().Msg("reconnected to RabbitMQ")
 	}
+}
 
-	if err = ch.ExchangeDeclare(
+// declareTopology 声明各服务共用的 exchange 和 DLQ，每次建立连接后重新声明
+func declareTopology(ch *amqp.Channel, delayedExchange bool) error {
+	if err := ch.ExchangeDeclare(
 		EventOrderCreated, amqp.ExchangeDirect,
 		true, false, false, false, nil,
 	); err != nil {
-		log.Fatal().Err(err).Str("exchange", EventOrderCreated).Msg("failed to declare exchange")
+		return fmt.Errorf("declare exchange %s: %w", EventOrderCreated, err)
 	}
 
-	if err = ch.ExchangeDeclare(
-		EventOrderPaid, amqp.ExchangeFanout,
-		true, false, false, false, nil,
-	); err != nil {
-		log.Fatal().Err(err).Str("exchange", EventOrderPaid).Msg("failed to declare exchange")
+	for _, exchange := range fanoutExchanges {
+		if err := ch.ExchangeDeclare(
+			exchange, amqp.ExchangeFanout,
+			true, false, false, false, nil,
+		); err != nil {
+			return fmt.Errorf("declare exchange %s: %w", exchange, err)
+		}
 	}
 
-	if err = createDLX(ch); err != nil {
-		log.Fatal().Err(err).Msg("failed to create dlx")
+	if delayedExchange {
+		if err := ch.ExchangeDeclare(
+			DelayedExchange, "x-delayed-message",
+			true, false, false, false, amqp.Table{"x-delayed-type": amqp.ExchangeDirect},
+		); err != nil {
+			return fmt.Errorf("declare exchange %s: %w", DelayedExchange, err)
+		}
 	}
 
-	return ch, coon.Close
+	if err := createDLX(ch); err != nil {
+		return fmt.Errorf("create dlx: %w", err)
+	}
+	return nil
 }
 
 func createDLX(ch *amqp.Channel) error {
@@ -79,56 +256,422 @@ func createDLX(ch *amqp.Channel) error {
 	return err
 }
 
-func HandlerRetry(ctx context.Context, ch *amqp.Channel, d *amqp.Delivery) (err error) {
-	var retryCount int64
+// Health 连接正常时返回 nil，否则返回 ErrNotConnected 以及最近一次连接断开或失败的原因
+func (r *RabbitMQ) Health() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	l, deferlog := logging.WhenRequest(ctx, "HandleRetry", map[string]any{
-		"delivery":        d,
-		"max_retry_count": maxRetryCount,
-	})
+	if r.conn != nil && !r.conn.IsClosed() {
+		return nil
+	}
+	if r.lastErr != nil && !errors.Is(r.lastErr, ErrNotConnected) {
+		return fmt.Errorf("%w: %v", ErrNotConnected, r.lastErr)
+	}
+	return ErrNotConnected
+}
+
+// Close 关闭连接并停止重连，订阅的 channel 随后关闭，可以重复调用
+func (r *RabbitMQ) Close() error {
+	r.closeOnce.Do(func() { close(r.closed) })
+
+	r.mu.Lock()
+	conn := r.conn
+	r.mu.Unlock()
+
+	if conn == nil || conn.IsClosed() {
+		return nil
+	}
+	return conn.Close()
+}
+
+func (r *RabbitMQ) Publish(ctx context.Context, p *Publishing) (err error) {
+	start, exchange := time.Now(), p.Exchange
 	defer func() {
-		deferlog(nil, &err)
+		r.recordPublish(exchange, time.Since(start), err)
 	}()
 
-	log.Info().Ctx(ctx).
-		Any("delivery", d).
-		Msg("handle_retry_start")
-	if d.Headers == nil {
-		d.Headers = make(amqp.Table)
+	pc, err := r.acquire(ctx)
+	if err != nil {
+		return err
 	}
+	defer r.release(pc)
 
-	retryCount, ok := d.Headers[amqpRetryHeaderKey].(int64)
-	if !ok {
-		retryCount = 0
+	if p.DeclareQueue {
+		if _, err = pc.ch.QueueDeclare(p.Key, true, false, false, false, nil); err != nil {
+			return fmt.Errorf("declare queue %s: %w", p.Key, err)
+		}
 	}
 
-	retryCount++
-	d.Headers[amqpRetryHeaderKey] = retryCount
-	l = l.With().Int64("retry_count", retryCount).Logger()
-
-	if retryCount > maxRetryCount {
-		log.Info().Ctx(ctx).Str("message_id", d.MessageId).Msg("moving message to dlq")
-		err = doPublish(ctx, ch, "", DLQ, false, false, amqp.Publishing{
-			Headers:      d.Headers,
-			ContentType:  "application/json",
-			Body:         d.Body,
-			DeliveryMode: amqp.Persistent,
-		})
-		if err != nil {
-			err = fmt.Errorf("publish to dlq: %w", err)
+	if p.Delay > 0 {
+		if p, err = r.delayed(pc.ch, p); err != nil {
 			return err
 		}
-		return errors.New("max retry count exceeded, message moved to dlq")
 	}
 
-	log.Debug().Ctx(ctx).Str("message_id", d.MessageId).Int64("retry_count", retryCount).Msg("retrying message")
-	time.Sleep(time.Second * time.Duration(retryCount))
-	return doPublish(ctx, ch, d.Exchange, d.RoutingKey, false, false, amqp.Publishing{
-		Headers:      d.Headers,
+	confirm, err := pc.ch.PublishWithDeferredConfirmWithContext(ctx, p.Exchange, p.Key, p.Mandatory, false, amqp.Publishing{
 		ContentType:  "application/json",
-		Body:         d.Body,
 		DeliveryMode: amqp.Persistent,
+		Body:         p.Body,
+		Headers:      p.Headers,
 	})
+	if err != nil {
+		return err
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, r.confirmTimeout)
+	defer cancel()
+
+	acked, err := confirm.WaitContext(waitCtx)
+	if err != nil {
+		// 确认可能在之后到达，关闭 channel 避免迟到的退回被算到下一条消息上
+		_ = pc.ch.Close()
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			return fmt.Errorf("exchange=%s key=%s: %w", p.Exchange, p.Key, ErrConfirmTimeout)
+		}
+		return err
+	}
+
+	// broker 在确认之前发送退回，确认到达时退回已经在 returns 中
+	select {
+	case ret, ok := <-pc.returns:
+		if ok {
+			return ReturnError{Exchange: ret.Exchange, Key: ret.RoutingKey, ReplyCode: ret.ReplyCode, ReplyText: ret.ReplyText}
+		}
+	default:
+	}
+
+	if !acked {
+		if pc.ch.IsClosed() {
+			// channel 关闭时未确认的消息都被视为拒绝
+			return fmt.Errorf("channel closed before confirm: %w", ErrNotConnected)
+		}
+		return NackError{Exchange: p.Exchange, Key: p.Key}
+	}
+	return nil
+}
+
+// delayed 返回延迟投递 p 实际发布的消息：
+// 启用延迟消息插件时发布到 DelayedExchange 并设置 x-delay，Key 对应的队列以队列名绑定到 DelayedExchange；
+// 否则发布到 TTL 为 Delay 的队列，消息过期后死信回到 Key 对应的队列。
+// 每个队列的每种延迟使用一个 TTL 队列，一段时间没有使用后自动删除
+func (r *RabbitMQ) delayed(ch *amqp.Channel, p *Publishing) (*Publishing, error) {
+	if p.Exchange != "" {
+		return nil, fmt.Errorf("delayed publishing to exchange %s is not supported", p.Exchange)
+	}
+
+	delayed := &Publishing{Headers: maps.Clone(p.Headers), Body: p.Body}
+	if delayed.Headers == nil {
+		delayed.Headers = make(map[string]any)
+	}
+
+	if r.delayedExchange {
+		if err := ch.QueueBind(p.Key, p.Key, DelayedExchange, false, nil); err != nil {
+			return nil, fmt.Errorf("bind queue %s to %s: %w", p.Key, DelayedExchange, err)
+		}
+		// 插件在延迟结束后才路由消息，发布时总是没有可投递的队列，不能设置 Mandatory
+		delayed.Exchange, delayed.Key = DelayedExchange, p.Key
+		delayed.Headers["x-delay"] = p.Delay.Milliseconds()
+		return delayed, nil
+	}
+
+	ttl := p.Delay.Milliseconds()
+	// 队列名不能以 amq. 开头，服务端命名的临时队列也可以使用
+	queue := fmt.Sprintf("retry.%s.%dms", p.Key, ttl)
+	if _, err := ch.QueueDeclare(queue, true, false, false, false, amqp.Table{
+		"x-message-ttl":             ttl,
+		"x-dead-letter-exchange":    "",
+		"x-dead-letter-routing-key": p.Key,
+		"x-expires":                 2*ttl + time.Minute.Milliseconds(),
+	}); err != nil {
+		return nil, fmt.Errorf("declare delay queue %s: %w", queue, err)
+	}
+	delayed.Key, delayed.Mandatory = queue, p.Mandatory
+	return delayed, nil
+}
+
+// recordPublish 记录发布结果和等待确认的耗时(毫秒)
+func (r *RabbitMQ) recordPublish(exchange string, latency time.Duration, err error) {
+	if exchange == "" {
+		exchange = "default"
+	}
+
+	key := func(outcome string) string {
+		return fmt.Sprintf("rabbitmq.publish.%s.%s", exchange, outcome)
+	}
+
+	var (
+		nackErr   NackError
+		returnErr ReturnError
+	)
+	switch {
+	case err == nil:
+		r.metricsClient.Inc(key("confirmed"), 1)
+		r.metricsClient.Inc(key("confirm_latency_ms"), int(latency.Milliseconds()))
+	case errors.As(err, &nackErr):
+		r.metricsClient.Inc(key("nacked"), 1)
+	case errors.As(err, &returnErr):
+		r.metricsClient.Inc(key("returned"), 1)
+	case errors.Is(err, ErrConfirmTimeout):
+		r.metricsClient.Inc(key("confirm_timeout"), 1)
+	default:
+		r.metricsClient.Inc(key("failure"), 1)
+	}
+}
+
+// publishChannel confirm 模式的发布 channel，returns 接收 Mandatory 消息的退回，generation 为创建 channel 的连接
+type publishChannel struct {
+	ch         *amqp.Channel
+	returns    chan amqp.Return
+	generation uint64
+}
+
+func newPublishChannel(conn *amqp.Connection, generation uint64) (*publishChannel, error) {
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("open channel: %w", err)
+	}
+
+	if err = ch.Confirm(false); err != nil {
+		_ = ch.Close()
+		return nil, fmt.Errorf("enable confirm mode: %w", err)
+	}
+
+	// channel 同一时间只有一条消息在等待确认，缓冲一条退回即可
+	return &publishChannel{ch: ch, returns: ch.NotifyReturn(make(chan amqp.Return, 1)), generation: generation}, nil
+}
+
+// acquire 从池中取出 channel，连接断开时返回 ErrNotConnected
+func (r *RabbitMQ) acquire(ctx context.Context) (*publishChannel, error) {
+	if err := r.Health(); err != nil {
+		return nil, err
+	}
+
+	select {
+	case pc := <-r.pool:
+		if r.current(pc) && !pc.ch.IsClosed() {
+			return pc, nil
+		}
+		// channel 属于已经断开的连接，或者因为协议错误、确认超时被关闭，在当前连接上重新创建
+		_ = pc.ch.Close()
+		return r.openChannel()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-r.closed:
+		return nil, ErrBrokerClosed
+	}
+}
+
+// release 将 channel 放回池中，已关闭的 channel 重新创建后放回，旧连接上的 channel 直接丢弃
+func (r *RabbitMQ) release(pc *publishChannel) {
+	if !r.current(pc) {
+		_ = pc.ch.Close()
+		return
+	}
+
+	if pc.ch.IsClosed() {
+		var err error
+		if pc, err = r.openChannel(); err != nil {
+			return
+		}
+	}
+
+	select {
+	case r.pool <- pc:
+	default:
+		_ = pc.ch.Close()
+	}
+}
+
+// current 判断 channel 是否属于当前的连接
+func (r *RabbitMQ) current(pc *publishChannel) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.conn != nil && pc.generation == r.generation
+}
+
+func (r *RabbitMQ) openChannel() (*publishChannel, error) {
+	r.mu.Lock()
+	conn, generation := r.conn, r.generation
+	r.mu.Unlock()
+
+	if conn == nil {
+		return nil, ErrNotConnected
+	}
+	return newPublishChannel(conn, generation)
+}
+
+// Subscribe 在独立的 channel 上声明队列并开始消费，连接断开后重新订阅。
+// ctx 结束或 Close 时关闭返回的 channel，未确认的消息重新投递
+func (r *RabbitMQ) Subscribe(ctx context.Context, s Subscription) (<-chan *Delivery, error) {
+	select {
+	case <-r.closed:
+		return nil, ErrBrokerClosed
+	default:
+	}
+
+	out := make(chan *Delivery)
+	go r.subscribe(ctx, s, out)
+	return out, nil
+}
+
+func (r *RabbitMQ) subscribe(ctx context.Context, s Subscription, out chan<- *Delivery) {
+	defer close(out)
+
+	backoff := r.minBackoff
+	for {
+		conn := r.waitConnected(ctx)
+		if conn == nil {
+			return
+		}
+
+		ch, err := conn.Channel()
+		var (
+			msgs  <-chan amqp.Delivery
+			queue string
+		)
+		if err == nil {
+			if msgs, queue, err = consume(ch, s); err != nil {
+				_ = ch.Close()
+			}
+		}
+		if err != nil {
+			log.Warn().Err(err).
+				Str("queue", s.Queue).
+				Str("exchange", s.Exchange).
+				Dur("backoff", backoff).
+				Msg("failed to subscribe, retrying")
+			if !r.sleep(ctx, backoff) {
+				return
+			}
+			backoff = min(backoff*2, r.maxBackoff)
+			continue
+		}
+
+		backoff = r.minBackoff
+		interrupted := r.forward(ctx, queue, msgs, out)
+		_ = ch.Close()
+		if !interrupted {
+			return
+		}
+		log.Warn().Str("queue", queue).Str("exchange", s.Exchange).Msg("subscription interrupted, resubscribing")
+	}
+}
+
+// forward 将 msgs 转发到 out，msgs 因为 channel 或连接关闭而结束时返回 true，ctx 结束或 Close 时返回 false
+func (r *RabbitMQ) forward(ctx context.Context, queue string, msgs <-chan amqp.Delivery, out chan<- *Delivery) bool {
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-r.closed:
+			return false
+		case msg, ok := <-msgs:
+			if !ok {
+				return true
+			}
+			select {
+			case out <- newAMQPDelivery(queue, msg):
+			case <-ctx.Done():
+				_ = msg.Nack(false, true)
+				return false
+			case <-r.closed:
+				_ = msg.Nack(false, true)
+				return false
+			}
+		}
+	}
+}
+
+// waitConnected 等待连接可用，ctx 结束或 Close 时返回 nil
+func (r *RabbitMQ) waitConnected(ctx context.Context) *amqp.Connection {
+	for {
+		r.mu.Lock()
+		conn, connected := r.conn, r.connected
+		r.mu.Unlock()
+
+		if conn != nil && !conn.IsClosed() {
+			return conn
+		}
+		if conn != nil {
+			// 连接已经断开，但 maintain 还没有处理
+			if !r.sleep(ctx, r.minBackoff) {
+				return nil
+			}
+			continue
+		}
+
+		select {
+		case <-connected:
+		case <-ctx.Done():
+			return nil
+		case <-r.closed:
+			return nil
+		}
+	}
+}
+
+// sleep 等待 d，ctx 结束或 Close 时返回 false
+func (r *RabbitMQ) sleep(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-time.After(d):
+		return true
+	case <-ctx.Done():
+		return false
+	case <-r.closed:
+		return false
+	}
+}
+
+func consume(ch *amqp.Channel, s Subscription) (<-chan amqp.Delivery, string, error) {
+	if s.Prefetch > 0 {
+		if err := ch.Qos(s.Prefetch, 0, false); err != nil {
+			return nil, "", fmt.Errorf("set prefetch count: %w", err)
+		}
+	}
+
+	exclusive := s.Queue == ""
+	q, err := ch.QueueDeclare(s.Queue, !exclusive, s.AutoDelete || exclusive, exclusive, false, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("declare queue %s: %w", s.Queue, err)
+	}
+
+	if s.Exchange != "" {
+		if err = ch.QueueBind(q.Name, "", s.Exchange, false, nil); err != nil {
+			return nil, "", fmt.Errorf("bind queue %s to %s: %w", q.Name, s.Exchange, err)
+		}
+	}
+
+	msgs, err := ch.Consume(q.Name, "", false, exclusive, false, false, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("consume queue %s: %w", q.Name, err)
+	}
+	return msgs, q.Name, nil
+}
+
+func newAMQPDelivery(queue string, msg amqp.Delivery) *Delivery {
+	return &Delivery{
+		MessageID:    msg.MessageId,
+		Queue:        queue,
+		Exchange:     msg.Exchange,
+		RoutingKey:   msg.RoutingKey,
+		Redelivered:  msg.Redelivered,
+		Headers:      msg.Headers,
+		Body:         msg.Body,
+		acknowledger: amqpAcknowledger{msg: msg},
+	}
+}
+
+type amqpAcknowledger struct {
+	msg amqp.Delivery
+}
+
+func (a amqpAcknowledger) Ack() error {
+	return a.msg.Ack(false)
+}
+
+func (a amqpAcknowledger) Nack(requeue bool) error {
+	return a.msg.Nack(false, requeue)
 }
 
 type RabbitMQHeaderCarrier map[string]any
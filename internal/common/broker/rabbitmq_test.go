@@ -0,0 +1,86 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingMetrics 记录每个 key 累加的值
+type countingMetrics struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func newCountingMetrics() *countingMetrics {
+	return &countingMetrics{counts: make(map[string]int)}
+}
+
+func (m *countingMetrics) Inc(key string, value int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counts[key] += value
+}
+
+func (m *countingMetrics) get(key string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.counts[key]
+}
+
+func TestRabbitMQ_RecordPublish(t *testing.T) {
+	metrics := newCountingMetrics()
+	r := &RabbitMQ{metricsClient: metrics}
+
+	r.recordPublish(EventOrderPaid, 12*time.Millisecond, nil)
+	r.recordPublish(EventOrderPaid, 0, NackError{Exchange: EventOrderPaid})
+	r.recordPublish(EventOrderPaid, 0, fmt.Errorf("publish event: %w", ReturnError{Exchange: EventOrderPaid}))
+	r.recordPublish(EventOrderPaid, 0, fmt.Errorf("exchange=%s: %w", EventOrderPaid, ErrConfirmTimeout))
+	r.recordPublish("", 0, ErrNotConnected)
+
+	prefix := "rabbitmq.publish." + EventOrderPaid + "."
+	assert.Equal(t, 1, metrics.get(prefix+"confirmed"))
+	assert.Equal(t, 12, metrics.get(prefix+"confirm_latency_ms"))
+	assert.Equal(t, 1, metrics.get(prefix+"nacked"))
+	assert.Equal(t, 1, metrics.get(prefix+"returned"))
+	assert.Equal(t, 1, metrics.get(prefix+"confirm_timeout"))
+	assert.Equal(t, 1, metrics.get("rabbitmq.publish.default.failure"))
+}
+
+// TestRabbitMQ_Disconnected 连接不上 RabbitMQ 时不阻塞启动，发布立即失败，订阅等待重连直到 Close
+func TestRabbitMQ_Disconnected(t *testing.T) {
+	metrics := newCountingMetrics()
+	// 1 号端口没有服务监听，连接立即被拒绝
+	mq, closeConn := Connect("guest", "guest", "127.0.0.1", "1", metrics)
+
+	err := mq.Health()
+	require.ErrorIs(t, err, ErrNotConnected)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err = mq.Publish(ctx, &Publishing{Exchange: EventOrderPaid, Body: []byte("1")})
+	assert.ErrorIs(t, err, ErrNotConnected)
+	assert.NoError(t, ctx.Err(), "publish should fail fast while disconnected")
+	assert.Equal(t, 1, metrics.get("rabbitmq.publish."+EventOrderPaid+".failure"))
+
+	msgs, err := mq.Subscribe(context.Background(), Subscription{Queue: testQueue})
+	require.NoError(t, err)
+
+	require.NoError(t, closeConn())
+	require.NoError(t, closeConn(), "close should be idempotent")
+
+	select {
+	case _, ok := <-msgs:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		require.FailNow(t, "subscription not closed after Close")
+	}
+
+	_, err = mq.Subscribe(context.Background(), Subscription{Queue: testQueue})
+	assert.ErrorIs(t, err, ErrBrokerClosed)
+}
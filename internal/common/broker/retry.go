@@ -0,0 +1,177 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/logging"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+)
+
+const amqpRetryHeaderKey = "x-retry-count"
+
+var (
+	// ErrMaxRetryExceeded 消息重试次数超过 RetryPolicy.MaxRetries，已被移入死信队列
+	ErrMaxRetryExceeded = errors.New("max retry count exceeded, message moved to dlq")
+	// ErrRetryNotScheduled 重试或移入死信队列的消息发布失败，原消息需要重新入队
+	ErrRetryNotScheduled = errors.New("retry not scheduled")
+)
+
+// RetryPolicy 消费失败的消息的重试策略，第 n 次重试在 min(BaseDelay*2^(n-1), MaxDelay) 后重新投递到原来的队列，
+// 零值字段使用 DefaultRetryPolicy 中的值
+type RetryPolicy struct {
+	MaxRetries int           `mapstructure:"max-retries"`
+	BaseDelay  time.Duration `mapstructure:"base-delay"`
+	MaxDelay   time.Duration `mapstructure:"max-delay"`
+}
+
+// DefaultRetryPolicy 最多重试 rabbitmq.max-retry 次，重试间隔从 1s 开始翻倍，最长 30s
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: viper.GetInt("rabbitmq.max-retry"),
+		BaseDelay:  time.Second,
+		MaxDelay:   30 * time.Second,
+	}
+}
+
+// RetryPolicyFromConfig 读取 key 下的重试策略，未配置的字段使用默认值
+func RetryPolicyFromConfig(key string) (RetryPolicy, error) {
+	var p RetryPolicy
+	if err := viper.UnmarshalKey(key, &p); err != nil {
+		return RetryPolicy{}, fmt.Errorf("parse retry policy %s: %w", key, err)
+	}
+	return p.withDefaults(), nil
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	d := DefaultRetryPolicy()
+	if p.MaxRetries <= 0 {
+		p.MaxRetries = d.MaxRetries
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = d.BaseDelay
+	}
+	if p.MaxDelay < p.BaseDelay {
+		p.MaxDelay = max(d.MaxDelay, p.BaseDelay)
+	}
+	return p
+}
+
+// delay 第 retryCount 次重试前等待的时间
+func (p RetryPolicy) delay(retryCount int64) time.Duration {
+	backoff := float64(p.BaseDelay) * math.Pow(2, float64(retryCount-1))
+	if backoff >= float64(p.MaxDelay) {
+		return p.MaxDelay
+	}
+	return time.Duration(backoff)
+}
+
+// HandlerRetry 将处理失败的消息延迟后重新投递到收到消息的队列，等待期间不阻塞消费者。
+// 超过最大重试次数时移入 DLQ 并返回 ErrMaxRetryExceeded，发布失败时返回 ErrRetryNotScheduled，
+// 调用方使用 Settle 确认原消息
+func HandlerRetry(ctx context.Context, publisher Publisher, d *Delivery, policy RetryPolicy) (err error) {
+	policy = policy.withDefaults()
+	l, deferlog := logging.WhenRequest(ctx, "HandleRetry", map[string]any{
+		"delivery":        d,
+		"max_retry_count": policy.MaxRetries,
+	})
+	defer func() {
+		deferlog(nil, &err)
+	}()
+
+	log.Info().Ctx(ctx).
+		Any("delivery", d).
+		Msg("handle_retry_start")
+	if d.Headers == nil {
+		d.Headers = make(map[string]any)
+	}
+
+	retryCount := retryCountOf(d.Headers) + 1
+	d.Headers[amqpRetryHeaderKey] = retryCount
+	l = l.With().Int64("retry_count", retryCount).Logger()
+
+	if retryCount > int64(policy.MaxRetries) {
+		l.Info().Ctx(ctx).Str("message_id", d.MessageID).Msg("moving message to dlq")
+		if err = doPublish(ctx, publisher, &Publishing{
+			Key:       DLQ,
+			Mandatory: true,
+			Headers:   d.Headers,
+			Body:      d.Body,
+		}); err != nil {
+			return fmt.Errorf("%w: publish to dlq: %w", ErrRetryNotScheduled, err)
+		}
+		return ErrMaxRetryExceeded
+	}
+
+	delay := policy.delay(retryCount)
+	l.Debug().Ctx(ctx).Str("message_id", d.MessageID).Dur("delay", delay).Msg("retrying message")
+	if err = doPublish(ctx, publisher, &Publishing{
+		Key:       d.Queue,
+		Delay:     delay,
+		Mandatory: true,
+		Headers:   d.Headers,
+		Body:      d.Body,
+	}); err != nil {
+		return fmt.Errorf("%w: %w", ErrRetryNotScheduled, err)
+	}
+	return nil
+}
+
+// Redeliver 将暂时还不能处理的消息在 delay 后重新投递到收到消息的队列，不计入重试次数，等待期间不占用消费者的预取额度。
+// 发布失败时返回 ErrRetryNotScheduled，调用方使用 Settle 确认原消息
+func Redeliver(ctx context.Context, publisher Publisher, d *Delivery, delay time.Duration) error {
+	if err := doPublish(ctx, publisher, &Publishing{
+		Key:       d.Queue,
+		Delay:     delay,
+		Mandatory: true,
+		Headers:   d.Headers,
+		Body:      d.Body,
+	}); err != nil {
+		return fmt.Errorf("%w: redeliver: %w", ErrRetryNotScheduled, err)
+	}
+	return nil
+}
+
+// retryCountOf 读取已经重试的次数，RabbitMQ 根据数值大小可能将 header 解码为不同的整数类型
+func retryCountOf(headers map[string]any) int64 {
+	switch v := headers[amqpRetryHeaderKey].(type) {
+	case int64:
+		return v
+	case int32:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int8:
+		return int64(v)
+	case int:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case uint16:
+		return int64(v)
+	case uint8:
+		return int64(v)
+	default:
+		return 0
+	}
+}
+
+// Settle 根据消费结果确认消息：
+//   - 处理成功、已安排重试或已经移入 DLQ 时 Ack
+//   - 重试没有安排成功时 Nack 并重新入队，避免消息丢失
+//   - 其他错误 Nack 丢弃
+func Settle(d *Delivery, err error) error {
+	switch {
+	case err == nil, errors.Is(err, ErrMaxRetryExceeded):
+		return d.Ack()
+	case errors.Is(err, ErrRetryNotScheduled):
+		return d.Nack(true)
+	default:
+		return d.Nack(false)
+	}
+}
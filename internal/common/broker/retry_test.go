@@ -0,0 +1,174 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	p := RetryPolicy{MaxRetries: 5, BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+
+	for retryCount, want := range map[int64]time.Duration{
+		1: time.Second,
+		2: 2 * time.Second,
+		3: 4 * time.Second,
+		4: 5 * time.Second,
+		// 次数很大时不能溢出
+		100: 5 * time.Second,
+	} {
+		assert.Equal(t, want, p.delay(retryCount), "retry %d", retryCount)
+	}
+}
+
+func TestRetryPolicy_WithDefaults(t *testing.T) {
+	d := DefaultRetryPolicy()
+
+	assert.Equal(t, d, RetryPolicy{}.withDefaults())
+	assert.Equal(t,
+		RetryPolicy{MaxRetries: 2, BaseDelay: time.Minute, MaxDelay: time.Minute},
+		RetryPolicy{MaxRetries: 2, BaseDelay: time.Minute}.withDefaults(),
+		"max delay should not be less than base delay",
+	)
+}
+
+func TestRetryCountOf(t *testing.T) {
+	// RabbitMQ 按数值大小将 header 解码为不同的整数类型
+	for _, v := range []any{int64(2), int32(2), int16(2), int8(2), 2, uint8(2)} {
+		assert.Equal(t, int64(2), retryCountOf(map[string]any{amqpRetryHeaderKey: v}), "%T", v)
+	}
+	assert.Equal(t, int64(0), retryCountOf(nil))
+	assert.Equal(t, int64(0), retryCountOf(map[string]any{amqpRetryHeaderKey: "2"}))
+}
+
+func TestHandlerRetry(t *testing.T) {
+	b := NewMemoryBroker()
+	defer b.Close()
+
+	var (
+		ctx    = context.Background()
+		policy = RetryPolicy{MaxRetries: 2, BaseDelay: 20 * time.Millisecond, MaxDelay: 30 * time.Millisecond}
+		msgs   = subscribeTest(t, b, Subscription{Queue: testQueue})
+		dlq    = subscribeTest(t, b, Subscription{Queue: DLQ})
+	)
+	require.NoError(t, b.Publish(ctx, &Publishing{Key: testQueue, Body: []byte("1")}))
+
+	// 前两次失败延迟后重新投递到原队列，第三次失败移入 DLQ
+	d := receive(t, msgs, time.Second)
+	for retry := int64(1); retry <= 2; retry++ {
+		start := time.Now()
+		require.NoError(t, HandlerRetry(ctx, b, d, policy))
+		require.NoError(t, Settle(d, nil))
+
+		d = receive(t, msgs, time.Second)
+		assert.GreaterOrEqual(t, time.Since(start), policy.delay(retry))
+		assert.Equal(t, retry, retryCountOf(d.Headers))
+	}
+
+	err := HandlerRetry(ctx, b, d, policy)
+	require.ErrorIs(t, err, ErrMaxRetryExceeded)
+	require.NoError(t, Settle(d, err))
+
+	dead := receive(t, dlq, time.Second)
+	assert.Equal(t, "1", string(dead.Body))
+	assert.Equal(t, int64(3), retryCountOf(dead.Headers))
+	require.NoError(t, dead.Ack())
+
+	assertNoMessage(t, msgs, 50*time.Millisecond)
+}
+
+type failingPublisher struct{}
+
+func (failingPublisher) Publish(context.Context, *Publishing) error {
+	return ErrNotConnected
+}
+
+func TestHandlerRetry_PublishFailed(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 1, BaseDelay: time.Millisecond}
+
+	err := HandlerRetry(context.Background(), failingPublisher{}, &Delivery{Queue: testQueue}, policy)
+	assert.ErrorIs(t, err, ErrRetryNotScheduled)
+	assert.ErrorIs(t, err, ErrNotConnected)
+
+	// 移入 DLQ 失败时原消息也需要重新入队
+	d := &Delivery{Queue: testQueue, Headers: map[string]any{amqpRetryHeaderKey: int64(1)}}
+	err = HandlerRetry(context.Background(), failingPublisher{}, d, policy)
+	assert.ErrorIs(t, err, ErrRetryNotScheduled)
+	assert.NotErrorIs(t, err, ErrMaxRetryExceeded)
+}
+
+func TestRedeliver(t *testing.T) {
+	b := NewMemoryBroker()
+	defer b.Close()
+
+	var (
+		ctx   = context.Background()
+		delay = 20 * time.Millisecond
+		msgs  = subscribeTest(t, b, Subscription{Queue: testQueue})
+	)
+	require.NoError(t, b.Publish(ctx, &Publishing{Key: testQueue, Body: []byte("1")}))
+
+	// 重新投递不计入重试次数
+	d := receive(t, msgs, time.Second)
+	for range 2 {
+		start := time.Now()
+		require.NoError(t, Redeliver(ctx, b, d, delay))
+		require.NoError(t, Settle(d, nil))
+
+		d = receive(t, msgs, time.Second)
+		assert.GreaterOrEqual(t, time.Since(start), delay)
+		assert.Equal(t, "1", string(d.Body))
+		assert.Equal(t, int64(0), retryCountOf(d.Headers))
+	}
+	require.NoError(t, d.Ack())
+
+	err := Redeliver(ctx, failingPublisher{}, &Delivery{Queue: testQueue}, delay)
+	assert.ErrorIs(t, err, ErrRetryNotScheduled)
+}
+
+// recordingAcknowledger 记录消息的确认结果
+type recordingAcknowledger struct {
+	acked   bool
+	requeue *bool
+}
+
+func (a *recordingAcknowledger) Ack() error {
+	a.acked = true
+	return nil
+}
+
+func (a *recordingAcknowledger) Nack(requeue bool) error {
+	a.requeue = &requeue
+	return nil
+}
+
+func TestSettle(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		err         error
+		wantAck     bool
+		wantRequeue bool
+	}{
+		{name: "success", err: nil, wantAck: true},
+		{name: "moved to dlq", err: ErrMaxRetryExceeded, wantAck: true},
+		{name: "retry not scheduled", err: errors.Join(ErrRetryNotScheduled, ErrNotConnected), wantRequeue: true},
+		{name: "other error", err: errors.New("unmarshal")},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ack := &recordingAcknowledger{}
+			require.NoError(t, Settle(&Delivery{acknowledger: ack}, tc.err))
+
+			assert.Equal(t, tc.wantAck, ack.acked)
+			if tc.wantAck {
+				assert.Nil(t, ack.requeue)
+				return
+			}
+			require.NotNil(t, ack.requeue)
+			assert.Equal(t, tc.wantRequeue, *ack.requeue)
+		})
+	}
+}
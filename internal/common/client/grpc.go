@@ -8,6 +8,7 @@ import (
 
 	"github.com/furutachiKurea/gorder/common/discovery"
 	"github.com/furutachiKurea/gorder/common/genproto/orderpb"
+	"github.com/furutachiKurea/gorder/common/genproto/paymentpb"
 	"github.com/furutachiKurea/gorder/common/genproto/stockpb"
 
 	"github.com/rs/zerolog/log"
@@ -65,6 +66,30 @@ func NewOrderGRPCClient(ctx context.Context) (
 	return orderpb.NewOrderServiceClient(coon), coon.Close, nil
 }
 
+func NewPaymentGRPCClient(ctx context.Context) (
+	client paymentpb.PaymentServiceClient,
+	close func() error,
+	err error,
+) {
+	if !waitForPaymentGRPCClient(viper.GetDuration("dial-grpc-timeout")) {
+		return nil, func() error { return nil }, errors.New("payment grpc not available")
+	}
+
+	grpcAddr, err := discovery.GetServiceAddr(ctx, viper.GetString("payment.service-name"))
+	if err != nil {
+		return nil, func() error { return nil }, err
+	}
+
+	opts := grpcDialOpts()
+
+	coon, err := grpc.NewClient(grpcAddr, opts...)
+	if err != nil {
+		return nil, func() error { return nil }, err
+	}
+
+	return paymentpb.NewPaymentServiceClient(coon), coon.Close, nil
+}
+
 func grpcDialOpts() []grpc.DialOption {
 	return []grpc.DialOption{
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
@@ -82,6 +107,11 @@ func waitForOrderGRPCClient(timeout time.Duration) bool {
 	return waitFor(viper.GetString("order.grpc-addr"), timeout)
 }
 
+func waitForPaymentGRPCClient(timeout time.Duration) bool {
+	log.Info().Str("timeout", timeout.String()).Msg("waiting for payment grpc client")
+	return waitFor(viper.GetString("payment.grpc-addr"), timeout)
+}
+
 // waitFor 尝试连接 addr 直至 timeout
 func waitFor(addr string, timeout time.Duration) bool {
 	portAvailable := make(chan struct{})
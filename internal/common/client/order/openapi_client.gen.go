@@ -96,6 +96,17 @@ type ClientInterface interface {
 
 	// GetCustomerCustomerIdOrdersOrderId request
 	GetCustomerCustomerIdOrdersOrderId(ctx context.Context, customerId string, orderId string, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// PostCustomerCustomerIdOrdersOrderIdPaymentLink request
+	PostCustomerCustomerIdOrdersOrderIdPaymentLink(ctx context.Context, customerId string, orderId string, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetProductsAvailability request
+	GetProductsAvailability(ctx context.Context, params *GetProductsAvailabilityParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// PostProductsAvailabilityCheckWithBody request with any body
+	PostProductsAvailabilityCheckWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	PostProductsAvailabilityCheck(ctx context.Context, body PostProductsAvailabilityCheckJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
 }
 
 func (c *Client) PostCustomerCustomerIdOrdersWithBody(ctx context.Context, customerId string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
@@ -134,6 +145,54 @@ func (c *Client) GetCustomerCustomerIdOrdersOrderId(ctx context.Context, custome
 	return c.Client.Do(req)
 }
 
+func (c *Client) PostCustomerCustomerIdOrdersOrderIdPaymentLink(ctx context.Context, customerId string, orderId string, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostCustomerCustomerIdOrdersOrderIdPaymentLinkRequest(c.Server, customerId, orderId)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetProductsAvailability(ctx context.Context, params *GetProductsAvailabilityParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetProductsAvailabilityRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) PostProductsAvailabilityCheckWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostProductsAvailabilityCheckRequestWithBody(c.Server, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) PostProductsAvailabilityCheck(ctx context.Context, body PostProductsAvailabilityCheckJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostProductsAvailabilityCheckRequest(c.Server, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
 // NewPostCustomerCustomerIdOrdersRequest calls the generic PostCustomerCustomerIdOrders builder with application/json body
 func NewPostCustomerCustomerIdOrdersRequest(server string, customerId string, body PostCustomerCustomerIdOrdersJSONRequestBody) (*http.Request, error) {
 	var bodyReader io.Reader
@@ -222,6 +281,132 @@ func NewGetCustomerCustomerIdOrdersOrderIdRequest(server string, customerId stri
 	return req, nil
 }
 
+// NewPostCustomerCustomerIdOrdersOrderIdPaymentLinkRequest generates requests for PostCustomerCustomerIdOrdersOrderIdPaymentLink
+func NewPostCustomerCustomerIdOrdersOrderIdPaymentLinkRequest(server string, customerId string, orderId string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "customer_id", runtime.ParamLocationPath, customerId)
+	if err != nil {
+		return nil, err
+	}
+
+	var pathParam1 string
+
+	pathParam1, err = runtime.StyleParamWithLocation("simple", false, "order_id", runtime.ParamLocationPath, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/customer/%s/orders/%s/payment-link", pathParam0, pathParam1)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetProductsAvailabilityRequest generates requests for GetProductsAvailability
+func NewGetProductsAvailabilityRequest(server string, params *GetProductsAvailabilityParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/products/availability")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", false, "product_ids", runtime.ParamLocationQuery, params.ProductIds); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewPostProductsAvailabilityCheckRequest calls the generic PostProductsAvailabilityCheck builder with application/json body
+func NewPostProductsAvailabilityCheckRequest(server string, body PostProductsAvailabilityCheckJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewPostProductsAvailabilityCheckRequestWithBody(server, "application/json", bodyReader)
+}
+
+// NewPostProductsAvailabilityCheckRequestWithBody generates requests for PostProductsAvailabilityCheck with any type of body
+func NewPostProductsAvailabilityCheckRequestWithBody(server string, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/products/availability/check")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
 func (c *Client) applyEditors(ctx context.Context, req *http.Request, additionalEditors []RequestEditorFn) error {
 	for _, r := range c.RequestEditors {
 		if err := r(ctx, req); err != nil {
@@ -272,6 +457,17 @@ type ClientWithResponsesInterface interface {
 
 	// GetCustomerCustomerIdOrdersOrderIdWithResponse request
 	GetCustomerCustomerIdOrdersOrderIdWithResponse(ctx context.Context, customerId string, orderId string, reqEditors ...RequestEditorFn) (*GetCustomerCustomerIdOrdersOrderIdResponse, error)
+
+	// PostCustomerCustomerIdOrdersOrderIdPaymentLinkWithResponse request
+	PostCustomerCustomerIdOrdersOrderIdPaymentLinkWithResponse(ctx context.Context, customerId string, orderId string, reqEditors ...RequestEditorFn) (*PostCustomerCustomerIdOrdersOrderIdPaymentLinkResponse, error)
+
+	// GetProductsAvailabilityWithResponse request
+	GetProductsAvailabilityWithResponse(ctx context.Context, params *GetProductsAvailabilityParams, reqEditors ...RequestEditorFn) (*GetProductsAvailabilityResponse, error)
+
+	// PostProductsAvailabilityCheckWithBodyWithResponse request with any body
+	PostProductsAvailabilityCheckWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostProductsAvailabilityCheckResponse, error)
+
+	PostProductsAvailabilityCheckWithResponse(ctx context.Context, body PostProductsAvailabilityCheckJSONRequestBody, reqEditors ...RequestEditorFn) (*PostProductsAvailabilityCheckResponse, error)
 }
 
 type PostCustomerCustomerIdOrdersResponse struct {
@@ -320,6 +516,75 @@ func (r GetCustomerCustomerIdOrdersOrderIdResponse) StatusCode() int {
 	return 0
 }
 
+type PostCustomerCustomerIdOrdersOrderIdPaymentLinkResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Response
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r PostCustomerCustomerIdOrdersOrderIdPaymentLinkResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r PostCustomerCustomerIdOrdersOrderIdPaymentLinkResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetProductsAvailabilityResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Response
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r GetProductsAvailabilityResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetProductsAvailabilityResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type PostProductsAvailabilityCheckResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Response
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r PostProductsAvailabilityCheckResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r PostProductsAvailabilityCheckResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
 // PostCustomerCustomerIdOrdersWithBodyWithResponse request with arbitrary body returning *PostCustomerCustomerIdOrdersResponse
 func (c *ClientWithResponses) PostCustomerCustomerIdOrdersWithBodyWithResponse(ctx context.Context, customerId string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostCustomerCustomerIdOrdersResponse, error) {
 	rsp, err := c.PostCustomerCustomerIdOrdersWithBody(ctx, customerId, contentType, body, reqEditors...)
@@ -346,6 +611,41 @@ func (c *ClientWithResponses) GetCustomerCustomerIdOrdersOrderIdWithResponse(ctx
 	return ParseGetCustomerCustomerIdOrdersOrderIdResponse(rsp)
 }
 
+// PostCustomerCustomerIdOrdersOrderIdPaymentLinkWithResponse request returning *PostCustomerCustomerIdOrdersOrderIdPaymentLinkResponse
+func (c *ClientWithResponses) PostCustomerCustomerIdOrdersOrderIdPaymentLinkWithResponse(ctx context.Context, customerId string, orderId string, reqEditors ...RequestEditorFn) (*PostCustomerCustomerIdOrdersOrderIdPaymentLinkResponse, error) {
+	rsp, err := c.PostCustomerCustomerIdOrdersOrderIdPaymentLink(ctx, customerId, orderId, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostCustomerCustomerIdOrdersOrderIdPaymentLinkResponse(rsp)
+}
+
+// GetProductsAvailabilityWithResponse request returning *GetProductsAvailabilityResponse
+func (c *ClientWithResponses) GetProductsAvailabilityWithResponse(ctx context.Context, params *GetProductsAvailabilityParams, reqEditors ...RequestEditorFn) (*GetProductsAvailabilityResponse, error) {
+	rsp, err := c.GetProductsAvailability(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetProductsAvailabilityResponse(rsp)
+}
+
+// PostProductsAvailabilityCheckWithBodyWithResponse request with arbitrary body returning *PostProductsAvailabilityCheckResponse
+func (c *ClientWithResponses) PostProductsAvailabilityCheckWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostProductsAvailabilityCheckResponse, error) {
+	rsp, err := c.PostProductsAvailabilityCheckWithBody(ctx, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostProductsAvailabilityCheckResponse(rsp)
+}
+
+func (c *ClientWithResponses) PostProductsAvailabilityCheckWithResponse(ctx context.Context, body PostProductsAvailabilityCheckJSONRequestBody, reqEditors ...RequestEditorFn) (*PostProductsAvailabilityCheckResponse, error) {
+	rsp, err := c.PostProductsAvailabilityCheck(ctx, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostProductsAvailabilityCheckResponse(rsp)
+}
+
 // ParsePostCustomerCustomerIdOrdersResponse parses an HTTP response from a PostCustomerCustomerIdOrdersWithResponse call
 func ParsePostCustomerCustomerIdOrdersResponse(rsp *http.Response) (*PostCustomerCustomerIdOrdersResponse, error) {
 	bodyBytes, err := io.ReadAll(rsp.Body)
@@ -411,3 +711,102 @@ func ParseGetCustomerCustomerIdOrdersOrderIdResponse(rsp *http.Response) (*GetCu
 
 	return response, nil
 }
+
+// ParsePostCustomerCustomerIdOrdersOrderIdPaymentLinkResponse parses an HTTP response from a PostCustomerCustomerIdOrdersOrderIdPaymentLinkWithResponse call
+func ParsePostCustomerCustomerIdOrdersOrderIdPaymentLinkResponse(rsp *http.Response) (*PostCustomerCustomerIdOrdersOrderIdPaymentLinkResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &PostCustomerCustomerIdOrdersOrderIdPaymentLinkResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Response
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetProductsAvailabilityResponse parses an HTTP response from a GetProductsAvailabilityWithResponse call
+func ParseGetProductsAvailabilityResponse(rsp *http.Response) (*GetProductsAvailabilityResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetProductsAvailabilityResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Response
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParsePostProductsAvailabilityCheckResponse parses an HTTP response from a PostProductsAvailabilityCheckWithResponse call
+func ParsePostProductsAvailabilityCheckResponse(rsp *http.Response) (*PostProductsAvailabilityCheckResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &PostProductsAvailabilityCheckResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Response
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
@@ -3,10 +3,27 @@
 // Code generated by github.com/oapi-codegen/oapi-codegen/v2 version v2.5.1 DO NOT EDIT.
 package order
 
+// AvailabilityCheckResult defines model for AvailabilityCheckResult.
+type AvailabilityCheckResult struct {
+	Available bool       `json:"available"`
+	Shortages []Shortage `json:"shortages"`
+}
+
+// CheckAvailabilityRequest defines model for CheckAvailabilityRequest.
+type CheckAvailabilityRequest struct {
+	Items []ItemWithQuantity `json:"items"`
+}
+
 // CreateOrderRequest defines model for CreateOrderRequest.
 type CreateOrderRequest struct {
-	CustomerId string             `json:"customer_id"`
-	Items      []ItemWithQuantity `json:"items"`
+	CustomerId string `json:"customer_id"`
+
+	// Express 加急订单，厨房优先制作
+	Express *bool              `json:"express,omitempty"`
+	Items   []ItemWithQuantity `json:"items"`
+
+	// PickupAt 预约取餐时间，unix 秒，必须晚于当前时间，不填时尽快制作
+	PickupAt *int64 `json:"pickup_at,omitempty"`
 }
 
 // Error defines model for Error.
@@ -16,10 +33,11 @@ type Error struct {
 
 // Item defines model for Item.
 type Item struct {
-	Id       string `json:"id"`
-	Name     string `json:"name"`
-	PriceId  string `json:"price_id"`
-	Quantity int64  `json:"quantity"`
+	FulfilmentStatus string `json:"fulfilment_status"`
+	Id               string `json:"id"`
+	Name             string `json:"name"`
+	PriceId          string `json:"price_id"`
+	Quantity         int64  `json:"quantity"`
 }
 
 // ItemWithQuantity defines model for ItemWithQuantity.
@@ -30,11 +48,27 @@ type ItemWithQuantity struct {
 
 // Order defines model for Order.
 type Order struct {
-	CustomerId  string `json:"customer_id"`
+	CustomerId string `json:"customer_id"`
+
+	// EstimatedReadyAt 厨房预计的完成时间，unix 秒，厨房尚未估计时不返回
+	EstimatedReadyAt *int64 `json:"estimated_ready_at,omitempty"`
+
+	// Express 加急订单，厨房优先制作
+	Express     *bool  `json:"express,omitempty"`
 	Id          string `json:"id"`
 	Items       []Item `json:"items"`
 	PaymentLink string `json:"payment_link"`
-	Status      string `json:"status"`
+
+	// PickupAt 预约取餐时间，unix 秒，未预约时不返回
+	PickupAt *int64 `json:"pickup_at,omitempty"`
+	Status   string `json:"status"`
+}
+
+// ProductAvailability defines model for ProductAvailability.
+type ProductAvailability struct {
+	Available int64  `json:"available"`
+	InStock   bool   `json:"in_stock"`
+	ProductId string `json:"product_id"`
 }
 
 // Response defines model for Response.
@@ -45,5 +79,20 @@ type Response struct {
 	TraceId string                 `json:"trace_id"`
 }
 
+// Shortage defines model for Shortage.
+type Shortage struct {
+	Have      int64  `json:"have"`
+	ProductId string `json:"product_id"`
+	Want      int64  `json:"want"`
+}
+
+// GetProductsAvailabilityParams defines parameters for GetProductsAvailability.
+type GetProductsAvailabilityParams struct {
+	ProductIds []string `form:"product_ids" json:"product_ids"`
+}
+
 // PostCustomerCustomerIdOrdersJSONRequestBody defines body for PostCustomerCustomerIdOrders for application/json ContentType.
 type PostCustomerCustomerIdOrdersJSONRequestBody = CreateOrderRequest
+
+// PostProductsAvailabilityCheckJSONRequestBody defines body for PostProductsAvailabilityCheck for application/json ContentType.
+type PostProductsAvailabilityCheckJSONRequestBody = CheckAvailabilityRequest
@@ -8,29 +8,43 @@ const (
 	ErrnoUnknowError = 1
 
 	// param error 1xxx
-	ErrnoBindRequestError     = 1000
-	ErrnoRequestValidateError = 1001
+	ErrnoBindRequestError      = 1000
+	ErrnoRequestValidateError  = 1001
+	ErrnoOrderStatusNotAllowed = 1002
+	ErrnoTooManyRequests       = 1003
 
 	// internal error 2xxx
 	ErrnoInternalError = 2000
+
+	// kitchen capacity error 3xxx
+	ErrnoKitchenPaused = 3000
+	ErrnoKitchenBusy   = 3001
 )
 
 var ErrMsg = map[int]string{
 	ErrnoSuccess:     "success",
 	ErrnoUnknowError: "unknown error",
 
-	ErrnoBindRequestError:     "bind request error",
-	ErrnoRequestValidateError: "request validate error",
+	ErrnoBindRequestError:      "bind request error",
+	ErrnoRequestValidateError:  "request validate error",
+	ErrnoOrderStatusNotAllowed: "order status not allowed",
+	ErrnoTooManyRequests:       "too many requests",
 
 	ErrnoInternalError: "internal error",
+
+	ErrnoKitchenPaused: "kitchen paused, not accepting orders",
+	ErrnoKitchenBusy:   "kitchen busy, try again later",
 }
 
 // HTTPStatus 根据 Errno 返回对应的 HTTP 状态码
 //
 //   - 0 (ErrnoSuccess)     	→ 200
 //   - 1 (ErrnoUnknowError) 	→ 500
+//   - 1003 (ErrnoTooManyRequests) → 429
 //   - 1xxx (param error)   	→ 400
 //   - 2xxx (internal error)	→ 500
+//   - 3000 (ErrnoKitchenPaused) → 503
+//   - 3001 (ErrnoKitchenBusy)   → 429
 //   - default     				→ 400
 func HTTPStatus(errno int) int {
 	switch {
@@ -38,10 +52,16 @@ func HTTPStatus(errno int) int {
 		return http.StatusOK
 	case errno == ErrnoUnknowError:
 		return http.StatusInternalServerError
+	case errno == ErrnoTooManyRequests:
+		return http.StatusTooManyRequests
 	case errno >= 1000 && errno < 2000:
 		return http.StatusBadRequest
 	case errno >= 2000 && errno < 3000:
 		return http.StatusInternalServerError
+	case errno == ErrnoKitchenPaused:
+		return http.StatusServiceUnavailable
+	case errno == ErrnoKitchenBusy:
+		return http.StatusTooManyRequests
 	default:
 		return http.StatusBadRequest
 	}
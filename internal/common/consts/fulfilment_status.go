@@ -0,0 +1,21 @@
+package consts
+
+// FulfilmentStatus 订单商品的履约状态
+type FulfilmentStatus string
+
+const (
+	// FulfilmentInStock 下单时由现货预占
+	FulfilmentInStock FulfilmentStatus = "in_stock"
+	// FulfilmentBackordered 缺货预订，等待补货后按先后顺序分配
+	FulfilmentBackordered FulfilmentStatus = "backordered"
+	// FulfilmentPreordered 预售商品，等待到货日期后分配
+	FulfilmentPreordered FulfilmentStatus = "preordered"
+	// FulfilmentAllocated 缺货预订或预售的商品已在补货后分配到库存
+	FulfilmentAllocated FulfilmentStatus = "allocated"
+)
+
+// IsReserved 商品是否已经占用了实际库存，只有已占用的商品才能在支付后扣减库存
+func (s FulfilmentStatus) IsReserved() bool {
+	// 兼容没有履约状态的历史订单
+	return s == "" || s == FulfilmentInStock || s == FulfilmentAllocated
+}
@@ -0,0 +1,13 @@
+package consts
+
+// KitchenLoadLevel 厨房的负载等级，订单服务据此决定是否接受新订单
+type KitchenLoadLevel string
+
+const (
+	// KitchenOpen 正常接单
+	KitchenOpen KitchenLoadLevel = "open"
+	// KitchenBusy 积压的工单较多，新订单被限流
+	KitchenBusy KitchenLoadLevel = "busy"
+	// KitchenPaused 厨房员工暂停接单，新订单被拒绝
+	KitchenPaused KitchenLoadLevel = "paused"
+)
@@ -6,5 +6,23 @@ const (
 	OrderStatusPending           OrderStatus = "pending"
 	OrderStatusWaitingForPayment OrderStatus = "waiting_for_payment"
 	OrderStatusPaid              OrderStatus = "paid"
-	OrderStatusReady             OrderStatus = "ready"
+	// OrderStatusPreparing 厨房已开始制作
+	OrderStatusPreparing OrderStatus = "preparing"
+	OrderStatusReady     OrderStatus = "ready"
+	// OrderStatusPaymentFailed 支付失败，订单占用的库存已释放
+	OrderStatusPaymentFailed OrderStatus = "payment_failed"
+	// OrderStatusPaymentExpired 支付会话过期未支付，订单占用的库存已释放
+	OrderStatusPaymentExpired OrderStatus = "payment_expired"
+	// OrderStatusKitchenFailed 厨房无法完成制作
+	OrderStatusKitchenFailed OrderStatus = "kitchen_failed"
 )
+
+// IsPaid 订单已经支付，包括厨房开始处理之后的状态
+func (s OrderStatus) IsPaid() bool {
+	switch s {
+	case OrderStatusPaid, OrderStatusPreparing, OrderStatusReady, OrderStatusKitchenFailed:
+		return true
+	default:
+		return false
+	}
+}
@@ -2,6 +2,7 @@ package convertor
 
 import (
 	"fmt"
+	"time"
 
 	oapi "github.com/furutachiKurea/gorder/common/client/order"
 	"github.com/furutachiKurea/gorder/common/consts"
@@ -16,81 +17,111 @@ type OrderConvertor struct{}
 func (c *OrderConvertor) EntityToProto(o *entity.Order) *orderpb.Order {
 	checkNil(o)
 	return &orderpb.Order{
-		Id:          o.ID,
-		CustomerId:  o.CustomerID,
-		Status:      string(o.Status),
-		PaymentLink: o.PaymentLink,
-		Items:       NewItemConvertor().EntitiesToProtos(o.Items),
+		Id:               o.ID,
+		CustomerId:       o.CustomerID,
+		Status:           string(o.Status),
+		PaymentLink:      o.PaymentLink,
+		Items:            NewItemConvertor().EntitiesToProtos(o.Items),
+		Express:          o.Express,
+		PickupAt:         unixOrZero(o.PickupAt),
+		EstimatedReadyAt: unixOrZero(o.EstimatedReadyAt),
 	}
 }
 
 func (c *OrderConvertor) ProtoToEntity(pb *orderpb.Order) *entity.Order {
 	checkNil(pb)
 	return &entity.Order{
-		ID:          pb.Id,
-		CustomerID:  pb.CustomerId,
-		Status:      consts.OrderStatus(pb.Status),
-		PaymentLink: pb.PaymentLink,
-		Items:       NewItemConvertor().ProtosToEntities(pb.Items),
+		ID:               pb.Id,
+		CustomerID:       pb.CustomerId,
+		Status:           consts.OrderStatus(pb.Status),
+		PaymentLink:      pb.PaymentLink,
+		Items:            NewItemConvertor().ProtosToEntities(pb.Items),
+		Express:          pb.Express,
+		PickupAt:         timeOrNil(pb.PickupAt),
+		EstimatedReadyAt: timeOrNil(pb.EstimatedReadyAt),
 	}
 }
 
 func (c *OrderConvertor) EntityToOAPI(o *entity.Order) *oapi.Order {
 	checkNil(o)
-	return &oapi.Order{
+	res := &oapi.Order{
 		Id:          o.ID,
 		CustomerId:  o.CustomerID,
 		Status:      string(o.Status),
 		PaymentLink: o.PaymentLink,
 		Items:       NewItemConvertor().EntitiesToOAPIs(o.Items),
+		Express:     &o.Express,
+	}
+	if o.PickupAt != nil {
+		pickupAt := o.PickupAt.Unix()
+		res.PickupAt = &pickupAt
+	}
+	if o.EstimatedReadyAt != nil {
+		estimatedReadyAt := o.EstimatedReadyAt.Unix()
+		res.EstimatedReadyAt = &estimatedReadyAt
 	}
+	return res
 }
 
 func (c *OrderConvertor) OAPIToEntity(oapi oapi.Order) *entity.Order {
-	return &entity.Order{
+	o := &entity.Order{
 		ID:          oapi.Id,
 		CustomerID:  oapi.CustomerId,
 		Status:      consts.OrderStatus(oapi.Status),
 		PaymentLink: oapi.PaymentLink,
 		Items:       NewItemConvertor().OAPIsToEntities(oapi.Items),
+		Express:     oapi.Express != nil && *oapi.Express,
+	}
+	if oapi.PickupAt != nil {
+		o.PickupAt = timeOrNil(*oapi.PickupAt)
+	}
+	if oapi.EstimatedReadyAt != nil {
+		o.EstimatedReadyAt = timeOrNil(*oapi.EstimatedReadyAt)
 	}
+	return o
 }
 
 type ItemConvertor struct{}
 
 func (c *ItemConvertor) EntityToProto(e *entity.Item) *orderpb.Item {
 	return &orderpb.Item{
-		Id:       e.ID,
-		Name:     e.Name,
-		Quantity: e.Quantity,
-		PriceId:  e.PriceID,
+		Id:               e.ID,
+		Name:             e.Name,
+		Quantity:         e.Quantity,
+		PriceId:          e.PriceID,
+		FulfilmentStatus: string(e.FulfilmentStatus),
+		BackorderId:      e.BackorderID,
 	}
 }
 
 func (c *ItemConvertor) ProtoToEntity(pb *orderpb.Item) *entity.Item {
 	return &entity.Item{
-		ID:       pb.Id,
-		Name:     pb.Name,
-		Quantity: pb.Quantity,
-		PriceID:  pb.PriceId,
+		ID:               pb.Id,
+		Name:             pb.Name,
+		Quantity:         pb.Quantity,
+		PriceID:          pb.PriceId,
+		FulfilmentStatus: consts.FulfilmentStatus(pb.FulfilmentStatus),
+		BackorderID:      pb.BackorderId,
 	}
 }
 
 func (c *ItemConvertor) EntityToOAPI(e *entity.Item) oapi.Item {
 	return oapi.Item{
-		Id:       e.ID,
-		Name:     e.Name,
-		Quantity: e.Quantity,
-		PriceId:  e.PriceID,
+		Id:               e.ID,
+		Name:             e.Name,
+		Quantity:         e.Quantity,
+		PriceId:          e.PriceID,
+		FulfilmentStatus: string(e.FulfilmentStatus),
 	}
 }
 
 func (c *ItemConvertor) OAPIToEntity(api oapi.Item) *entity.Item {
 	return &entity.Item{
-		ID:       api.Id,
-		Name:     api.Name,
-		Quantity: api.Quantity,
-		PriceID:  api.PriceId,
+		ID:               api.Id,
+		Name:             api.Name,
+		Quantity:         api.Quantity,
+		PriceID:          api.PriceId,
+		FulfilmentStatus: consts.FulfilmentStatus(api.FulfilmentStatus),
 	}
 }
 
@@ -193,3 +224,20 @@ func checkNil[T any](o *T) {
 		panic(fmt.Sprintf("can not convert nil %T", o))
 	}
 }
+
+// unixOrZero 将可选的时间转换为 unix 秒，nil 转换为 0
+func unixOrZero(t *time.Time) int64 {
+	if t == nil {
+		return 0
+	}
+	return t.Unix()
+}
+
+// timeOrNil 将 unix 秒转换为可选的时间，0 转换为 nil
+func timeOrNil(sec int64) *time.Time {
+	if sec == 0 {
+		return nil
+	}
+	t := time.Unix(sec, 0)
+	return &t
+}
@@ -93,3 +93,7 @@ func (r *Registry) Discover(ctx context.Context, serviceName string) (ips []stri
 func (r *Registry) HealthCheck(instanceID, _ string) error {
 	return r.client.Agent().UpdateTTL(instanceID, "online", api.HealthPassing)
 }
+
+func (r *Registry) Unhealthy(instanceID, _ string, reason string) error {
+	return r.client.Agent().UpdateTTL(instanceID, reason, api.HealthCritical)
+}
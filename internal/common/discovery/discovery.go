@@ -12,6 +12,8 @@ type Registry interface {
 	DeRegister(ctx context.Context, instanceID, serviceName string) error
 	Discover(ctx context.Context, serviceName string) (ips []string, err error)
 	HealthCheck(instanceID, serviceName string) error
+	// Unhealthy 上报实例不可用，reason 为不可用的原因
+	Unhealthy(instanceID, serviceName, reason string) error
 }
 
 func GenerateInstanceID(serviceName string) string {
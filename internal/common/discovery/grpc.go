@@ -11,7 +11,9 @@ import (
 	"github.com/spf13/viper"
 )
 
-func RegisterToConsul(ctx context.Context, serviceName string) (func() error, error) {
+// RegisterToConsul 将服务注册到 consul 并定时发送心跳，checks 中任一检查失败时上报实例不可用，
+// 客户端不会再发现该实例，直到检查恢复
+func RegisterToConsul(ctx context.Context, serviceName string, checks ...func() error) (func() error, error) {
 	registry, err := consul.New(viper.GetString("consul.addr"))
 	if err != nil {
 		return func() error { return nil }, err
@@ -25,7 +27,17 @@ func RegisterToConsul(ctx context.Context, serviceName string) (func() error, er
 
 	go func() {
 		for {
-			if err := registry.HealthCheck(instanceID, serviceName); err != nil {
+			var err error
+			if reason := failedCheck(checks); reason != nil {
+				err = registry.Unhealthy(instanceID, serviceName, reason.Error())
+			} else {
+				err = registry.HealthCheck(instanceID, serviceName)
+			}
+			if err != nil {
+				// 实例不可用超过 DeregisterCriticalServiceAfter 后被 consul 注销，重新注册
+				err = registry.Register(ctx, instanceID, serviceName, grpcAddr)
+			}
+			if err != nil {
 				log.Panic().Msgf("ho heart beat from %s to registry, err=%v", instanceID, err)
 			}
 
@@ -43,6 +55,16 @@ func RegisterToConsul(ctx context.Context, serviceName string) (func() error, er
 	}, nil
 }
 
+// failedCheck 返回第一个失败的检查的错误，全部通过时返回 nil
+func failedCheck(checks []func() error) error {
+	for _, check := range checks {
+		if err := check(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func GetServiceAddr(ctx context.Context, serviceName string) (string, error) {
 	registry, err := consul.New(viper.GetString("consul.addr"))
 	if err != nil {
@@ -3,15 +3,19 @@ package entity
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/furutachiKurea/gorder/common/consts"
 )
 
 type Item struct {
-	ID       string
-	Name     string
-	Quantity int64
-	PriceID  string
+	ID               string
+	Name             string
+	Quantity         int64
+	PriceID          string
+	FulfilmentStatus consts.FulfilmentStatus
+	// BackorderID 缺货预订或预售时 stock 服务生成的预订记录 ID
+	BackorderID string
 }
 
 func NewItem(id string, name string, quantity int64, priceID string) *Item {
@@ -77,10 +81,60 @@ func (i ItemWithQuantity) validate() error {
 	return fmt.Errorf("item with quantity=%v invalid, invalid fields=[%s]", i, strings.Join(invalidFields, ", "))
 }
 
+// BackorderAllocation 补货后缺货预订被分配到库存的事件内容
+type BackorderAllocation struct {
+	BackorderID string
+	ProductID   string
+	Quantity    int64
+}
+
+// SLABreach 厨房工单未能在 SLA 目标时间内开始或完成制作的事件内容
+type SLABreach struct {
+	TicketID   string
+	OrderID    string
+	CustomerID string
+	// Target 被违反的目标：start 开始制作，ready 完成制作
+	Target     string
+	DueAt      time.Time
+	BreachedAt time.Time
+	Express    bool
+}
+
+// OrderETA 厨房估计的订单完成时间，EstimatedAt 为估计的时间，用于丢弃乱序到达的旧估计
+type OrderETA struct {
+	OrderID          string
+	CustomerID       string
+	EstimatedReadyAt time.Time
+	EstimatedAt      time.Time
+}
+
+// TicketEvent 厨房工单开始制作、完成制作或制作失败的事件内容，Reason 为制作失败的原因
+type TicketEvent struct {
+	TicketID   string
+	OrderID    string
+	CustomerID string
+	Reason     string
+	OccurredAt time.Time
+}
+
+// KitchenLoad 厨房发布的负载，Backlog 为等待中和制作中的工单数量，Reason 为暂停接单的原因
+type KitchenLoad struct {
+	Level       consts.KitchenLoadLevel
+	Backlog     int
+	Reason      string
+	PublishedAt time.Time
+}
+
 type Order struct {
 	ID          string
 	CustomerID  string
 	Status      consts.OrderStatus
 	PaymentLink string
 	Items       []*Item
+	// Express 加急订单，厨房优先制作
+	Express bool
+	// PickupAt 预约取餐时间，为 nil 时尽快制作
+	PickupAt *time.Time
+	// EstimatedReadyAt 厨房估计的完成时间，尚未估计时为 nil
+	EstimatedReadyAt *time.Time
 }
@@ -0,0 +1,718 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        v3.19.6
+// source: kitchenpb/kitchen.proto
+
+package kitchenpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type TicketItem struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Id       string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name     string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Quantity int64                  `protobuf:"varint,3,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	// 制作时间，单位为秒
+	PrepTime      int64 `protobuf:"varint,4,opt,name=prep_time,json=prepTime,proto3" json:"prep_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TicketItem) Reset() {
+	*x = TicketItem{}
+	mi := &file_kitchenpb_kitchen_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TicketItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TicketItem) ProtoMessage() {}
+
+func (x *TicketItem) ProtoReflect() protoreflect.Message {
+	mi := &file_kitchenpb_kitchen_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TicketItem.ProtoReflect.Descriptor instead.
+func (*TicketItem) Descriptor() ([]byte, []int) {
+	return file_kitchenpb_kitchen_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TicketItem) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TicketItem) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TicketItem) GetQuantity() int64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *TicketItem) GetPrepTime() int64 {
+	if x != nil {
+		return x.PrepTime
+	}
+	return 0
+}
+
+type Ticket struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Id         string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	OrderId    string                 `protobuf:"bytes,2,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	CustomerId string                 `protobuf:"bytes,3,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	// queued | cooking | done | failed
+	Status    string        `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	Items     []*TicketItem `protobuf:"bytes,5,rep,name=items,proto3" json:"items,omitempty"`
+	CreatedAt int64         `protobuf:"varint,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt int64         `protobuf:"varint,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	StartedAt int64         `protobuf:"varint,8,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	DoneAt    int64         `protobuf:"varint,9,opt,name=done_at,json=doneAt,proto3" json:"done_at,omitempty"`
+	// 制作失败的原因，仅在 status 为 failed 时有值
+	FailureReason string `protobuf:"bytes,10,opt,name=failure_reason,json=failureReason,proto3" json:"failure_reason,omitempty"`
+	// 加急订单
+	Express bool `protobuf:"varint,11,opt,name=express,proto3" json:"express,omitempty"`
+	// 预约取餐时间，为 0 时尽快制作
+	PickupAt int64 `protobuf:"varint,12,opt,name=pickup_at,json=pickupAt,proto3" json:"pickup_at,omitempty"`
+	// SLA 要求的开始和完成制作时间，为 0 时没有要求
+	StartBy int64 `protobuf:"varint,13,opt,name=start_by,json=startBy,proto3" json:"start_by,omitempty"`
+	ReadyBy int64 `protobuf:"varint,14,opt,name=ready_by,json=readyBy,proto3" json:"ready_by,omitempty"`
+	// 已经违反的 SLA 目标: start | ready
+	SlaBreaches []string `protobuf:"bytes,15,rep,name=sla_breaches,json=slaBreaches,proto3" json:"sla_breaches,omitempty"`
+	// 预计完成时间，为 0 时尚未估计
+	EstimatedReadyAt int64 `protobuf:"varint,16,opt,name=estimated_ready_at,json=estimatedReadyAt,proto3" json:"estimated_ready_at,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Ticket) Reset() {
+	*x = Ticket{}
+	mi := &file_kitchenpb_kitchen_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Ticket) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ticket) ProtoMessage() {}
+
+func (x *Ticket) ProtoReflect() protoreflect.Message {
+	mi := &file_kitchenpb_kitchen_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ticket.ProtoReflect.Descriptor instead.
+func (*Ticket) Descriptor() ([]byte, []int) {
+	return file_kitchenpb_kitchen_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Ticket) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Ticket) GetOrderId() string {
+	if x != nil {
+		return x.OrderId
+	}
+	return ""
+}
+
+func (x *Ticket) GetCustomerId() string {
+	if x != nil {
+		return x.CustomerId
+	}
+	return ""
+}
+
+func (x *Ticket) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Ticket) GetItems() []*TicketItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *Ticket) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *Ticket) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+func (x *Ticket) GetStartedAt() int64 {
+	if x != nil {
+		return x.StartedAt
+	}
+	return 0
+}
+
+func (x *Ticket) GetDoneAt() int64 {
+	if x != nil {
+		return x.DoneAt
+	}
+	return 0
+}
+
+func (x *Ticket) GetFailureReason() string {
+	if x != nil {
+		return x.FailureReason
+	}
+	return ""
+}
+
+func (x *Ticket) GetExpress() bool {
+	if x != nil {
+		return x.Express
+	}
+	return false
+}
+
+func (x *Ticket) GetPickupAt() int64 {
+	if x != nil {
+		return x.PickupAt
+	}
+	return 0
+}
+
+func (x *Ticket) GetStartBy() int64 {
+	if x != nil {
+		return x.StartBy
+	}
+	return 0
+}
+
+func (x *Ticket) GetReadyBy() int64 {
+	if x != nil {
+		return x.ReadyBy
+	}
+	return 0
+}
+
+func (x *Ticket) GetSlaBreaches() []string {
+	if x != nil {
+		return x.SlaBreaches
+	}
+	return nil
+}
+
+func (x *Ticket) GetEstimatedReadyAt() int64 {
+	if x != nil {
+		return x.EstimatedReadyAt
+	}
+	return 0
+}
+
+type ListTicketsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTicketsRequest) Reset() {
+	*x = ListTicketsRequest{}
+	mi := &file_kitchenpb_kitchen_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTicketsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTicketsRequest) ProtoMessage() {}
+
+func (x *ListTicketsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_kitchenpb_kitchen_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTicketsRequest.ProtoReflect.Descriptor instead.
+func (*ListTicketsRequest) Descriptor() ([]byte, []int) {
+	return file_kitchenpb_kitchen_proto_rawDescGZIP(), []int{2}
+}
+
+type ListTicketsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tickets       []*Ticket              `protobuf:"bytes,1,rep,name=tickets,proto3" json:"tickets,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTicketsResponse) Reset() {
+	*x = ListTicketsResponse{}
+	mi := &file_kitchenpb_kitchen_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTicketsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTicketsResponse) ProtoMessage() {}
+
+func (x *ListTicketsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_kitchenpb_kitchen_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTicketsResponse.ProtoReflect.Descriptor instead.
+func (*ListTicketsResponse) Descriptor() ([]byte, []int) {
+	return file_kitchenpb_kitchen_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListTicketsResponse) GetTickets() []*Ticket {
+	if x != nil {
+		return x.Tickets
+	}
+	return nil
+}
+
+// WatchTicketsRequest since 为 0 时先推送所有等待中和制作中的工单，再推送之后的变化
+type WatchTicketsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Since         int64                  `protobuf:"varint,1,opt,name=since,proto3" json:"since,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchTicketsRequest) Reset() {
+	*x = WatchTicketsRequest{}
+	mi := &file_kitchenpb_kitchen_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchTicketsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchTicketsRequest) ProtoMessage() {}
+
+func (x *WatchTicketsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_kitchenpb_kitchen_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchTicketsRequest.ProtoReflect.Descriptor instead.
+func (*WatchTicketsRequest) Descriptor() ([]byte, []int) {
+	return file_kitchenpb_kitchen_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *WatchTicketsRequest) GetSince() int64 {
+	if x != nil {
+		return x.Since
+	}
+	return 0
+}
+
+type BumpTicketRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	TicketId string                 `protobuf:"bytes,1,opt,name=ticket_id,json=ticketId,proto3" json:"ticket_id,omitempty"`
+	// started | ready
+	Status        string `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BumpTicketRequest) Reset() {
+	*x = BumpTicketRequest{}
+	mi := &file_kitchenpb_kitchen_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BumpTicketRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BumpTicketRequest) ProtoMessage() {}
+
+func (x *BumpTicketRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_kitchenpb_kitchen_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BumpTicketRequest.ProtoReflect.Descriptor instead.
+func (*BumpTicketRequest) Descriptor() ([]byte, []int) {
+	return file_kitchenpb_kitchen_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *BumpTicketRequest) GetTicketId() string {
+	if x != nil {
+		return x.TicketId
+	}
+	return ""
+}
+
+func (x *BumpTicketRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type GetLoadRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLoadRequest) Reset() {
+	*x = GetLoadRequest{}
+	mi := &file_kitchenpb_kitchen_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLoadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLoadRequest) ProtoMessage() {}
+
+func (x *GetLoadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_kitchenpb_kitchen_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLoadRequest.ProtoReflect.Descriptor instead.
+func (*GetLoadRequest) Descriptor() ([]byte, []int) {
+	return file_kitchenpb_kitchen_proto_rawDescGZIP(), []int{6}
+}
+
+type PauseIntakeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// true 暂停接单，false 恢复接单
+	Paused bool `protobuf:"varint,1,opt,name=paused,proto3" json:"paused,omitempty"`
+	// 暂停的原因，返回给下单的顾客
+	Reason        string `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PauseIntakeRequest) Reset() {
+	*x = PauseIntakeRequest{}
+	mi := &file_kitchenpb_kitchen_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PauseIntakeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PauseIntakeRequest) ProtoMessage() {}
+
+func (x *PauseIntakeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_kitchenpb_kitchen_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PauseIntakeRequest.ProtoReflect.Descriptor instead.
+func (*PauseIntakeRequest) Descriptor() ([]byte, []int) {
+	return file_kitchenpb_kitchen_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *PauseIntakeRequest) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+func (x *PauseIntakeRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type KitchenLoad struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// open | busy | paused
+	Level string `protobuf:"bytes,1,opt,name=level,proto3" json:"level,omitempty"`
+	// 等待中和制作中的工单数量
+	Backlog int64 `protobuf:"varint,2,opt,name=backlog,proto3" json:"backlog,omitempty"`
+	// 暂停接单的原因，仅在 level 为 paused 时有值
+	Reason        string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KitchenLoad) Reset() {
+	*x = KitchenLoad{}
+	mi := &file_kitchenpb_kitchen_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KitchenLoad) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KitchenLoad) ProtoMessage() {}
+
+func (x *KitchenLoad) ProtoReflect() protoreflect.Message {
+	mi := &file_kitchenpb_kitchen_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KitchenLoad.ProtoReflect.Descriptor instead.
+func (*KitchenLoad) Descriptor() ([]byte, []int) {
+	return file_kitchenpb_kitchen_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *KitchenLoad) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+func (x *KitchenLoad) GetBacklog() int64 {
+	if x != nil {
+		return x.Backlog
+	}
+	return 0
+}
+
+func (x *KitchenLoad) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+var File_kitchenpb_kitchen_proto protoreflect.FileDescriptor
+
+const file_kitchenpb_kitchen_proto_rawDesc = "" +
+	"\n" +
+	"\x17kitchenpb/kitchen.proto\x12\tkitchenpb\"i\n" +
+	"\n" +
+	"TicketItem\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1a\n" +
+	"\bquantity\x18\x03 \x01(\x03R\bquantity\x12\x1b\n" +
+	"\tprep_time\x18\x04 \x01(\x03R\bprepTime\"\xf4\x03\n" +
+	"\x06Ticket\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
+	"\border_id\x18\x02 \x01(\tR\aorderId\x12\x1f\n" +
+	"\vcustomer_id\x18\x03 \x01(\tR\n" +
+	"customerId\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12+\n" +
+	"\x05items\x18\x05 \x03(\v2\x15.kitchenpb.TicketItemR\x05items\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\a \x01(\x03R\tupdatedAt\x12\x1d\n" +
+	"\n" +
+	"started_at\x18\b \x01(\x03R\tstartedAt\x12\x17\n" +
+	"\adone_at\x18\t \x01(\x03R\x06doneAt\x12%\n" +
+	"\x0efailure_reason\x18\n" +
+	" \x01(\tR\rfailureReason\x12\x18\n" +
+	"\aexpress\x18\v \x01(\bR\aexpress\x12\x1b\n" +
+	"\tpickup_at\x18\f \x01(\x03R\bpickupAt\x12\x19\n" +
+	"\bstart_by\x18\r \x01(\x03R\astartBy\x12\x19\n" +
+	"\bready_by\x18\x0e \x01(\x03R\areadyBy\x12!\n" +
+	"\fsla_breaches\x18\x0f \x03(\tR\vslaBreaches\x12,\n" +
+	"\x12estimated_ready_at\x18\x10 \x01(\x03R\x10estimatedReadyAt\"\x14\n" +
+	"\x12ListTicketsRequest\"B\n" +
+	"\x13ListTicketsResponse\x12+\n" +
+	"\atickets\x18\x01 \x03(\v2\x11.kitchenpb.TicketR\atickets\"+\n" +
+	"\x13WatchTicketsRequest\x12\x14\n" +
+	"\x05since\x18\x01 \x01(\x03R\x05since\"H\n" +
+	"\x11BumpTicketRequest\x12\x1b\n" +
+	"\tticket_id\x18\x01 \x01(\tR\bticketId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\"\x10\n" +
+	"\x0eGetLoadRequest\"D\n" +
+	"\x12PauseIntakeRequest\x12\x16\n" +
+	"\x06paused\x18\x01 \x01(\bR\x06paused\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"U\n" +
+	"\vKitchenLoad\x12\x14\n" +
+	"\x05level\x18\x01 \x01(\tR\x05level\x12\x18\n" +
+	"\abacklog\x18\x02 \x01(\x03R\abacklog\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason2\xe6\x02\n" +
+	"\x0eKitchenService\x12L\n" +
+	"\vListTickets\x12\x1d.kitchenpb.ListTicketsRequest\x1a\x1e.kitchenpb.ListTicketsResponse\x12C\n" +
+	"\fWatchTickets\x12\x1e.kitchenpb.WatchTicketsRequest\x1a\x11.kitchenpb.Ticket0\x01\x12=\n" +
+	"\n" +
+	"BumpTicket\x12\x1c.kitchenpb.BumpTicketRequest\x1a\x11.kitchenpb.Ticket\x12<\n" +
+	"\aGetLoad\x12\x19.kitchenpb.GetLoadRequest\x1a\x16.kitchenpb.KitchenLoad\x12D\n" +
+	"\vPauseIntake\x12\x1d.kitchenpb.PauseIntakeRequest\x1a\x16.kitchenpb.KitchenLoadB<Z:github.com/furutachiKurea/gorder/common/genproto/kitchenpbb\x06proto3"
+
+var (
+	file_kitchenpb_kitchen_proto_rawDescOnce sync.Once
+	file_kitchenpb_kitchen_proto_rawDescData []byte
+)
+
+func file_kitchenpb_kitchen_proto_rawDescGZIP() []byte {
+	file_kitchenpb_kitchen_proto_rawDescOnce.Do(func() {
+		file_kitchenpb_kitchen_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_kitchenpb_kitchen_proto_rawDesc), len(file_kitchenpb_kitchen_proto_rawDesc)))
+	})
+	return file_kitchenpb_kitchen_proto_rawDescData
+}
+
+var file_kitchenpb_kitchen_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_kitchenpb_kitchen_proto_goTypes = []any{
+	(*TicketItem)(nil),          // 0: kitchenpb.TicketItem
+	(*Ticket)(nil),              // 1: kitchenpb.Ticket
+	(*ListTicketsRequest)(nil),  // 2: kitchenpb.ListTicketsRequest
+	(*ListTicketsResponse)(nil), // 3: kitchenpb.ListTicketsResponse
+	(*WatchTicketsRequest)(nil), // 4: kitchenpb.WatchTicketsRequest
+	(*BumpTicketRequest)(nil),   // 5: kitchenpb.BumpTicketRequest
+	(*GetLoadRequest)(nil),      // 6: kitchenpb.GetLoadRequest
+	(*PauseIntakeRequest)(nil),  // 7: kitchenpb.PauseIntakeRequest
+	(*KitchenLoad)(nil),         // 8: kitchenpb.KitchenLoad
+}
+var file_kitchenpb_kitchen_proto_depIdxs = []int32{
+	0, // 0: kitchenpb.Ticket.items:type_name -> kitchenpb.TicketItem
+	1, // 1: kitchenpb.ListTicketsResponse.tickets:type_name -> kitchenpb.Ticket
+	2, // 2: kitchenpb.KitchenService.ListTickets:input_type -> kitchenpb.ListTicketsRequest
+	4, // 3: kitchenpb.KitchenService.WatchTickets:input_type -> kitchenpb.WatchTicketsRequest
+	5, // 4: kitchenpb.KitchenService.BumpTicket:input_type -> kitchenpb.BumpTicketRequest
+	6, // 5: kitchenpb.KitchenService.GetLoad:input_type -> kitchenpb.GetLoadRequest
+	7, // 6: kitchenpb.KitchenService.PauseIntake:input_type -> kitchenpb.PauseIntakeRequest
+	3, // 7: kitchenpb.KitchenService.ListTickets:output_type -> kitchenpb.ListTicketsResponse
+	1, // 8: kitchenpb.KitchenService.WatchTickets:output_type -> kitchenpb.Ticket
+	1, // 9: kitchenpb.KitchenService.BumpTicket:output_type -> kitchenpb.Ticket
+	8, // 10: kitchenpb.KitchenService.GetLoad:output_type -> kitchenpb.KitchenLoad
+	8, // 11: kitchenpb.KitchenService.PauseIntake:output_type -> kitchenpb.KitchenLoad
+	7, // [7:12] is the sub-list for method output_type
+	2, // [2:7] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_kitchenpb_kitchen_proto_init() }
+func file_kitchenpb_kitchen_proto_init() {
+	if File_kitchenpb_kitchen_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_kitchenpb_kitchen_proto_rawDesc), len(file_kitchenpb_kitchen_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_kitchenpb_kitchen_proto_goTypes,
+		DependencyIndexes: file_kitchenpb_kitchen_proto_depIdxs,
+		MessageInfos:      file_kitchenpb_kitchen_proto_msgTypes,
+	}.Build()
+	File_kitchenpb_kitchen_proto = out.File
+	file_kitchenpb_kitchen_proto_goTypes = nil
+	file_kitchenpb_kitchen_proto_depIdxs = nil
+}
@@ -0,0 +1,285 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v3.19.6
+// source: kitchenpb/kitchen.proto
+
+package kitchenpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	KitchenService_ListTickets_FullMethodName  = "/kitchenpb.KitchenService/ListTickets"
+	KitchenService_WatchTickets_FullMethodName = "/kitchenpb.KitchenService/WatchTickets"
+	KitchenService_BumpTicket_FullMethodName   = "/kitchenpb.KitchenService/BumpTicket"
+	KitchenService_GetLoad_FullMethodName      = "/kitchenpb.KitchenService/GetLoad"
+	KitchenService_PauseIntake_FullMethodName  = "/kitchenpb.KitchenService/PauseIntake"
+)
+
+// KitchenServiceClient is the client API for KitchenService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type KitchenServiceClient interface {
+	// ListTickets 获取等待中和制作中的工单
+	ListTickets(ctx context.Context, in *ListTicketsRequest, opts ...grpc.CallOption) (*ListTicketsResponse, error)
+	// WatchTickets 持续推送新创建和状态变化的工单，用于厨房显示屏
+	WatchTickets(ctx context.Context, in *WatchTicketsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Ticket], error)
+	// BumpTicket 手动将工单推进到开始制作(started)或制作完成(ready)，制作完成时更新订单状态
+	BumpTicket(ctx context.Context, in *BumpTicketRequest, opts ...grpc.CallOption) (*Ticket, error)
+	// GetLoad 获取厨房当前的负载
+	GetLoad(ctx context.Context, in *GetLoadRequest, opts ...grpc.CallOption) (*KitchenLoad, error)
+	// PauseIntake 暂停或恢复接单，暂停期间订单服务拒绝新订单
+	PauseIntake(ctx context.Context, in *PauseIntakeRequest, opts ...grpc.CallOption) (*KitchenLoad, error)
+}
+
+type kitchenServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewKitchenServiceClient(cc grpc.ClientConnInterface) KitchenServiceClient {
+	return &kitchenServiceClient{cc}
+}
+
+func (c *kitchenServiceClient) ListTickets(ctx context.Context, in *ListTicketsRequest, opts ...grpc.CallOption) (*ListTicketsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTicketsResponse)
+	err := c.cc.Invoke(ctx, KitchenService_ListTickets_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kitchenServiceClient) WatchTickets(ctx context.Context, in *WatchTicketsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Ticket], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &KitchenService_ServiceDesc.Streams[0], KitchenService_WatchTickets_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchTicketsRequest, Ticket]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type KitchenService_WatchTicketsClient = grpc.ServerStreamingClient[Ticket]
+
+func (c *kitchenServiceClient) BumpTicket(ctx context.Context, in *BumpTicketRequest, opts ...grpc.CallOption) (*Ticket, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Ticket)
+	err := c.cc.Invoke(ctx, KitchenService_BumpTicket_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kitchenServiceClient) GetLoad(ctx context.Context, in *GetLoadRequest, opts ...grpc.CallOption) (*KitchenLoad, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(KitchenLoad)
+	err := c.cc.Invoke(ctx, KitchenService_GetLoad_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kitchenServiceClient) PauseIntake(ctx context.Context, in *PauseIntakeRequest, opts ...grpc.CallOption) (*KitchenLoad, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(KitchenLoad)
+	err := c.cc.Invoke(ctx, KitchenService_PauseIntake_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// KitchenServiceServer is the server API for KitchenService service.
+// All implementations should embed UnimplementedKitchenServiceServer
+// for forward compatibility.
+type KitchenServiceServer interface {
+	// ListTickets 获取等待中和制作中的工单
+	ListTickets(context.Context, *ListTicketsRequest) (*ListTicketsResponse, error)
+	// WatchTickets 持续推送新创建和状态变化的工单，用于厨房显示屏
+	WatchTickets(*WatchTicketsRequest, grpc.ServerStreamingServer[Ticket]) error
+	// BumpTicket 手动将工单推进到开始制作(started)或制作完成(ready)，制作完成时更新订单状态
+	BumpTicket(context.Context, *BumpTicketRequest) (*Ticket, error)
+	// GetLoad 获取厨房当前的负载
+	GetLoad(context.Context, *GetLoadRequest) (*KitchenLoad, error)
+	// PauseIntake 暂停或恢复接单，暂停期间订单服务拒绝新订单
+	PauseIntake(context.Context, *PauseIntakeRequest) (*KitchenLoad, error)
+}
+
+// UnimplementedKitchenServiceServer should be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedKitchenServiceServer struct{}
+
+func (UnimplementedKitchenServiceServer) ListTickets(context.Context, *ListTicketsRequest) (*ListTicketsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTickets not implemented")
+}
+func (UnimplementedKitchenServiceServer) WatchTickets(*WatchTicketsRequest, grpc.ServerStreamingServer[Ticket]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchTickets not implemented")
+}
+func (UnimplementedKitchenServiceServer) BumpTicket(context.Context, *BumpTicketRequest) (*Ticket, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BumpTicket not implemented")
+}
+func (UnimplementedKitchenServiceServer) GetLoad(context.Context, *GetLoadRequest) (*KitchenLoad, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLoad not implemented")
+}
+func (UnimplementedKitchenServiceServer) PauseIntake(context.Context, *PauseIntakeRequest) (*KitchenLoad, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PauseIntake not implemented")
+}
+func (UnimplementedKitchenServiceServer) testEmbeddedByValue() {}
+
+// UnsafeKitchenServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to KitchenServiceServer will
+// result in compilation errors.
+type UnsafeKitchenServiceServer interface {
+	mustEmbedUnimplementedKitchenServiceServer()
+}
+
+func RegisterKitchenServiceServer(s grpc.ServiceRegistrar, srv KitchenServiceServer) {
+	// If the following call pancis, it indicates UnimplementedKitchenServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&KitchenService_ServiceDesc, srv)
+}
+
+func _KitchenService_ListTickets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTicketsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KitchenServiceServer).ListTickets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KitchenService_ListTickets_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KitchenServiceServer).ListTickets(ctx, req.(*ListTicketsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KitchenService_WatchTickets_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchTicketsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(KitchenServiceServer).WatchTickets(m, &grpc.GenericServerStream[WatchTicketsRequest, Ticket]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type KitchenService_WatchTicketsServer = grpc.ServerStreamingServer[Ticket]
+
+func _KitchenService_BumpTicket_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BumpTicketRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KitchenServiceServer).BumpTicket(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KitchenService_BumpTicket_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KitchenServiceServer).BumpTicket(ctx, req.(*BumpTicketRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KitchenService_GetLoad_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLoadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KitchenServiceServer).GetLoad(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KitchenService_GetLoad_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KitchenServiceServer).GetLoad(ctx, req.(*GetLoadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KitchenService_PauseIntake_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PauseIntakeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KitchenServiceServer).PauseIntake(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KitchenService_PauseIntake_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KitchenServiceServer).PauseIntake(ctx, req.(*PauseIntakeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// KitchenService_ServiceDesc is the grpc.ServiceDesc for KitchenService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var KitchenService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "kitchenpb.KitchenService",
+	HandlerType: (*KitchenServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListTickets",
+			Handler:    _KitchenService_ListTickets_Handler,
+		},
+		{
+			MethodName: "BumpTicket",
+			Handler:    _KitchenService_BumpTicket_Handler,
+		},
+		{
+			MethodName: "GetLoad",
+			Handler:    _KitchenService_GetLoad_Handler,
+		},
+		{
+			MethodName: "PauseIntake",
+			Handler:    _KitchenService_PauseIntake_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchTickets",
+			Handler:       _KitchenService_WatchTickets_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "kitchenpb/kitchen.proto",
+}
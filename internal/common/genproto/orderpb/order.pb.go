@@ -179,14 +179,20 @@ func (x *ItemWithQuantity) GetQuantity() int64 {
 }
 
 type Order struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	CustomerId    string                 `protobuf:"bytes,2,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
-	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
-	PaymentLink   string                 `protobuf:"bytes,5,opt,name=payment_link,json=paymentLink,proto3" json:"payment_link,omitempty"`
-	Items         []*Item                `protobuf:"bytes,4,rep,name=items,proto3" json:"items,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CustomerId  string                 `protobuf:"bytes,2,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	Status      string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	PaymentLink string                 `protobuf:"bytes,5,opt,name=payment_link,json=paymentLink,proto3" json:"payment_link,omitempty"`
+	Items       []*Item                `protobuf:"bytes,4,rep,name=items,proto3" json:"items,omitempty"`
+	// 加急订单，厨房优先制作
+	Express bool `protobuf:"varint,6,opt,name=express,proto3" json:"express,omitempty"`
+	// 预约取餐时间，unix 秒，为 0 时尽快制作
+	PickupAt int64 `protobuf:"varint,7,opt,name=pickup_at,json=pickupAt,proto3" json:"pickup_at,omitempty"`
+	// 厨房预计的完成时间，unix 秒，为 0 时尚未估计
+	EstimatedReadyAt int64 `protobuf:"varint,8,opt,name=estimated_ready_at,json=estimatedReadyAt,proto3" json:"estimated_ready_at,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *Order) Reset() {
@@ -254,14 +260,37 @@ func (x *Order) GetItems() []*Item {
 	return nil
 }
 
+func (x *Order) GetExpress() bool {
+	if x != nil {
+		return x.Express
+	}
+	return false
+}
+
+func (x *Order) GetPickupAt() int64 {
+	if x != nil {
+		return x.PickupAt
+	}
+	return 0
+}
+
+func (x *Order) GetEstimatedReadyAt() int64 {
+	if x != nil {
+		return x.EstimatedReadyAt
+	}
+	return 0
+}
+
 type Item struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Quantity      int64                  `protobuf:"varint,3,opt,name=quantity,proto3" json:"quantity,omitempty"`
-	PriceId       string                 `protobuf:"bytes,4,opt,name=price_id,json=priceId,proto3" json:"price_id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name             string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Quantity         int64                  `protobuf:"varint,3,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	PriceId          string                 `protobuf:"bytes,4,opt,name=price_id,json=priceId,proto3" json:"price_id,omitempty"`
+	FulfilmentStatus string                 `protobuf:"bytes,5,opt,name=fulfilment_status,json=fulfilmentStatus,proto3" json:"fulfilment_status,omitempty"`
+	BackorderId      string                 `protobuf:"bytes,6,opt,name=backorder_id,json=backorderId,proto3" json:"backorder_id,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *Item) Reset() {
@@ -322,6 +351,20 @@ func (x *Item) GetPriceId() string {
 	return ""
 }
 
+func (x *Item) GetFulfilmentStatus() string {
+	if x != nil {
+		return x.FulfilmentStatus
+	}
+	return ""
+}
+
+func (x *Item) GetBackorderId() string {
+	if x != nil {
+		return x.BackorderId
+	}
+	return ""
+}
+
 var File_orderpb_order_proto protoreflect.FileDescriptor
 
 const file_orderpb_order_proto_rawDesc = "" +
@@ -337,19 +380,24 @@ const file_orderpb_order_proto_rawDesc = "" +
 	"customerId\">\n" +
 	"\x10ItemWithQuantity\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
-	"\bquantity\x18\x02 \x01(\x03R\bquantity\"\x98\x01\n" +
+	"\bquantity\x18\x02 \x01(\x03R\bquantity\"\xfd\x01\n" +
 	"\x05Order\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1f\n" +
 	"\vcustomer_id\x18\x02 \x01(\tR\n" +
 	"customerId\x12\x16\n" +
 	"\x06status\x18\x03 \x01(\tR\x06status\x12!\n" +
 	"\fpayment_link\x18\x05 \x01(\tR\vpaymentLink\x12#\n" +
-	"\x05items\x18\x04 \x03(\v2\r.orderpb.ItemR\x05items\"a\n" +
+	"\x05items\x18\x04 \x03(\v2\r.orderpb.ItemR\x05items\x12\x18\n" +
+	"\aexpress\x18\x06 \x01(\bR\aexpress\x12\x1b\n" +
+	"\tpickup_at\x18\a \x01(\x03R\bpickupAt\x12,\n" +
+	"\x12estimated_ready_at\x18\b \x01(\x03R\x10estimatedReadyAt\"\xb1\x01\n" +
 	"\x04Item\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1a\n" +
 	"\bquantity\x18\x03 \x01(\x03R\bquantity\x12\x19\n" +
-	"\bprice_id\x18\x04 \x01(\tR\apriceId2\xbf\x01\n" +
+	"\bprice_id\x18\x04 \x01(\tR\apriceId\x12+\n" +
+	"\x11fulfilment_status\x18\x05 \x01(\tR\x10fulfilmentStatus\x12!\n" +
+	"\fbackorder_id\x18\x06 \x01(\tR\vbackorderId2\xbf\x01\n" +
 	"\fOrderService\x12B\n" +
 	"\vCreateOrder\x12\x1b.orderpb.CreateOrderRequest\x1a\x16.google.protobuf.Empty\x124\n" +
 	"\bGetOrder\x12\x18.orderpb.GetOrderRequest\x1a\x0e.orderpb.Order\x125\n" +
@@ -0,0 +1,487 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        v3.19.6
+// source: paymentpb/payment.proto
+
+package paymentpb
+
+import (
+	orderpb "github.com/furutachiKurea/gorder/common/genproto/orderpb"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// GetPaymentRequest 按支付 ID 或订单 ID 查询，payment_id 优先
+type GetPaymentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PaymentId     string                 `protobuf:"bytes,1,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
+	OrderId       string                 `protobuf:"bytes,2,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPaymentRequest) Reset() {
+	*x = GetPaymentRequest{}
+	mi := &file_paymentpb_payment_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPaymentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPaymentRequest) ProtoMessage() {}
+
+func (x *GetPaymentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_paymentpb_payment_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPaymentRequest.ProtoReflect.Descriptor instead.
+func (*GetPaymentRequest) Descriptor() ([]byte, []int) {
+	return file_paymentpb_payment_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetPaymentRequest) GetPaymentId() string {
+	if x != nil {
+		return x.PaymentId
+	}
+	return ""
+}
+
+func (x *GetPaymentRequest) GetOrderId() string {
+	if x != nil {
+		return x.OrderId
+	}
+	return ""
+}
+
+type Payment struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Id                string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	OrderId           string                 `protobuf:"bytes,2,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	CustomerId        string                 `protobuf:"bytes,3,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	Provider          string                 `protobuf:"bytes,4,opt,name=provider,proto3" json:"provider,omitempty"`
+	ProviderSessionId string                 `protobuf:"bytes,5,opt,name=provider_session_id,json=providerSessionId,proto3" json:"provider_session_id,omitempty"`
+	ProviderIntentId  string                 `protobuf:"bytes,6,opt,name=provider_intent_id,json=providerIntentId,proto3" json:"provider_intent_id,omitempty"`
+	// 以货币最小单位表示的金额
+	Amount      int64           `protobuf:"varint,7,opt,name=amount,proto3" json:"amount,omitempty"`
+	Currency    string          `protobuf:"bytes,8,opt,name=currency,proto3" json:"currency,omitempty"`
+	Status      string          `protobuf:"bytes,9,opt,name=status,proto3" json:"status,omitempty"`
+	PaymentLink string          `protobuf:"bytes,10,opt,name=payment_link,json=paymentLink,proto3" json:"payment_link,omitempty"`
+	Items       []*orderpb.Item `protobuf:"bytes,11,rep,name=items,proto3" json:"items,omitempty"`
+	CreatedAt   int64           `protobuf:"varint,12,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt   int64           `protobuf:"varint,13,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	PaidAt      int64           `protobuf:"varint,14,opt,name=paid_at,json=paidAt,proto3" json:"paid_at,omitempty"`
+	// 支付失败的原因，仅在 status 为 failed 时有值
+	FailureReason string `protobuf:"bytes,15,opt,name=failure_reason,json=failureReason,proto3" json:"failure_reason,omitempty"`
+	// 由礼品卡和商店余额抵扣的金额，amount 为支付渠道收取的剩余金额
+	Credit        int64 `protobuf:"varint,16,opt,name=credit,proto3" json:"credit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Payment) Reset() {
+	*x = Payment{}
+	mi := &file_paymentpb_payment_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Payment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Payment) ProtoMessage() {}
+
+func (x *Payment) ProtoReflect() protoreflect.Message {
+	mi := &file_paymentpb_payment_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Payment.ProtoReflect.Descriptor instead.
+func (*Payment) Descriptor() ([]byte, []int) {
+	return file_paymentpb_payment_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Payment) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Payment) GetOrderId() string {
+	if x != nil {
+		return x.OrderId
+	}
+	return ""
+}
+
+func (x *Payment) GetCustomerId() string {
+	if x != nil {
+		return x.CustomerId
+	}
+	return ""
+}
+
+func (x *Payment) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *Payment) GetProviderSessionId() string {
+	if x != nil {
+		return x.ProviderSessionId
+	}
+	return ""
+}
+
+func (x *Payment) GetProviderIntentId() string {
+	if x != nil {
+		return x.ProviderIntentId
+	}
+	return ""
+}
+
+func (x *Payment) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Payment) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *Payment) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Payment) GetPaymentLink() string {
+	if x != nil {
+		return x.PaymentLink
+	}
+	return ""
+}
+
+func (x *Payment) GetItems() []*orderpb.Item {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *Payment) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *Payment) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+func (x *Payment) GetPaidAt() int64 {
+	if x != nil {
+		return x.PaidAt
+	}
+	return 0
+}
+
+func (x *Payment) GetFailureReason() string {
+	if x != nil {
+		return x.FailureReason
+	}
+	return ""
+}
+
+func (x *Payment) GetCredit() int64 {
+	if x != nil {
+		return x.Credit
+	}
+	return 0
+}
+
+type GetPaymentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Payments      []*Payment             `protobuf:"bytes,1,rep,name=payments,proto3" json:"payments,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPaymentResponse) Reset() {
+	*x = GetPaymentResponse{}
+	mi := &file_paymentpb_payment_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPaymentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPaymentResponse) ProtoMessage() {}
+
+func (x *GetPaymentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_paymentpb_payment_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPaymentResponse.ProtoReflect.Descriptor instead.
+func (*GetPaymentResponse) Descriptor() ([]byte, []int) {
+	return file_paymentpb_payment_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetPaymentResponse) GetPayments() []*Payment {
+	if x != nil {
+		return x.Payments
+	}
+	return nil
+}
+
+type RegeneratePaymentLinkRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Order         *orderpb.Order         `protobuf:"bytes,1,opt,name=order,proto3" json:"order,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegeneratePaymentLinkRequest) Reset() {
+	*x = RegeneratePaymentLinkRequest{}
+	mi := &file_paymentpb_payment_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegeneratePaymentLinkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegeneratePaymentLinkRequest) ProtoMessage() {}
+
+func (x *RegeneratePaymentLinkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_paymentpb_payment_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegeneratePaymentLinkRequest.ProtoReflect.Descriptor instead.
+func (*RegeneratePaymentLinkRequest) Descriptor() ([]byte, []int) {
+	return file_paymentpb_payment_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *RegeneratePaymentLinkRequest) GetOrder() *orderpb.Order {
+	if x != nil {
+		return x.Order
+	}
+	return nil
+}
+
+type RegeneratePaymentLinkResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PaymentId     string                 `protobuf:"bytes,1,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
+	PaymentLink   string                 `protobuf:"bytes,2,opt,name=payment_link,json=paymentLink,proto3" json:"payment_link,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegeneratePaymentLinkResponse) Reset() {
+	*x = RegeneratePaymentLinkResponse{}
+	mi := &file_paymentpb_payment_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegeneratePaymentLinkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegeneratePaymentLinkResponse) ProtoMessage() {}
+
+func (x *RegeneratePaymentLinkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_paymentpb_payment_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegeneratePaymentLinkResponse.ProtoReflect.Descriptor instead.
+func (*RegeneratePaymentLinkResponse) Descriptor() ([]byte, []int) {
+	return file_paymentpb_payment_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *RegeneratePaymentLinkResponse) GetPaymentId() string {
+	if x != nil {
+		return x.PaymentId
+	}
+	return ""
+}
+
+func (x *RegeneratePaymentLinkResponse) GetPaymentLink() string {
+	if x != nil {
+		return x.PaymentLink
+	}
+	return ""
+}
+
+var File_paymentpb_payment_proto protoreflect.FileDescriptor
+
+const file_paymentpb_payment_proto_rawDesc = "" +
+	"\n" +
+	"\x17paymentpb/payment.proto\x12\tpaymentpb\x1a\x13orderpb/order.proto\"M\n" +
+	"\x11GetPaymentRequest\x12\x1d\n" +
+	"\n" +
+	"payment_id\x18\x01 \x01(\tR\tpaymentId\x12\x19\n" +
+	"\border_id\x18\x02 \x01(\tR\aorderId\"\xf9\x03\n" +
+	"\aPayment\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
+	"\border_id\x18\x02 \x01(\tR\aorderId\x12\x1f\n" +
+	"\vcustomer_id\x18\x03 \x01(\tR\n" +
+	"customerId\x12\x1a\n" +
+	"\bprovider\x18\x04 \x01(\tR\bprovider\x12.\n" +
+	"\x13provider_session_id\x18\x05 \x01(\tR\x11providerSessionId\x12,\n" +
+	"\x12provider_intent_id\x18\x06 \x01(\tR\x10providerIntentId\x12\x16\n" +
+	"\x06amount\x18\a \x01(\x03R\x06amount\x12\x1a\n" +
+	"\bcurrency\x18\b \x01(\tR\bcurrency\x12\x16\n" +
+	"\x06status\x18\t \x01(\tR\x06status\x12!\n" +
+	"\fpayment_link\x18\n" +
+	" \x01(\tR\vpaymentLink\x12#\n" +
+	"\x05items\x18\v \x03(\v2\r.orderpb.ItemR\x05items\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\f \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\r \x01(\x03R\tupdatedAt\x12\x17\n" +
+	"\apaid_at\x18\x0e \x01(\x03R\x06paidAt\x12%\n" +
+	"\x0efailure_reason\x18\x0f \x01(\tR\rfailureReason\x12\x16\n" +
+	"\x06credit\x18\x10 \x01(\x03R\x06credit\"D\n" +
+	"\x12GetPaymentResponse\x12.\n" +
+	"\bpayments\x18\x01 \x03(\v2\x12.paymentpb.PaymentR\bpayments\"D\n" +
+	"\x1cRegeneratePaymentLinkRequest\x12$\n" +
+	"\x05order\x18\x01 \x01(\v2\x0e.orderpb.OrderR\x05order\"a\n" +
+	"\x1dRegeneratePaymentLinkResponse\x12\x1d\n" +
+	"\n" +
+	"payment_id\x18\x01 \x01(\tR\tpaymentId\x12!\n" +
+	"\fpayment_link\x18\x02 \x01(\tR\vpaymentLink2\xc7\x01\n" +
+	"\x0ePaymentService\x12I\n" +
+	"\n" +
+	"GetPayment\x12\x1c.paymentpb.GetPaymentRequest\x1a\x1d.paymentpb.GetPaymentResponse\x12j\n" +
+	"\x15RegeneratePaymentLink\x12'.paymentpb.RegeneratePaymentLinkRequest\x1a(.paymentpb.RegeneratePaymentLinkResponseB<Z:github.com/furutachiKurea/gorder/common/genproto/paymentpbb\x06proto3"
+
+var (
+	file_paymentpb_payment_proto_rawDescOnce sync.Once
+	file_paymentpb_payment_proto_rawDescData []byte
+)
+
+func file_paymentpb_payment_proto_rawDescGZIP() []byte {
+	file_paymentpb_payment_proto_rawDescOnce.Do(func() {
+		file_paymentpb_payment_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_paymentpb_payment_proto_rawDesc), len(file_paymentpb_payment_proto_rawDesc)))
+	})
+	return file_paymentpb_payment_proto_rawDescData
+}
+
+var file_paymentpb_payment_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_paymentpb_payment_proto_goTypes = []any{
+	(*GetPaymentRequest)(nil),             // 0: paymentpb.GetPaymentRequest
+	(*Payment)(nil),                       // 1: paymentpb.Payment
+	(*GetPaymentResponse)(nil),            // 2: paymentpb.GetPaymentResponse
+	(*RegeneratePaymentLinkRequest)(nil),  // 3: paymentpb.RegeneratePaymentLinkRequest
+	(*RegeneratePaymentLinkResponse)(nil), // 4: paymentpb.RegeneratePaymentLinkResponse
+	(*orderpb.Item)(nil),                  // 5: orderpb.Item
+	(*orderpb.Order)(nil),                 // 6: orderpb.Order
+}
+var file_paymentpb_payment_proto_depIdxs = []int32{
+	5, // 0: paymentpb.Payment.items:type_name -> orderpb.Item
+	1, // 1: paymentpb.GetPaymentResponse.payments:type_name -> paymentpb.Payment
+	6, // 2: paymentpb.RegeneratePaymentLinkRequest.order:type_name -> orderpb.Order
+	0, // 3: paymentpb.PaymentService.GetPayment:input_type -> paymentpb.GetPaymentRequest
+	3, // 4: paymentpb.PaymentService.RegeneratePaymentLink:input_type -> paymentpb.RegeneratePaymentLinkRequest
+	2, // 5: paymentpb.PaymentService.GetPayment:output_type -> paymentpb.GetPaymentResponse
+	4, // 6: paymentpb.PaymentService.RegeneratePaymentLink:output_type -> paymentpb.RegeneratePaymentLinkResponse
+	5, // [5:7] is the sub-list for method output_type
+	3, // [3:5] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_paymentpb_payment_proto_init() }
+func file_paymentpb_payment_proto_init() {
+	if File_paymentpb_payment_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_paymentpb_payment_proto_rawDesc), len(file_paymentpb_payment_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_paymentpb_payment_proto_goTypes,
+		DependencyIndexes: file_paymentpb_payment_proto_depIdxs,
+		MessageInfos:      file_paymentpb_payment_proto_msgTypes,
+	}.Build()
+	File_paymentpb_payment_proto = out.File
+	file_paymentpb_payment_proto_goTypes = nil
+	file_paymentpb_payment_proto_depIdxs = nil
+}
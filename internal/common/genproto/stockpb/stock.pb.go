@@ -110,6 +110,351 @@ func (x *GetItemsResponse) GetItems() []*orderpb.Item {
 	return nil
 }
 
+type GetStockRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductIds    []string               `protobuf:"bytes,1,rep,name=product_ids,json=productIds,proto3" json:"product_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStockRequest) Reset() {
+	*x = GetStockRequest{}
+	mi := &file_stockpb_stock_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStockRequest) ProtoMessage() {}
+
+func (x *GetStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stockpb_stock_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStockRequest.ProtoReflect.Descriptor instead.
+func (*GetStockRequest) Descriptor() ([]byte, []int) {
+	return file_stockpb_stock_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetStockRequest) GetProductIds() []string {
+	if x != nil {
+		return x.ProductIds
+	}
+	return nil
+}
+
+type StockLevel struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ProductId      string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Quantity       int64                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Reserved       int64                  `protobuf:"varint,3,opt,name=reserved,proto3" json:"reserved,omitempty"`
+	Available      int64                  `protobuf:"varint,4,opt,name=available,proto3" json:"available,omitempty"`
+	Backordered    int64                  `protobuf:"varint,5,opt,name=backordered,proto3" json:"backordered,omitempty"`
+	Policy         string                 `protobuf:"bytes,6,opt,name=policy,proto3" json:"policy,omitempty"`
+	BackorderLimit int64                  `protobuf:"varint,7,opt,name=backorder_limit,json=backorderLimit,proto3" json:"backorder_limit,omitempty"`
+	// RFC3339 格式的预售到货日期，非预售商品为空
+	AvailableAt   string `protobuf:"bytes,8,opt,name=available_at,json=availableAt,proto3" json:"available_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StockLevel) Reset() {
+	*x = StockLevel{}
+	mi := &file_stockpb_stock_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StockLevel) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StockLevel) ProtoMessage() {}
+
+func (x *StockLevel) ProtoReflect() protoreflect.Message {
+	mi := &file_stockpb_stock_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StockLevel.ProtoReflect.Descriptor instead.
+func (*StockLevel) Descriptor() ([]byte, []int) {
+	return file_stockpb_stock_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *StockLevel) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *StockLevel) GetQuantity() int64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *StockLevel) GetReserved() int64 {
+	if x != nil {
+		return x.Reserved
+	}
+	return 0
+}
+
+func (x *StockLevel) GetAvailable() int64 {
+	if x != nil {
+		return x.Available
+	}
+	return 0
+}
+
+func (x *StockLevel) GetBackordered() int64 {
+	if x != nil {
+		return x.Backordered
+	}
+	return 0
+}
+
+func (x *StockLevel) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+func (x *StockLevel) GetBackorderLimit() int64 {
+	if x != nil {
+		return x.BackorderLimit
+	}
+	return 0
+}
+
+func (x *StockLevel) GetAvailableAt() string {
+	if x != nil {
+		return x.AvailableAt
+	}
+	return ""
+}
+
+type GetStockResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stocks        []*StockLevel          `protobuf:"bytes,1,rep,name=stocks,proto3" json:"stocks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStockResponse) Reset() {
+	*x = GetStockResponse{}
+	mi := &file_stockpb_stock_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStockResponse) ProtoMessage() {}
+
+func (x *GetStockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_stockpb_stock_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStockResponse.ProtoReflect.Descriptor instead.
+func (*GetStockResponse) Descriptor() ([]byte, []int) {
+	return file_stockpb_stock_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetStockResponse) GetStocks() []*StockLevel {
+	if x != nil {
+		return x.Stocks
+	}
+	return nil
+}
+
+type CheckAvailabilityRequest struct {
+	state         protoimpl.MessageState      `protogen:"open.v1"`
+	Items         []*orderpb.ItemWithQuantity `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckAvailabilityRequest) Reset() {
+	*x = CheckAvailabilityRequest{}
+	mi := &file_stockpb_stock_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckAvailabilityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckAvailabilityRequest) ProtoMessage() {}
+
+func (x *CheckAvailabilityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stockpb_stock_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckAvailabilityRequest.ProtoReflect.Descriptor instead.
+func (*CheckAvailabilityRequest) Descriptor() ([]byte, []int) {
+	return file_stockpb_stock_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CheckAvailabilityRequest) GetItems() []*orderpb.ItemWithQuantity {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type Shortage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Want          int64                  `protobuf:"varint,2,opt,name=want,proto3" json:"want,omitempty"`
+	Have          int64                  `protobuf:"varint,3,opt,name=have,proto3" json:"have,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Shortage) Reset() {
+	*x = Shortage{}
+	mi := &file_stockpb_stock_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Shortage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Shortage) ProtoMessage() {}
+
+func (x *Shortage) ProtoReflect() protoreflect.Message {
+	mi := &file_stockpb_stock_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Shortage.ProtoReflect.Descriptor instead.
+func (*Shortage) Descriptor() ([]byte, []int) {
+	return file_stockpb_stock_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Shortage) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *Shortage) GetWant() int64 {
+	if x != nil {
+		return x.Want
+	}
+	return 0
+}
+
+func (x *Shortage) GetHave() int64 {
+	if x != nil {
+		return x.Have
+	}
+	return 0
+}
+
+type CheckAvailabilityResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Available     bool                   `protobuf:"varint,1,opt,name=available,proto3" json:"available,omitempty"`
+	Shortages     []*Shortage            `protobuf:"bytes,2,rep,name=shortages,proto3" json:"shortages,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckAvailabilityResponse) Reset() {
+	*x = CheckAvailabilityResponse{}
+	mi := &file_stockpb_stock_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckAvailabilityResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckAvailabilityResponse) ProtoMessage() {}
+
+func (x *CheckAvailabilityResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_stockpb_stock_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckAvailabilityResponse.ProtoReflect.Descriptor instead.
+func (*CheckAvailabilityResponse) Descriptor() ([]byte, []int) {
+	return file_stockpb_stock_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CheckAvailabilityResponse) GetAvailable() bool {
+	if x != nil {
+		return x.Available
+	}
+	return false
+}
+
+func (x *CheckAvailabilityResponse) GetShortages() []*Shortage {
+	if x != nil {
+		return x.Shortages
+	}
+	return nil
+}
+
 type ReserveStockRequest struct {
 	state         protoimpl.MessageState      `protogen:"open.v1"`
 	Items         []*orderpb.ItemWithQuantity `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
@@ -119,7 +464,7 @@ type ReserveStockRequest struct {
 
 func (x *ReserveStockRequest) Reset() {
 	*x = ReserveStockRequest{}
-	mi := &file_stockpb_stock_proto_msgTypes[2]
+	mi := &file_stockpb_stock_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -131,7 +476,7 @@ func (x *ReserveStockRequest) String() string {
 func (*ReserveStockRequest) ProtoMessage() {}
 
 func (x *ReserveStockRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_stockpb_stock_proto_msgTypes[2]
+	mi := &file_stockpb_stock_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -144,7 +489,7 @@ func (x *ReserveStockRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ReserveStockRequest.ProtoReflect.Descriptor instead.
 func (*ReserveStockRequest) Descriptor() ([]byte, []int) {
-	return file_stockpb_stock_proto_rawDescGZIP(), []int{2}
+	return file_stockpb_stock_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *ReserveStockRequest) GetItems() []*orderpb.ItemWithQuantity {
@@ -163,7 +508,7 @@ type ReserveStockResponse struct {
 
 func (x *ReserveStockResponse) Reset() {
 	*x = ReserveStockResponse{}
-	mi := &file_stockpb_stock_proto_msgTypes[3]
+	mi := &file_stockpb_stock_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -175,7 +520,7 @@ func (x *ReserveStockResponse) String() string {
 func (*ReserveStockResponse) ProtoMessage() {}
 
 func (x *ReserveStockResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_stockpb_stock_proto_msgTypes[3]
+	mi := &file_stockpb_stock_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -188,7 +533,7 @@ func (x *ReserveStockResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ReserveStockResponse.ProtoReflect.Descriptor instead.
 func (*ReserveStockResponse) Descriptor() ([]byte, []int) {
-	return file_stockpb_stock_proto_rawDescGZIP(), []int{3}
+	return file_stockpb_stock_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *ReserveStockResponse) GetItems() []*orderpb.Item {
@@ -207,7 +552,7 @@ type ConfirmStockReservationRequest struct {
 
 func (x *ConfirmStockReservationRequest) Reset() {
 	*x = ConfirmStockReservationRequest{}
-	mi := &file_stockpb_stock_proto_msgTypes[4]
+	mi := &file_stockpb_stock_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -219,7 +564,7 @@ func (x *ConfirmStockReservationRequest) String() string {
 func (*ConfirmStockReservationRequest) ProtoMessage() {}
 
 func (x *ConfirmStockReservationRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_stockpb_stock_proto_msgTypes[4]
+	mi := &file_stockpb_stock_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -232,7 +577,7 @@ func (x *ConfirmStockReservationRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ConfirmStockReservationRequest.ProtoReflect.Descriptor instead.
 func (*ConfirmStockReservationRequest) Descriptor() ([]byte, []int) {
-	return file_stockpb_stock_proto_rawDescGZIP(), []int{4}
+	return file_stockpb_stock_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *ConfirmStockReservationRequest) GetItems() []*orderpb.ItemWithQuantity {
@@ -251,7 +596,7 @@ type ConfirmStockReservationResponse struct {
 
 func (x *ConfirmStockReservationResponse) Reset() {
 	*x = ConfirmStockReservationResponse{}
-	mi := &file_stockpb_stock_proto_msgTypes[5]
+	mi := &file_stockpb_stock_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -263,7 +608,7 @@ func (x *ConfirmStockReservationResponse) String() string {
 func (*ConfirmStockReservationResponse) ProtoMessage() {}
 
 func (x *ConfirmStockReservationResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_stockpb_stock_proto_msgTypes[5]
+	mi := &file_stockpb_stock_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -276,7 +621,7 @@ func (x *ConfirmStockReservationResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ConfirmStockReservationResponse.ProtoReflect.Descriptor instead.
 func (*ConfirmStockReservationResponse) Descriptor() ([]byte, []int) {
-	return file_stockpb_stock_proto_rawDescGZIP(), []int{5}
+	return file_stockpb_stock_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *ConfirmStockReservationResponse) GetItems() []*orderpb.Item {
@@ -286,6 +631,358 @@ func (x *ConfirmStockReservationResponse) GetItems() []*orderpb.Item {
 	return nil
 }
 
+type UpdateStockPolicyRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ProductId string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	// deny, backorder 或 preorder
+	Policy         string `protobuf:"bytes,2,opt,name=policy,proto3" json:"policy,omitempty"`
+	BackorderLimit int64  `protobuf:"varint,3,opt,name=backorder_limit,json=backorderLimit,proto3" json:"backorder_limit,omitempty"`
+	// RFC3339 格式的预售到货日期，仅 preorder 需要
+	AvailableAt   string `protobuf:"bytes,4,opt,name=available_at,json=availableAt,proto3" json:"available_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateStockPolicyRequest) Reset() {
+	*x = UpdateStockPolicyRequest{}
+	mi := &file_stockpb_stock_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateStockPolicyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateStockPolicyRequest) ProtoMessage() {}
+
+func (x *UpdateStockPolicyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stockpb_stock_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateStockPolicyRequest.ProtoReflect.Descriptor instead.
+func (*UpdateStockPolicyRequest) Descriptor() ([]byte, []int) {
+	return file_stockpb_stock_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *UpdateStockPolicyRequest) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *UpdateStockPolicyRequest) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+func (x *UpdateStockPolicyRequest) GetBackorderLimit() int64 {
+	if x != nil {
+		return x.BackorderLimit
+	}
+	return 0
+}
+
+func (x *UpdateStockPolicyRequest) GetAvailableAt() string {
+	if x != nil {
+		return x.AvailableAt
+	}
+	return ""
+}
+
+type UpdateStockPolicyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateStockPolicyResponse) Reset() {
+	*x = UpdateStockPolicyResponse{}
+	mi := &file_stockpb_stock_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateStockPolicyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateStockPolicyResponse) ProtoMessage() {}
+
+func (x *UpdateStockPolicyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_stockpb_stock_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateStockPolicyResponse.ProtoReflect.Descriptor instead.
+func (*UpdateStockPolicyResponse) Descriptor() ([]byte, []int) {
+	return file_stockpb_stock_proto_rawDescGZIP(), []int{13}
+}
+
+type RestockRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Quantity      int64                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestockRequest) Reset() {
+	*x = RestockRequest{}
+	mi := &file_stockpb_stock_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestockRequest) ProtoMessage() {}
+
+func (x *RestockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stockpb_stock_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestockRequest.ProtoReflect.Descriptor instead.
+func (*RestockRequest) Descriptor() ([]byte, []int) {
+	return file_stockpb_stock_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RestockRequest) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *RestockRequest) GetQuantity() int64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+type BackorderAllocation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BackorderId   string                 `protobuf:"bytes,1,opt,name=backorder_id,json=backorderId,proto3" json:"backorder_id,omitempty"`
+	ProductId     string                 `protobuf:"bytes,2,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Quantity      int64                  `protobuf:"varint,3,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BackorderAllocation) Reset() {
+	*x = BackorderAllocation{}
+	mi := &file_stockpb_stock_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BackorderAllocation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackorderAllocation) ProtoMessage() {}
+
+func (x *BackorderAllocation) ProtoReflect() protoreflect.Message {
+	mi := &file_stockpb_stock_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackorderAllocation.ProtoReflect.Descriptor instead.
+func (*BackorderAllocation) Descriptor() ([]byte, []int) {
+	return file_stockpb_stock_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *BackorderAllocation) GetBackorderId() string {
+	if x != nil {
+		return x.BackorderId
+	}
+	return ""
+}
+
+func (x *BackorderAllocation) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *BackorderAllocation) GetQuantity() int64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+type RestockResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Allocations   []*BackorderAllocation `protobuf:"bytes,1,rep,name=allocations,proto3" json:"allocations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestockResponse) Reset() {
+	*x = RestockResponse{}
+	mi := &file_stockpb_stock_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestockResponse) ProtoMessage() {}
+
+func (x *RestockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_stockpb_stock_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestockResponse.ProtoReflect.Descriptor instead.
+func (*RestockResponse) Descriptor() ([]byte, []int) {
+	return file_stockpb_stock_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *RestockResponse) GetAllocations() []*BackorderAllocation {
+	if x != nil {
+		return x.Allocations
+	}
+	return nil
+}
+
+// ReleaseStockReservationRequest 释放未支付订单占用的库存，items 为已占用库存的商品，
+// backorder_ids 为订单中的缺货预订和预售，已分配库存的预订会释放其占用的库存
+type ReleaseStockReservationRequest struct {
+	state         protoimpl.MessageState      `protogen:"open.v1"`
+	Items         []*orderpb.ItemWithQuantity `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	BackorderIds  []string                    `protobuf:"bytes,2,rep,name=backorder_ids,json=backorderIds,proto3" json:"backorder_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReleaseStockReservationRequest) Reset() {
+	*x = ReleaseStockReservationRequest{}
+	mi := &file_stockpb_stock_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReleaseStockReservationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReleaseStockReservationRequest) ProtoMessage() {}
+
+func (x *ReleaseStockReservationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stockpb_stock_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReleaseStockReservationRequest.ProtoReflect.Descriptor instead.
+func (*ReleaseStockReservationRequest) Descriptor() ([]byte, []int) {
+	return file_stockpb_stock_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ReleaseStockReservationRequest) GetItems() []*orderpb.ItemWithQuantity {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *ReleaseStockReservationRequest) GetBackorderIds() []string {
+	if x != nil {
+		return x.BackorderIds
+	}
+	return nil
+}
+
+type ReleaseStockReservationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReleaseStockReservationResponse) Reset() {
+	*x = ReleaseStockReservationResponse{}
+	mi := &file_stockpb_stock_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReleaseStockReservationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReleaseStockReservationResponse) ProtoMessage() {}
+
+func (x *ReleaseStockReservationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_stockpb_stock_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReleaseStockReservationResponse.ProtoReflect.Descriptor instead.
+func (*ReleaseStockReservationResponse) Descriptor() ([]byte, []int) {
+	return file_stockpb_stock_proto_rawDescGZIP(), []int{18}
+}
+
 var File_stockpb_stock_proto protoreflect.FileDescriptor
 
 const file_stockpb_stock_proto_rawDesc = "" +
@@ -294,7 +991,33 @@ const file_stockpb_stock_proto_rawDesc = "" +
 	"\x0fGetItemsRequest\x12\x19\n" +
 	"\bitem_ids\x18\x01 \x03(\tR\aitemIds\"7\n" +
 	"\x10GetItemsResponse\x12#\n" +
-	"\x05items\x18\x01 \x03(\v2\r.orderpb.ItemR\x05items\"F\n" +
+	"\x05items\x18\x01 \x03(\v2\r.orderpb.ItemR\x05items\"2\n" +
+	"\x0fGetStockRequest\x12\x1f\n" +
+	"\vproduct_ids\x18\x01 \x03(\tR\n" +
+	"productIds\"\x87\x02\n" +
+	"\n" +
+	"StockLevel\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\x03R\bquantity\x12\x1a\n" +
+	"\breserved\x18\x03 \x01(\x03R\breserved\x12\x1c\n" +
+	"\tavailable\x18\x04 \x01(\x03R\tavailable\x12 \n" +
+	"\vbackordered\x18\x05 \x01(\x03R\vbackordered\x12\x16\n" +
+	"\x06policy\x18\x06 \x01(\tR\x06policy\x12'\n" +
+	"\x0fbackorder_limit\x18\a \x01(\x03R\x0ebackorderLimit\x12!\n" +
+	"\favailable_at\x18\b \x01(\tR\vavailableAt\"?\n" +
+	"\x10GetStockResponse\x12+\n" +
+	"\x06stocks\x18\x01 \x03(\v2\x13.stockpb.StockLevelR\x06stocks\"K\n" +
+	"\x18CheckAvailabilityRequest\x12/\n" +
+	"\x05items\x18\x01 \x03(\v2\x19.orderpb.ItemWithQuantityR\x05items\"Q\n" +
+	"\bShortage\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x12\n" +
+	"\x04want\x18\x02 \x01(\x03R\x04want\x12\x12\n" +
+	"\x04have\x18\x03 \x01(\x03R\x04have\"j\n" +
+	"\x19CheckAvailabilityResponse\x12\x1c\n" +
+	"\tavailable\x18\x01 \x01(\bR\tavailable\x12/\n" +
+	"\tshortages\x18\x02 \x03(\v2\x11.stockpb.ShortageR\tshortages\"F\n" +
 	"\x13ReserveStockRequest\x12/\n" +
 	"\x05items\x18\x01 \x03(\v2\x19.orderpb.ItemWithQuantityR\x05items\";\n" +
 	"\x14ReserveStockResponse\x12#\n" +
@@ -302,11 +1025,38 @@ const file_stockpb_stock_proto_rawDesc = "" +
 	"\x1eConfirmStockReservationRequest\x12/\n" +
 	"\x05items\x18\x01 \x03(\v2\x19.orderpb.ItemWithQuantityR\x05items\"F\n" +
 	"\x1fConfirmStockReservationResponse\x12#\n" +
-	"\x05items\x18\x01 \x03(\v2\r.orderpb.ItemR\x05items2\x8a\x02\n" +
+	"\x05items\x18\x01 \x03(\v2\r.orderpb.ItemR\x05items\"\x9d\x01\n" +
+	"\x18UpdateStockPolicyRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x16\n" +
+	"\x06policy\x18\x02 \x01(\tR\x06policy\x12'\n" +
+	"\x0fbackorder_limit\x18\x03 \x01(\x03R\x0ebackorderLimit\x12!\n" +
+	"\favailable_at\x18\x04 \x01(\tR\vavailableAt\"\x1b\n" +
+	"\x19UpdateStockPolicyResponse\"K\n" +
+	"\x0eRestockRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\x03R\bquantity\"s\n" +
+	"\x13BackorderAllocation\x12!\n" +
+	"\fbackorder_id\x18\x01 \x01(\tR\vbackorderId\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x02 \x01(\tR\tproductId\x12\x1a\n" +
+	"\bquantity\x18\x03 \x01(\x03R\bquantity\"Q\n" +
+	"\x0fRestockResponse\x12>\n" +
+	"\vallocations\x18\x01 \x03(\v2\x1c.stockpb.BackorderAllocationR\vallocations\"v\n" +
+	"\x1eReleaseStockReservationRequest\x12/\n" +
+	"\x05items\x18\x01 \x03(\v2\x19.orderpb.ItemWithQuantityR\x05items\x12#\n" +
+	"\rbackorder_ids\x18\x02 \x03(\tR\fbackorderIds\"!\n" +
+	"\x1fReleaseStockReservationResponse2\xaf\x05\n" +
 	"\fStockService\x12?\n" +
-	"\bGetItems\x12\x18.stockpb.GetItemsRequest\x1a\x19.stockpb.GetItemsResponse\x12K\n" +
+	"\bGetItems\x12\x18.stockpb.GetItemsRequest\x1a\x19.stockpb.GetItemsResponse\x12?\n" +
+	"\bGetStock\x12\x18.stockpb.GetStockRequest\x1a\x19.stockpb.GetStockResponse\x12Z\n" +
+	"\x11CheckAvailability\x12!.stockpb.CheckAvailabilityRequest\x1a\".stockpb.CheckAvailabilityResponse\x12K\n" +
 	"\fReserveStock\x12\x1c.stockpb.ReserveStockRequest\x1a\x1d.stockpb.ReserveStockResponse\x12l\n" +
-	"\x17ConfirmStockReservation\x12'.stockpb.ConfirmStockReservationRequest\x1a(.stockpb.ConfirmStockReservationResponseB:Z8github.com/furutachiKurea/gorder/common/genproto/stockpbb\x06proto3"
+	"\x17ConfirmStockReservation\x12'.stockpb.ConfirmStockReservationRequest\x1a(.stockpb.ConfirmStockReservationResponse\x12Z\n" +
+	"\x11UpdateStockPolicy\x12!.stockpb.UpdateStockPolicyRequest\x1a\".stockpb.UpdateStockPolicyResponse\x12<\n" +
+	"\aRestock\x12\x17.stockpb.RestockRequest\x1a\x18.stockpb.RestockResponse\x12l\n" +
+	"\x17ReleaseStockReservation\x12'.stockpb.ReleaseStockReservationRequest\x1a(.stockpb.ReleaseStockReservationResponseB:Z8github.com/furutachiKurea/gorder/common/genproto/stockpbb\x06proto3"
 
 var (
 	file_stockpb_stock_proto_rawDescOnce sync.Once
@@ -320,34 +1070,62 @@ func file_stockpb_stock_proto_rawDescGZIP() []byte {
 	return file_stockpb_stock_proto_rawDescData
 }
 
-var file_stockpb_stock_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_stockpb_stock_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
 var file_stockpb_stock_proto_goTypes = []any{
 	(*GetItemsRequest)(nil),                 // 0: stockpb.GetItemsRequest
 	(*GetItemsResponse)(nil),                // 1: stockpb.GetItemsResponse
-	(*ReserveStockRequest)(nil),             // 2: stockpb.ReserveStockRequest
-	(*ReserveStockResponse)(nil),            // 3: stockpb.ReserveStockResponse
-	(*ConfirmStockReservationRequest)(nil),  // 4: stockpb.ConfirmStockReservationRequest
-	(*ConfirmStockReservationResponse)(nil), // 5: stockpb.ConfirmStockReservationResponse
-	(*orderpb.Item)(nil),                    // 6: orderpb.Item
-	(*orderpb.ItemWithQuantity)(nil),        // 7: orderpb.ItemWithQuantity
+	(*GetStockRequest)(nil),                 // 2: stockpb.GetStockRequest
+	(*StockLevel)(nil),                      // 3: stockpb.StockLevel
+	(*GetStockResponse)(nil),                // 4: stockpb.GetStockResponse
+	(*CheckAvailabilityRequest)(nil),        // 5: stockpb.CheckAvailabilityRequest
+	(*Shortage)(nil),                        // 6: stockpb.Shortage
+	(*CheckAvailabilityResponse)(nil),       // 7: stockpb.CheckAvailabilityResponse
+	(*ReserveStockRequest)(nil),             // 8: stockpb.ReserveStockRequest
+	(*ReserveStockResponse)(nil),            // 9: stockpb.ReserveStockResponse
+	(*ConfirmStockReservationRequest)(nil),  // 10: stockpb.ConfirmStockReservationRequest
+	(*ConfirmStockReservationResponse)(nil), // 11: stockpb.ConfirmStockReservationResponse
+	(*UpdateStockPolicyRequest)(nil),        // 12: stockpb.UpdateStockPolicyRequest
+	(*UpdateStockPolicyResponse)(nil),       // 13: stockpb.UpdateStockPolicyResponse
+	(*RestockRequest)(nil),                  // 14: stockpb.RestockRequest
+	(*BackorderAllocation)(nil),             // 15: stockpb.BackorderAllocation
+	(*RestockResponse)(nil),                 // 16: stockpb.RestockResponse
+	(*ReleaseStockReservationRequest)(nil),  // 17: stockpb.ReleaseStockReservationRequest
+	(*ReleaseStockReservationResponse)(nil), // 18: stockpb.ReleaseStockReservationResponse
+	(*orderpb.Item)(nil),                    // 19: orderpb.Item
+	(*orderpb.ItemWithQuantity)(nil),        // 20: orderpb.ItemWithQuantity
 }
 var file_stockpb_stock_proto_depIdxs = []int32{
-	6, // 0: stockpb.GetItemsResponse.items:type_name -> orderpb.Item
-	7, // 1: stockpb.ReserveStockRequest.items:type_name -> orderpb.ItemWithQuantity
-	6, // 2: stockpb.ReserveStockResponse.items:type_name -> orderpb.Item
-	7, // 3: stockpb.ConfirmStockReservationRequest.items:type_name -> orderpb.ItemWithQuantity
-	6, // 4: stockpb.ConfirmStockReservationResponse.items:type_name -> orderpb.Item
-	0, // 5: stockpb.StockService.GetItems:input_type -> stockpb.GetItemsRequest
-	2, // 6: stockpb.StockService.ReserveStock:input_type -> stockpb.ReserveStockRequest
-	4, // 7: stockpb.StockService.ConfirmStockReservation:input_type -> stockpb.ConfirmStockReservationRequest
-	1, // 8: stockpb.StockService.GetItems:output_type -> stockpb.GetItemsResponse
-	3, // 9: stockpb.StockService.ReserveStock:output_type -> stockpb.ReserveStockResponse
-	5, // 10: stockpb.StockService.ConfirmStockReservation:output_type -> stockpb.ConfirmStockReservationResponse
-	8, // [8:11] is the sub-list for method output_type
-	5, // [5:8] is the sub-list for method input_type
-	5, // [5:5] is the sub-list for extension type_name
-	5, // [5:5] is the sub-list for extension extendee
-	0, // [0:5] is the sub-list for field type_name
+	19, // 0: stockpb.GetItemsResponse.items:type_name -> orderpb.Item
+	3,  // 1: stockpb.GetStockResponse.stocks:type_name -> stockpb.StockLevel
+	20, // 2: stockpb.CheckAvailabilityRequest.items:type_name -> orderpb.ItemWithQuantity
+	6,  // 3: stockpb.CheckAvailabilityResponse.shortages:type_name -> stockpb.Shortage
+	20, // 4: stockpb.ReserveStockRequest.items:type_name -> orderpb.ItemWithQuantity
+	19, // 5: stockpb.ReserveStockResponse.items:type_name -> orderpb.Item
+	20, // 6: stockpb.ConfirmStockReservationRequest.items:type_name -> orderpb.ItemWithQuantity
+	19, // 7: stockpb.ConfirmStockReservationResponse.items:type_name -> orderpb.Item
+	15, // 8: stockpb.RestockResponse.allocations:type_name -> stockpb.BackorderAllocation
+	20, // 9: stockpb.ReleaseStockReservationRequest.items:type_name -> orderpb.ItemWithQuantity
+	0,  // 10: stockpb.StockService.GetItems:input_type -> stockpb.GetItemsRequest
+	2,  // 11: stockpb.StockService.GetStock:input_type -> stockpb.GetStockRequest
+	5,  // 12: stockpb.StockService.CheckAvailability:input_type -> stockpb.CheckAvailabilityRequest
+	8,  // 13: stockpb.StockService.ReserveStock:input_type -> stockpb.ReserveStockRequest
+	10, // 14: stockpb.StockService.ConfirmStockReservation:input_type -> stockpb.ConfirmStockReservationRequest
+	12, // 15: stockpb.StockService.UpdateStockPolicy:input_type -> stockpb.UpdateStockPolicyRequest
+	14, // 16: stockpb.StockService.Restock:input_type -> stockpb.RestockRequest
+	17, // 17: stockpb.StockService.ReleaseStockReservation:input_type -> stockpb.ReleaseStockReservationRequest
+	1,  // 18: stockpb.StockService.GetItems:output_type -> stockpb.GetItemsResponse
+	4,  // 19: stockpb.StockService.GetStock:output_type -> stockpb.GetStockResponse
+	7,  // 20: stockpb.StockService.CheckAvailability:output_type -> stockpb.CheckAvailabilityResponse
+	9,  // 21: stockpb.StockService.ReserveStock:output_type -> stockpb.ReserveStockResponse
+	11, // 22: stockpb.StockService.ConfirmStockReservation:output_type -> stockpb.ConfirmStockReservationResponse
+	13, // 23: stockpb.StockService.UpdateStockPolicy:output_type -> stockpb.UpdateStockPolicyResponse
+	16, // 24: stockpb.StockService.Restock:output_type -> stockpb.RestockResponse
+	18, // 25: stockpb.StockService.ReleaseStockReservation:output_type -> stockpb.ReleaseStockReservationResponse
+	18, // [18:26] is the sub-list for method output_type
+	10, // [10:18] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
 }
 
 func init() { file_stockpb_stock_proto_init() }
@@ -361,7 +1139,7 @@ func file_stockpb_stock_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_stockpb_stock_proto_rawDesc), len(file_stockpb_stock_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   6,
+			NumMessages:   19,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -20,8 +20,13 @@ const _ = grpc.SupportPackageIsVersion9
 
 const (
 	StockService_GetItems_FullMethodName                = "/stockpb.StockService/GetItems"
+	StockService_GetStock_FullMethodName                = "/stockpb.StockService/GetStock"
+	StockService_CheckAvailability_FullMethodName       = "/stockpb.StockService/CheckAvailability"
 	StockService_ReserveStock_FullMethodName            = "/stockpb.StockService/ReserveStock"
 	StockService_ConfirmStockReservation_FullMethodName = "/stockpb.StockService/ConfirmStockReservation"
+	StockService_UpdateStockPolicy_FullMethodName       = "/stockpb.StockService/UpdateStockPolicy"
+	StockService_Restock_FullMethodName                 = "/stockpb.StockService/Restock"
+	StockService_ReleaseStockReservation_FullMethodName = "/stockpb.StockService/ReleaseStockReservation"
 )
 
 // StockServiceClient is the client API for StockService service.
@@ -29,8 +34,13 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type StockServiceClient interface {
 	GetItems(ctx context.Context, in *GetItemsRequest, opts ...grpc.CallOption) (*GetItemsResponse, error)
+	GetStock(ctx context.Context, in *GetStockRequest, opts ...grpc.CallOption) (*GetStockResponse, error)
+	CheckAvailability(ctx context.Context, in *CheckAvailabilityRequest, opts ...grpc.CallOption) (*CheckAvailabilityResponse, error)
 	ReserveStock(ctx context.Context, in *ReserveStockRequest, opts ...grpc.CallOption) (*ReserveStockResponse, error)
 	ConfirmStockReservation(ctx context.Context, in *ConfirmStockReservationRequest, opts ...grpc.CallOption) (*ConfirmStockReservationResponse, error)
+	UpdateStockPolicy(ctx context.Context, in *UpdateStockPolicyRequest, opts ...grpc.CallOption) (*UpdateStockPolicyResponse, error)
+	Restock(ctx context.Context, in *RestockRequest, opts ...grpc.CallOption) (*RestockResponse, error)
+	ReleaseStockReservation(ctx context.Context, in *ReleaseStockReservationRequest, opts ...grpc.CallOption) (*ReleaseStockReservationResponse, error)
 }
 
 type stockServiceClient struct {
@@ -51,6 +61,26 @@ func (c *stockServiceClient) GetItems(ctx context.Context, in *GetItemsRequest,
 	return out, nil
 }
 
+func (c *stockServiceClient) GetStock(ctx context.Context, in *GetStockRequest, opts ...grpc.CallOption) (*GetStockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetStockResponse)
+	err := c.cc.Invoke(ctx, StockService_GetStock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stockServiceClient) CheckAvailability(ctx context.Context, in *CheckAvailabilityRequest, opts ...grpc.CallOption) (*CheckAvailabilityResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckAvailabilityResponse)
+	err := c.cc.Invoke(ctx, StockService_CheckAvailability_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *stockServiceClient) ReserveStock(ctx context.Context, in *ReserveStockRequest, opts ...grpc.CallOption) (*ReserveStockResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ReserveStockResponse)
@@ -71,13 +101,48 @@ func (c *stockServiceClient) ConfirmStockReservation(ctx context.Context, in *Co
 	return out, nil
 }
 
+func (c *stockServiceClient) UpdateStockPolicy(ctx context.Context, in *UpdateStockPolicyRequest, opts ...grpc.CallOption) (*UpdateStockPolicyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateStockPolicyResponse)
+	err := c.cc.Invoke(ctx, StockService_UpdateStockPolicy_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stockServiceClient) Restock(ctx context.Context, in *RestockRequest, opts ...grpc.CallOption) (*RestockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RestockResponse)
+	err := c.cc.Invoke(ctx, StockService_Restock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stockServiceClient) ReleaseStockReservation(ctx context.Context, in *ReleaseStockReservationRequest, opts ...grpc.CallOption) (*ReleaseStockReservationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReleaseStockReservationResponse)
+	err := c.cc.Invoke(ctx, StockService_ReleaseStockReservation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // StockServiceServer is the server API for StockService service.
 // All implementations should embed UnimplementedStockServiceServer
 // for forward compatibility.
 type StockServiceServer interface {
 	GetItems(context.Context, *GetItemsRequest) (*GetItemsResponse, error)
+	GetStock(context.Context, *GetStockRequest) (*GetStockResponse, error)
+	CheckAvailability(context.Context, *CheckAvailabilityRequest) (*CheckAvailabilityResponse, error)
 	ReserveStock(context.Context, *ReserveStockRequest) (*ReserveStockResponse, error)
 	ConfirmStockReservation(context.Context, *ConfirmStockReservationRequest) (*ConfirmStockReservationResponse, error)
+	UpdateStockPolicy(context.Context, *UpdateStockPolicyRequest) (*UpdateStockPolicyResponse, error)
+	Restock(context.Context, *RestockRequest) (*RestockResponse, error)
+	ReleaseStockReservation(context.Context, *ReleaseStockReservationRequest) (*ReleaseStockReservationResponse, error)
 }
 
 // UnimplementedStockServiceServer should be embedded to have
@@ -90,12 +155,27 @@ type UnimplementedStockServiceServer struct{}
 func (UnimplementedStockServiceServer) GetItems(context.Context, *GetItemsRequest) (*GetItemsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetItems not implemented")
 }
+func (UnimplementedStockServiceServer) GetStock(context.Context, *GetStockRequest) (*GetStockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStock not implemented")
+}
+func (UnimplementedStockServiceServer) CheckAvailability(context.Context, *CheckAvailabilityRequest) (*CheckAvailabilityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckAvailability not implemented")
+}
 func (UnimplementedStockServiceServer) ReserveStock(context.Context, *ReserveStockRequest) (*ReserveStockResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ReserveStock not implemented")
 }
 func (UnimplementedStockServiceServer) ConfirmStockReservation(context.Context, *ConfirmStockReservationRequest) (*ConfirmStockReservationResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ConfirmStockReservation not implemented")
 }
+func (UnimplementedStockServiceServer) UpdateStockPolicy(context.Context, *UpdateStockPolicyRequest) (*UpdateStockPolicyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateStockPolicy not implemented")
+}
+func (UnimplementedStockServiceServer) Restock(context.Context, *RestockRequest) (*RestockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Restock not implemented")
+}
+func (UnimplementedStockServiceServer) ReleaseStockReservation(context.Context, *ReleaseStockReservationRequest) (*ReleaseStockReservationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReleaseStockReservation not implemented")
+}
 func (UnimplementedStockServiceServer) testEmbeddedByValue() {}
 
 // UnsafeStockServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -134,6 +214,42 @@ func _StockService_GetItems_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _StockService_GetStock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StockServiceServer).GetStock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StockService_GetStock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StockServiceServer).GetStock(ctx, req.(*GetStockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StockService_CheckAvailability_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckAvailabilityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StockServiceServer).CheckAvailability(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StockService_CheckAvailability_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StockServiceServer).CheckAvailability(ctx, req.(*CheckAvailabilityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _StockService_ReserveStock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ReserveStockRequest)
 	if err := dec(in); err != nil {
@@ -170,6 +286,60 @@ func _StockService_ConfirmStockReservation_Handler(srv interface{}, ctx context.
 	return interceptor(ctx, in, info, handler)
 }
 
+func _StockService_UpdateStockPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateStockPolicyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StockServiceServer).UpdateStockPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StockService_UpdateStockPolicy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StockServiceServer).UpdateStockPolicy(ctx, req.(*UpdateStockPolicyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StockService_Restock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StockServiceServer).Restock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StockService_Restock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StockServiceServer).Restock(ctx, req.(*RestockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StockService_ReleaseStockReservation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReleaseStockReservationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StockServiceServer).ReleaseStockReservation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StockService_ReleaseStockReservation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StockServiceServer).ReleaseStockReservation(ctx, req.(*ReleaseStockReservationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // StockService_ServiceDesc is the grpc.ServiceDesc for StockService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -181,6 +351,14 @@ var StockService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetItems",
 			Handler:    _StockService_GetItems_Handler,
 		},
+		{
+			MethodName: "GetStock",
+			Handler:    _StockService_GetStock_Handler,
+		},
+		{
+			MethodName: "CheckAvailability",
+			Handler:    _StockService_CheckAvailability_Handler,
+		},
 		{
 			MethodName: "ReserveStock",
 			Handler:    _StockService_ReserveStock_Handler,
@@ -189,6 +367,18 @@ var StockService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ConfirmStockReservation",
 			Handler:    _StockService_ConfirmStockReservation_Handler,
 		},
+		{
+			MethodName: "UpdateStockPolicy",
+			Handler:    _StockService_UpdateStockPolicy_Handler,
+		},
+		{
+			MethodName: "Restock",
+			Handler:    _StockService_Restock_Handler,
+		},
+		{
+			MethodName: "ReleaseStockReservation",
+			Handler:    _StockService_ReleaseStockReservation_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "stockpb/stock.proto",
@@ -43,8 +43,9 @@ func Errno(err error) int {
 		return consts.ErrnoSuccess
 	}
 
-	target := &Error{}
-	if errors.As(err, target) {
+	// NewWithError 返回的是 *Error，target 需要是 **Error 才能匹配
+	var target *Error
+	if errors.As(err, &target) {
 		log.Debug().Err(err).Msg("is errors.Error")
 		return target.code
 	}
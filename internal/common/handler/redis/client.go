@@ -35,6 +35,32 @@ func SetNX(ctx context.Context, client *redis.Client, key, value string, ttl tim
 	return err
 }
 
+// SetIfAbsent 在 key 不存在时设置 value，返回是否设置成功，key 已存在时返回 false
+func SetIfAbsent(ctx context.Context, client *redis.Client, key, value string, ttl time.Duration) (ok bool, err error) {
+	now := time.Now()
+	defer func() {
+		l := log.Logger.With().Ctx(ctx).
+			Time("start", now).
+			Str("key", key).
+			Str("value", value).
+			Bool("ok", ok).
+			Err(err).
+			Int64(logging.Cost, time.Since(now).Nanoseconds()).Logger()
+
+		if err == nil {
+			l.Info().Msg("redis_set_if_absent_success")
+		} else {
+			l.Warn().Msg("redis_set_if_absent_error")
+		}
+	}()
+
+	if client == nil {
+		return false, errors.New("redis client is nil")
+	}
+
+	return client.SetNX(ctx, key, value, ttl).Result()
+}
+
 func Del(ctx context.Context, client *redis.Client, key string) (err error) {
 	now := time.Now()
 	defer func() {
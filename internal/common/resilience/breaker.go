@@ -0,0 +1,145 @@
+package resilience
+
+import (
+	"sync"
+	"time"
+)
+
+// BreakerConfig 熔断器配置：Window 内至少有 MinRequests 次调用且失败比例达到 FailureRatio 时打开，
+// 打开 OpenTimeout 后进入半开状态，只放行一次探测调用，探测成功则关闭，失败则再次打开
+type BreakerConfig struct {
+	Window       time.Duration `mapstructure:"window"`
+	MinRequests  int           `mapstructure:"min-requests"`
+	FailureRatio float64       `mapstructure:"failure-ratio"`
+	OpenTimeout  time.Duration `mapstructure:"open-timeout"`
+}
+
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		Window:       30 * time.Second,
+		MinRequests:  10,
+		FailureRatio: 0.5,
+		OpenTimeout:  30 * time.Second,
+	}
+}
+
+func (c BreakerConfig) withDefaults() BreakerConfig {
+	d := DefaultBreakerConfig()
+	if c.Window <= 0 {
+		c.Window = d.Window
+	}
+	if c.MinRequests <= 0 {
+		c.MinRequests = d.MinRequests
+	}
+	if c.FailureRatio <= 0 || c.FailureRatio > 1 {
+		c.FailureRatio = d.FailureRatio
+	}
+	if c.OpenTimeout <= 0 {
+		c.OpenTimeout = d.OpenTimeout
+	}
+	return c
+}
+
+type breakerState int
+
+const (
+	stateClosed breakerState = iota
+	stateOpen
+	stateHalfOpen
+)
+
+// breaker 按固定时间窗口统计调用结果的熔断器
+type breaker struct {
+	config BreakerConfig
+
+	lock        sync.Mutex
+	state       breakerState
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	probing     bool
+}
+
+func newBreaker(config BreakerConfig) *breaker {
+	return &breaker{config: config}
+}
+
+// allow 判断当前是否允许调用，半开状态下同一时间只允许一次探测
+func (b *breaker) allow(now time.Time) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	switch b.state {
+	case stateOpen:
+		if now.Sub(b.openedAt) < b.config.OpenTimeout {
+			return false
+		}
+		b.state = stateHalfOpen
+		b.probing = true
+		return true
+	case stateHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record 记录一次调用结果，返回熔断器是否因此次失败而打开
+func (b *breaker) record(now time.Time, failed bool) (opened bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.state == stateHalfOpen {
+		b.probing = false
+		if failed {
+			b.open(now)
+			return true
+		}
+		b.state = stateClosed
+		b.resetWindow(now)
+		return false
+	}
+
+	if now.Sub(b.windowStart) >= b.config.Window {
+		b.resetWindow(now)
+	}
+	b.requests++
+	if failed {
+		b.failures++
+	}
+
+	if b.state == stateClosed &&
+		b.requests >= b.config.MinRequests &&
+		float64(b.failures)/float64(b.requests) >= b.config.FailureRatio {
+		b.open(now)
+		return true
+	}
+	return false
+}
+
+// release 放弃本次调用的结果，半开状态下允许下一次调用重新探测
+func (b *breaker) release() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.state == stateHalfOpen {
+		b.probing = false
+	}
+}
+
+func (b *breaker) open(now time.Time) {
+	b.state = stateOpen
+	b.openedAt = now
+	b.resetWindow(now)
+}
+
+func (b *breaker) resetWindow(now time.Time) {
+	b.windowStart = now
+	b.requests = 0
+	b.failures = 0
+}
@@ -0,0 +1,100 @@
+package resilience
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBreaker() *breaker {
+	return newBreaker(BreakerConfig{
+		Window:       time.Minute,
+		MinRequests:  4,
+		FailureRatio: 0.5,
+		OpenTimeout:  10 * time.Second,
+	})
+}
+
+func TestBreaker_OpensOnFailureRatio(t *testing.T) {
+	b := newTestBreaker()
+	now := time.Now()
+
+	assert.False(t, b.record(now, true))
+	assert.False(t, b.record(now, false))
+	assert.False(t, b.record(now, false))
+	assert.Equal(t, stateClosed, b.state, "ratio below threshold")
+
+	assert.True(t, b.record(now, true))
+	assert.Equal(t, stateOpen, b.state)
+	assert.False(t, b.allow(now.Add(time.Second)))
+}
+
+func TestBreaker_MinRequests(t *testing.T) {
+	b := newTestBreaker()
+	now := time.Now()
+
+	for range 3 {
+		assert.False(t, b.record(now, true))
+	}
+	assert.Equal(t, stateClosed, b.state)
+	assert.True(t, b.allow(now))
+}
+
+func TestBreaker_WindowReset(t *testing.T) {
+	b := newTestBreaker()
+	now := time.Now()
+
+	for range 3 {
+		b.record(now, true)
+	}
+	// 新窗口重新计数，上个窗口的失败不会让熔断器打开
+	later := now.Add(time.Minute)
+	assert.False(t, b.record(later, true))
+	assert.Equal(t, 1, b.requests)
+	assert.Equal(t, stateClosed, b.state)
+}
+
+func TestBreaker_HalfOpen(t *testing.T) {
+	tests := []struct {
+		name   string
+		failed bool
+		want   breakerState
+	}{
+		{name: "probe success closes", failed: false, want: stateClosed},
+		{name: "probe failure reopens", failed: true, want: stateOpen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBreaker()
+			now := time.Now()
+			b.open(now)
+
+			assert.False(t, b.allow(now.Add(9*time.Second)))
+
+			probeAt := now.Add(10 * time.Second)
+			require.True(t, b.allow(probeAt))
+			assert.Equal(t, stateHalfOpen, b.state)
+			assert.False(t, b.allow(probeAt), "only one probe at a time")
+
+			assert.Equal(t, tt.failed, b.record(probeAt, tt.failed))
+			assert.Equal(t, tt.want, b.state)
+			assert.Equal(t, !tt.failed, b.allow(probeAt))
+		})
+	}
+}
+
+func TestBreaker_ReleaseProbe(t *testing.T) {
+	b := newTestBreaker()
+	now := time.Now()
+	b.open(now)
+
+	probeAt := now.Add(10 * time.Second)
+	require.True(t, b.allow(probeAt))
+	b.release()
+
+	assert.Equal(t, stateHalfOpen, b.state, "released probe should not close the breaker")
+	assert.True(t, b.allow(probeAt), "next call should probe again")
+}
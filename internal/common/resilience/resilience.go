@@ -0,0 +1,191 @@
+// Package resilience 为调用外部服务商(Stripe 等)的客户端提供统一的超时、重试和熔断
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/tracing"
+
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrCircuitOpen 熔断器处于打开状态，调用被直接拒绝
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// Config 外部调用的策略，零值字段使用 DefaultConfig 中的值
+type Config struct {
+	// Timeout 单次尝试的超时时间，不会超过 ctx 本身的截止时间
+	Timeout time.Duration `mapstructure:"timeout"`
+	// MaxAttempts 包含首次调用在内的最大尝试次数
+	MaxAttempts int `mapstructure:"max-attempts"`
+	// BaseBackoff, MaxBackoff 重试间隔的基数和上限，第 n 次重试在 [0, min(MaxBackoff, BaseBackoff*2^n)) 中随机等待
+	BaseBackoff time.Duration `mapstructure:"base-backoff"`
+	MaxBackoff  time.Duration `mapstructure:"max-backoff"`
+	Breaker     BreakerConfig `mapstructure:"breaker"`
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Timeout:     10 * time.Second,
+		MaxAttempts: 3,
+		BaseBackoff: 200 * time.Millisecond,
+		MaxBackoff:  2 * time.Second,
+		Breaker:     DefaultBreakerConfig(),
+	}
+}
+
+func (c Config) withDefaults() Config {
+	d := DefaultConfig()
+	if c.Timeout <= 0 {
+		c.Timeout = d.Timeout
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = d.MaxAttempts
+	}
+	if c.BaseBackoff <= 0 {
+		c.BaseBackoff = d.BaseBackoff
+	}
+	if c.MaxBackoff < c.BaseBackoff {
+		c.MaxBackoff = max(d.MaxBackoff, c.BaseBackoff)
+	}
+	c.Breaker = c.Breaker.withDefaults()
+	return c
+}
+
+// RetryableFunc 判断错误是否为可安全重试的临时错误，只有这类错误会被重试并计入熔断器的失败
+type RetryableFunc func(err error) bool
+
+// Caller 包装对同一个外部服务商的调用，同一服务商的所有调用共享一个熔断器
+type Caller struct {
+	name          string
+	config        Config
+	retryable     RetryableFunc
+	breaker       *breaker
+	metricsClient decorator.MetricsClient
+}
+
+// NewCaller 创建名称为 name 的外部调用包装，retryable 为 nil 时只重试网络错误和单次尝试超时
+func NewCaller(name string, config Config, retryable RetryableFunc, metricsClient decorator.MetricsClient) *Caller {
+	if name == "" {
+		panic("empty caller name")
+	}
+
+	if metricsClient == nil {
+		panic("metricsClient is nil")
+	}
+
+	if retryable == nil {
+		retryable = IsTransient
+	}
+
+	config = config.withDefaults()
+	return &Caller{
+		name:          name,
+		config:        config,
+		retryable:     retryable,
+		breaker:       newBreaker(config.Breaker),
+		metricsClient: metricsClient,
+	}
+}
+
+// Do 调用 fn，每次尝试使用单独的超时 ctx，临时错误按带随机抖动的指数退避重试。
+// 熔断器打开时直接返回 ErrCircuitOpen，调用方的 ctx 结束后不再重试
+func (c *Caller) Do(ctx context.Context, op string, fn func(ctx context.Context) error) (err error) {
+	ctx, span := tracing.Start(ctx, fmt.Sprintf("outbound.%s.%s", c.name, op))
+	defer span.End()
+
+	attempt := 0
+	defer func() {
+		span.SetAttributes(attribute.Int("outbound.attempts", attempt))
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			c.metricsClient.Inc(c.metricKey(op, "failure"), 1)
+		} else {
+			c.metricsClient.Inc(c.metricKey(op, "success"), 1)
+		}
+	}()
+
+	for attempt = 1; ; attempt++ {
+		if !c.breaker.allow(time.Now()) {
+			c.metricsClient.Inc(c.metricKey(op, "rejected"), 1)
+			return fmt.Errorf("%s %s: %w", c.name, op, ErrCircuitOpen)
+		}
+
+		err = c.try(ctx, fn)
+		if err != nil && ctx.Err() != nil {
+			// 调用方取消的调用不能说明服务商是否可用，不计入熔断器
+			c.breaker.release()
+			return err
+		}
+
+		transient := err != nil && c.retryable(err)
+		if c.breaker.record(time.Now(), transient) {
+			c.metricsClient.Inc(fmt.Sprintf("outbound.%s.breaker.open", c.name), 1)
+			span.AddEvent("circuit breaker opened")
+			log.Warn().Ctx(ctx).Err(err).Str("provider", c.name).Str("op", op).Msg("circuit breaker opened")
+		}
+		if !transient || attempt >= c.config.MaxAttempts {
+			return err
+		}
+
+		wait := c.backoff(attempt)
+		c.metricsClient.Inc(c.metricKey(op, "retry"), 1)
+		span.AddEvent("retry", trace.WithAttributes(
+			attribute.Int("outbound.attempt", attempt),
+			attribute.String("outbound.backoff", wait.String()),
+		))
+		log.Warn().Ctx(ctx).Err(err).
+			Str("provider", c.name).
+			Str("op", op).
+			Int("attempt", attempt).
+			Dur("backoff", wait).
+			Msg("outbound call failed, retrying")
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (c *Caller) try(ctx context.Context, fn func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	return fn(ctx)
+}
+
+// backoff 第 attempt 次失败后的等待时间，使用 full jitter 避免多个实例同时重试
+func (c *Caller) backoff(attempt int) time.Duration {
+	ceiling := c.config.BaseBackoff << min(attempt-1, 30)
+	if ceiling <= 0 || ceiling > c.config.MaxBackoff {
+		ceiling = c.config.MaxBackoff
+	}
+
+	return rand.N(ceiling) + 1
+}
+
+func (c *Caller) metricKey(op, result string) string {
+	return fmt.Sprintf("outbound.%s.%s.%s", c.name, op, result)
+}
+
+// IsTransient 网络错误和单次尝试超时视为临时错误
+func IsTransient(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
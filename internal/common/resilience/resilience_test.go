@@ -0,0 +1,123 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/metrics"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	errTransient = errors.New("transient")
+	errPermanent = errors.New("permanent")
+)
+
+func isTestRetryable(err error) bool {
+	return errors.Is(err, errTransient)
+}
+
+func newTestCaller(breaker BreakerConfig) *Caller {
+	return NewCaller("test", Config{
+		Timeout:     time.Second,
+		MaxAttempts: 3,
+		BaseBackoff: time.Millisecond,
+		MaxBackoff:  time.Millisecond,
+		Breaker:     breaker,
+	}, isTestRetryable, metrics.TodoMetrics{})
+}
+
+// failing 返回前 n 次调用失败的 fn 以及调用次数
+func failing(n int, err error) (func(context.Context) error, *int) {
+	calls := 0
+	return func(context.Context) error {
+		calls++
+		if calls <= n {
+			return err
+		}
+		return nil
+	}, &calls
+}
+
+func TestCaller_Do_RetriesTransient(t *testing.T) {
+	c := newTestCaller(BreakerConfig{})
+
+	fn, calls := failing(2, errTransient)
+	require.NoError(t, c.Do(context.Background(), "op", fn))
+	assert.Equal(t, 3, *calls)
+}
+
+func TestCaller_Do_MaxAttempts(t *testing.T) {
+	c := newTestCaller(BreakerConfig{})
+
+	fn, calls := failing(5, errTransient)
+	assert.ErrorIs(t, c.Do(context.Background(), "op", fn), errTransient)
+	assert.Equal(t, 3, *calls)
+}
+
+func TestCaller_Do_NotRetryable(t *testing.T) {
+	c := newTestCaller(BreakerConfig{})
+
+	fn, calls := failing(5, errPermanent)
+	assert.ErrorIs(t, c.Do(context.Background(), "op", fn), errPermanent)
+	assert.Equal(t, 1, *calls)
+	assert.Equal(t, 0, c.breaker.failures, "permanent errors should not count as breaker failures")
+}
+
+func TestCaller_Do_AttemptTimeout(t *testing.T) {
+	c := NewCaller("test", Config{
+		Timeout:     10 * time.Millisecond,
+		MaxAttempts: 2,
+		BaseBackoff: time.Millisecond,
+		MaxBackoff:  time.Millisecond,
+	}, nil, metrics.TodoMetrics{})
+
+	calls := 0
+	err := c.Do(context.Background(), "op", func(ctx context.Context) error {
+		calls++
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 2, calls, "per-attempt timeout should be retried")
+}
+
+func TestCaller_Do_CircuitOpen(t *testing.T) {
+	c := newTestCaller(BreakerConfig{MinRequests: 3, FailureRatio: 1, OpenTimeout: time.Minute})
+
+	fn, calls := failing(5, errTransient)
+	assert.ErrorIs(t, c.Do(context.Background(), "op", fn), errTransient)
+	assert.Equal(t, 3, *calls)
+
+	assert.ErrorIs(t, c.Do(context.Background(), "op", fn), ErrCircuitOpen)
+	assert.Equal(t, 3, *calls, "open breaker should reject without calling")
+}
+
+func TestCaller_Do_CancelledProbe(t *testing.T) {
+	c := newTestCaller(BreakerConfig{MinRequests: 1, FailureRatio: 1, OpenTimeout: 10 * time.Millisecond})
+
+	// 首次失败后熔断器打开，重试被拒绝
+	fn, calls := failing(1, errTransient)
+	require.ErrorIs(t, c.Do(context.Background(), "op", fn), ErrCircuitOpen)
+	require.Equal(t, 1, *calls)
+	require.Equal(t, stateOpen, c.breaker.state)
+	time.Sleep(20 * time.Millisecond)
+
+	// 调用方在探测过程中取消，熔断器保持半开，下一次调用重新探测
+	ctx, cancel := context.WithCancel(context.Background())
+	err := c.Do(ctx, "op", func(context.Context) error {
+		cancel()
+		return context.Canceled
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, stateHalfOpen, c.breaker.state)
+
+	fn, calls = failing(1, errTransient)
+	assert.ErrorIs(t, c.Do(context.Background(), "op", fn), ErrCircuitOpen)
+	assert.Equal(t, 1, *calls)
+	assert.Equal(t, stateOpen, c.breaker.state, "failed probe should reopen")
+}
@@ -0,0 +1,61 @@
+package resilience
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/furutachiKurea/gorder/common/decorator"
+
+	"github.com/spf13/viper"
+	"github.com/stripe/stripe-go/v84"
+)
+
+const (
+	stripeCallerName = "stripe"
+	// stripeConfigKey 各服务中 Stripe 请求的超时、重试和熔断策略的配置项
+	stripeConfigKey = "outbound.stripe"
+)
+
+// NewStripeClient 创建使用独立 API Key 的 Stripe 客户端，关闭 SDK 自带的重试，统一由 Caller 控制超时和重试
+func NewStripeClient(apiKey string) *stripe.Client {
+	if apiKey == "" {
+		panic("empty API key")
+	}
+
+	return stripe.NewClient(apiKey, stripe.WithBackends(stripe.NewBackendsWithConfig(&stripe.BackendConfig{
+		MaxNetworkRetries: stripe.Int64(0),
+	})))
+}
+
+// NewStripeCaller 同一服务中所有 Stripe 请求共享的 Caller，按 IsStripeRetryable 判断是否重试
+func NewStripeCaller(config Config, metricsClient decorator.MetricsClient) *Caller {
+	return NewCaller(stripeCallerName, config, IsStripeRetryable, metricsClient)
+}
+
+// NewStripeCallerFromConfig 按 outbound.stripe 的配置创建 Stripe Caller，未配置的字段使用默认值
+func NewStripeCallerFromConfig(metricsClient decorator.MetricsClient) (*Caller, error) {
+	var config Config
+	if err := viper.UnmarshalKey(stripeConfigKey, &config); err != nil {
+		return nil, fmt.Errorf("parse %s config: %w", stripeConfigKey, err)
+	}
+	return NewStripeCaller(config, metricsClient), nil
+}
+
+// IsStripeRetryable 与 Stripe SDK 的重试规则一致：网络错误、锁超时导致的 429、409 和 5xx 可以安全重试，
+// 其他 4xx(包括限流)重试也不会成功
+func IsStripeRetryable(err error) bool {
+	var stripeErr *stripe.Error
+	if !errors.As(err, &stripeErr) {
+		return IsTransient(err)
+	}
+
+	switch {
+	case stripeErr.HTTPStatusCode == http.StatusTooManyRequests:
+		return stripeErr.Code == stripe.ErrorCodeLockTimeout
+	case stripeErr.HTTPStatusCode == http.StatusConflict:
+		return true
+	default:
+		return stripeErr.HTTPStatusCode >= http.StatusInternalServerError
+	}
+}
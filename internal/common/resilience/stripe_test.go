@@ -0,0 +1,34 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stripe/stripe-go/v84"
+)
+
+func TestIsStripeRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "attempt timeout", err: fmt.Errorf("retrieve: %w", context.DeadlineExceeded), want: true},
+		{name: "unknown error", err: errors.New("boom"), want: false},
+		{name: "lock timeout", err: &stripe.Error{HTTPStatusCode: http.StatusTooManyRequests, Code: stripe.ErrorCodeLockTimeout}, want: true},
+		{name: "rate limited", err: &stripe.Error{HTTPStatusCode: http.StatusTooManyRequests, Code: stripe.ErrorCodeRateLimit}, want: false},
+		{name: "conflict", err: &stripe.Error{HTTPStatusCode: http.StatusConflict}, want: true},
+		{name: "server error", err: &stripe.Error{HTTPStatusCode: http.StatusBadGateway}, want: true},
+		{name: "bad request", err: &stripe.Error{HTTPStatusCode: http.StatusBadRequest}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsStripeRetryable(tt.err))
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthCheck 检查依赖是否可用，不可用时返回原因
+type HealthCheck func() error
+
+// RegisterHealthRoutes 注册存活和就绪探针：
+//   - /healthz 进程存活即返回 200
+//   - /readyz 所有 checks 通过时返回 200，否则返回 503 以及不可用的依赖和原因
+func RegisterHealthRoutes(router *gin.Engine, checks map[string]HealthCheck) {
+	router.GET("/healthz", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	router.GET("/readyz", func(c *gin.Context) {
+		failures := make(map[string]string)
+		for name, check := range checks {
+			if err := check(); err != nil {
+				failures[name] = err.Error()
+			}
+		}
+
+		if len(failures) > 0 {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "failures": failures})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+}
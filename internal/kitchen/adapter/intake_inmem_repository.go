@@ -0,0 +1,33 @@
+package adapter
+
+import (
+	"context"
+	"sync"
+
+	"github.com/furutachiKurea/gorder/kitchen/domain/intake"
+)
+
+type MemoryIntakeRepository struct {
+	lock  *sync.RWMutex
+	pause intake.Pause
+}
+
+func NewMemoryIntakeRepository() *MemoryIntakeRepository {
+	return &MemoryIntakeRepository{lock: &sync.RWMutex{}}
+}
+
+func (m *MemoryIntakeRepository) GetPause(_ context.Context) (*intake.Pause, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	got := m.pause
+	return &got, nil
+}
+
+func (m *MemoryIntakeRepository) SetPause(_ context.Context, pause *intake.Pause) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.pause = *pause
+	return nil
+}
@@ -0,0 +1,77 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/logging"
+	"github.com/furutachiKurea/gorder/kitchen/domain/intake"
+
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// intakeDocID 暂停接单的状态只有一条记录
+const intakeDocID = "intake"
+
+type IntakeRepositoryMongo struct {
+	db *mongo.Client
+}
+
+func NewIntakeRepositoryMongo(db *mongo.Client) *IntakeRepositoryMongo {
+	return &IntakeRepositoryMongo{db: db}
+}
+
+func (r *IntakeRepositoryMongo) GetPause(ctx context.Context) (got *intake.Pause, err error) {
+	_, deferlog := logging.WhenRequest(ctx, "IntakeRepositoryMongo.GetPause", nil)
+	defer deferlog(got, &err)
+
+	read := &pauseModel{}
+	if err = r.collection().FindOne(ctx, bson.M{"_id": intakeDocID}).Decode(read); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return &intake.Pause{}, nil
+		}
+		return nil, err
+	}
+
+	return &intake.Pause{
+		Paused:    read.Paused,
+		Reason:    read.Reason,
+		UpdatedAt: read.UpdatedAt,
+	}, nil
+}
+
+func (r *IntakeRepositoryMongo) SetPause(ctx context.Context, pause *intake.Pause) (err error) {
+	_, deferlog := logging.WhenRequest(ctx, "IntakeRepositoryMongo.SetPause", map[string]any{
+		"pause": pause,
+	})
+	defer deferlog(nil, &err)
+
+	_, err = r.collection().ReplaceOne(ctx,
+		bson.M{"_id": intakeDocID},
+		&pauseModel{
+			ID:        intakeDocID,
+			Paused:    pause.Paused,
+			Reason:    pause.Reason,
+			UpdatedAt: pause.UpdatedAt,
+		},
+		options.Replace().SetUpsert(true),
+	)
+	return err
+}
+
+// collection 获取接单状态 collection
+func (r *IntakeRepositoryMongo) collection() *mongo.Collection {
+	return r.db.Database(viper.GetString("kitchen.mongo-db-name")).Collection(viper.GetString("kitchen.mongo-intake-coll-name"))
+}
+
+// pauseModel MongoDB 的暂停接单状态模型
+type pauseModel struct {
+	ID        string    `bson:"_id"`
+	Paused    bool      `bson:"paused"`
+	Reason    string    `bson:"reason"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
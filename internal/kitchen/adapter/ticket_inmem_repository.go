@@ -0,0 +1,132 @@
+package adapter
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	domain "github.com/furutachiKurea/gorder/kitchen/domain/ticket"
+)
+
+type MemoryTicketRepository struct {
+	lock  *sync.RWMutex
+	store map[string]*domain.Ticket
+}
+
+func NewMemoryTicketRepository() *MemoryTicketRepository {
+	return &MemoryTicketRepository{
+		lock:  &sync.RWMutex{},
+		store: make(map[string]*domain.Ticket),
+	}
+}
+
+func (m *MemoryTicketRepository) Create(_ context.Context, ticket *domain.Ticket) (*domain.Ticket, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, t := range m.store {
+		if t.OrderID == ticket.OrderID {
+			got := *t
+			return &got, nil
+		}
+	}
+
+	created := *ticket
+	created.ID = strconv.FormatInt(time.Now().UnixNano(), 10)
+	m.store[created.ID] = &created
+
+	result := created
+	return &result, nil
+}
+
+func (m *MemoryTicketRepository) Get(_ context.Context, ticketID string) (*domain.Ticket, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	t, ok := m.store[ticketID]
+	if !ok {
+		return nil, domain.NotFoundError{TicketID: ticketID}
+	}
+
+	got := *t
+	return &got, nil
+}
+
+func (m *MemoryTicketRepository) GetByOrderID(_ context.Context, orderID string) (*domain.Ticket, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	for _, t := range m.store {
+		if t.OrderID == orderID {
+			got := *t
+			return &got, nil
+		}
+	}
+
+	return nil, domain.NotFoundError{TicketID: "order/" + orderID}
+}
+
+func (m *MemoryTicketRepository) ListActive(_ context.Context) ([]*domain.Ticket, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	var res []*domain.Ticket
+	for _, t := range m.store {
+		if t.IsActive() {
+			got := *t
+			res = append(res, &got)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].CreatedAt.Before(res[j].CreatedAt)
+	})
+
+	return res, nil
+}
+
+func (m *MemoryTicketRepository) ListUpdatedSince(_ context.Context, since time.Time, limit int) ([]*domain.Ticket, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	var res []*domain.Ticket
+	for _, t := range m.store {
+		if !t.UpdatedAt.Before(since) {
+			got := *t
+			res = append(res, &got)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].UpdatedAt.Before(res[j].UpdatedAt)
+	})
+	if len(res) > limit {
+		res = res[:limit]
+	}
+
+	return res, nil
+}
+
+func (m *MemoryTicketRepository) Update(
+	ctx context.Context,
+	ticketID string,
+	updateFn func(ctx context.Context, ticket *domain.Ticket) (*domain.Ticket, error),
+) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	t, ok := m.store[ticketID]
+	if !ok {
+		return domain.NotFoundError{TicketID: ticketID}
+	}
+
+	current := *t
+	updated, err := updateFn(ctx, &current)
+	if err != nil {
+		return err
+	}
+	updated.UpdatedAt = time.Now()
+	m.store[ticketID] = updated
+
+	return nil
+}
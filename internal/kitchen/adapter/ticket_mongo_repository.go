@@ -0,0 +1,276 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/logging"
+	domain "github.com/furutachiKurea/gorder/kitchen/domain/ticket"
+
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type TicketRepositoryMongo struct {
+	db *mongo.Client
+}
+
+func NewTicketRepositoryMongo(db *mongo.Client) *TicketRepositoryMongo {
+	return &TicketRepositoryMongo{db: db}
+}
+
+// Create 以 order_id 为条件 upsert 工单，多个实例重复消费同一订单时只有第一次写入生效
+func (r *TicketRepositoryMongo) Create(ctx context.Context, ticket *domain.Ticket) (created *domain.Ticket, err error) {
+	_, deferlog := logging.WhenRequest(ctx, "TicketRepositoryMongo.Create", map[string]any{
+		"ticket": ticket,
+	})
+	defer deferlog(created, &err)
+
+	write := r.domainToMongo(ticket)
+	write.MongoID = primitive.NewObjectID()
+
+	read := &ticketModel{}
+	err = r.collection().FindOneAndUpdate(ctx,
+		bson.M{"order_id": ticket.OrderID},
+		bson.M{"$setOnInsert": write},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(read)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.unmarshal(read), nil
+}
+
+func (r *TicketRepositoryMongo) Get(ctx context.Context, ticketID string) (got *domain.Ticket, err error) {
+	_, deferlog := logging.WhenRequest(ctx, "TicketRepositoryMongo.Get", map[string]any{
+		"ticket_id": ticketID,
+	})
+	defer deferlog(got, &err)
+
+	mongoID, err := primitive.ObjectIDFromHex(ticketID)
+	if err != nil {
+		return nil, domain.NotFoundError{TicketID: ticketID}
+	}
+
+	return r.findOne(ctx, bson.M{"_id": mongoID}, ticketID)
+}
+
+func (r *TicketRepositoryMongo) GetByOrderID(ctx context.Context, orderID string) (got *domain.Ticket, err error) {
+	_, deferlog := logging.WhenRequest(ctx, "TicketRepositoryMongo.GetByOrderID", map[string]any{
+		"order_id": orderID,
+	})
+	defer deferlog(got, &err)
+
+	return r.findOne(ctx, bson.M{"order_id": orderID}, "order/"+orderID)
+}
+
+func (r *TicketRepositoryMongo) ListActive(ctx context.Context) (got []*domain.Ticket, err error) {
+	_, deferlog := logging.WhenRequest(ctx, "TicketRepositoryMongo.ListActive", nil)
+	defer deferlog(got, &err)
+
+	return r.find(ctx,
+		bson.M{"status": bson.M{"$in": []string{string(domain.StatusQueued), string(domain.StatusCooking)}}},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+}
+
+func (r *TicketRepositoryMongo) ListUpdatedSince(ctx context.Context, since time.Time, limit int) (got []*domain.Ticket, err error) {
+	_, deferlog := logging.WhenRequest(ctx, "TicketRepositoryMongo.ListUpdatedSince", map[string]any{
+		"since": since,
+		"limit": limit,
+	})
+	defer deferlog(got, &err)
+
+	return r.find(ctx,
+		bson.M{"updated_at": bson.M{"$gte": since}},
+		options.Find().SetSort(bson.D{{Key: "updated_at", Value: 1}}).SetLimit(int64(limit)))
+}
+
+// Update 在事务中读取工单，执行 updateFn 后写回
+func (r *TicketRepositoryMongo) Update(
+	ctx context.Context,
+	ticketID string,
+	updateFn func(ctx context.Context, ticket *domain.Ticket) (*domain.Ticket, error),
+) (err error) {
+	_, deferlog := logging.WhenRequest(ctx, "TicketRepositoryMongo.Update", map[string]any{
+		"ticket_id": ticketID,
+	})
+	defer deferlog(nil, &err)
+
+	session, err := r.db.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (any, error) {
+		ticket, err := r.Get(sessCtx, ticketID)
+		if err != nil {
+			return nil, err
+		}
+
+		updated, err := updateFn(sessCtx, ticket)
+		if err != nil {
+			return nil, err
+		}
+		updated.UpdatedAt = time.Now()
+
+		write := r.domainToMongo(updated)
+		write.MongoID, _ = primitive.ObjectIDFromHex(ticketID)
+		_, err = r.collection().ReplaceOne(sessCtx, bson.M{"_id": write.MongoID}, write)
+		return nil, err
+	})
+
+	return err
+}
+
+func (r *TicketRepositoryMongo) findOne(ctx context.Context, cond bson.M, key string) (*domain.Ticket, error) {
+	read := &ticketModel{}
+	if err := r.collection().FindOne(ctx, cond).Decode(read); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.NotFoundError{TicketID: key}
+		}
+		return nil, fmt.Errorf("find ticket %s: %w", key, err)
+	}
+
+	return r.unmarshal(read), nil
+}
+
+func (r *TicketRepositoryMongo) find(ctx context.Context, cond bson.M, opts *options.FindOptions) ([]*domain.Ticket, error) {
+	cursor, err := r.collection().Find(ctx, cond, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var read []*ticketModel
+	if err = cursor.All(ctx, &read); err != nil {
+		return nil, fmt.Errorf("decode tickets: %w", err)
+	}
+
+	got := make([]*domain.Ticket, 0, len(read))
+	for _, m := range read {
+		got = append(got, r.unmarshal(m))
+	}
+	return got, nil
+}
+
+// collection 获取工单 collection
+func (r *TicketRepositoryMongo) collection() *mongo.Collection {
+	return r.db.Database(viper.GetString("kitchen.mongo-db-name")).Collection(viper.GetString("kitchen.mongo-coll-name"))
+}
+
+func (r *TicketRepositoryMongo) domainToMongo(t *domain.Ticket) *ticketModel {
+	items := make([]*ticketItemModel, 0, len(t.Items))
+	for _, i := range t.Items {
+		items = append(items, &ticketItemModel{
+			ID:       i.ID,
+			Name:     i.Name,
+			Quantity: i.Quantity,
+			PrepTime: i.PrepTime,
+		})
+	}
+
+	breaches := make([]*ticketBreachModel, 0, len(t.Breaches))
+	for _, b := range t.Breaches {
+		breaches = append(breaches, &ticketBreachModel{
+			Target:     string(b.Target),
+			DueAt:      b.DueAt,
+			BreachedAt: b.BreachedAt,
+		})
+	}
+
+	return &ticketModel{
+		OrderID:          t.OrderID,
+		CustomerID:       t.CustomerID,
+		Status:           string(t.Status),
+		Items:            items,
+		CreatedAt:        t.CreatedAt,
+		UpdatedAt:        t.UpdatedAt,
+		StartedAt:        t.StartedAt,
+		DoneAt:           t.DoneAt,
+		FailureReason:    t.FailureReason,
+		Express:          t.Express,
+		PickupAt:         t.PickupAt,
+		StartBy:          t.SLA.StartBy,
+		ReadyBy:          t.SLA.ReadyBy,
+		Breaches:         breaches,
+		EstimatedReadyAt: t.EstimatedReadyAt,
+	}
+}
+
+func (r *TicketRepositoryMongo) unmarshal(m *ticketModel) *domain.Ticket {
+	items := make([]*domain.Item, 0, len(m.Items))
+	for _, i := range m.Items {
+		items = append(items, &domain.Item{
+			ID:       i.ID,
+			Name:     i.Name,
+			Quantity: i.Quantity,
+			PrepTime: i.PrepTime,
+		})
+	}
+
+	breaches := make([]*domain.Breach, 0, len(m.Breaches))
+	for _, b := range m.Breaches {
+		breaches = append(breaches, &domain.Breach{
+			Target:     domain.SLATarget(b.Target),
+			DueAt:      b.DueAt,
+			BreachedAt: b.BreachedAt,
+		})
+	}
+
+	return &domain.Ticket{
+		ID:               m.MongoID.Hex(),
+		OrderID:          m.OrderID,
+		CustomerID:       m.CustomerID,
+		Status:           domain.Status(m.Status),
+		Items:            items,
+		CreatedAt:        m.CreatedAt,
+		UpdatedAt:        m.UpdatedAt,
+		StartedAt:        m.StartedAt,
+		DoneAt:           m.DoneAt,
+		FailureReason:    m.FailureReason,
+		Express:          m.Express,
+		PickupAt:         m.PickupAt,
+		SLA:              domain.SLA{StartBy: m.StartBy, ReadyBy: m.ReadyBy},
+		Breaches:         breaches,
+		EstimatedReadyAt: m.EstimatedReadyAt,
+	}
+}
+
+// ticketModel MongoDB 的工单模型
+type ticketModel struct {
+	MongoID          primitive.ObjectID   `bson:"_id"`
+	OrderID          string               `bson:"order_id"`
+	CustomerID       string               `bson:"customer_id"`
+	Status           string               `bson:"status"`
+	Items            []*ticketItemModel   `bson:"items"`
+	CreatedAt        time.Time            `bson:"created_at"`
+	UpdatedAt        time.Time            `bson:"updated_at"`
+	StartedAt        *time.Time           `bson:"started_at"`
+	DoneAt           *time.Time           `bson:"done_at"`
+	FailureReason    string               `bson:"failure_reason"`
+	Express          bool                 `bson:"express"`
+	PickupAt         *time.Time           `bson:"pickup_at"`
+	StartBy          time.Time            `bson:"start_by"`
+	ReadyBy          time.Time            `bson:"ready_by"`
+	Breaches         []*ticketBreachModel `bson:"breaches"`
+	EstimatedReadyAt *time.Time           `bson:"estimated_ready_at"`
+}
+
+type ticketItemModel struct {
+	ID       string        `bson:"id"`
+	Name     string        `bson:"name"`
+	Quantity int64         `bson:"quantity"`
+	PrepTime time.Duration `bson:"prep_time"`
+}
+
+type ticketBreachModel struct {
+	Target     string    `bson:"target"`
+	DueAt      time.Time `bson:"due_at"`
+	BreachedAt time.Time `bson:"breached_at"`
+}
@@ -0,0 +1,28 @@
+package app
+
+import (
+	"github.com/furutachiKurea/gorder/kitchen/app/command"
+	"github.com/furutachiKurea/gorder/kitchen/app/query"
+)
+
+type Application struct {
+	Commands Commands
+	Queries  Queries
+}
+
+type Commands struct {
+	CreateTicket       command.CreateTicketHandler
+	CookTicket         command.CookTicketHandler
+	FailTicket         command.FailTicketHandler
+	BumpTicket         command.BumpTicketHandler
+	CheckSLA           command.CheckSLAHandler
+	EstimateReadyTimes command.EstimateReadyTimesHandler
+	PublishLoad        command.PublishLoadHandler
+	PauseIntake        command.PauseIntakeHandler
+}
+
+type Queries struct {
+	ListActiveTickets query.ListActiveTicketsHandler
+	ListTicketChanges query.ListTicketChangesHandler
+	GetLoad           query.GetLoadHandler
+}
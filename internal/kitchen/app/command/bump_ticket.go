@@ -0,0 +1,116 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/broker"
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/logging"
+	"github.com/furutachiKurea/gorder/common/tracing"
+	domain "github.com/furutachiKurea/gorder/kitchen/domain/ticket"
+
+	"github.com/rs/zerolog"
+)
+
+// BumpTicket 厨房员工手动推进工单，Status 为 StatusCooking(开始制作) 或 StatusDone(制作完成)
+type BumpTicket struct {
+	TicketID string
+	Status   domain.Status
+}
+
+// BumpTicketHandler 推进工单状态并发布工单事件。
+// 等待中的工单可以直接完成；已完成的工单再次完成时只重新发布 ready 事件，用于订单未更新时的手动补发
+type BumpTicketHandler decorator.CommandHandler[BumpTicket, *domain.Ticket]
+
+type bumpTicketHandler struct {
+	ticketRepo domain.Repository
+	events     ticketEventPublisher
+	reporter   slaReporter
+}
+
+func NewBumpTicketHandler(
+	ticketRepo domain.Repository,
+	publisher broker.Publisher,
+	logger zerolog.Logger,
+	metricsClient decorator.MetricsClient,
+) BumpTicketHandler {
+	if ticketRepo == nil {
+		panic("ticketRepo is nil")
+	}
+
+	if publisher == nil {
+		panic("publisher is nil")
+	}
+
+	return decorator.ApplyCommandDecorators[BumpTicket, *domain.Ticket](
+		bumpTicketHandler{
+			ticketRepo: ticketRepo,
+			events:     ticketEventPublisher{publisher: publisher},
+			reporter:   slaReporter{publisher: publisher, metricsClient: metricsClient},
+		},
+		logger,
+		metricsClient,
+	)
+}
+
+func (h bumpTicketHandler) Handle(ctx context.Context, cmd BumpTicket) (*domain.Ticket, error) {
+	var err error
+	defer logging.WhenCommandExecute(ctx, "BumpTicketHandler", cmd, err)
+
+	ctx, span := tracing.Start(ctx, "bumpTicketHandler")
+	defer span.End()
+
+	now := time.Now()
+	switch cmd.Status {
+	case domain.StatusCooking:
+		t, breaches, err := updateTicketWithSLA(ctx, h.ticketRepo, cmd.TicketID, func(t *domain.Ticket) error {
+			if t.Status == domain.StatusCooking {
+				return nil
+			}
+			return t.Start(now)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		h.reporter.report(ctx, t, breaches)
+		if err = h.events.started(ctx, t); err != nil {
+			return nil, err
+		}
+		return t, nil
+	case domain.StatusDone:
+		// skipped 等待中的工单直接完成，跳过了开始制作，需要先补发 started 事件
+		var skipped bool
+		t, breaches, err := updateTicketWithSLA(ctx, h.ticketRepo, cmd.TicketID, func(t *domain.Ticket) error {
+			skipped = false
+			if t.Status == domain.StatusDone {
+				return nil
+			}
+			if t.Status == domain.StatusQueued {
+				if err := t.Start(now); err != nil {
+					return err
+				}
+				skipped = true
+			}
+			return t.Done(now)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		h.reporter.report(ctx, t, breaches)
+		if skipped {
+			if err = h.events.started(ctx, t); err != nil {
+				return nil, err
+			}
+		}
+		if err = h.events.ready(ctx, t); err != nil {
+			return nil, err
+		}
+		return t, nil
+	default:
+		return nil, fmt.Errorf("bump ticket %s to %s not supported", cmd.TicketID, cmd.Status)
+	}
+}
@@ -0,0 +1,81 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/broker"
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/logging"
+	"github.com/furutachiKurea/gorder/common/tracing"
+	domain "github.com/furutachiKurea/gorder/kitchen/domain/ticket"
+
+	"github.com/rs/zerolog"
+)
+
+// CheckSLA 检查等待中和制作中的工单是否违反 SLA，返回新发现违约的数量
+type CheckSLA struct{}
+
+// CheckSLAHandler 在工单上记录新发现的违约后发布 kitchen.sla_breached 事件，多个实例同时检查时每个违约只发布一次
+type CheckSLAHandler decorator.CommandHandler[CheckSLA, int]
+
+type checkSLAHandler struct {
+	ticketRepo domain.Repository
+	reporter   slaReporter
+}
+
+func NewCheckSLAHandler(
+	ticketRepo domain.Repository,
+	publisher broker.Publisher,
+	logger zerolog.Logger,
+	metricsClient decorator.MetricsClient,
+) CheckSLAHandler {
+	if ticketRepo == nil {
+		panic("ticketRepo is nil")
+	}
+
+	if publisher == nil {
+		panic("publisher is nil")
+	}
+
+	return decorator.ApplyCommandDecorators[CheckSLA, int](
+		checkSLAHandler{
+			ticketRepo: ticketRepo,
+			reporter:   slaReporter{publisher: publisher, metricsClient: metricsClient},
+		},
+		logger,
+		metricsClient,
+	)
+}
+
+func (h checkSLAHandler) Handle(ctx context.Context, cmd CheckSLA) (int, error) {
+	var err error
+	defer logging.WhenCommandExecute(ctx, "CheckSLAHandler", cmd, err)
+
+	ctx, span := tracing.Start(ctx, "checkSLAHandler")
+	defer span.End()
+
+	tickets, err := h.ticketRepo.ListActive(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("list active tickets: %w", err)
+	}
+
+	var breached int
+	for _, t := range tickets {
+		// 先在读取的工单上检查，没有新违约的工单不写回，避免显示屏收到没有变化的工单
+		if len(t.CheckSLA(time.Now())) == 0 {
+			continue
+		}
+
+		updated, breaches, err := updateTicketWithSLA(ctx, h.ticketRepo, t.ID, func(*domain.Ticket) error { return nil })
+		if err != nil {
+			return breached, fmt.Errorf("check sla of ticket %s: %w", t.ID, err)
+		}
+
+		h.reporter.report(ctx, updated, breaches)
+		breached += len(breaches)
+	}
+
+	return breached, nil
+}
@@ -0,0 +1,140 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/broker"
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/logging"
+	"github.com/furutachiKurea/gorder/common/tracing"
+	domain "github.com/furutachiKurea/gorder/kitchen/domain/ticket"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// CookTicket 制作工单，开始和完成制作时发布工单事件
+type CookTicket struct {
+	TicketID string
+}
+
+// CookTicketHandler 按工单中商品的制作时间制作工单，可重复调用：
+// 制作被中断(实例重启)的工单重新开始制作，已完成和失败的工单不做处理
+type CookTicketHandler decorator.CommandHandler[CookTicket, *domain.Ticket]
+
+type cookTicketHandler struct {
+	ticketRepo domain.Repository
+	events     ticketEventPublisher
+	reporter   slaReporter
+}
+
+func NewCookTicketHandler(
+	ticketRepo domain.Repository,
+	publisher broker.Publisher,
+	logger zerolog.Logger,
+	metricsClient decorator.MetricsClient,
+) CookTicketHandler {
+	if ticketRepo == nil {
+		panic("ticketRepo is nil")
+	}
+
+	if publisher == nil {
+		panic("publisher is nil")
+	}
+
+	return decorator.ApplyCommandDecorators[CookTicket, *domain.Ticket](
+		cookTicketHandler{
+			ticketRepo: ticketRepo,
+			events:     ticketEventPublisher{publisher: publisher},
+			reporter:   slaReporter{publisher: publisher, metricsClient: metricsClient},
+		},
+		logger,
+		metricsClient,
+	)
+}
+
+func (h cookTicketHandler) Handle(ctx context.Context, cmd CookTicket) (*domain.Ticket, error) {
+	var err error
+	defer logging.WhenCommandExecute(ctx, "CookTicketHandler", cmd, err)
+
+	ctx, span := tracing.Start(ctx, "cookTicketHandler")
+	defer span.End()
+
+	t, err := h.ticketRepo.Get(ctx, cmd.TicketID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch t.Status {
+	case domain.StatusFailed:
+		log.Warn().Ctx(ctx).Str("ticket_id", t.ID).Str("order_id", t.OrderID).Msg("ticket already failed, skip cooking")
+		return t, nil
+	case domain.StatusDone:
+		// 完成后 ready 事件可能发布失败，重新投递的消息重新发布
+		log.Info().Ctx(ctx).Str("ticket_id", t.ID).Str("order_id", t.OrderID).Msg("ticket already done, republish ready")
+		if err = h.events.ready(ctx, t); err != nil {
+			return nil, err
+		}
+		return t, nil
+	default:
+		return h.cook(ctx, t)
+	}
+}
+
+// cook 开始制作工单，等待工单的制作时间后完成，ctx 结束时工单保持制作中，由重新投递的消息继续制作。
+// 开始和完成的状态保存后发布工单事件并检查工单是否违反 SLA，中断后重新制作的工单会再次发布 started 事件
+func (h cookTicketHandler) cook(ctx context.Context, t *domain.Ticket) (*domain.Ticket, error) {
+	started, breaches, err := updateTicketWithSLA(ctx, h.ticketRepo, t.ID, func(t *domain.Ticket) error {
+		if t.Status == domain.StatusCooking {
+			return nil
+		}
+		return t.Start(time.Now())
+	})
+	if err != nil {
+		return nil, err
+	}
+	h.reporter.report(ctx, started, breaches)
+	if err = h.events.started(ctx, started); err != nil {
+		return nil, err
+	}
+
+	prepTime := t.PrepTime()
+	log.Info().Ctx(ctx).Str("ticket_id", t.ID).Str("order_id", t.OrderID).Dur("prep_time", prepTime).Msg("cooking ticket")
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(prepTime):
+	}
+
+	done, breaches, err := updateTicketWithSLA(ctx, h.ticketRepo, t.ID, func(t *domain.Ticket) error {
+		return t.Done(time.Now())
+	})
+	if err != nil {
+		return nil, err
+	}
+	h.reporter.report(ctx, done, breaches)
+	if err = h.events.ready(ctx, done); err != nil {
+		return nil, err
+	}
+
+	log.Info().Ctx(ctx).Str("ticket_id", t.ID).Str("order_id", t.OrderID).Msg("ticket done")
+	return done, nil
+}
+
+// updateTicket 在工单上执行 fn 并写回，返回更新后的工单
+func updateTicket(ctx context.Context, ticketRepo domain.Repository, ticketID string, fn func(t *domain.Ticket) error) (*domain.Ticket, error) {
+	var updated *domain.Ticket
+	err := ticketRepo.Update(ctx, ticketID, func(_ context.Context, t *domain.Ticket) (*domain.Ticket, error) {
+		if err := fn(t); err != nil {
+			return nil, err
+		}
+		updated = t
+		return t, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
@@ -0,0 +1,69 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/furutachiKurea/gorder/common/consts"
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/entity"
+	"github.com/furutachiKurea/gorder/common/logging"
+	"github.com/furutachiKurea/gorder/common/tracing"
+	domain "github.com/furutachiKurea/gorder/kitchen/domain/ticket"
+
+	"github.com/rs/zerolog"
+)
+
+// CreateTicket 为已支付的订单创建工单，订单已有工单时返回已有的工单
+type CreateTicket struct {
+	Order *entity.Order
+}
+
+type CreateTicketHandler decorator.CommandHandler[CreateTicket, *domain.Ticket]
+
+type createTicketHandler struct {
+	ticketRepo domain.Repository
+	prepTimes  domain.PrepTimes
+	slaPolicy  domain.SLAPolicy
+}
+
+func NewCreateTicketHandler(
+	ticketRepo domain.Repository,
+	prepTimes domain.PrepTimes,
+	slaPolicy domain.SLAPolicy,
+	logger zerolog.Logger,
+	metricsClient decorator.MetricsClient,
+) CreateTicketHandler {
+	if ticketRepo == nil {
+		panic("ticketRepo is nil")
+	}
+
+	return decorator.ApplyCommandDecorators[CreateTicket, *domain.Ticket](
+		createTicketHandler{ticketRepo: ticketRepo, prepTimes: prepTimes, slaPolicy: slaPolicy},
+		logger,
+		metricsClient,
+	)
+}
+
+func (h createTicketHandler) Handle(ctx context.Context, cmd CreateTicket) (*domain.Ticket, error) {
+	var err error
+	defer logging.WhenCommandExecute(ctx, "CreateTicketHandler", cmd, err)
+
+	ctx, span := tracing.Start(ctx, "createTicketHandler")
+	defer span.End()
+
+	if cmd.Order == nil {
+		return nil, errors.New("empty order")
+	}
+	if cmd.Order.Status != consts.OrderStatusPaid {
+		return nil, fmt.Errorf("order %s is %s, not paid, cannot cook", cmd.Order.ID, cmd.Order.Status)
+	}
+
+	t, err := domain.NewTicket(cmd.Order, h.prepTimes, h.slaPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.ticketRepo.Create(ctx, t)
+}
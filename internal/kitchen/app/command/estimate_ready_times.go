@@ -0,0 +1,133 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/broker"
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/entity"
+	"github.com/furutachiKurea/gorder/common/logging"
+	"github.com/furutachiKurea/gorder/common/tracing"
+	domain "github.com/furutachiKurea/gorder/kitchen/domain/ticket"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+)
+
+// EstimateReadyTimes 根据当前的队列重新估计等待中和制作中的工单的完成时间，返回发布了新估计的工单数量
+type EstimateReadyTimes struct{}
+
+// EstimateReadyTimesHandler 估计变化达到 minChange 时将估计写入工单并发布 kitchen.eta_updated 事件。
+// 事件在更新事务内发布，发布失败时估计不会写入，下次估计时会再次发布
+type EstimateReadyTimesHandler decorator.CommandHandler[EstimateReadyTimes, int]
+
+type estimateReadyTimesHandler struct {
+	ticketRepo domain.Repository
+	priority   domain.PriorityPolicy
+	capacity   int
+	minChange  time.Duration
+	publisher  broker.Publisher
+}
+
+// NewEstimateReadyTimesHandler capacity 为厨房同时制作的工单数量
+func NewEstimateReadyTimesHandler(
+	ticketRepo domain.Repository,
+	priority domain.PriorityPolicy,
+	capacity int,
+	minChange time.Duration,
+	publisher broker.Publisher,
+	logger zerolog.Logger,
+	metricsClient decorator.MetricsClient,
+) EstimateReadyTimesHandler {
+	if ticketRepo == nil {
+		panic("ticketRepo is nil")
+	}
+
+	if publisher == nil {
+		panic("publisher is nil")
+	}
+
+	return decorator.ApplyCommandDecorators[EstimateReadyTimes, int](
+		estimateReadyTimesHandler{
+			ticketRepo: ticketRepo,
+			priority:   priority,
+			capacity:   capacity,
+			minChange:  minChange,
+			publisher:  publisher,
+		},
+		logger,
+		metricsClient,
+	)
+}
+
+func (h estimateReadyTimesHandler) Handle(ctx context.Context, cmd EstimateReadyTimes) (int, error) {
+	var err error
+	defer logging.WhenCommandExecute(ctx, "EstimateReadyTimesHandler", cmd, err)
+
+	ctx, span := tracing.Start(ctx, "estimateReadyTimesHandler")
+	defer span.End()
+
+	tickets, err := h.ticketRepo.ListActive(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("list active tickets: %w", err)
+	}
+
+	now := time.Now()
+	h.priority.Sort(tickets, now)
+	estimates := domain.EstimateReadyTimes(tickets, h.priority, h.capacity, now)
+
+	var updated int
+	for _, t := range tickets {
+		readyAt := estimates[t.ID]
+		// 先在读取的工单上比较，变化不大的工单不写回
+		if !t.UpdateEstimate(readyAt, h.minChange) {
+			continue
+		}
+
+		var changed bool
+		_, err = updateTicket(ctx, h.ticketRepo, t.ID, func(t *domain.Ticket) error {
+			if changed = t.UpdateEstimate(readyAt, h.minChange); !changed {
+				return nil
+			}
+			return h.publish(ctx, t, now)
+		})
+		if err != nil {
+			return updated, fmt.Errorf("update estimate of ticket %s: %w", t.ID, err)
+		}
+		if changed {
+			updated++
+		}
+	}
+
+	return updated, nil
+}
+
+func (h estimateReadyTimesHandler) publish(ctx context.Context, t *domain.Ticket, estimatedAt time.Time) error {
+	ctx, span := otel.Tracer("rabbitmq").Start(ctx, fmt.Sprintf("rabbitmq.%s.publish", broker.EventKitchenETAUpdated))
+	defer span.End()
+
+	if err := broker.PublishEvent(ctx, &broker.PublishEventReq{
+		Publisher: h.publisher,
+		Routing:   broker.FanOut,
+		Queue:     "",
+		Exchange:  broker.EventKitchenETAUpdated,
+		Body: &entity.OrderETA{
+			OrderID:          t.OrderID,
+			CustomerID:       t.CustomerID,
+			EstimatedReadyAt: *t.EstimatedReadyAt,
+			EstimatedAt:      estimatedAt,
+		},
+	}); err != nil {
+		return fmt.Errorf("publish event error exchange=%s, err:%w", broker.EventKitchenETAUpdated, err)
+	}
+
+	log.Info().Ctx(ctx).
+		Str("ticket_id", t.ID).
+		Str("order_id", t.OrderID).
+		Time("estimated_ready_at", *t.EstimatedReadyAt).
+		Msgf("message published to %s", broker.EventKitchenETAUpdated)
+	return nil
+}
@@ -0,0 +1,70 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/broker"
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/logging"
+	"github.com/furutachiKurea/gorder/common/tracing"
+	domain "github.com/furutachiKurea/gorder/kitchen/domain/ticket"
+
+	"github.com/rs/zerolog"
+)
+
+// FailTicket 将无法完成的工单标记为失败并发布 kitchen.ticket_failed 事件
+type FailTicket struct {
+	TicketID string
+	Reason   string
+}
+
+type FailTicketHandler decorator.CommandHandler[FailTicket, any]
+
+type failTicketHandler struct {
+	ticketRepo domain.Repository
+	events     ticketEventPublisher
+}
+
+func NewFailTicketHandler(
+	ticketRepo domain.Repository,
+	publisher broker.Publisher,
+	logger zerolog.Logger,
+	metricsClient decorator.MetricsClient,
+) FailTicketHandler {
+	if ticketRepo == nil {
+		panic("ticketRepo is nil")
+	}
+
+	if publisher == nil {
+		panic("publisher is nil")
+	}
+
+	return decorator.ApplyCommandDecorators[FailTicket, any](
+		failTicketHandler{ticketRepo: ticketRepo, events: ticketEventPublisher{publisher: publisher}},
+		logger,
+		metricsClient,
+	)
+}
+
+func (h failTicketHandler) Handle(ctx context.Context, cmd FailTicket) (any, error) {
+	var err error
+	defer logging.WhenCommandExecute(ctx, "FailTicketHandler", cmd, err)
+
+	ctx, span := tracing.Start(ctx, "failTicketHandler")
+	defer span.End()
+
+	// 已经失败的工单不再更新，重试时重新发布 failed 事件
+	t, err := updateTicket(ctx, h.ticketRepo, cmd.TicketID, func(t *domain.Ticket) error {
+		if t.Status == domain.StatusFailed {
+			return nil
+		}
+		return t.Fail(cmd.Reason, time.Now())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = h.events.failed(ctx, t)
+	return nil, err
+}
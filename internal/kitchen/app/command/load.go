@@ -0,0 +1,56 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/broker"
+	"github.com/furutachiKurea/gorder/common/entity"
+	"github.com/furutachiKurea/gorder/kitchen/domain/intake"
+	domain "github.com/furutachiKurea/gorder/kitchen/domain/ticket"
+
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+)
+
+// loadPublisher 计算厨房当前的负载并发布 kitchen.load_updated 事件
+type loadPublisher struct {
+	ticketRepo domain.Repository
+	intakeRepo intake.Repository
+	policy     intake.Policy
+	publisher  broker.Publisher
+}
+
+func (p loadPublisher) publish(ctx context.Context) (*entity.KitchenLoad, error) {
+	pause, err := p.intakeRepo.GetPause(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get intake pause: %w", err)
+	}
+
+	tickets, err := p.ticketRepo.ListActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list active tickets: %w", err)
+	}
+
+	load := p.policy.Load(pause, len(tickets), time.Now())
+
+	ctx, span := otel.Tracer("rabbitmq").Start(ctx, fmt.Sprintf("rabbitmq.%s.publish", broker.EventKitchenLoadUpdated))
+	defer span.End()
+
+	if err = broker.PublishEvent(ctx, &broker.PublishEventReq{
+		Publisher: p.publisher,
+		Routing:   broker.FanOut,
+		Queue:     "",
+		Exchange:  broker.EventKitchenLoadUpdated,
+		Body:      load,
+	}); err != nil {
+		return nil, fmt.Errorf("publish event error exchange=%s, err:%w", broker.EventKitchenLoadUpdated, err)
+	}
+
+	log.Debug().Ctx(ctx).
+		Str("level", string(load.Level)).
+		Int("backlog", load.Backlog).
+		Msgf("message published to %s", broker.EventKitchenLoadUpdated)
+	return load, nil
+}
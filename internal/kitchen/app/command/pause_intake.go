@@ -0,0 +1,84 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/broker"
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/entity"
+	"github.com/furutachiKurea/gorder/common/logging"
+	"github.com/furutachiKurea/gorder/common/tracing"
+	"github.com/furutachiKurea/gorder/kitchen/domain/intake"
+	domain "github.com/furutachiKurea/gorder/kitchen/domain/ticket"
+
+	"github.com/rs/zerolog"
+)
+
+// PauseIntake 厨房员工暂停(Paused 为 true)或恢复接单，Reason 为暂停的原因
+type PauseIntake struct {
+	Paused bool
+	Reason string
+}
+
+// PauseIntakeHandler 保存暂停状态后立即发布新的负载，不等待下一次定期发布
+type PauseIntakeHandler decorator.CommandHandler[PauseIntake, *entity.KitchenLoad]
+
+type pauseIntakeHandler struct {
+	intakeRepo intake.Repository
+	publisher  loadPublisher
+}
+
+func NewPauseIntakeHandler(
+	ticketRepo domain.Repository,
+	intakeRepo intake.Repository,
+	policy intake.Policy,
+	publisher broker.Publisher,
+	logger zerolog.Logger,
+	metricsClient decorator.MetricsClient,
+) PauseIntakeHandler {
+	if ticketRepo == nil {
+		panic("ticketRepo is nil")
+	}
+
+	if intakeRepo == nil {
+		panic("intakeRepo is nil")
+	}
+
+	if publisher == nil {
+		panic("publisher is nil")
+	}
+
+	return decorator.ApplyCommandDecorators[PauseIntake, *entity.KitchenLoad](
+		pauseIntakeHandler{
+			intakeRepo: intakeRepo,
+			publisher: loadPublisher{
+				ticketRepo: ticketRepo,
+				intakeRepo: intakeRepo,
+				policy:     policy,
+				publisher:  publisher,
+			},
+		},
+		logger,
+		metricsClient,
+	)
+}
+
+func (h pauseIntakeHandler) Handle(ctx context.Context, cmd PauseIntake) (*entity.KitchenLoad, error) {
+	var err error
+	defer logging.WhenCommandExecute(ctx, "PauseIntakeHandler", cmd, err)
+
+	ctx, span := tracing.Start(ctx, "pauseIntakeHandler")
+	defer span.End()
+
+	pause := &intake.Pause{Paused: cmd.Paused, UpdatedAt: time.Now()}
+	if cmd.Paused {
+		pause.Reason = cmd.Reason
+	}
+	if err = h.intakeRepo.SetPause(ctx, pause); err != nil {
+		return nil, fmt.Errorf("set intake pause: %w", err)
+	}
+
+	return h.publisher.publish(ctx)
+}
@@ -0,0 +1,69 @@
+package command
+
+import (
+	"context"
+
+	"github.com/furutachiKurea/gorder/common/broker"
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/entity"
+	"github.com/furutachiKurea/gorder/common/logging"
+	"github.com/furutachiKurea/gorder/common/tracing"
+	"github.com/furutachiKurea/gorder/kitchen/domain/intake"
+	domain "github.com/furutachiKurea/gorder/kitchen/domain/ticket"
+
+	"github.com/rs/zerolog"
+)
+
+// PublishLoad 发布厨房当前的负载，订单服务据此决定是否接受新订单
+type PublishLoad struct{}
+
+// PublishLoadHandler 定期发布负载，订单服务将长时间未更新的负载视为过期
+type PublishLoadHandler decorator.CommandHandler[PublishLoad, *entity.KitchenLoad]
+
+type publishLoadHandler struct {
+	publisher loadPublisher
+}
+
+func NewPublishLoadHandler(
+	ticketRepo domain.Repository,
+	intakeRepo intake.Repository,
+	policy intake.Policy,
+	publisher broker.Publisher,
+	logger zerolog.Logger,
+	metricsClient decorator.MetricsClient,
+) PublishLoadHandler {
+	if ticketRepo == nil {
+		panic("ticketRepo is nil")
+	}
+
+	if intakeRepo == nil {
+		panic("intakeRepo is nil")
+	}
+
+	if publisher == nil {
+		panic("publisher is nil")
+	}
+
+	return decorator.ApplyCommandDecorators[PublishLoad, *entity.KitchenLoad](
+		publishLoadHandler{
+			publisher: loadPublisher{
+				ticketRepo: ticketRepo,
+				intakeRepo: intakeRepo,
+				policy:     policy,
+				publisher:  publisher,
+			},
+		},
+		logger,
+		metricsClient,
+	)
+}
+
+func (h publishLoadHandler) Handle(ctx context.Context, cmd PublishLoad) (*entity.KitchenLoad, error) {
+	var err error
+	defer logging.WhenCommandExecute(ctx, "PublishLoadHandler", cmd, err)
+
+	ctx, span := tracing.Start(ctx, "publishLoadHandler")
+	defer span.End()
+
+	return h.publisher.publish(ctx)
+}
@@ -0,0 +1,84 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/broker"
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/entity"
+	domain "github.com/furutachiKurea/gorder/kitchen/domain/ticket"
+
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+)
+
+// updateTicketWithSLA 在工单上执行 fn 后检查 SLA，返回更新后的工单和新发现的违约
+func updateTicketWithSLA(
+	ctx context.Context,
+	ticketRepo domain.Repository,
+	ticketID string,
+	fn func(t *domain.Ticket) error,
+) (*domain.Ticket, []*domain.Breach, error) {
+	var breaches []*domain.Breach
+	updated, err := updateTicket(ctx, ticketRepo, ticketID, func(t *domain.Ticket) error {
+		if err := fn(t); err != nil {
+			return err
+		}
+		breaches = t.CheckSLA(time.Now())
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return updated, breaches, nil
+}
+
+// slaReporter 为新发现的 SLA 违约发布 kitchen.sla_breached 事件并记录指标。
+// 违约在发布前已经写入工单，发布失败只记录日志，不会重复发布
+type slaReporter struct {
+	publisher     broker.Publisher
+	metricsClient decorator.MetricsClient
+}
+
+func (r slaReporter) report(ctx context.Context, t *domain.Ticket, breaches []*domain.Breach) {
+	for _, b := range breaches {
+		r.metricsClient.Inc(fmt.Sprintf("kitchen.sla_breached.%s", b.Target), 1)
+		log.Warn().Ctx(ctx).
+			Str("ticket_id", t.ID).
+			Str("order_id", t.OrderID).
+			Str("target", string(b.Target)).
+			Time("due_at", b.DueAt).
+			Time("breached_at", b.BreachedAt).
+			Msg("ticket sla breached")
+
+		if err := r.publish(ctx, t, b); err != nil {
+			log.Error().Ctx(ctx).Err(err).Str("ticket_id", t.ID).Msg("failed to publish sla breach")
+		}
+	}
+}
+
+func (r slaReporter) publish(ctx context.Context, t *domain.Ticket, b *domain.Breach) error {
+	ctx, span := otel.Tracer("rabbitmq").Start(ctx, fmt.Sprintf("rabbitmq.%s.publish", broker.EventKitchenSLABreached))
+	defer span.End()
+
+	if err := broker.PublishEvent(ctx, &broker.PublishEventReq{
+		Publisher: r.publisher,
+		Routing:   broker.FanOut,
+		Queue:     "",
+		Exchange:  broker.EventKitchenSLABreached,
+		Body: &entity.SLABreach{
+			TicketID:   t.ID,
+			OrderID:    t.OrderID,
+			CustomerID: t.CustomerID,
+			Target:     string(b.Target),
+			DueAt:      b.DueAt,
+			BreachedAt: b.BreachedAt,
+			Express:    t.Express,
+		},
+	}); err != nil {
+		return fmt.Errorf("publish event error exchange=%s, err:%w", broker.EventKitchenSLABreached, err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/furutachiKurea/gorder/common/broker"
+	"github.com/furutachiKurea/gorder/common/entity"
+	domain "github.com/furutachiKurea/gorder/kitchen/domain/ticket"
+
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+)
+
+// ticketEventPublisher 发布工单的生命周期事件，订单服务根据事件更新订单状态。
+// 事件在工单的更新事务提交后发布：事务冲突时会重新执行，在事务内发布会重复发布事件，或为回滚的状态发布事件。
+// 发布失败时返回错误，调用方重试时按工单已保存的状态重新发布，订单服务忽略重复和过期的事件
+type ticketEventPublisher struct {
+	publisher broker.Publisher
+}
+
+// started 发布 kitchen.ticket_started 事件
+func (p ticketEventPublisher) started(ctx context.Context, t *domain.Ticket) error {
+	return p.publish(ctx, broker.EventKitchenTicketStarted, t, &entity.TicketEvent{OccurredAt: *t.StartedAt})
+}
+
+// ready 发布 kitchen.ticket_ready 事件
+func (p ticketEventPublisher) ready(ctx context.Context, t *domain.Ticket) error {
+	return p.publish(ctx, broker.EventKitchenTicketReady, t, &entity.TicketEvent{OccurredAt: *t.DoneAt})
+}
+
+// failed 发布 kitchen.ticket_failed 事件
+func (p ticketEventPublisher) failed(ctx context.Context, t *domain.Ticket) error {
+	return p.publish(ctx, broker.EventKitchenTicketFailed, t, &entity.TicketEvent{Reason: t.FailureReason, OccurredAt: t.UpdatedAt})
+}
+
+func (p ticketEventPublisher) publish(ctx context.Context, exchange string, t *domain.Ticket, body *entity.TicketEvent) error {
+	ctx, span := otel.Tracer("rabbitmq").Start(ctx, fmt.Sprintf("rabbitmq.%s.publish", exchange))
+	defer span.End()
+
+	body.TicketID, body.OrderID, body.CustomerID = t.ID, t.OrderID, t.CustomerID
+	if err := broker.PublishEvent(ctx, &broker.PublishEventReq{
+		Publisher: p.publisher,
+		Routing:   broker.FanOut,
+		Queue:     "",
+		Exchange:  exchange,
+		Body:      body,
+		Mandatory: true,
+	}); err != nil {
+		return fmt.Errorf("publish event error exchange=%s, err:%w", exchange, err)
+	}
+
+	log.Info().Ctx(ctx).
+		Str("ticket_id", t.ID).
+		Str("order_id", t.OrderID).
+		Msgf("message published to %s", exchange)
+	return nil
+}
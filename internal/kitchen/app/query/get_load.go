@@ -0,0 +1,65 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/entity"
+	"github.com/furutachiKurea/gorder/common/tracing"
+	"github.com/furutachiKurea/gorder/kitchen/domain/intake"
+	domain "github.com/furutachiKurea/gorder/kitchen/domain/ticket"
+
+	"github.com/rs/zerolog"
+)
+
+// GetLoad 查询厨房当前的负载和暂停接单的状态
+type GetLoad struct{}
+
+type GetLoadHandler decorator.QueryHandler[GetLoad, *entity.KitchenLoad]
+
+type getLoadHandler struct {
+	ticketRepo domain.Repository
+	intakeRepo intake.Repository
+	policy     intake.Policy
+}
+
+func NewGetLoadHandler(
+	ticketRepo domain.Repository,
+	intakeRepo intake.Repository,
+	policy intake.Policy,
+	logger zerolog.Logger,
+	metricsClient decorator.MetricsClient,
+) GetLoadHandler {
+	if ticketRepo == nil {
+		panic("ticketRepo is nil")
+	}
+
+	if intakeRepo == nil {
+		panic("intakeRepo is nil")
+	}
+
+	return decorator.ApplyQueryDecorators[GetLoad, *entity.KitchenLoad](
+		getLoadHandler{ticketRepo: ticketRepo, intakeRepo: intakeRepo, policy: policy},
+		logger,
+		metricsClient,
+	)
+}
+
+func (h getLoadHandler) Handle(ctx context.Context, _ GetLoad) (*entity.KitchenLoad, error) {
+	ctx, span := tracing.Start(ctx, "getLoadHandler")
+	defer span.End()
+
+	pause, err := h.intakeRepo.GetPause(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get intake pause: %w", err)
+	}
+
+	tickets, err := h.ticketRepo.ListActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list active tickets: %w", err)
+	}
+
+	return h.policy.Load(pause, len(tickets), time.Now()), nil
+}
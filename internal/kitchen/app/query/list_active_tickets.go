@@ -0,0 +1,53 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/tracing"
+	domain "github.com/furutachiKurea/gorder/kitchen/domain/ticket"
+
+	"github.com/rs/zerolog"
+)
+
+// ListActiveTickets 查询等待中和制作中的工单，用于厨房显示屏，工单按制作顺序排序
+type ListActiveTickets struct{}
+
+type ListActiveTicketsHandler decorator.QueryHandler[ListActiveTickets, []*domain.Ticket]
+
+type listActiveTicketsHandler struct {
+	ticketRepo domain.Repository
+	priority   domain.PriorityPolicy
+}
+
+func NewListActiveTicketsHandler(
+	ticketRepo domain.Repository,
+	priority domain.PriorityPolicy,
+	logger zerolog.Logger,
+	metricsClient decorator.MetricsClient,
+) ListActiveTicketsHandler {
+	if ticketRepo == nil {
+		panic("ticketRepo is nil")
+	}
+
+	return decorator.ApplyQueryDecorators[ListActiveTickets, []*domain.Ticket](
+		listActiveTicketsHandler{ticketRepo: ticketRepo, priority: priority},
+		logger,
+		metricsClient,
+	)
+}
+
+func (h listActiveTicketsHandler) Handle(ctx context.Context, _ ListActiveTickets) ([]*domain.Ticket, error) {
+	ctx, span := tracing.Start(ctx, "listActiveTicketsHandler")
+	defer span.End()
+
+	tickets, err := h.ticketRepo.ListActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list active tickets: %w", err)
+	}
+
+	h.priority.Sort(tickets, time.Now())
+	return tickets, nil
+}
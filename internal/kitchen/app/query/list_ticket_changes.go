@@ -0,0 +1,52 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/tracing"
+	domain "github.com/furutachiKurea/gorder/kitchen/domain/ticket"
+
+	"github.com/rs/zerolog"
+)
+
+// ListTicketChanges 查询更新时间不早于 Since 的工单，包括新创建的工单，按更新时间排序，最多返回 Limit 条
+type ListTicketChanges struct {
+	Since time.Time
+	Limit int
+}
+
+type ListTicketChangesHandler decorator.QueryHandler[ListTicketChanges, []*domain.Ticket]
+
+type listTicketChangesHandler struct {
+	ticketRepo domain.Repository
+}
+
+func NewListTicketChangesHandler(
+	ticketRepo domain.Repository,
+	logger zerolog.Logger,
+	metricsClient decorator.MetricsClient,
+) ListTicketChangesHandler {
+	if ticketRepo == nil {
+		panic("ticketRepo is nil")
+	}
+
+	return decorator.ApplyQueryDecorators[ListTicketChanges, []*domain.Ticket](
+		listTicketChangesHandler{ticketRepo: ticketRepo},
+		logger,
+		metricsClient,
+	)
+}
+
+func (h listTicketChangesHandler) Handle(ctx context.Context, query ListTicketChanges) ([]*domain.Ticket, error) {
+	ctx, span := tracing.Start(ctx, "listTicketChangesHandler")
+	defer span.End()
+
+	tickets, err := h.ticketRepo.ListUpdatedSince(ctx, query.Since, query.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("list tickets updated since %s: %w", query.Since, err)
+	}
+	return tickets, nil
+}
@@ -0,0 +1,51 @@
+package intake
+
+import (
+	"context"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/consts"
+	"github.com/furutachiKurea/gorder/common/entity"
+)
+
+// Pause 厨房员工手动暂停接单的状态，所有实例共享
+type Pause struct {
+	Paused    bool
+	Reason    string
+	UpdatedAt time.Time
+}
+
+// Policy 根据积压的工单数量计算厨房的负载等级，积压达到 BusyBacklog 时为 busy，BusyBacklog 为 0 时不限制
+type Policy struct {
+	BusyBacklog int
+}
+
+// Level 暂停接单时为 paused，否则根据积压的工单数量为 busy 或 open
+func (p Policy) Level(pause *Pause, backlog int) consts.KitchenLoadLevel {
+	if pause != nil && pause.Paused {
+		return consts.KitchenPaused
+	}
+	if p.BusyBacklog > 0 && backlog >= p.BusyBacklog {
+		return consts.KitchenBusy
+	}
+	return consts.KitchenOpen
+}
+
+// Load 计算厨房在 now 时的负载，暂停接单时带上暂停的原因
+func (p Policy) Load(pause *Pause, backlog int, now time.Time) *entity.KitchenLoad {
+	load := &entity.KitchenLoad{
+		Level:       p.Level(pause, backlog),
+		Backlog:     backlog,
+		PublishedAt: now,
+	}
+	if load.Level == consts.KitchenPaused {
+		load.Reason = pause.Reason
+	}
+	return load
+}
+
+type Repository interface {
+	// GetPause 获取暂停接单的状态，从未设置过时返回未暂停
+	GetPause(ctx context.Context) (*Pause, error)
+	SetPause(ctx context.Context, pause *Pause) error
+}
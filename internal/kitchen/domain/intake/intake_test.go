@@ -0,0 +1,37 @@
+package intake
+
+import (
+	"testing"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/consts"
+	"github.com/furutachiKurea/gorder/common/entity"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy_Level(t *testing.T) {
+	policy := Policy{BusyBacklog: 5}
+
+	assert.Equal(t, consts.KitchenOpen, policy.Level(nil, 4))
+	assert.Equal(t, consts.KitchenBusy, policy.Level(nil, 5))
+	assert.Equal(t, consts.KitchenOpen, policy.Level(&Pause{}, 0))
+	assert.Equal(t, consts.KitchenPaused, policy.Level(&Pause{Paused: true}, 0))
+	// BusyBacklog 为 0 时不限制
+	assert.Equal(t, consts.KitchenOpen, Policy{}.Level(nil, 1000))
+}
+
+func TestPolicy_Load(t *testing.T) {
+	now := time.Now()
+	policy := Policy{BusyBacklog: 5}
+
+	assert.Equal(t,
+		&entity.KitchenLoad{Level: consts.KitchenPaused, Backlog: 2, Reason: "cleaning", PublishedAt: now},
+		policy.Load(&Pause{Paused: true, Reason: "cleaning"}, 2, now),
+	)
+	// 恢复接单后不再带上暂停的原因
+	assert.Equal(t,
+		&entity.KitchenLoad{Level: consts.KitchenBusy, Backlog: 6, PublishedAt: now},
+		policy.Load(&Pause{Reason: "cleaning"}, 6, now),
+	)
+}
@@ -0,0 +1,71 @@
+package ticket
+
+import "time"
+
+// EstimateReadyTimes 模拟 capacity 名厨师按顺序制作 tickets，估计每个工单的完成时间，key 为工单 ID。
+// tickets 需要已经按 PriorityPolicy.Sort 排序：制作中的工单在开始时间加上制作时间完成，
+// 等待中的工单由最早空闲的厨师制作，预约工单不早于 EligibleAt 开始，完成时间不早于取餐时间
+func EstimateReadyTimes(tickets []*Ticket, policy PriorityPolicy, capacity int, now time.Time) map[string]time.Time {
+	if capacity <= 0 {
+		capacity = 1
+	}
+
+	// cooks 每名厨师空闲的时间
+	cooks := make([]time.Time, capacity)
+	for i := range cooks {
+		cooks[i] = now
+	}
+	earliest := func() int {
+		idx := 0
+		for i := range cooks {
+			if cooks[i].Before(cooks[idx]) {
+				idx = i
+			}
+		}
+		return idx
+	}
+
+	estimates := make(map[string]time.Time, len(tickets))
+	for _, t := range tickets {
+		if !t.IsActive() {
+			continue
+		}
+
+		cook := earliest()
+		var readyAt time.Time
+		if t.Status == StatusCooking && t.StartedAt != nil {
+			// 超出预计时间仍在制作的工单视为马上完成
+			readyAt = latest(t.StartedAt.Add(t.PrepTime()), now)
+		} else {
+			readyAt = latest(cooks[cook], policy.EligibleAt(t)).Add(t.PrepTime())
+		}
+		cooks[cook] = latest(cooks[cook], readyAt)
+
+		if t.PickupAt != nil {
+			readyAt = latest(readyAt, *t.PickupAt)
+		}
+		estimates[t.ID] = readyAt
+	}
+
+	return estimates
+}
+
+// UpdateEstimate 更新工单的预计完成时间，与原来的预计相差不足 minChange 时不更新，返回是否更新
+func (t *Ticket) UpdateEstimate(readyAt time.Time, minChange time.Duration) bool {
+	if t.EstimatedReadyAt != nil {
+		diff := readyAt.Sub(*t.EstimatedReadyAt)
+		if diff < minChange && diff > -minChange {
+			return false
+		}
+	}
+
+	t.EstimatedReadyAt = &readyAt
+	return true
+}
+
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
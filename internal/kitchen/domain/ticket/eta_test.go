@@ -0,0 +1,62 @@
+package ticket
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateReadyTimes(t *testing.T) {
+	now := time.Now()
+	prep := func(d time.Duration) []*Item { return []*Item{{ID: "item", PrepTime: d}} }
+	startedAt := now.Add(-2 * time.Minute)
+	pickupAt := now.Add(time.Hour)
+
+	tickets := []*Ticket{
+		{ID: "cooking", Status: StatusCooking, CreatedAt: startedAt, StartedAt: &startedAt, Items: prep(5 * time.Minute)},
+		{ID: "first", Status: StatusQueued, CreatedAt: now, Items: prep(4 * time.Minute)},
+		{ID: "second", Status: StatusQueued, CreatedAt: now, Items: prep(2 * time.Minute)},
+		{ID: "scheduled", Status: StatusQueued, CreatedAt: now, Items: prep(10 * time.Minute), PickupAt: &pickupAt},
+		{ID: "done", Status: StatusDone, CreatedAt: now, Items: prep(time.Minute)},
+	}
+
+	got := EstimateReadyTimes(tickets, PriorityPolicy{}, 2, now)
+	assert.Equal(t, map[string]time.Time{
+		// 制作中的工单在开始时间加上制作时间完成
+		"cooking": now.Add(3 * time.Minute),
+		// 空闲的厨师立即开始
+		"first": now.Add(4 * time.Minute),
+		// 等待制作中的工单完成后开始
+		"second": now.Add(5 * time.Minute),
+		// 预约工单不早于取餐时间完成
+		"scheduled": pickupAt,
+	}, got)
+}
+
+func TestEstimateReadyTimes_Overdue(t *testing.T) {
+	now := time.Now()
+	startedAt := now.Add(-time.Hour)
+	tickets := []*Ticket{
+		{ID: "overdue", Status: StatusCooking, StartedAt: &startedAt, Items: []*Item{{PrepTime: time.Minute}}},
+		{ID: "next", Status: StatusQueued, CreatedAt: now, Items: []*Item{{PrepTime: time.Minute}}},
+	}
+
+	// 超出预计时间仍在制作的工单视为马上完成，capacity 非正数时按一名厨师计算
+	got := EstimateReadyTimes(tickets, PriorityPolicy{}, 0, now)
+	assert.Equal(t, now, got["overdue"])
+	assert.Equal(t, now.Add(time.Minute), got["next"])
+}
+
+func TestTicket_UpdateEstimate(t *testing.T) {
+	now := time.Now()
+	ticket := &Ticket{}
+
+	assert.True(t, ticket.UpdateEstimate(now, time.Minute))
+	assert.False(t, ticket.UpdateEstimate(now.Add(30*time.Second), time.Minute))
+	assert.False(t, ticket.UpdateEstimate(now.Add(-30*time.Second), time.Minute))
+	assert.Equal(t, now, *ticket.EstimatedReadyAt)
+
+	assert.True(t, ticket.UpdateEstimate(now.Add(time.Minute), time.Minute))
+	assert.Equal(t, now.Add(time.Minute), *ticket.EstimatedReadyAt)
+}
@@ -0,0 +1,65 @@
+package ticket
+
+import (
+	"slices"
+	"time"
+)
+
+// PriorityPolicy 工单的制作优先级规则，优先级为工单的有效等待时间(秒)，越大越先制作：
+//   - 工单从 EligibleAt 开始等待，预约取餐的工单在应开始制作前 PickupLead 才开始等待，之前的优先级为负数
+//   - 等待超过 AgingAfter 的部分按 AgingFactor 倍计算，避免普通订单一直被加急订单插队
+//   - 加急订单额外增加 ExpressBoost 的等待时间
+type PriorityPolicy struct {
+	ExpressBoost time.Duration
+	PickupLead   time.Duration
+	AgingAfter   time.Duration
+	AgingFactor  float64
+}
+
+// EligibleAt 工单开始排队等待制作的时间
+func (p PriorityPolicy) EligibleAt(t *Ticket) time.Time {
+	if t.PickupAt == nil || t.SLA.StartBy.IsZero() {
+		return t.CreatedAt
+	}
+
+	eligibleAt := t.SLA.StartBy.Add(-p.PickupLead)
+	if eligibleAt.Before(t.CreatedAt) {
+		return t.CreatedAt
+	}
+	return eligibleAt
+}
+
+// Score 工单在 now 时的优先级
+func (p PriorityPolicy) Score(t *Ticket, now time.Time) float64 {
+	wait := now.Sub(p.EligibleAt(t)).Seconds()
+	if agingAfter := p.AgingAfter.Seconds(); p.AgingFactor > 1 && wait > agingAfter {
+		wait = agingAfter + (wait-agingAfter)*p.AgingFactor
+	}
+
+	if t.Express {
+		wait += p.ExpressBoost.Seconds()
+	}
+	return wait
+}
+
+// Sort 将工单按制作顺序排序：制作中的工单在前，等待中的工单按优先级从高到低，优先级相同时先创建的在前
+func (p PriorityPolicy) Sort(tickets []*Ticket, now time.Time) {
+	slices.SortStableFunc(tickets, func(a, b *Ticket) int {
+		if (a.Status == StatusCooking) != (b.Status == StatusCooking) {
+			if a.Status == StatusCooking {
+				return -1
+			}
+			return 1
+		}
+
+		sa, sb := p.Score(a, now), p.Score(b, now)
+		switch {
+		case sa > sb:
+			return -1
+		case sa < sb:
+			return 1
+		default:
+			return a.CreatedAt.Compare(b.CreatedAt)
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package ticket
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriorityPolicy_Score(t *testing.T) {
+	now := time.Now()
+	policy := PriorityPolicy{
+		ExpressBoost: 2 * time.Minute,
+		AgingAfter:   5 * time.Minute,
+		AgingFactor:  2,
+	}
+
+	assert.InDelta(t, 60, policy.Score(&Ticket{CreatedAt: now.Add(-time.Minute)}, now), 0.001)
+	assert.InDelta(t, 180, policy.Score(&Ticket{CreatedAt: now.Add(-time.Minute), Express: true}, now), 0.001)
+	// 超过 AgingAfter 的 5 分钟按 2 倍计算
+	assert.InDelta(t, 300+600, policy.Score(&Ticket{CreatedAt: now.Add(-10 * time.Minute)}, now), 0.001)
+
+	// 等待足够久的普通订单排在刚到的加急订单之前
+	aged := &Ticket{CreatedAt: now.Add(-10 * time.Minute)}
+	express := &Ticket{CreatedAt: now, Express: true}
+	assert.Greater(t, policy.Score(aged, now), policy.Score(express, now))
+}
+
+func TestPriorityPolicy_EligibleAt(t *testing.T) {
+	now := time.Now()
+	policy := PriorityPolicy{PickupLead: 10 * time.Minute}
+
+	assert.Equal(t, now, policy.EligibleAt(&Ticket{CreatedAt: now}))
+
+	pickupAt := now.Add(time.Hour)
+	scheduled := &Ticket{CreatedAt: now, PickupAt: &pickupAt, SLA: SLA{StartBy: now.Add(50 * time.Minute)}}
+	assert.Equal(t, now.Add(40*time.Minute), policy.EligibleAt(scheduled))
+
+	// 取餐时间很近时不早于创建时间
+	soon := now.Add(5 * time.Minute)
+	urgent := &Ticket{CreatedAt: now, PickupAt: &soon, SLA: SLA{StartBy: now.Add(time.Minute)}}
+	assert.Equal(t, now, policy.EligibleAt(urgent))
+}
+
+func TestPriorityPolicy_Sort(t *testing.T) {
+	now := time.Now()
+	policy := PriorityPolicy{ExpressBoost: 5 * time.Minute}
+
+	tickets := []*Ticket{
+		{ID: "queued", Status: StatusQueued, CreatedAt: now.Add(-time.Minute)},
+		{ID: "express", Status: StatusQueued, CreatedAt: now, Express: true},
+		{ID: "cooking", Status: StatusCooking, CreatedAt: now},
+		{ID: "tie", Status: StatusQueued, CreatedAt: now.Add(-time.Minute)},
+	}
+	policy.Sort(tickets, now)
+
+	var ids []string
+	for _, ticket := range tickets {
+		ids = append(ids, ticket.ID)
+	}
+	assert.Equal(t, []string{"cooking", "express", "queued", "tie"}, ids)
+}
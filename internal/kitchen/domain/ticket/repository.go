@@ -0,0 +1,44 @@
+package ticket
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type Repository interface {
+	// Create 保存新的工单，订单已有工单时不覆盖，返回已有的工单
+	Create(ctx context.Context, ticket *Ticket) (*Ticket, error)
+	Get(ctx context.Context, ticketID string) (*Ticket, error)
+	// GetByOrderID 获取订单的工单
+	GetByOrderID(ctx context.Context, orderID string) (*Ticket, error)
+	// ListActive 获取等待中和制作中的工单，按创建时间排序
+	ListActive(ctx context.Context) ([]*Ticket, error)
+	// ListUpdatedSince 获取更新时间不早于 since 的工单，按更新时间排序，最多返回 limit 条
+	ListUpdatedSince(ctx context.Context, since time.Time, limit int) ([]*Ticket, error)
+	// Update 获取工单并执行 updateFn，updateFn 返回的工单会被写回存储
+	Update(
+		ctx context.Context,
+		ticketID string,
+		updateFn func(ctx context.Context, ticket *Ticket) (*Ticket, error),
+	) error
+}
+
+type NotFoundError struct {
+	TicketID string
+}
+
+func (e NotFoundError) Error() string {
+	return "ticket " + e.TicketID + " not found"
+}
+
+// InvalidTransitionError 工单当前状态不允许流转到目标状态
+type InvalidTransitionError struct {
+	TicketID string
+	From     Status
+	To       Status
+}
+
+func (e InvalidTransitionError) Error() string {
+	return fmt.Sprintf("ticket %s cannot change from %s to %s", e.TicketID, e.From, e.To)
+}
@@ -0,0 +1,107 @@
+package ticket
+
+import "time"
+
+// SLATarget SLA 目标的类型
+type SLATarget string
+
+const (
+	// SLATargetStart 工单需要在 SLA.StartBy 之前开始制作
+	SLATargetStart SLATarget = "start"
+	// SLATargetReady 工单需要在 SLA.ReadyBy 之前完成制作
+	SLATargetReady SLATarget = "ready"
+)
+
+// SLA 工单开始和完成制作的目标时间，零值表示没有目标
+type SLA struct {
+	StartBy time.Time
+	ReadyBy time.Time
+}
+
+// Breach 工单违反的 SLA 目标，BreachedAt 为实际开始/完成的时间，尚未开始/完成时为发现违约的时间
+type Breach struct {
+	Target     SLATarget
+	DueAt      time.Time
+	BreachedAt time.Time
+}
+
+// SLAPolicy 工单从创建起开始和完成制作的目标时长，加急订单使用 Express 开头的目标
+type SLAPolicy struct {
+	Start        time.Duration
+	Ready        time.Duration
+	ExpressStart time.Duration
+	ExpressReady time.Duration
+}
+
+// For 计算工单的 SLA 目标。
+// 预约取餐的工单需要在取餐时间前完成，并在取餐时间减去制作时间前开始，但目标不会早于立即制作时的目标
+func (p SLAPolicy) For(t *Ticket) SLA {
+	start, ready := p.Start, p.Ready
+	if t.Express {
+		start, ready = p.ExpressStart, p.ExpressReady
+	}
+
+	sla := SLA{}
+	if start > 0 {
+		sla.StartBy = t.CreatedAt.Add(start)
+	}
+	if ready > 0 {
+		sla.ReadyBy = t.CreatedAt.Add(ready)
+	}
+
+	if t.PickupAt != nil {
+		if startBy := t.PickupAt.Add(-t.PrepTime()); startBy.After(sla.StartBy) {
+			sla.StartBy = startBy
+		}
+		if t.PickupAt.After(sla.ReadyBy) {
+			sla.ReadyBy = *t.PickupAt
+		}
+	}
+	return sla
+}
+
+// CheckSLA 检查工单在 now 时是否违反 SLA 目标，记录并返回新发现的违约，已记录的违约不会重复返回。
+// 失败的工单不再检查
+func (t *Ticket) CheckSLA(now time.Time) []*Breach {
+	if t.Status == StatusFailed {
+		return nil
+	}
+
+	var breaches []*Breach
+	if b := t.checkTarget(SLATargetStart, t.SLA.StartBy, t.StartedAt, now); b != nil {
+		breaches = append(breaches, b)
+	}
+	if b := t.checkTarget(SLATargetReady, t.SLA.ReadyBy, t.DoneAt, now); b != nil {
+		breaches = append(breaches, b)
+	}
+
+	t.Breaches = append(t.Breaches, breaches...)
+	return breaches
+}
+
+// checkTarget reachedAt 为实际开始/完成的时间，尚未达到时为 nil
+func (t *Ticket) checkTarget(target SLATarget, dueAt time.Time, reachedAt *time.Time, now time.Time) *Breach {
+	if dueAt.IsZero() || t.Breached(target) {
+		return nil
+	}
+
+	breachedAt := now
+	if reachedAt != nil {
+		breachedAt = *reachedAt
+	}
+	if !breachedAt.After(dueAt) {
+		return nil
+	}
+
+	return &Breach{Target: target, DueAt: dueAt, BreachedAt: breachedAt}
+}
+
+// Breached 工单是否已经记录了 target 的违约
+func (t *Ticket) Breached(target SLATarget) bool {
+	for _, b := range t.Breaches {
+		if b.Target == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package ticket
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSLAPolicy_For(t *testing.T) {
+	now := time.Now()
+	policy := SLAPolicy{
+		Start:        5 * time.Minute,
+		Ready:        15 * time.Minute,
+		ExpressStart: time.Minute,
+		ExpressReady: 5 * time.Minute,
+	}
+	items := []*Item{{ID: "burger", PrepTime: 10 * time.Minute}}
+
+	assert.Equal(t,
+		SLA{StartBy: now.Add(5 * time.Minute), ReadyBy: now.Add(15 * time.Minute)},
+		policy.For(&Ticket{CreatedAt: now, Items: items}),
+	)
+	assert.Equal(t,
+		SLA{StartBy: now.Add(time.Minute), ReadyBy: now.Add(5 * time.Minute)},
+		policy.For(&Ticket{CreatedAt: now, Items: items, Express: true}),
+	)
+
+	// 预约取餐的工单在取餐时间减去制作时间前开始
+	pickupAt := now.Add(time.Hour)
+	assert.Equal(t,
+		SLA{StartBy: now.Add(50 * time.Minute), ReadyBy: pickupAt},
+		policy.For(&Ticket{CreatedAt: now, Items: items, PickupAt: &pickupAt}),
+	)
+
+	// 取餐时间很近时不早于立即制作时的目标
+	soon := now.Add(10 * time.Minute)
+	assert.Equal(t,
+		SLA{StartBy: now.Add(5 * time.Minute), ReadyBy: now.Add(15 * time.Minute)},
+		policy.For(&Ticket{CreatedAt: now, Items: items, PickupAt: &soon}),
+	)
+
+	assert.Equal(t, SLA{}, SLAPolicy{}.For(&Ticket{CreatedAt: now, Items: items}))
+}
+
+func TestTicket_CheckSLA(t *testing.T) {
+	now := time.Now()
+	ticket := &Ticket{
+		Status:    StatusQueued,
+		CreatedAt: now,
+		SLA:       SLA{StartBy: now.Add(time.Minute), ReadyBy: now.Add(10 * time.Minute)},
+	}
+
+	assert.Empty(t, ticket.CheckSLA(now.Add(time.Minute)))
+
+	// 超过 StartBy 仍未开始，违约时间为发现的时间
+	breaches := ticket.CheckSLA(now.Add(2 * time.Minute))
+	require.Len(t, breaches, 1)
+	assert.Equal(t, &Breach{Target: SLATargetStart, DueAt: now.Add(time.Minute), BreachedAt: now.Add(2 * time.Minute)}, breaches[0])
+	assert.True(t, ticket.Breached(SLATargetStart))
+
+	// 已记录的违约不会重复返回
+	require.NoError(t, ticket.Start(now.Add(3*time.Minute)))
+	assert.Empty(t, ticket.CheckSLA(now.Add(4*time.Minute)))
+
+	// 完成时间晚于 ReadyBy，违约时间为实际完成的时间
+	require.NoError(t, ticket.Done(now.Add(12*time.Minute)))
+	breaches = ticket.CheckSLA(now.Add(20 * time.Minute))
+	require.Len(t, breaches, 1)
+	assert.Equal(t, SLATargetReady, breaches[0].Target)
+	assert.Equal(t, now.Add(12*time.Minute), breaches[0].BreachedAt)
+	assert.Len(t, ticket.Breaches, 2)
+}
+
+func TestTicket_CheckSLA_Failed(t *testing.T) {
+	now := time.Now()
+	ticket := &Ticket{Status: StatusQueued, CreatedAt: now, SLA: SLA{StartBy: now.Add(time.Minute)}}
+	require.NoError(t, ticket.Fail("burnt", now))
+
+	assert.Empty(t, ticket.CheckSLA(now.Add(time.Hour)))
+}
@@ -0,0 +1,160 @@
+package ticket
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/entity"
+)
+
+// Status 工单状态
+type Status string
+
+const (
+	// StatusQueued 等待空闲的厨师
+	StatusQueued  Status = "queued"
+	StatusCooking Status = "cooking"
+	StatusDone    Status = "done"
+	// StatusFailed 制作失败，订单不会被标记为 ready
+	StatusFailed Status = "failed"
+)
+
+// Ticket 已支付订单在厨房中的制作工单，一个订单只有一个工单
+type Ticket struct {
+	ID         string
+	OrderID    string
+	CustomerID string
+	Status     Status
+	Items      []*Item
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	StartedAt  *time.Time
+	DoneAt     *time.Time
+	// FailureReason 制作失败的原因，仅在 StatusFailed 时有值
+	FailureReason string
+	// Express 加急订单
+	Express bool
+	// PickupAt 预约取餐时间，为 nil 时尽快制作
+	PickupAt *time.Time
+	// SLA 创建工单时确定的开始和完成制作的目标时间
+	SLA SLA
+	// Breaches 已经记录的 SLA 违约，每个目标最多一条
+	Breaches []*Breach
+	// EstimatedReadyAt 最近一次发布给订单服务的预计完成时间
+	EstimatedReadyAt *time.Time
+}
+
+// Item 工单中的一种商品，PrepTime 为创建工单时确定的制作时间
+type Item struct {
+	ID       string
+	Name     string
+	Quantity int64
+	PrepTime time.Duration
+}
+
+// NewTicket 使用已支付的订单创建等待制作的工单，商品的制作时间由 prepTimes 决定，SLA 目标由 slaPolicy 决定
+func NewTicket(order *entity.Order, prepTimes PrepTimes, slaPolicy SLAPolicy) (*Ticket, error) {
+	if order == nil || order.ID == "" {
+		return nil, errors.New("empty order")
+	}
+	if len(order.Items) == 0 {
+		return nil, fmt.Errorf("order %s has no items", order.ID)
+	}
+
+	items := make([]*Item, 0, len(order.Items))
+	for _, i := range order.Items {
+		items = append(items, &Item{
+			ID:       i.ID,
+			Name:     i.Name,
+			Quantity: i.Quantity,
+			PrepTime: prepTimes.For(i.ID),
+		})
+	}
+
+	now := time.Now()
+	t := &Ticket{
+		OrderID:    order.ID,
+		CustomerID: order.CustomerID,
+		Status:     StatusQueued,
+		Items:      items,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		Express:    order.Express,
+		PickupAt:   order.PickupAt,
+	}
+	t.SLA = slaPolicy.For(t)
+	return t, nil
+}
+
+// PrepTime 制作整个工单需要的时间，同一工单的商品由一名厨师同时制作，取最长的商品制作时间
+func (t *Ticket) PrepTime() time.Duration {
+	var d time.Duration
+	for _, i := range t.Items {
+		d = max(d, i.PrepTime)
+	}
+	return d
+}
+
+// Start 开始制作工单，只有等待中的工单可以开始，制作中的工单保持原来的开始时间
+func (t *Ticket) Start(startedAt time.Time) error {
+	switch t.Status {
+	case StatusCooking:
+		return nil
+	case StatusQueued:
+	default:
+		return InvalidTransitionError{TicketID: t.ID, From: t.Status, To: StatusCooking}
+	}
+
+	t.Status = StatusCooking
+	t.StartedAt = &startedAt
+	t.UpdatedAt = startedAt
+	return nil
+}
+
+// Done 完成制作，只有制作中的工单可以完成
+func (t *Ticket) Done(doneAt time.Time) error {
+	if t.Status != StatusCooking {
+		return InvalidTransitionError{TicketID: t.ID, From: t.Status, To: StatusDone}
+	}
+
+	t.Status = StatusDone
+	t.DoneAt = &doneAt
+	t.UpdatedAt = doneAt
+	return nil
+}
+
+// Fail 将工单标记为制作失败，已完成的工单不能再失败
+func (t *Ticket) Fail(reason string, failedAt time.Time) error {
+	if t.IsFinished() {
+		return InvalidTransitionError{TicketID: t.ID, From: t.Status, To: StatusFailed}
+	}
+
+	t.Status = StatusFailed
+	t.FailureReason = reason
+	t.UpdatedAt = failedAt
+	return nil
+}
+
+// IsFinished 工单是否已经完成或失败
+func (t *Ticket) IsFinished() bool {
+	return t.Status == StatusDone || t.Status == StatusFailed
+}
+
+// IsActive 工单是否仍需要厨房处理，即等待中或制作中
+func (t *Ticket) IsActive() bool {
+	return t.Status == StatusQueued || t.Status == StatusCooking
+}
+
+// PrepTimes 商品的制作时间，未配置的商品使用 Default
+type PrepTimes struct {
+	Default time.Duration
+	Items   map[string]time.Duration
+}
+
+func (p PrepTimes) For(itemID string) time.Duration {
+	if d, ok := p.Items[itemID]; ok {
+		return d
+	}
+	return p.Default
+}
@@ -0,0 +1,90 @@
+package ticket
+
+import (
+	"testing"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTicket(t *testing.T) *Ticket {
+	t.Helper()
+
+	ticket, err := NewTicket(&entity.Order{
+		ID:         "order",
+		CustomerID: "customer",
+		Items: []*entity.Item{
+			{ID: "burger", Quantity: 1},
+			{ID: "fries", Quantity: 2},
+		},
+	}, PrepTimes{Default: time.Minute, Items: map[string]time.Duration{"burger": 5 * time.Minute}}, SLAPolicy{})
+	require.NoError(t, err)
+	return ticket
+}
+
+func TestNewTicket(t *testing.T) {
+	ticket := newTestTicket(t)
+	assert.Equal(t, StatusQueued, ticket.Status)
+	assert.True(t, ticket.IsActive())
+	// 同一工单的商品同时制作，取最长的制作时间
+	assert.Equal(t, 5*time.Minute, ticket.PrepTime())
+
+	_, err := NewTicket(nil, PrepTimes{}, SLAPolicy{})
+	assert.Error(t, err)
+	_, err = NewTicket(&entity.Order{ID: "order"}, PrepTimes{}, SLAPolicy{})
+	assert.Error(t, err)
+}
+
+func TestTicket_Lifecycle(t *testing.T) {
+	ticket := newTestTicket(t)
+
+	// 完成前必须先开始制作
+	var transitionErr InvalidTransitionError
+	require.ErrorAs(t, ticket.Done(time.Now()), &transitionErr)
+	assert.Equal(t, StatusQueued, transitionErr.From)
+	assert.Equal(t, StatusDone, transitionErr.To)
+
+	startedAt := time.Now()
+	require.NoError(t, ticket.Start(startedAt))
+	assert.Equal(t, StatusCooking, ticket.Status)
+	assert.Equal(t, &startedAt, ticket.StartedAt)
+
+	// 重复开始保持原来的开始时间
+	require.NoError(t, ticket.Start(startedAt.Add(time.Minute)))
+	assert.Equal(t, &startedAt, ticket.StartedAt)
+
+	doneAt := startedAt.Add(5 * time.Minute)
+	require.NoError(t, ticket.Done(doneAt))
+	assert.Equal(t, StatusDone, ticket.Status)
+	assert.Equal(t, &doneAt, ticket.DoneAt)
+	assert.True(t, ticket.IsFinished())
+	assert.False(t, ticket.IsActive())
+
+	// 已完成的工单不能重新开始、再次完成或失败
+	assert.ErrorAs(t, ticket.Start(time.Now()), &transitionErr)
+	assert.ErrorAs(t, ticket.Done(time.Now()), &transitionErr)
+	assert.ErrorAs(t, ticket.Fail("burnt", time.Now()), &transitionErr)
+	assert.Equal(t, StatusDone, ticket.Status)
+}
+
+func TestTicket_Fail(t *testing.T) {
+	for _, start := range []bool{false, true} {
+		ticket := newTestTicket(t)
+		if start {
+			require.NoError(t, ticket.Start(time.Now()))
+		}
+
+		require.NoError(t, ticket.Fail("burnt", time.Now()))
+		assert.Equal(t, StatusFailed, ticket.Status)
+		assert.Equal(t, "burnt", ticket.FailureReason)
+		assert.True(t, ticket.IsFinished())
+
+		var transitionErr InvalidTransitionError
+		assert.ErrorAs(t, ticket.Start(time.Now()), &transitionErr)
+		assert.ErrorAs(t, ticket.Fail("again", time.Now()), &transitionErr)
+		assert.Equal(t, "burnt", ticket.FailureReason)
+	}
+}
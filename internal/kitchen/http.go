@@ -0,0 +1,155 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/furutachiKurea/gorder/kitchen/app"
+	"github.com/furutachiKurea/gorder/kitchen/app/command"
+	"github.com/furutachiKurea/gorder/kitchen/app/query"
+	domain "github.com/furutachiKurea/gorder/kitchen/domain/ticket"
+	"github.com/furutachiKurea/gorder/kitchen/ports"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type KitchenHandler struct {
+	app  app.Application
+	feed *ports.TicketFeed
+}
+
+func NewKitchenHandler(app app.Application, feed *ports.TicketFeed) *KitchenHandler {
+	return &KitchenHandler{app: app, feed: feed}
+}
+
+func (h KitchenHandler) RegisterRoutes(router *gin.Engine) {
+	router.GET("/api/kitchen/tickets", h.listTickets)
+	router.GET("/api/kitchen/tickets/stream", h.streamTickets)
+	router.POST("/api/kitchen/tickets/:ticket_id/bump", h.bumpTicket)
+	router.GET("/api/kitchen/intake", h.getLoad)
+	router.POST("/api/kitchen/intake/pause", h.pauseIntake)
+	router.POST("/api/kitchen/intake/resume", h.resumeIntake)
+}
+
+// listTickets 获取等待中和制作中的工单
+func (h KitchenHandler) listTickets(c *gin.Context) {
+	tickets, err := h.app.Queries.ListActiveTickets.Handle(c.Request.Context(), query.ListActiveTickets{})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	resp := make([]any, 0, len(tickets))
+	for _, t := range tickets {
+		resp = append(resp, ports.TicketToProto(t))
+	}
+	c.JSON(http.StatusOK, gin.H{"tickets": resp})
+}
+
+// streamTickets 以 Server-Sent Events 推送新创建和状态变化的工单，GET /api/kitchen/tickets/stream?since=<unix 秒>
+func (h KitchenHandler) streamTickets(c *gin.Context) {
+	var since time.Time
+	if s := c.Query("since"); s != "" {
+		sec, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "since must be unix seconds"})
+			return
+		}
+		since = time.Unix(sec, 0)
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	err := h.feed.Watch(c.Request.Context(), since, func(t *domain.Ticket) error {
+		c.SSEvent("ticket", ports.TicketToProto(t))
+		c.Writer.Flush()
+		return nil
+	})
+	if err != nil && c.Request.Context().Err() == nil {
+		log.Warn().Ctx(c.Request.Context()).Err(err).Msg("ticket stream stopped")
+	}
+}
+
+type bumpTicketReq struct {
+	Status string `json:"status" binding:"required,oneof=started ready"`
+}
+
+// bumpTicket 将工单推进到开始制作或制作完成，POST /api/kitchen/tickets/:ticket_id/bump
+func (h KitchenHandler) bumpTicket(c *gin.Context) {
+	var req bumpTicketReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	target, err := ports.ParseBumpStatus(req.Status)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	t, err := h.app.Commands.BumpTicket.Handle(c.Request.Context(), command.BumpTicket{
+		TicketID: c.Param("ticket_id"),
+		Status:   target,
+	})
+	if err != nil {
+		var (
+			notFound   domain.NotFoundError
+			transition domain.InvalidTransitionError
+		)
+		switch {
+		case errors.As(err, &notFound):
+			c.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
+		case errors.As(err, &transition):
+			c.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ticket": ports.TicketToProto(t)})
+}
+
+// getLoad 获取厨房当前的负载，GET /api/kitchen/intake
+func (h KitchenHandler) getLoad(c *gin.Context) {
+	load, err := h.app.Queries.GetLoad.Handle(c.Request.Context(), query.GetLoad{})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"load": ports.LoadToProto(load)})
+}
+
+type pauseIntakeReq struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// pauseIntake 暂停接单，POST /api/kitchen/intake/pause
+func (h KitchenHandler) pauseIntake(c *gin.Context) {
+	var req pauseIntakeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	h.setIntake(c, command.PauseIntake{Paused: true, Reason: req.Reason})
+}
+
+// resumeIntake 恢复接单，POST /api/kitchen/intake/resume
+func (h KitchenHandler) resumeIntake(c *gin.Context) {
+	h.setIntake(c, command.PauseIntake{Paused: false})
+}
+
+func (h KitchenHandler) setIntake(c *gin.Context, cmd command.PauseIntake) {
+	load, err := h.app.Commands.PauseIntake.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"load": ports.LoadToProto(load)})
+}
@@ -8,108 +8,237 @@ import (
 	"time"
 
 	"github.com/furutachiKurea/gorder/common/broker"
-	"github.com/furutachiKurea/gorder/common/consts"
-	"github.com/furutachiKurea/gorder/common/convertor"
 	"github.com/furutachiKurea/gorder/common/entity"
-	"github.com/furutachiKurea/gorder/common/genproto/orderpb"
+	"github.com/furutachiKurea/gorder/common/tracing"
+	"github.com/furutachiKurea/gorder/kitchen/app"
+	"github.com/furutachiKurea/gorder/kitchen/app/command"
+	domain "github.com/furutachiKurea/gorder/kitchen/domain/ticket"
 
-	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/rs/zerolog/log"
-	"go.opentelemetry.io/otel"
 )
 
-type OrderService interface {
-	UpdateOrder(context.Context, *orderpb.Order) error
-}
+// QueueOrderPaid 所有厨房实例共享的持久化队列，实例之间竞争消费已支付的订单
+const QueueOrderPaid = "kitchen.order_paid"
+
+// maxParkDelay 还没到制作时间的预约订单每次最多延迟这么久后重新投递，延迟按秒取整，限制延迟队列的数量
+const maxParkDelay = time.Minute
 
 type Consumer struct {
-	orderGRPC OrderService
+	app app.Application
+	// publisher 用于重新发布处理失败的消息
+	publisher broker.Publisher
+	// retry 处理失败的消息的重试策略
+	retry   broker.RetryPolicy
+	workers int
+	// autoCook 为 true 时按制作时间自动完成工单，否则工单保存后等待厨房员工在显示屏上推进
+	autoCook bool
+	// queueSize 自动制作时本地优先级队列中最多等待制作的订单数量
+	queueSize int
+	priority  domain.PriorityPolicy
 }
 
-func NewConsumer(orderService OrderService) *Consumer {
-	return &Consumer{
-		orderGRPC: orderService,
+// NewConsumer workers 为同时处理的消息数量上限，自动制作时即厨师数量；
+// 自动制作时实例额外预取 queueSize 个订单，按 priority 决定制作顺序
+func NewConsumer(app app.Application, publisher broker.Publisher, retry broker.RetryPolicy, workers int, autoCook bool, queueSize int, priority domain.PriorityPolicy) *Consumer {
+	if workers <= 0 {
+		workers = 1
 	}
-}
 
-func (c *Consumer) Listen(ch *amqp.Channel) {
-	q, err := ch.QueueDeclare("", true, false, true, false, nil)
-	if err != nil {
-		log.Fatal().Err(err).Msg("")
+	if queueSize < 0 {
+		queueSize = 0
 	}
 
-	if err = ch.QueueBind(q.Name, "", broker.EventOrderPaid, false, nil); err != nil {
-		log.Fatal().Err(err).Msg("")
+	return &Consumer{
+		app:       app,
+		publisher: publisher,
+		retry:     retry,
+		workers:   workers,
+		autoCook:  autoCook,
+		queueSize: queueSize,
+		priority:  priority,
+	}
+}
+
+// Listen 消费 QueueOrderPaid。
+// 自动制作时 prefetch 为 workers + queueSize，收到的订单创建工单后放入本地优先级队列，由 workers 个厨师按优先级制作，
+// 消息在工单制作完成后才确认，实例重启时未完成的订单会被重新投递给其他实例。
+// 还没到制作时间的预约订单不占用预取额度，确认后延迟重新投递，到制作时间后才放入队列。
+// 否则 prefetch 与 workers 相同，消息在工单保存后确认，制作顺序由显示屏上的排序决定
+func (c *Consumer) Listen(sub broker.Subscriber) {
+	prefetch := c.workers
+	if c.autoCook {
+		prefetch += c.queueSize
 	}
 
-	msgs, err := ch.Consume(q.Name, "", false, false, false, false, nil)
+	msgs, err := sub.Subscribe(context.Background(), broker.Subscription{
+		Queue:    QueueOrderPaid,
+		Exchange: broker.EventOrderPaid,
+		Prefetch: prefetch,
+	})
 	if err != nil {
-		log.Warn().Err(err).Str("queue", q.Name).Msg("failed to consume queue")
+		log.Fatal().Err(err).Str("queue", QueueOrderPaid).Msg("failed to subscribe")
 	}
 
 	var forever chan struct{}
+	if c.autoCook {
+		c.cookByPriority(msgs)
+	} else {
+		for range c.workers {
+			go func() {
+				for msg := range msgs {
+					c.handleMessage(msg, nil)
+				}
+			}()
+		}
+	}
+
+	<-forever
+}
+
+// cookByPriority 收到的订单创建工单后放入本地优先级队列，workers 个厨师从队列中取出工单制作
+func (c *Consumer) cookByPriority(msgs <-chan *broker.Delivery) {
+	queue := newPriorityQueue(c.priority)
 	go func() {
 		for msg := range msgs {
-			c.handleMessage(ch, msg, q)
+			c.handleMessage(msg, queue)
 		}
 	}()
 
-	<-forever
+	for range c.workers {
+		go func() {
+			for {
+				p, err := queue.pop(context.Background())
+				if err != nil {
+					return
+				}
+				c.cook(p)
+			}
+		}()
+	}
 }
 
-// handleMessage 处理接收到的订单支付消息，制作订单并更新状态为 ready
-func (c *Consumer) handleMessage(ch *amqp.Channel, msg amqp.Delivery, q amqp.Queue) {
-	var err error
+// handleMessage 为已支付的订单创建工单，queue 不为 nil 时将工单放入队列等待制作，由 cook 确认消息，
+// 还没到制作时间的工单延迟重新投递。创建工单失败时延迟重试，无法解析的消息直接丢弃
+func (c *Consumer) handleMessage(msg *broker.Delivery, queue *priorityQueue) {
 	log.Info().
 		Str("msg", string(msg.Body)).
-		Msgf("kitchen received message from %s", q.Name)
+		Msgf("kitchen received message from %s", msg.Queue)
 
 	ctx := broker.ExtractRabbitMQHeaders(context.Background(), msg.Headers)
-	t := otel.Tracer("rabbitmq")
-	ctx, span := t.Start(ctx, fmt.Sprintf("rabbitmq.%s.consume", q.Name))
-
+	ctx, span := tracing.Start(ctx, fmt.Sprintf("rabbitmq.%s.consume", msg.Queue))
+	defer span.End()
+
+	var (
+		t      *domain.Ticket
+		parked bool
+		err    error
+	)
 	defer func() {
-		span.End()
-		if err != nil {
-			_ = msg.Nack(false, false)
-		} else {
-			_ = msg.Ack(false)
+		switch {
+		case err != nil:
+			_ = broker.Settle(msg, err)
+			log.Warn().Ctx(ctx).
+				Err(err).
+				Str("from", msg.Queue).
+				Str("msg", string(msg.Body)).
+				Msg("consume failed")
+		case t == nil:
+			// 创建工单失败，已经安排重试
+			_ = msg.Ack()
+		case parked:
+			span.AddEvent("kitchen.ticket_parked")
+			_ = msg.Ack()
+		default:
+			span.AddEvent("kitchen.ticket_created")
+			if queue == nil {
+				_ = msg.Ack()
+				log.Info().Ctx(ctx).Msg("consume success")
+			}
 		}
 	}()
 
 	o := &entity.Order{}
 	if err = json.Unmarshal(msg.Body, o); err != nil {
-		log.Info().
-			Err(err).
-			Msg("failed to unmarshal msg to body")
+		err = fmt.Errorf("unmarshal msg to body: %w", err)
+		return
+	}
+
+	t, err = c.app.Commands.CreateTicket.Handle(ctx, command.CreateTicket{Order: o})
+	if err != nil {
+		log.Warn().Ctx(ctx).Err(err).Str("order_id", o.ID).Msg("failed to create ticket, retrying")
+		t = nil
+		if err = broker.HandlerRetry(ctx, c.publisher, msg, c.retry); err != nil {
+			err = fmt.Errorf("handle retry, messageId=%s: %w", msg.MessageID, err)
+		}
 		return
 	}
 
-	if o.Status != consts.OrderStatusPaid {
-		err = errors.New("order not paid, cannot cook")
+	if queue == nil {
 		return
 	}
 
-	cook(ctx, o)
-	span.AddEvent(fmt.Sprintf("order_cook: %v", &o))
-	if err = c.orderGRPC.UpdateOrder(ctx, &orderpb.Order{
-		Id:          o.ID,
-		CustomerId:  o.CustomerID,
-		Status:      string(consts.OrderStatusReady),
-		PaymentLink: o.PaymentLink,
-		Items:       convertor.NewItemConvertor().EntitiesToProtos(o.Items),
-	}); err != nil {
-		if err = broker.HandlerRetry(ctx, ch, &msg); err != nil {
-			log.Warn().Err(err).Msg("kitchen: error handling retry")
+	// 工单已经保存，重新投递时创建工单直接返回已有的工单
+	if wait := time.Until(c.priority.EligibleAt(t)); wait > 0 {
+		delay := parkDelay(wait)
+		log.Info().Ctx(ctx).Str("ticket_id", t.ID).Dur("delay", delay).Msg("ticket not eligible yet, park until later")
+		if err = broker.Redeliver(ctx, c.publisher, msg, delay); err != nil {
+			err = fmt.Errorf("park ticket %s: %w", t.ID, err)
+			return
 		}
+		parked = true
 		return
 	}
 
-	log.Info().Msg("kitchen.order.finished.updated")
+	queue.push(&pendingTicket{ctx: ctx, msg: msg, ticket: t})
+}
+
+// parkDelay 预约订单重新投递前的延迟，最长 maxParkDelay，向上取整到秒
+func parkDelay(wait time.Duration) time.Duration {
+	if wait >= maxParkDelay {
+		return maxParkDelay
+	}
+	return (wait + time.Second - 1).Truncate(time.Second)
+}
+
+// cook 制作从优先级队列中取出的工单，制作完成后确认消息
+func (c *Consumer) cook(p *pendingTicket) {
+	ctx, span := tracing.Start(p.ctx, fmt.Sprintf("rabbitmq.%s.cook", p.msg.Queue))
+	defer span.End()
+
+	msg, t := p.msg, p.ticket
+	var err error
+	defer func() {
+		_ = broker.Settle(msg, err)
+		if err != nil {
+			log.Warn().Ctx(ctx).
+				Err(err).
+				Str("from", msg.Queue).
+				Str("msg", string(msg.Body)).
+				Msg("consume failed")
+		} else {
+			log.Info().Ctx(ctx).Msg("consume success")
+		}
+	}()
+
+	if _, err = c.app.Commands.CookTicket.Handle(ctx, command.CookTicket{TicketID: t.ID}); err != nil {
+		err = fmt.Errorf("cook ticket %s: %w", t.ID, err)
+		if retryErr := broker.HandlerRetry(ctx, c.publisher, msg, c.retry); retryErr != nil {
+			if errors.Is(retryErr, broker.ErrMaxRetryExceeded) {
+				c.failTicket(ctx, t.ID, err)
+			}
+			err = fmt.Errorf("handle retry, messageId=%s: %w", msg.MessageID, retryErr)
+			return
+		}
+		err = nil
+	}
 }
 
-func cook(ctx context.Context, o *entity.Order) {
-	log.Info().Ctx(ctx).Str("order", o.ID).Msg("cooking order")
-	time.Sleep(5 * time.Second)
-	log.Info().Ctx(ctx).Str("order", o.ID).Msg("order done!")
+// failTicket 重试次数耗尽后将工单标记为失败，失败只记录日志
+func (c *Consumer) failTicket(ctx context.Context, ticketID string, cause error) {
+	if _, err := c.app.Commands.FailTicket.Handle(ctx, command.FailTicket{
+		TicketID: ticketID,
+		Reason:   cause.Error(),
+	}); err != nil {
+		log.Warn().Ctx(ctx).Err(err).Str("ticket_id", ticketID).Msg("failed to mark ticket failed")
+	}
 }
@@ -0,0 +1,113 @@
+package consumer
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/broker"
+	domain "github.com/furutachiKurea/gorder/kitchen/domain/ticket"
+)
+
+// pendingTicket 已创建工单、等待厨师制作的消息，制作完成后才确认
+type pendingTicket struct {
+	ctx    context.Context
+	msg    *broker.Delivery
+	ticket *domain.Ticket
+}
+
+// priorityQueue 实例本地的工单优先级队列，替代按投递顺序制作：
+// 预取的消息先放入队列，空闲的厨师取出已到制作时间且优先级最高的工单
+type priorityQueue struct {
+	mu     sync.Mutex
+	policy domain.PriorityPolicy
+	items  []*pendingTicket
+	notify chan struct{}
+}
+
+func newPriorityQueue(policy domain.PriorityPolicy) *priorityQueue {
+	return &priorityQueue{
+		policy: policy,
+		notify: make(chan struct{}, 1),
+	}
+}
+
+func (q *priorityQueue) push(p *pendingTicket) {
+	q.mu.Lock()
+	q.items = append(q.items, p)
+	q.mu.Unlock()
+
+	q.signal()
+}
+
+// pop 取出优先级最高的工单，没有可以制作的工单时等待新的工单或最早的预约工单到达制作时间
+func (q *priorityQueue) pop(ctx context.Context) (*pendingTicket, error) {
+	for {
+		p, wait := q.next(time.Now())
+		if p != nil {
+			return p, nil
+		}
+
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			timeout = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return nil, ctx.Err()
+		case <-q.notify:
+		case <-timeout:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// next 取出已到制作时间且优先级最高的工单，优先级相同时先到的在前；
+// 没有可以制作的工单时返回最早的预约工单到达制作时间还需等待的时间，队列为空时为 0
+func (q *priorityQueue) next(now time.Time) (*pendingTicket, time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	best := -1
+	var bestScore float64
+	var wait time.Duration
+	for i, p := range q.items {
+		if eligibleAt := q.policy.EligibleAt(p.ticket); eligibleAt.After(now) {
+			if d := eligibleAt.Sub(now); wait == 0 || d < wait {
+				wait = d
+			}
+			continue
+		}
+
+		if score := q.policy.Score(p.ticket, now); best < 0 || score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if best < 0 {
+		return nil, wait
+	}
+
+	p := q.items[best]
+	q.items = slices.Delete(q.items, best, best+1)
+	if len(q.items) > 0 {
+		// 唤醒其他空闲的厨师处理剩余的工单
+		q.signal()
+	}
+	return p, 0
+}
+
+func (q *priorityQueue) signal() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,80 @@
+package consumer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	domain "github.com/furutachiKurea/gorder/kitchen/domain/ticket"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pending(orderID string, createdAt time.Time, express bool) *pendingTicket {
+	return &pendingTicket{ticket: &domain.Ticket{OrderID: orderID, CreatedAt: createdAt, Express: express}}
+}
+
+func TestPriorityQueue_Next(t *testing.T) {
+	now := time.Now()
+	q := newPriorityQueue(domain.PriorityPolicy{ExpressBoost: 5 * time.Minute})
+
+	// 空队列不需要等待
+	p, wait := q.next(now)
+	assert.Nil(t, p)
+	assert.Zero(t, wait)
+
+	q.push(pending("early", now.Add(-2*time.Minute), false))
+	q.push(pending("late", now.Add(-time.Minute), false))
+	q.push(pending("express", now, true))
+
+	// 加急订单的等待时间加上 ExpressBoost 后最长，其余按先到先做
+	var order []string
+	for range 3 {
+		p, _ := q.next(now)
+		require.NotNil(t, p)
+		order = append(order, p.ticket.OrderID)
+	}
+	assert.Equal(t, []string{"express", "early", "late"}, order)
+}
+
+func TestPriorityQueue_Next_Scheduled(t *testing.T) {
+	now := time.Now()
+	q := newPriorityQueue(domain.PriorityPolicy{PickupLead: time.Minute})
+
+	pickupAt := now.Add(time.Hour)
+	q.push(&pendingTicket{ticket: &domain.Ticket{
+		OrderID:   "scheduled",
+		CreatedAt: now,
+		PickupAt:  &pickupAt,
+		SLA:       domain.SLA{StartBy: now.Add(30 * time.Minute)},
+	}})
+
+	// 预约工单在 StartBy - PickupLead 之前不能制作，返回需要等待的时间
+	p, wait := q.next(now)
+	assert.Nil(t, p)
+	assert.Equal(t, 29*time.Minute, wait)
+
+	p, _ = q.next(now.Add(29 * time.Minute))
+	require.NotNil(t, p)
+	assert.Equal(t, "scheduled", p.ticket.OrderID)
+}
+
+func TestPriorityQueue_Pop(t *testing.T) {
+	q := newPriorityQueue(domain.PriorityPolicy{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// 新的工单会唤醒等待中的厨师
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		q.push(pending("order", time.Now(), false))
+	}()
+	p, err := q.pop(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "order", p.ticket.OrderID)
+
+	_, err = q.pop(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
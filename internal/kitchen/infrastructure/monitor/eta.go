@@ -0,0 +1,57 @@
+package monitor
+
+import (
+	"context"
+	"time"
+
+	"github.com/furutachiKurea/gorder/kitchen/app"
+	"github.com/furutachiKurea/gorder/kitchen/app/command"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ETAEstimator 定期根据队列的变化重新估计工单的完成时间并通知订单服务
+type ETAEstimator struct {
+	app      app.Application
+	interval time.Duration
+}
+
+// NewETAEstimator interval 为估计间隔，即队列变化反映到预计完成时间的最大延迟
+func NewETAEstimator(app app.Application, interval time.Duration) *ETAEstimator {
+	return &ETAEstimator{
+		app:      app,
+		interval: interval,
+	}
+}
+
+// Run 按 interval 估计直到 ctx 结束
+func (e *ETAEstimator) Run(ctx context.Context) {
+	if e.interval <= 0 {
+		log.Warn().Dur("interval", e.interval).Msg("kitchen eta estimator disabled")
+		return
+	}
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.runOnce(ctx)
+		}
+	}
+}
+
+func (e *ETAEstimator) runOnce(ctx context.Context) {
+	updated, err := e.app.Commands.EstimateReadyTimes.Handle(ctx, command.EstimateReadyTimes{})
+	if err != nil {
+		log.Error().Ctx(ctx).Err(err).Msg("kitchen eta estimate failed")
+		return
+	}
+
+	if updated > 0 {
+		log.Info().Ctx(ctx).Int("updated", updated).Msg("kitchen eta estimate report")
+	}
+}
@@ -0,0 +1,52 @@
+package monitor
+
+import (
+	"context"
+	"time"
+
+	"github.com/furutachiKurea/gorder/kitchen/app"
+	"github.com/furutachiKurea/gorder/kitchen/app/command"
+
+	"github.com/rs/zerolog/log"
+)
+
+// LoadPublisher 定期发布厨房的负载，同时作为心跳，订单服务将长时间未收到的负载视为过期
+type LoadPublisher struct {
+	app      app.Application
+	interval time.Duration
+}
+
+// NewLoadPublisher interval 为发布间隔，需要小于订单服务的 order.intake.load-stale-after
+func NewLoadPublisher(app app.Application, interval time.Duration) *LoadPublisher {
+	return &LoadPublisher{
+		app:      app,
+		interval: interval,
+	}
+}
+
+// Run 启动时立即发布一次，之后按 interval 发布直到 ctx 结束
+func (p *LoadPublisher) Run(ctx context.Context) {
+	if p.interval <= 0 {
+		log.Warn().Dur("interval", p.interval).Msg("kitchen load publisher disabled")
+		return
+	}
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.runOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *LoadPublisher) runOnce(ctx context.Context) {
+	if _, err := p.app.Commands.PublishLoad.Handle(ctx, command.PublishLoad{}); err != nil {
+		log.Error().Ctx(ctx).Err(err).Msg("kitchen load publish failed")
+	}
+}
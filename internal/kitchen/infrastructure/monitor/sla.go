@@ -0,0 +1,58 @@
+package monitor
+
+import (
+	"context"
+	"time"
+
+	"github.com/furutachiKurea/gorder/kitchen/app"
+	"github.com/furutachiKurea/gorder/kitchen/app/command"
+
+	"github.com/rs/zerolog/log"
+)
+
+// SLAMonitor 定期检查等待中和制作中的工单，发现还未开始或完成但已超过 SLA 目标的工单。
+// 工单开始和完成时的违约由制作和推进工单的命令检查
+type SLAMonitor struct {
+	app      app.Application
+	interval time.Duration
+}
+
+// NewSLAMonitor interval 为检查间隔，即违约被发现的最大延迟
+func NewSLAMonitor(app app.Application, interval time.Duration) *SLAMonitor {
+	return &SLAMonitor{
+		app:      app,
+		interval: interval,
+	}
+}
+
+// Run 按 interval 检查工单直到 ctx 结束
+func (m *SLAMonitor) Run(ctx context.Context) {
+	if m.interval <= 0 {
+		log.Warn().Dur("interval", m.interval).Msg("kitchen sla monitor disabled")
+		return
+	}
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.runOnce(ctx)
+		}
+	}
+}
+
+func (m *SLAMonitor) runOnce(ctx context.Context) {
+	breached, err := m.app.Commands.CheckSLA.Handle(ctx, command.CheckSLA{})
+	if err != nil {
+		log.Error().Ctx(ctx).Err(err).Msg("kitchen sla check failed")
+		return
+	}
+
+	if breached > 0 {
+		log.Info().Ctx(ctx).Int("breached", breached).Msg("kitchen sla check report")
+	}
+}
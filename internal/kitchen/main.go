@@ -6,16 +6,23 @@ import (
 	"syscall"
 
 	"github.com/furutachiKurea/gorder/common/broker"
-	"github.com/furutachiKurea/gorder/common/client"
 	_ "github.com/furutachiKurea/gorder/common/config"
+	"github.com/furutachiKurea/gorder/common/discovery"
+	"github.com/furutachiKurea/gorder/common/genproto/kitchenpb"
 	"github.com/furutachiKurea/gorder/common/logging"
+	"github.com/furutachiKurea/gorder/common/metrics"
+	"github.com/furutachiKurea/gorder/common/server"
 	"github.com/furutachiKurea/gorder/common/tracing"
-	"github.com/furutachiKurea/gorder/kitchen/adapter"
 	"github.com/furutachiKurea/gorder/kitchen/infrastructure/consumer"
+	"github.com/furutachiKurea/gorder/kitchen/infrastructure/monitor"
+	"github.com/furutachiKurea/gorder/kitchen/ports"
+	"github.com/furutachiKurea/gorder/kitchen/service"
 
+	"github.com/gin-gonic/gin"
 	_ "github.com/joho/godotenv/autoload"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/viper"
+	"google.golang.org/grpc"
 )
 
 func init() {
@@ -24,7 +31,6 @@ func init() {
 
 func main() {
 	serviceName := viper.GetString("kitchen.service-name")
-	// serverType := viper.GetString("stock.server-to-run")
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -37,27 +43,58 @@ func main() {
 		_ = shutdown(ctx)
 	}()
 
-	ch, closeCoon := broker.Connect(
+	metricsClient := metrics.NewPrometheusMetricsClient(
+		&metrics.PrometheusMetricsClientConfig{
+			Host:        viper.GetString("kitchen.metrics-export-addr"),
+			ServiceName: serviceName,
+		})
+	mq, closeMQ := broker.Connect(
 		viper.GetString("rabbitmq.user"),
 		viper.GetString("rabbitmq.password"),
 		viper.GetString("rabbitmq.host"),
 		viper.GetString("rabbitmq.port"),
+		metricsClient,
 	)
 	defer func() {
-		_ = ch.Close()
-		_ = closeCoon()
+		_ = closeMQ()
 	}()
 
-	orderClient, closeOrderClient, err := client.NewOrderGRPCClient(ctx)
+	app, cleanup := service.NewApplication(ctx, mq, metricsClient)
+	defer cleanup()
+
+	deregisterFn, err := discovery.RegisterToConsul(ctx, serviceName, mq.Health)
 	if err != nil {
-		log.Fatal().Err(err).Msg("failed to create order grpc client")
+		log.Fatal().Err(err).Msgf("failed to register service %s to consul", serviceName)
 	}
-	defer func() {
-		_ = closeOrderClient()
-	}()
+	defer func() { _ = deregisterFn() }()
+
+	retry, err := broker.RetryPolicyFromConfig("kitchen.retry")
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to parse kitchen retry policy")
+	}
+	go consumer.NewConsumer(
+		app,
+		mq,
+		retry,
+		viper.GetInt("kitchen.workers"),
+		viper.GetBool("kitchen.auto-cook"),
+		viper.GetInt("kitchen.queue-size"),
+		service.NewPriorityPolicy(),
+	).Listen(mq)
+
+	go monitor.NewSLAMonitor(app, viper.GetDuration("kitchen.sla.check-interval")).Run(ctx)
+	go monitor.NewETAEstimator(app, viper.GetDuration("kitchen.eta.interval")).Run(ctx)
+	go monitor.NewLoadPublisher(app, viper.GetDuration("kitchen.load.publish-interval")).Run(ctx)
+
+	feed := ports.NewTicketFeed(app, viper.GetDuration("kitchen.display-poll-interval"))
+	go server.RunGRPCServer(serviceName, func(server *grpc.Server) {
+		kitchenpb.RegisterKitchenServiceServer(server, ports.NewGRPCServer(app, feed))
+	})
 
-	orderGRPC := adapter.NewOderGRPC(orderClient)
-	go consumer.NewConsumer(orderGRPC).Listen(ch)
+	go server.RunHTTPServer(serviceName, func(router *gin.Engine) {
+		server.RegisterHealthRoutes(router, map[string]server.HealthCheck{"rabbitmq": mq.Health})
+		NewKitchenHandler(app, feed).RegisterRoutes(router)
+	})
 
 	ctx, stop := signal.NotifyContext(ctx, syscall.SIGTERM, syscall.SIGINT)
 	defer stop()
@@ -0,0 +1,83 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/furutachiKurea/gorder/kitchen/app"
+	"github.com/furutachiKurea/gorder/kitchen/app/query"
+	domain "github.com/furutachiKurea/gorder/kitchen/domain/ticket"
+)
+
+// feedBatchSize 每次轮询读取的工单数量上限，读满时立即继续读取
+const feedBatchSize = 100
+
+// TicketFeed 轮询工单存储，向显示屏推送新创建和状态变化的工单。
+// 工单由多个厨房实例共同处理，轮询存储可以让连接到任一实例的显示屏看到所有工单
+type TicketFeed struct {
+	app      app.Application
+	interval time.Duration
+}
+
+func NewTicketFeed(app app.Application, interval time.Duration) *TicketFeed {
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	return &TicketFeed{app: app, interval: interval}
+}
+
+// Watch 推送更新时间不早于 since 的工单直到 ctx 结束或 send 返回错误。
+// since 为零值时先推送所有等待中和制作中的工单，再推送之后的变化
+func (f *TicketFeed) Watch(ctx context.Context, since time.Time, send func(t *domain.Ticket) error) error {
+	if since.IsZero() {
+		since = time.Now()
+		active, err := f.app.Queries.ListActiveTickets.Handle(ctx, query.ListActiveTickets{})
+		if err != nil {
+			return err
+		}
+		for _, t := range active {
+			if err = send(t); err != nil {
+				return err
+			}
+		}
+	}
+
+	// sent 记录更新时间等于 since 且已推送的工单，since 使用 $gte 查询时避免重复推送
+	sent := make(map[string]bool)
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+	for {
+		changes, err := f.app.Queries.ListTicketChanges.Handle(ctx, query.ListTicketChanges{
+			Since: since,
+			Limit: feedBatchSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		prev := since
+		for _, t := range changes {
+			if t.UpdatedAt.Equal(since) && sent[t.ID] {
+				continue
+			}
+			if err = send(t); err != nil {
+				return err
+			}
+			if t.UpdatedAt.After(since) {
+				since = t.UpdatedAt
+				clear(sent)
+			}
+			sent[t.ID] = true
+		}
+		if len(changes) == feedBatchSize && since.After(prev) {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
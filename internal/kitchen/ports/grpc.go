@@ -0,0 +1,165 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/entity"
+	"github.com/furutachiKurea/gorder/common/genproto/kitchenpb"
+	"github.com/furutachiKurea/gorder/kitchen/app"
+	"github.com/furutachiKurea/gorder/kitchen/app/command"
+	"github.com/furutachiKurea/gorder/kitchen/app/query"
+	domain "github.com/furutachiKurea/gorder/kitchen/domain/ticket"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type GRPCServer struct {
+	app  app.Application
+	feed *TicketFeed
+}
+
+func NewGRPCServer(app app.Application, feed *TicketFeed) *GRPCServer {
+	return &GRPCServer{app: app, feed: feed}
+}
+
+func (G GRPCServer) ListTickets(ctx context.Context, _ *kitchenpb.ListTicketsRequest) (*kitchenpb.ListTicketsResponse, error) {
+	tickets, err := G.app.Queries.ListActiveTickets.Handle(ctx, query.ListActiveTickets{})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	res := &kitchenpb.ListTicketsResponse{}
+	for _, t := range tickets {
+		res.Tickets = append(res.Tickets, TicketToProto(t))
+	}
+	return res, nil
+}
+
+func (G GRPCServer) WatchTickets(request *kitchenpb.WatchTicketsRequest, stream kitchenpb.KitchenService_WatchTicketsServer) error {
+	var since time.Time
+	if request.Since > 0 {
+		since = time.Unix(request.Since, 0)
+	}
+
+	err := G.feed.Watch(stream.Context(), since, func(t *domain.Ticket) error {
+		return stream.Send(TicketToProto(t))
+	})
+	if err != nil && stream.Context().Err() == nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+func (G GRPCServer) BumpTicket(ctx context.Context, request *kitchenpb.BumpTicketRequest) (*kitchenpb.Ticket, error) {
+	if request.TicketId == "" {
+		return nil, status.Error(codes.InvalidArgument, "ticket_id is required")
+	}
+	target, err := ParseBumpStatus(request.Status)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	t, err := G.app.Commands.BumpTicket.Handle(ctx, command.BumpTicket{TicketID: request.TicketId, Status: target})
+	if err != nil {
+		var (
+			notFound   domain.NotFoundError
+			transition domain.InvalidTransitionError
+		)
+		switch {
+		case errors.As(err, &notFound):
+			return nil, status.Error(codes.NotFound, err.Error())
+		case errors.As(err, &transition):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		default:
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	return TicketToProto(t), nil
+}
+
+func (G GRPCServer) GetLoad(ctx context.Context, _ *kitchenpb.GetLoadRequest) (*kitchenpb.KitchenLoad, error) {
+	load, err := G.app.Queries.GetLoad.Handle(ctx, query.GetLoad{})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return LoadToProto(load), nil
+}
+
+func (G GRPCServer) PauseIntake(ctx context.Context, request *kitchenpb.PauseIntakeRequest) (*kitchenpb.KitchenLoad, error) {
+	load, err := G.app.Commands.PauseIntake.Handle(ctx, command.PauseIntake{Paused: request.Paused, Reason: request.Reason})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return LoadToProto(load), nil
+}
+
+// ParseBumpStatus 将显示屏上的操作转换为工单的目标状态: started 开始制作，ready 制作完成
+func ParseBumpStatus(s string) (domain.Status, error) {
+	switch s {
+	case "started":
+		return domain.StatusCooking, nil
+	case "ready":
+		return domain.StatusDone, nil
+	default:
+		return "", errors.New("status must be started or ready")
+	}
+}
+
+// TicketToProto 将工单转换为 protobuf 结构，时间使用 unix 秒，未开始或未完成时 started_at、done_at 为 0
+func TicketToProto(t *domain.Ticket) *kitchenpb.Ticket {
+	pb := &kitchenpb.Ticket{
+		Id:            t.ID,
+		OrderId:       t.OrderID,
+		CustomerId:    t.CustomerID,
+		Status:        string(t.Status),
+		CreatedAt:     t.CreatedAt.Unix(),
+		UpdatedAt:     t.UpdatedAt.Unix(),
+		FailureReason: t.FailureReason,
+		Express:       t.Express,
+		StartBy:       unixOrZero(t.SLA.StartBy),
+		ReadyBy:       unixOrZero(t.SLA.ReadyBy),
+	}
+	for _, i := range t.Items {
+		pb.Items = append(pb.Items, &kitchenpb.TicketItem{
+			Id:       i.ID,
+			Name:     i.Name,
+			Quantity: i.Quantity,
+			PrepTime: int64(i.PrepTime.Seconds()),
+		})
+	}
+	if t.StartedAt != nil {
+		pb.StartedAt = t.StartedAt.Unix()
+	}
+	if t.DoneAt != nil {
+		pb.DoneAt = t.DoneAt.Unix()
+	}
+	if t.PickupAt != nil {
+		pb.PickupAt = t.PickupAt.Unix()
+	}
+	if t.EstimatedReadyAt != nil {
+		pb.EstimatedReadyAt = t.EstimatedReadyAt.Unix()
+	}
+	for _, b := range t.Breaches {
+		pb.SlaBreaches = append(pb.SlaBreaches, string(b.Target))
+	}
+	return pb
+}
+
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func LoadToProto(load *entity.KitchenLoad) *kitchenpb.KitchenLoad {
+	return &kitchenpb.KitchenLoad{
+		Level:   string(load.Level),
+		Backlog: int64(load.Backlog),
+		Reason:  load.Reason,
+	}
+}
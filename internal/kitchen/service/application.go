@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/broker"
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/kitchen/adapter"
+	"github.com/furutachiKurea/gorder/kitchen/app"
+	"github.com/furutachiKurea/gorder/kitchen/app/command"
+	"github.com/furutachiKurea/gorder/kitchen/app/query"
+	"github.com/furutachiKurea/gorder/kitchen/domain/intake"
+	domain "github.com/furutachiKurea/gorder/kitchen/domain/ticket"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+func NewApplication(ctx context.Context, publisher broker.Publisher, metricsClient decorator.MetricsClient) (app app.Application, close func()) {
+	mongoClient, disconnectMongo := newMongoClient(ctx)
+	ticketRepo := adapter.NewTicketRepositoryMongo(mongoClient)
+	intakeRepo := adapter.NewIntakeRepositoryMongo(mongoClient)
+
+	return newApplication(ctx, ticketRepo, intakeRepo, newPrepTimes(), newSLAPolicy(), NewPriorityPolicy(), newIntakePolicy(), publisher, metricsClient), func() {
+		_ = disconnectMongo(ctx)
+	}
+}
+
+func newApplication(
+	_ context.Context,
+	ticketRepo domain.Repository,
+	intakeRepo intake.Repository,
+	prepTimes domain.PrepTimes,
+	slaPolicy domain.SLAPolicy,
+	priority domain.PriorityPolicy,
+	intakePolicy intake.Policy,
+	publisher broker.Publisher,
+	metricsClient decorator.MetricsClient,
+) app.Application {
+	logger := log.Logger
+	return app.Application{
+		Commands: app.Commands{
+			CreateTicket: command.NewCreateTicketHandler(
+				ticketRepo,
+				prepTimes,
+				slaPolicy,
+				logger,
+				metricsClient,
+			),
+			CookTicket: command.NewCookTicketHandler(
+				ticketRepo,
+				publisher,
+				logger,
+				metricsClient,
+			),
+			FailTicket: command.NewFailTicketHandler(
+				ticketRepo,
+				publisher,
+				logger,
+				metricsClient,
+			),
+			BumpTicket: command.NewBumpTicketHandler(
+				ticketRepo,
+				publisher,
+				logger,
+				metricsClient,
+			),
+			CheckSLA: command.NewCheckSLAHandler(
+				ticketRepo,
+				publisher,
+				logger,
+				metricsClient,
+			),
+			EstimateReadyTimes: command.NewEstimateReadyTimesHandler(
+				ticketRepo,
+				priority,
+				viper.GetInt("kitchen.eta.capacity"),
+				viper.GetDuration("kitchen.eta.min-change"),
+				publisher,
+				logger,
+				metricsClient,
+			),
+			PublishLoad: command.NewPublishLoadHandler(
+				ticketRepo,
+				intakeRepo,
+				intakePolicy,
+				publisher,
+				logger,
+				metricsClient,
+			),
+			PauseIntake: command.NewPauseIntakeHandler(
+				ticketRepo,
+				intakeRepo,
+				intakePolicy,
+				publisher,
+				logger,
+				metricsClient,
+			),
+		},
+		Queries: app.Queries{
+			ListActiveTickets: query.NewListActiveTicketsHandler(
+				ticketRepo,
+				priority,
+				logger,
+				metricsClient,
+			),
+			ListTicketChanges: query.NewListTicketChangesHandler(
+				ticketRepo,
+				logger,
+				metricsClient,
+			),
+			GetLoad: query.NewGetLoadHandler(
+				ticketRepo,
+				intakeRepo,
+				intakePolicy,
+				logger,
+				metricsClient,
+			),
+		},
+	}
+}
+
+// newPrepTimes 读取 kitchen.prep-times 中配置的商品制作时间，未配置的商品使用 kitchen.default-prep-time
+func newPrepTimes() domain.PrepTimes {
+	var configs []struct {
+		ItemID   string        `mapstructure:"item-id"`
+		PrepTime time.Duration `mapstructure:"prep-time"`
+	}
+	if err := viper.UnmarshalKey("kitchen.prep-times", &configs); err != nil {
+		log.Fatal().Err(err).Msg("failed to parse kitchen prep times")
+	}
+
+	prepTimes := domain.PrepTimes{
+		Default: viper.GetDuration("kitchen.default-prep-time"),
+		Items:   make(map[string]time.Duration, len(configs)),
+	}
+	for _, c := range configs {
+		prepTimes.Items[c.ItemID] = c.PrepTime
+	}
+	return prepTimes
+}
+
+// newSLAPolicy 读取 kitchen.sla 中配置的工单开始和完成制作的目标时长
+func newSLAPolicy() domain.SLAPolicy {
+	return domain.SLAPolicy{
+		Start:        viper.GetDuration("kitchen.sla.start"),
+		Ready:        viper.GetDuration("kitchen.sla.ready"),
+		ExpressStart: viper.GetDuration("kitchen.sla.express-start"),
+		ExpressReady: viper.GetDuration("kitchen.sla.express-ready"),
+	}
+}
+
+// NewPriorityPolicy 读取 kitchen.priority 中配置的工单优先级规则
+func NewPriorityPolicy() domain.PriorityPolicy {
+	return domain.PriorityPolicy{
+		ExpressBoost: viper.GetDuration("kitchen.priority.express-boost"),
+		PickupLead:   viper.GetDuration("kitchen.priority.pickup-lead"),
+		AgingAfter:   viper.GetDuration("kitchen.priority.aging-after"),
+		AgingFactor:  viper.GetFloat64("kitchen.priority.aging-factor"),
+	}
+}
+
+// newIntakePolicy 读取 kitchen.load 中配置的负载等级规则
+func newIntakePolicy() intake.Policy {
+	return intake.Policy{
+		BusyBacklog: viper.GetInt("kitchen.load.busy-backlog"),
+	}
+}
+
+func newMongoClient(ctx context.Context) (*mongo.Client, func(ctx context.Context) error) {
+	uri := fmt.Sprintf("mongodb://%s:%s@%s:%s",
+		viper.GetString("mongo.user"),
+		viper.GetString("mongo.password"),
+		viper.GetString("mongo.host"),
+		viper.GetString("mongo.port"),
+	)
+
+	c, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		panic(err)
+	}
+
+	if err = c.Ping(ctx, readpref.Primary()); err != nil {
+		panic(err)
+	}
+
+	return c, c.Disconnect
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/broker"
+	"github.com/furutachiKurea/gorder/common/consts"
+	"github.com/furutachiKurea/gorder/common/entity"
+	"github.com/furutachiKurea/gorder/common/metrics"
+	"github.com/furutachiKurea/gorder/kitchen/adapter"
+	"github.com/furutachiKurea/gorder/kitchen/app/command"
+	domain "github.com/furutachiKurea/gorder/kitchen/domain/ticket"
+	"github.com/furutachiKurea/gorder/kitchen/infrastructure/consumer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplication_TicketFromPaidOrder(t *testing.T) {
+	ctx := context.Background()
+	mb := broker.NewMemoryBroker()
+	defer mb.Close()
+
+	ticketRepo := adapter.NewMemoryTicketRepository()
+	application := newApplication(
+		ctx,
+		ticketRepo,
+		adapter.NewMemoryIntakeRepository(),
+		domain.PrepTimes{Default: time.Minute},
+		domain.SLAPolicy{},
+		domain.PriorityPolicy{},
+		newIntakePolicy(),
+		mb,
+		metrics.TodoMetrics{},
+	)
+
+	dlq, err := mb.Subscribe(ctx, broker.Subscription{Queue: broker.DLQ})
+	require.NoError(t, err)
+
+	retry := broker.RetryPolicy{MaxRetries: 1, BaseDelay: 10 * time.Millisecond}
+	go consumer.NewConsumer(application, mb, retry, 1, false, 0, domain.PriorityPolicy{}).Listen(mb)
+
+	publish := func(o *entity.Order) {
+		require.Eventually(t, func() bool {
+			return broker.PublishEvent(ctx, &broker.PublishEventReq{
+				Publisher: mb,
+				Routing:   broker.FanOut,
+				Exchange:  broker.EventOrderPaid,
+				Body:      o,
+				Mandatory: true,
+			}) == nil
+		}, time.Second, 10*time.Millisecond)
+	}
+
+	paid := &entity.Order{
+		ID:         "order",
+		CustomerID: "customer",
+		Status:     consts.OrderStatusPaid,
+		Items:      []*entity.Item{{ID: "item1", Name: "item1", Quantity: 1}},
+	}
+	publish(paid)
+	// 重复的 order.paid 不会创建第二个工单
+	publish(paid)
+
+	var ticket *domain.Ticket
+	require.Eventually(t, func() bool {
+		ticket, err = ticketRepo.GetByOrderID(ctx, "order")
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, domain.StatusQueued, ticket.Status)
+
+	active, err := ticketRepo.ListActive(ctx)
+	require.NoError(t, err)
+	assert.Len(t, active, 1)
+
+	// 无法创建工单的订单重试后移入 DLQ
+	publish(&entity.Order{ID: "unpaid", CustomerID: "customer", Status: consts.OrderStatusWaitingForPayment})
+	select {
+	case d := <-dlq:
+		var o entity.Order
+		require.NoError(t, json.Unmarshal(d.Body, &o))
+		assert.Equal(t, "unpaid", o.ID)
+		require.NoError(t, d.Ack())
+	case <-time.After(time.Second):
+		require.FailNow(t, "unpaid order not moved to dlq")
+	}
+}
+
+func TestApplication_ScheduledTicketsDoNotBlockPrefetch(t *testing.T) {
+	ctx := context.Background()
+	mb := broker.NewMemoryBroker()
+	defer mb.Close()
+
+	ticketRepo := adapter.NewMemoryTicketRepository()
+	application := newApplication(
+		ctx,
+		ticketRepo,
+		adapter.NewMemoryIntakeRepository(),
+		domain.PrepTimes{Default: 10 * time.Millisecond},
+		domain.SLAPolicy{},
+		domain.PriorityPolicy{},
+		newIntakePolicy(),
+		mb,
+		metrics.TodoMetrics{},
+	)
+
+	// 工单事件是 mandatory 消息，需要有队列接收
+	for _, exchange := range []string{broker.EventKitchenTicketStarted, broker.EventKitchenTicketReady} {
+		_, err := mb.Subscribe(ctx, broker.Subscription{Queue: "test." + exchange, Exchange: exchange})
+		require.NoError(t, err)
+	}
+
+	// 只有一个厨师且不额外预取，预约订单如果占用预取额度，之后的订单都无法投递
+	retry := broker.RetryPolicy{MaxRetries: 1, BaseDelay: 10 * time.Millisecond}
+	go consumer.NewConsumer(application, mb, retry, 1, true, 0, domain.PriorityPolicy{}).Listen(mb)
+
+	publish := func(o *entity.Order) {
+		require.Eventually(t, func() bool {
+			return broker.PublishEvent(ctx, &broker.PublishEventReq{
+				Publisher: mb,
+				Routing:   broker.FanOut,
+				Exchange:  broker.EventOrderPaid,
+				Body:      o,
+				Mandatory: true,
+			}) == nil
+		}, time.Second, 10*time.Millisecond)
+	}
+
+	pickupAt := time.Now().Add(time.Hour)
+	publish(&entity.Order{
+		ID:         "scheduled",
+		CustomerID: "customer",
+		Status:     consts.OrderStatusPaid,
+		PickupAt:   &pickupAt,
+		Items:      []*entity.Item{{ID: "item1", Quantity: 1}},
+	})
+	publish(&entity.Order{
+		ID:         "express",
+		CustomerID: "customer",
+		Status:     consts.OrderStatusPaid,
+		Express:    true,
+		Items:      []*entity.Item{{ID: "item1", Quantity: 1}},
+	})
+
+	require.Eventually(t, func() bool {
+		ticket, err := ticketRepo.GetByOrderID(ctx, "express")
+		return err == nil && ticket.Status == domain.StatusDone
+	}, time.Second, 10*time.Millisecond)
+
+	scheduled, err := ticketRepo.GetByOrderID(ctx, "scheduled")
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusQueued, scheduled.Status)
+}
+
+func TestApplication_TicketEventsPublishedAfterUpdate(t *testing.T) {
+	ctx := context.Background()
+	mb := broker.NewMemoryBroker()
+	defer mb.Close()
+
+	ticketRepo := adapter.NewMemoryTicketRepository()
+	application := newApplication(
+		ctx,
+		ticketRepo,
+		adapter.NewMemoryIntakeRepository(),
+		domain.PrepTimes{Default: time.Millisecond},
+		domain.SLAPolicy{},
+		domain.PriorityPolicy{},
+		newIntakePolicy(),
+		mb,
+		metrics.TodoMetrics{},
+	)
+
+	ticket, err := application.Commands.CreateTicket.Handle(ctx, command.CreateTicket{Order: &entity.Order{
+		ID:         "order",
+		CustomerID: "customer",
+		Status:     consts.OrderStatusPaid,
+		Items:      []*entity.Item{{ID: "item1", Quantity: 1}},
+	}})
+	require.NoError(t, err)
+
+	_, err = mb.Subscribe(ctx, broker.Subscription{Queue: "test.started", Exchange: broker.EventKitchenTicketStarted})
+	require.NoError(t, err)
+
+	// 没有队列接收 ready 事件时发布失败，工单已经保存为完成
+	_, err = application.Commands.CookTicket.Handle(ctx, command.CookTicket{TicketID: ticket.ID})
+	var returnErr broker.ReturnError
+	require.ErrorAs(t, err, &returnErr)
+	got, err := ticketRepo.Get(ctx, ticket.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusDone, got.Status)
+
+	// 消息重新投递时按已保存的状态重新发布 ready 事件
+	ready, err := mb.Subscribe(ctx, broker.Subscription{Queue: "test.ready", Exchange: broker.EventKitchenTicketReady})
+	require.NoError(t, err)
+	_, err = application.Commands.CookTicket.Handle(ctx, command.CookTicket{TicketID: ticket.ID})
+	require.NoError(t, err)
+	select {
+	case d := <-ready:
+		var event entity.TicketEvent
+		require.NoError(t, json.Unmarshal(d.Body, &event))
+		assert.Equal(t, "order", event.OrderID)
+		require.NoError(t, d.Ack())
+	case <-time.After(time.Second):
+		require.FailNow(t, "ticket ready event not republished")
+	}
+}
@@ -0,0 +1,70 @@
+package grpc
+
+import (
+	"context"
+	"sync"
+
+	grpcclient "github.com/furutachiKurea/gorder/common/client"
+	"github.com/furutachiKurea/gorder/common/genproto/orderpb"
+	"github.com/furutachiKurea/gorder/common/genproto/paymentpb"
+	"github.com/furutachiKurea/gorder/common/logging"
+)
+
+// PaymentGRPC 在第一次调用时才连接 payment 服务：payment 服务启动时需要等待 order 服务，
+// 如果 order 服务启动时也等待 payment 服务，两者会互相等待直到超时
+type PaymentGRPC struct {
+	ctx    context.Context
+	lock   sync.Mutex
+	client paymentpb.PaymentServiceClient
+	close  func() error
+}
+
+func NewPaymentGRPC(ctx context.Context) *PaymentGRPC {
+	return &PaymentGRPC{ctx: ctx}
+}
+
+func (p *PaymentGRPC) RegeneratePaymentLink(ctx context.Context, order *orderpb.Order) (link string, err error) {
+	_, deferlog := logging.WhenRequest(ctx, "PaymentGRPC.RegeneratePaymentLink", order)
+	defer deferlog(link, &err)
+
+	client, err := p.getClient()
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := client.RegeneratePaymentLink(ctx, &paymentpb.RegeneratePaymentLinkRequest{Order: order})
+	if err != nil {
+		return "", err
+	}
+
+	return resp.PaymentLink, nil
+}
+
+// Close 关闭已建立的连接
+func (p *PaymentGRPC) Close() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.close == nil {
+		return nil
+	}
+	return p.close()
+}
+
+// getClient 返回已建立的连接，连接失败时不缓存错误，下次调用时重试
+func (p *PaymentGRPC) getClient() (paymentpb.PaymentServiceClient, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.client != nil {
+		return p.client, nil
+	}
+
+	client, closeFn, err := grpcclient.NewPaymentGRPCClient(p.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.client, p.close = client, closeFn
+	return client, nil
+}
@@ -27,6 +27,25 @@ func (s StockGRPC) GetItems(ctx context.Context, itemIDs []string) (items []*ord
 	return resp.Items, nil
 }
 
+func (s StockGRPC) GetStock(ctx context.Context, productIDs []string) (stocks []*stockpb.StockLevel, err error) {
+	_, deferlog := logging.WhenRequest(ctx, "StockGRPC.GetStock", productIDs)
+	defer deferlog(stocks, &err)
+
+	resp, err := s.client.GetStock(ctx, &stockpb.GetStockRequest{ProductIds: productIDs})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Stocks, nil
+}
+
+func (s StockGRPC) CheckAvailability(ctx context.Context, items []*orderpb.ItemWithQuantity) (resp *stockpb.CheckAvailabilityResponse, err error) {
+	_, deferlog := logging.WhenRequest(ctx, "StockGRPC.CheckAvailability", items)
+	defer deferlog(resp, &err)
+
+	return s.client.CheckAvailability(ctx, &stockpb.CheckAvailabilityRequest{Items: items})
+}
+
 func (s StockGRPC) ReserveStock(ctx context.Context, items []*orderpb.ItemWithQuantity) (resp *stockpb.ReserveStockResponse, err error) {
 	_, deferlog := logging.WhenRequest(ctx, "StockGRPC.ReserveStock", items)
 	defer deferlog(resp, &err)
@@ -45,3 +64,20 @@ func (s StockGRPC) ConfirmStockReservation(ctx context.Context, items []*orderpb
 		&stockpb.ConfirmStockReservationRequest{Items: items},
 	)
 }
+
+func (s StockGRPC) ReleaseStockReservation(
+	ctx context.Context,
+	items []*orderpb.ItemWithQuantity,
+	backorderIDs []string,
+) (resp *stockpb.ReleaseStockReservationResponse, err error) {
+	_, deferlog := logging.WhenRequest(ctx, "StockGRPC.ReleaseStockReservation", map[string]any{
+		"items":         items,
+		"backorder_ids": backorderIDs,
+	})
+	defer deferlog(resp, &err)
+
+	return s.client.ReleaseStockReservation(
+		ctx,
+		&stockpb.ReleaseStockReservationRequest{Items: items, BackorderIds: backorderIDs},
+	)
+}
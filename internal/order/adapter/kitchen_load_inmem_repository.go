@@ -0,0 +1,41 @@
+package adapter
+
+import (
+	"context"
+	"sync"
+
+	"github.com/furutachiKurea/gorder/common/entity"
+)
+
+// MemoryKitchenLoadRepository 每个订单服务实例都订阅厨房的负载，只需要保存在内存中
+type MemoryKitchenLoadRepository struct {
+	lock   *sync.RWMutex
+	latest *entity.KitchenLoad
+}
+
+func NewMemoryKitchenLoadRepository() *MemoryKitchenLoadRepository {
+	return &MemoryKitchenLoadRepository{lock: &sync.RWMutex{}}
+}
+
+func (m *MemoryKitchenLoadRepository) Get(_ context.Context) (*entity.KitchenLoad, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	if m.latest == nil {
+		return nil, nil
+	}
+	got := *m.latest
+	return &got, nil
+}
+
+func (m *MemoryKitchenLoadRepository) Save(_ context.Context, load *entity.KitchenLoad) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.latest != nil && load.PublishedAt.Before(m.latest.PublishedAt) {
+		return nil
+	}
+	saved := *load
+	m.latest = &saved
+	return nil
+}
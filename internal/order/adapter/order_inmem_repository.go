@@ -6,6 +6,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/furutachiKurea/gorder/common/consts"
+	"github.com/furutachiKurea/gorder/common/entity"
 	domain "github.com/furutachiKurea/gorder/order/domain/order"
 	"github.com/rs/zerolog/log"
 )
@@ -42,6 +44,8 @@ func (m *MemoryOrderRepository) Create(_ context.Context, order *domain.Order) (
 		Status:      order.Status,
 		PaymentLink: order.PaymentLink,
 		Items:       order.Items,
+		Express:     order.Express,
+		PickupAt:    order.PickupAt,
 	}
 
 	m.store = append(m.store, newOrder)
@@ -82,6 +86,37 @@ func (m *MemoryOrderRepository) Get(_ context.Context, orderID, customerID strin
 	return nil, domain.NotFoundError{OrderID: orderID}
 }
 
+func (m *MemoryOrderRepository) GetByBackorderID(_ context.Context, backorderID string) (*domain.Order, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	for _, o := range m.store {
+		for _, item := range o.Items {
+			if item.BackorderID == backorderID {
+				return o, nil
+			}
+		}
+	}
+
+	return nil, domain.BackorderNotFoundError{BackorderID: backorderID}
+}
+
+func (m *MemoryOrderRepository) AllocateBackorder(_ context.Context, backorderID string) (*domain.Order, *entity.Item, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, o := range m.store {
+		for _, item := range o.Items {
+			if item.BackorderID == backorderID {
+				allocated, err := o.AllocateBackorder(backorderID)
+				return o, allocated, err
+			}
+		}
+	}
+
+	return nil, nil, domain.BackorderNotFoundError{BackorderID: backorderID}
+}
+
 func (m *MemoryOrderRepository) Update(ctx context.Context, updates *domain.Order) error {
 	m.lock.Lock()
 	defer m.lock.Unlock()
@@ -100,3 +135,44 @@ func (m *MemoryOrderRepository) Update(ctx context.Context, updates *domain.Orde
 
 	return nil
 }
+
+func (m *MemoryOrderRepository) Reopen(_ context.Context, orderID, customerID string, items []*entity.Item) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, o := range m.store {
+		if o.ID == orderID && o.CustomerID == customerID {
+			return o.Reopen(items)
+		}
+	}
+
+	return domain.NotFoundError{OrderID: orderID}
+}
+
+func (m *MemoryOrderRepository) UpdateEstimate(_ context.Context, orderID, customerID string, readyAt, estimatedAt time.Time) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, o := range m.store {
+		if o.ID == orderID && o.CustomerID == customerID {
+			o.UpdateEstimate(readyAt, estimatedAt)
+			return nil
+		}
+	}
+
+	return domain.NotFoundError{OrderID: orderID}
+}
+
+func (m *MemoryOrderRepository) UpdateKitchenProgress(_ context.Context, orderID, customerID string, status consts.OrderStatus) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, o := range m.store {
+		if o.ID == orderID && o.CustomerID == customerID {
+			_, err := o.ApplyKitchenProgress(status)
+			return err
+		}
+	}
+
+	return domain.NotFoundError{OrderID: orderID}
+}
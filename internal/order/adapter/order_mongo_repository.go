@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	_ "github.com/furutachiKurea/gorder/common/config"
 	"github.com/furutachiKurea/gorder/common/consts"
@@ -16,6 +17,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 var (
@@ -77,6 +79,63 @@ func (r *OrderRepositoryMongo) Get(ctx context.Context, orderID, customerID stri
 	return r.unmarshal(read), nil
 }
 
+func (r *OrderRepositoryMongo) GetByBackorderID(ctx context.Context, backorderID string) (got *domain.Order, err error) {
+	_, deferlog := logging.WhenRequest(ctx, "OrderRepositoryMongo.GetByBackorderID", map[string]any{
+		"backorder_id": backorderID,
+	})
+	defer deferlog(got, &err)
+
+	read := &orderModel{}
+	err = r.collection().FindOne(ctx, bson.M{"items.backorderid": backorderID}).Decode(read)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.BackorderNotFoundError{BackorderID: backorderID}
+		}
+		return nil, err
+	}
+
+	return r.unmarshal(read), nil
+}
+
+// AllocateBackorder 在一次更新中只修改缺货预订对应的商品，不会覆盖订单其他字段的并发修改
+func (r *OrderRepositoryMongo) AllocateBackorder(ctx context.Context, backorderID string) (got *domain.Order, item *entity.Item, err error) {
+	_, deferlog := logging.WhenRequest(ctx, "OrderRepositoryMongo.AllocateBackorder", map[string]any{
+		"backorder_id": backorderID,
+	})
+	defer deferlog(got, &err)
+
+	read := &orderModel{}
+	err = r.collection().FindOneAndUpdate(
+		ctx,
+		bson.M{"items": bson.M{"$elemMatch": bson.M{
+			"backorderid":      backorderID,
+			"fulfilmentstatus": bson.M{"$in": []consts.FulfilmentStatus{consts.FulfilmentBackordered, consts.FulfilmentPreordered}},
+		}}},
+		bson.M{"$set": bson.M{"items.$.fulfilmentstatus": consts.FulfilmentAllocated}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(read)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		// 没有待分配的商品，由领域对象判断是重复的分配事件还是无效的预订
+		got, err = r.GetByBackorderID(ctx, backorderID)
+		if err != nil {
+			return nil, nil, err
+		}
+		item, err = got.AllocateBackorder(backorderID)
+		return got, item, err
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	got = r.unmarshal(read)
+	for _, i := range got.Items {
+		if i.BackorderID == backorderID {
+			item = i
+		}
+	}
+	return got, item, nil
+}
+
 // Update 先查找对应的 Order，然后 apply updateFn，再写入 Mongo
 func (r *OrderRepositoryMongo) Update(ctx context.Context, updates *domain.Order) (err error) {
 	_, deferlog := logging.WhenRequest(ctx, "OrderRepositoryMongo.Update", map[string]any{
@@ -88,6 +147,55 @@ func (r *OrderRepositoryMongo) Update(ctx context.Context, updates *domain.Order
 		panic("got nil order")
 	}
 
+	return r.update(ctx, updates.ID, updates.CustomerID, func(order *domain.Order) error {
+		return order.UpdateTo(updates)
+	})
+}
+
+func (r *OrderRepositoryMongo) Reopen(ctx context.Context, orderID, customerID string, items []*entity.Item) (err error) {
+	_, deferlog := logging.WhenRequest(ctx, "OrderRepositoryMongo.Reopen", map[string]any{
+		"order_id":    orderID,
+		"customer_id": customerID,
+		"items":       items,
+	})
+	defer deferlog(nil, &err)
+
+	return r.update(ctx, orderID, customerID, func(order *domain.Order) error {
+		return order.Reopen(items)
+	})
+}
+
+func (r *OrderRepositoryMongo) UpdateEstimate(ctx context.Context, orderID, customerID string, readyAt, estimatedAt time.Time) (err error) {
+	_, deferlog := logging.WhenRequest(ctx, "OrderRepositoryMongo.UpdateEstimate", map[string]any{
+		"order_id":     orderID,
+		"customer_id":  customerID,
+		"ready_at":     readyAt,
+		"estimated_at": estimatedAt,
+	})
+	defer deferlog(nil, &err)
+
+	return r.update(ctx, orderID, customerID, func(order *domain.Order) error {
+		order.UpdateEstimate(readyAt, estimatedAt)
+		return nil
+	})
+}
+
+func (r *OrderRepositoryMongo) UpdateKitchenProgress(ctx context.Context, orderID, customerID string, status consts.OrderStatus) (err error) {
+	_, deferlog := logging.WhenRequest(ctx, "OrderRepositoryMongo.UpdateKitchenProgress", map[string]any{
+		"order_id":    orderID,
+		"customer_id": customerID,
+		"status":      status,
+	})
+	defer deferlog(nil, &err)
+
+	return r.update(ctx, orderID, customerID, func(order *domain.Order) error {
+		_, err := order.ApplyKitchenProgress(status)
+		return err
+	})
+}
+
+// update 在事务中查找对应的 Order，apply updateFn 后写入 Mongo
+func (r *OrderRepositoryMongo) update(ctx context.Context, orderID, customerID string, updateFn func(order *domain.Order) error) (err error) {
 	session, err := r.db.StartSession()
 	if err != nil {
 		return
@@ -106,12 +214,12 @@ func (r *OrderRepositoryMongo) Update(ctx context.Context, updates *domain.Order
 	}()
 
 	// transaction in (end at defer)
-	order, err := r.Get(ctx, updates.ID, updates.CustomerID)
+	order, err := r.Get(ctx, orderID, customerID)
 	if err != nil {
 		return
 	}
 
-	err = order.UpdateTo(updates)
+	err = updateFn(order)
 	if err != nil {
 		return err
 	}
@@ -122,9 +230,12 @@ func (r *OrderRepositoryMongo) Update(ctx context.Context, updates *domain.Order
 		ctx,
 		bson.M{"_id": mongoID},
 		bson.M{"$set": bson.M{
-			"id":           mongoID,
-			"status":       order.Status,
-			"payment_link": order.PaymentLink,
+			"id":                 mongoID,
+			"status":             order.Status,
+			"payment_link":       order.PaymentLink,
+			"items":              order.Items,
+			"estimated_ready_at": order.EstimatedReadyAt,
+			"estimated_at":       order.EstimatedAt,
 		}},
 	)
 	if err != nil {
@@ -141,31 +252,43 @@ func (r *OrderRepositoryMongo) collection() *mongo.Collection {
 
 func (r *OrderRepositoryMongo) domainToMongo(order *domain.Order) *orderModel {
 	return &orderModel{
-		MongoID:     primitive.NewObjectID(),
-		ID:          order.ID,
-		CustomerID:  order.CustomerID,
-		Status:      string(order.Status),
-		PaymentLink: order.PaymentLink,
-		Items:       order.Items,
+		MongoID:          primitive.NewObjectID(),
+		ID:               order.ID,
+		CustomerID:       order.CustomerID,
+		Status:           string(order.Status),
+		PaymentLink:      order.PaymentLink,
+		Items:            order.Items,
+		Express:          order.Express,
+		PickupAt:         order.PickupAt,
+		EstimatedReadyAt: order.EstimatedReadyAt,
+		EstimatedAt:      order.EstimatedAt,
 	}
 }
 
 func (r *OrderRepositoryMongo) unmarshal(m *orderModel) *domain.Order {
 	return &domain.Order{
-		ID:          m.MongoID.Hex(),
-		CustomerID:  m.CustomerID,
-		Status:      consts.OrderStatus(m.Status),
-		PaymentLink: m.PaymentLink,
-		Items:       m.Items,
+		ID:               m.MongoID.Hex(),
+		CustomerID:       m.CustomerID,
+		Status:           consts.OrderStatus(m.Status),
+		PaymentLink:      m.PaymentLink,
+		Items:            m.Items,
+		Express:          m.Express,
+		PickupAt:         m.PickupAt,
+		EstimatedReadyAt: m.EstimatedReadyAt,
+		EstimatedAt:      m.EstimatedAt,
 	}
 }
 
 // orderModel MongoDB 的订单模型
 type orderModel struct {
-	MongoID     primitive.ObjectID `bson:"_id"`
-	ID          string             `bson:"id"` // ID 与 MongoID 对应
-	CustomerID  string             `bson:"customer_id"`
-	Status      string             `bson:"status"`
-	PaymentLink string             `bson:"payment_link"`
-	Items       []*entity.Item     `bson:"items"`
+	MongoID          primitive.ObjectID `bson:"_id"`
+	ID               string             `bson:"id"` // ID 与 MongoID 对应
+	CustomerID       string             `bson:"customer_id"`
+	Status           string             `bson:"status"`
+	PaymentLink      string             `bson:"payment_link"`
+	Items            []*entity.Item     `bson:"items"`
+	Express          bool               `bson:"express"`
+	PickupAt         *time.Time         `bson:"pickup_at"`
+	EstimatedReadyAt *time.Time         `bson:"estimated_ready_at"`
+	EstimatedAt      *time.Time         `bson:"estimated_at"`
 }
@@ -12,11 +12,19 @@ type Application struct {
 }
 
 type Commands struct {
-	CreateOrder      command.CreateOrderHandler
-	UpdateOrder      command.UpdateOrderHandler
-	ConfirmOrderPaid command.ConfirmOrderPaidHandler
+	CreateOrder           command.CreateOrderHandler
+	UpdateOrder           command.UpdateOrderHandler
+	ConfirmOrderPaid      command.ConfirmOrderPaidHandler
+	AllocateBackorder     command.AllocateBackorderHandler
+	CloseOrderPayment     command.CloseOrderPaymentHandler
+	RegeneratePaymentLink command.RegeneratePaymentLinkHandler
+	UpdateOrderEstimate   command.UpdateOrderEstimateHandler
+	UpdateKitchenLoad     command.UpdateKitchenLoadHandler
+	UpdateKitchenProgress command.UpdateKitchenProgressHandler
 }
 
 type Queries struct {
-	GetCustomerOrder query.GetCustomerOrderHandler
+	GetCustomerOrder       query.GetCustomerOrderHandler
+	GetProductAvailability query.GetProductAvailabilityHandler
+	CheckAvailability      query.CheckAvailabilityHandler
 }
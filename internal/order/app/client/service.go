@@ -9,6 +9,14 @@ import (
 
 type StockService interface {
 	GetItems(ctx context.Context, itemIDs []string) ([]*orderpb.Item, error)
+	GetStock(ctx context.Context, productIDs []string) ([]*stockpb.StockLevel, error)
+	CheckAvailability(ctx context.Context, items []*orderpb.ItemWithQuantity) (*stockpb.CheckAvailabilityResponse, error)
 	ReserveStock(ctx context.Context, items []*orderpb.ItemWithQuantity) (*stockpb.ReserveStockResponse, error)
 	ConfirmStockReservation(ctx context.Context, items []*orderpb.ItemWithQuantity) (*stockpb.ConfirmStockReservationResponse, error)
+	ReleaseStockReservation(ctx context.Context, items []*orderpb.ItemWithQuantity, backorderIDs []string) (*stockpb.ReleaseStockReservationResponse, error)
+}
+
+type PaymentService interface {
+	// RegeneratePaymentLink 使订单当前的支付会话失效并创建新的支付会话，返回新的支付链接
+	RegeneratePaymentLink(ctx context.Context, order *orderpb.Order) (string, error)
 }
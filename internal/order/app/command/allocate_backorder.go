@@ -0,0 +1,85 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/furutachiKurea/gorder/common/convertor"
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/entity"
+	"github.com/furutachiKurea/gorder/common/logging"
+	"github.com/furutachiKurea/gorder/order/app/client"
+	domain "github.com/furutachiKurea/gorder/order/domain/order"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+type AllocateBackorder struct {
+	Allocation *entity.BackorderAllocation
+}
+
+// AllocateBackorderHandler 补货后将订单中缺货预订的商品标记为已分配，
+// 订单已经支付时，该商品在支付确认时被跳过，需要在这里完成库存的实际减扣
+type AllocateBackorderHandler decorator.CommandHandler[AllocateBackorder, any]
+
+type allocateBackorderHandler struct {
+	orderRepo domain.Repository
+	stockGRPC client.StockService
+}
+
+func NewAllocateBackorderHandler(
+	orderRepo domain.Repository,
+	stockGRPC client.StockService,
+	logger zerolog.Logger,
+	metricsClient decorator.MetricsClient,
+) AllocateBackorderHandler {
+	if orderRepo == nil {
+		panic("orderRepo is nil")
+	}
+	if stockGRPC == nil {
+		panic("stockGRPC is nil")
+	}
+
+	return decorator.ApplyCommandDecorators[AllocateBackorder, any](
+		allocateBackorderHandler{
+			orderRepo: orderRepo,
+			stockGRPC: stockGRPC,
+		},
+		logger,
+		metricsClient,
+	)
+}
+
+func (h allocateBackorderHandler) Handle(ctx context.Context, cmd AllocateBackorder) (any, error) {
+	var err error
+	defer logging.WhenCommandExecute(ctx, "AllocateBackorderHandler", cmd, err)
+
+	order, item, err := h.orderRepo.AllocateBackorder(ctx, cmd.Allocation.BackorderID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		log.Info().Ctx(ctx).
+			Str("order_id", order.ID).
+			Str("backorder_id", cmd.Allocation.BackorderID).
+			Msg("backorder already allocated, skip")
+		return nil, nil
+	}
+
+	if !order.IsPaymentCompleted() {
+		return nil, nil
+	}
+
+	_, err = h.stockGRPC.ConfirmStockReservation(
+		ctx,
+		convertor.NewItemWithQuantityConvertor().EntitiesToProtos([]*entity.ItemWithQuantity{
+			{ID: item.ID, Quantity: item.Quantity},
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("confirm stock reservation: %w", err)
+	}
+
+	return nil, nil
+}
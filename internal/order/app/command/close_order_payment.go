@@ -0,0 +1,101 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/furutachiKurea/gorder/common/consts"
+	"github.com/furutachiKurea/gorder/common/convertor"
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/logging"
+	"github.com/furutachiKurea/gorder/common/tracing"
+	"github.com/furutachiKurea/gorder/order/app/client"
+	domain "github.com/furutachiKurea/gorder/order/domain/order"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// CloseOrderPayment 订单支付失败或过期，Order.Status 为 payment_failed 或 payment_expired
+type CloseOrderPayment struct {
+	Order *domain.Order
+}
+
+// CloseOrderPaymentHandler 关闭支付失败或过期的订单，并释放订单占用的库存
+type CloseOrderPaymentHandler decorator.CommandHandler[CloseOrderPayment, any]
+
+type closeOrderPaymentHandler struct {
+	orderRepo domain.Repository
+	stockGRPC client.StockService
+}
+
+func NewCloseOrderPaymentHandler(
+	orderRepo domain.Repository,
+	stockGRPC client.StockService,
+	logger zerolog.Logger,
+	metricsClient decorator.MetricsClient,
+) CloseOrderPaymentHandler {
+	if orderRepo == nil {
+		panic("orderRepo is nil")
+	}
+	if stockGRPC == nil {
+		panic("stockGRPC is nil")
+	}
+
+	return decorator.ApplyCommandDecorators[CloseOrderPayment, any](
+		closeOrderPaymentHandler{
+			orderRepo: orderRepo,
+			stockGRPC: stockGRPC,
+		},
+		logger,
+		metricsClient,
+	)
+}
+
+// Handle 先释放库存再更新订单状态，已关闭或已支付的订单直接跳过，避免重复的消息重复释放库存
+func (h closeOrderPaymentHandler) Handle(ctx context.Context, cmd CloseOrderPayment) (any, error) {
+	var err error
+	defer logging.WhenCommandExecute(ctx, "CloseOrderPaymentHandler", cmd, err)
+
+	ctx, span := tracing.Start(ctx, "closeOrderPaymentHandler")
+	defer span.End()
+
+	if cmd.Order.Status != consts.OrderStatusPaymentFailed && cmd.Order.Status != consts.OrderStatusPaymentExpired {
+		return nil, fmt.Errorf("close order payment with status %s not allowed, order_id=%s", cmd.Order.Status, cmd.Order.ID)
+	}
+
+	// 以存储的订单为准，消息中的商品履约状态可能已经过期
+	order, err := h.orderRepo.Get(ctx, cmd.Order.ID, cmd.Order.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != consts.OrderStatusPending && order.Status != consts.OrderStatusWaitingForPayment {
+		log.Info().Ctx(ctx).
+			Str("order_id", order.ID).
+			Str("status", string(order.Status)).
+			Str("tried_status", string(cmd.Order.Status)).
+			Msg("order is not waiting for payment, skip")
+		return nil, nil
+	}
+
+	items, backorderIDs := order.ReservedStock()
+	if len(items) > 0 || len(backorderIDs) > 0 {
+		if _, err = h.stockGRPC.ReleaseStockReservation(
+			ctx,
+			convertor.NewItemWithQuantityConvertor().EntitiesToProtos(items),
+			backorderIDs,
+		); err != nil {
+			return nil, fmt.Errorf("release stock reservation: %w", err)
+		}
+	}
+
+	if err = h.orderRepo.Update(ctx, &domain.Order{
+		ID:         order.ID,
+		CustomerID: order.CustomerID,
+		Status:     cmd.Order.Status,
+	}); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
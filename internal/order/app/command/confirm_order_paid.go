@@ -13,6 +13,7 @@ import (
 	domain "github.com/furutachiKurea/gorder/order/domain/order"
 
 	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
 )
 
 type ConfirmOrderPaid struct {
@@ -47,6 +48,8 @@ func NewConfirmOrderPaidHandler(
 	)
 }
 
+// Handle 支付服务可能重复发布 order.paid (Webhook 重试、对账)，已经支付的订单直接跳过，不会重复扣减库存。
+// 先确认扣减再将订单标记为已支付，扣减失败时订单仍为待支付，消息重试时会再次扣减
 func (c confirmOrderPaidHandler) Handle(ctx context.Context, cmd ConfirmOrderPaid) (any, error) {
 	var err error
 	defer logging.WhenCommandExecute(ctx, "ConfirmOrderPaidHandler", cmd, err)
@@ -56,24 +59,43 @@ func (c confirmOrderPaidHandler) Handle(ctx context.Context, cmd ConfirmOrderPai
 	if err := cmd.Order.IsPaid(); err != nil {
 		return nil, err
 	}
-	if err := c.orderRepo.Update(ctx, cmd.Order); err != nil {
+
+	// 以存储的订单为准，消息中的商品履约状态可能已经过期
+	stored, err := c.orderRepo.Get(ctx, cmd.Order.ID, cmd.Order.CustomerID)
+	if err != nil {
 		return nil, err
 	}
+	if stored.Status.IsPaid() {
+		log.Info().Ctx(ctx).
+			Str("order_id", stored.ID).
+			Str("status", string(stored.Status)).
+			Msg("order already paid, skip duplicated paid event")
+		return nil, nil
+	}
 
+	// 缺货预订和预售的商品尚未占用库存，等补货分配后再扣减
 	var itemWithQuantities []*entity.ItemWithQuantity
-	for _, orderItem := range cmd.Order.Items {
+	for _, orderItem := range stored.Items {
+		if !orderItem.FulfilmentStatus.IsReserved() {
+			continue
+		}
 		itemWithQuantities = append(itemWithQuantities, &entity.ItemWithQuantity{
 			ID:       orderItem.ID,
 			Quantity: orderItem.Quantity,
 		})
 	}
+	if len(itemWithQuantities) > 0 {
+		_, err = c.stockGRPC.ConfirmStockReservation(
+			ctx,
+			convertor.NewItemWithQuantityConvertor().EntitiesToProtos(itemWithQuantities),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("confirm stock reservation: %w", err)
+		}
+	}
 
-	_, err = c.stockGRPC.ConfirmStockReservation(
-		ctx,
-		convertor.NewItemWithQuantityConvertor().EntitiesToProtos(itemWithQuantities),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("confirm stock reservation: %w", err)
+	if err = c.orderRepo.Update(ctx, cmd.Order); err != nil {
+		return nil, err
 	}
 
 	return nil, nil
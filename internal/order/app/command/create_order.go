@@ -4,16 +4,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/furutachiKurea/gorder/common/broker"
 	"github.com/furutachiKurea/gorder/common/convertor"
 	"github.com/furutachiKurea/gorder/common/decorator"
 	"github.com/furutachiKurea/gorder/common/entity"
+	"github.com/furutachiKurea/gorder/common/handler/redis"
 	"github.com/furutachiKurea/gorder/common/logging"
 	"github.com/furutachiKurea/gorder/order/app/client"
+	"github.com/furutachiKurea/gorder/order/domain/intake"
 	domain "github.com/furutachiKurea/gorder/order/domain/order"
 
-	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"go.opentelemetry.io/otel"
@@ -23,25 +25,36 @@ import (
 type CreateOrder struct {
 	CustomerID string
 	Items      []*entity.ItemWithQuantity
+	// Express 加急订单
+	Express bool
+	// PickupAt 预约取餐时间，为 nil 时尽快制作
+	PickupAt *time.Time
 }
 
 type CreateOrderResult struct {
 	OrderID string
 }
 
-// CreateOrderHandler 创建订单，校验库存后发布订单创建事件到 RabbitMQ
+// CreateOrderHandler 创建订单，根据厨房的负载决定是否接受订单，校验库存后发布订单创建事件到 RabbitMQ
 type CreateOrderHandler decorator.CommandHandler[CreateOrder, *CreateOrderResult]
 
 type createOrderHandler struct {
 	orderRepo domain.Repository
 	stockGRPC client.StockService
-	channel   *amqp.Channel
+	publisher broker.Publisher
+	loadRepo  intake.Repository
+	intake    intake.Policy
+	// throttleInterval 厨房繁忙时所有订单服务实例在该间隔内只接受一个新订单
+	throttleInterval time.Duration
 }
 
 func NewCreateOrderHandler(
 	orderRepo domain.Repository,
 	stockGRPC client.StockService,
-	channel *amqp.Channel,
+	publisher broker.Publisher,
+	loadRepo intake.Repository,
+	intakePolicy intake.Policy,
+	throttleInterval time.Duration,
 	logger zerolog.Logger,
 	metricsClient decorator.MetricsClient,
 ) CreateOrderHandler {
@@ -53,14 +66,21 @@ func NewCreateOrderHandler(
 		panic("stockGRPC is nil")
 	}
 
-	if channel == nil {
-		panic("channel is nil")
+	if publisher == nil {
+		panic("publisher is nil")
+	}
+
+	if loadRepo == nil {
+		panic("loadRepo is nil")
 	}
 	return decorator.ApplyCommandDecorators[CreateOrder, *CreateOrderResult](
 		createOrderHandler{
-			orderRepo: orderRepo,
-			stockGRPC: stockGRPC,
-			channel:   channel,
+			orderRepo:        orderRepo,
+			stockGRPC:        stockGRPC,
+			publisher:        publisher,
+			loadRepo:         loadRepo,
+			intake:           intakePolicy,
+			throttleInterval: throttleInterval,
 		},
 		logger,
 		metricsClient,
@@ -75,6 +95,22 @@ func (c createOrderHandler) Handle(ctx context.Context, cmd CreateOrder) (*Creat
 	ctx, span := t.Start(ctx, fmt.Sprintf("rabbitmq.%s.publish", broker.EventOrderPaid))
 	defer span.End()
 
+	throttled, err := c.checkIntake(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if throttled {
+		// 被拒绝的订单不占用限流的名额
+		defer func() {
+			if err == nil {
+				return
+			}
+			if delErr := redis.Del(ctx, redis.LocalClient(), orderIntakeThrottleKey); delErr != nil {
+				log.Warn().Ctx(ctx).Err(delErr).Msg("release order intake throttle failed")
+			}
+		}()
+	}
+
 	validItems, err := c.validate(ctx, cmd.Items)
 	if err != nil {
 		return nil, err
@@ -88,17 +124,20 @@ func (c createOrderHandler) Handle(ctx context.Context, cmd CreateOrder) (*Creat
 	if err != nil {
 		return nil, err
 	}
+	if err = pendingOrder.Schedule(cmd.Express, cmd.PickupAt, time.Now()); err != nil {
+		return nil, err
+	}
 	order, err := c.orderRepo.Create(ctx, pendingOrder)
 	if err != nil {
 		return nil, fmt.Errorf("create order: %w", err)
 	}
 	log.Debug().Ctx(ctx).Any("order", order).Msg("create order in repository")
 	err = broker.PublishEvent(ctx, &broker.PublishEventReq{
-		Channel:  c.channel,
-		Routing:  broker.Direct,
-		Queue:    broker.EventOrderCreated,
-		Exchange: "",
-		Body:     order,
+		Publisher: c.publisher,
+		Routing:   broker.Direct,
+		Queue:     broker.EventOrderCreated,
+		Exchange:  "",
+		Body:      order,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("publish event error q.Name=%s, err:%w", broker.EventOrderPaid, err)
@@ -110,6 +149,29 @@ func (c createOrderHandler) Handle(ctx context.Context, cmd CreateOrder) (*Creat
 
 }
 
+// checkIntake 厨房暂停接单或积压过多时拒绝新订单，厨房繁忙时按 throttleInterval 限流，
+// 返回是否占用了限流的名额，订单创建失败时由调用方释放
+func (c createOrderHandler) checkIntake(ctx context.Context) (bool, error) {
+	load, err := c.loadRepo.Get(ctx)
+	if err != nil {
+		return false, fmt.Errorf("get kitchen load: %w", err)
+	}
+
+	throttle, err := c.intake.Check(load, time.Now())
+	if err != nil || !throttle {
+		return false, err
+	}
+
+	ok, err := redis.SetIfAbsent(ctx, redis.LocalClient(), orderIntakeThrottleKey, "1", c.throttleInterval)
+	if err != nil {
+		return false, fmt.Errorf("redis intake throttle: %w", err)
+	}
+	if !ok {
+		return false, intake.KitchenBusyError{Backlog: load.Backlog}
+	}
+	return true, nil
+}
+
 // validate 校验订单是否合法，合并商品数量，库存充足并正确预扣库存后返回订单 Item
 func (c createOrderHandler) validate(ctx context.Context, items []*entity.ItemWithQuantity) ([]*entity.Item, error) {
 	if len(items) == 0 {
@@ -130,6 +192,9 @@ func (c createOrderHandler) validate(ctx context.Context, items []*entity.ItemWi
 	return convertor.NewItemConvertor().ProtosToEntities(resp.Items), nil
 }
 
+// orderIntakeThrottleKey 厨房繁忙时限流新订单的 redis key
+const orderIntakeThrottleKey = "order_intake_throttle"
+
 // packItems 合并相同商品的数量
 func packItems(items []*entity.ItemWithQuantity) []*entity.ItemWithQuantity {
 	merged := make(map[string]int64)
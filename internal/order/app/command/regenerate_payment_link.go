@@ -0,0 +1,191 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/consts"
+	"github.com/furutachiKurea/gorder/common/convertor"
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/entity"
+	"github.com/furutachiKurea/gorder/common/handler/redis"
+	"github.com/furutachiKurea/gorder/common/logging"
+	"github.com/furutachiKurea/gorder/common/tracing"
+	"github.com/furutachiKurea/gorder/order/app/client"
+	domain "github.com/furutachiKurea/gorder/order/domain/order"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrPaymentLinkRateLimited 同一订单在限流间隔内重复生成支付链接
+var ErrPaymentLinkRateLimited = errors.New("payment link regenerated too frequently")
+
+type RegeneratePaymentLink struct {
+	CustomerID string
+	OrderID    string
+}
+
+type RegeneratePaymentLinkResult struct {
+	PaymentLink string
+}
+
+// RegeneratePaymentLinkHandler 为未支付的订单重新生成支付链接，每个订单在 interval 内只能生成一次
+type RegeneratePaymentLinkHandler decorator.CommandHandler[RegeneratePaymentLink, *RegeneratePaymentLinkResult]
+
+type regeneratePaymentLinkHandler struct {
+	orderRepo   domain.Repository
+	stockGRPC   client.StockService
+	paymentGRPC client.PaymentService
+	interval    time.Duration
+}
+
+func NewRegeneratePaymentLinkHandler(
+	orderRepo domain.Repository,
+	stockGRPC client.StockService,
+	paymentGRPC client.PaymentService,
+	interval time.Duration,
+	logger zerolog.Logger,
+	metricsClient decorator.MetricsClient,
+) RegeneratePaymentLinkHandler {
+	if orderRepo == nil {
+		panic("orderRepo is nil")
+	}
+
+	if stockGRPC == nil {
+		panic("stockGRPC is nil")
+	}
+
+	if paymentGRPC == nil {
+		panic("paymentGRPC is nil")
+	}
+
+	return decorator.ApplyCommandDecorators[RegeneratePaymentLink, *RegeneratePaymentLinkResult](
+		regeneratePaymentLinkHandler{
+			orderRepo:   orderRepo,
+			stockGRPC:   stockGRPC,
+			paymentGRPC: paymentGRPC,
+			interval:    interval,
+		},
+		logger,
+		metricsClient,
+	)
+}
+
+// Handle 由 payment 服务使旧的支付会话失效并创建新的支付会话，新的支付链接由 payment 服务通过 UpdateOrder 保存到订单。
+// 支付过期的订单已释放库存，需要先重新预扣库存并重新打开订单，生成支付链接失败时释放库存并恢复为支付过期。
+// 订单检查通过后才占用限流，生成失败时释放限流，客户可以立即重试
+func (h regeneratePaymentLinkHandler) Handle(ctx context.Context, cmd RegeneratePaymentLink) (*RegeneratePaymentLinkResult, error) {
+	var err error
+	defer logging.WhenCommandExecute(ctx, "RegeneratePaymentLinkHandler", cmd, err)
+
+	ctx, span := tracing.Start(ctx, "regeneratePaymentLinkHandler")
+	defer span.End()
+
+	order, err := h.orderRepo.Get(ctx, cmd.OrderID, cmd.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	if err = order.CanRegeneratePaymentLink(); err != nil {
+		return nil, err
+	}
+
+	key := getPaymentLinkRateLimitKey(cmd.OrderID)
+	ok, err := redis.SetIfAbsent(ctx, redis.LocalClient(), key, "1", h.interval)
+	if err != nil {
+		return nil, fmt.Errorf("redis rate limit, order_id=%s: %w", cmd.OrderID, err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("order %s: %w", cmd.OrderID, ErrPaymentLinkRateLimited)
+	}
+	defer func() {
+		if err == nil {
+			return
+		}
+		if delErr := redis.Del(ctx, redis.LocalClient(), key); delErr != nil {
+			log.Warn().Ctx(ctx).Err(delErr).Str("order_id", cmd.OrderID).Msg("release payment link rate limit failed")
+		}
+	}()
+
+	reopened := order.Status == consts.OrderStatusPaymentExpired
+	if reopened {
+		if order, err = h.reopen(ctx, order); err != nil {
+			return nil, err
+		}
+	}
+
+	link, err := h.paymentGRPC.RegeneratePaymentLink(ctx, convertor.NewOrderConvertor().EntityToProto(order.ToProto()))
+	if err != nil {
+		// 订单已经支付时保持重新打开的状态，等待 order.paid 更新订单
+		if status.Code(err) == codes.FailedPrecondition {
+			err = domain.PaymentLinkNotAllowedError{OrderID: order.ID, Status: consts.OrderStatusPaid}
+			return nil, err
+		}
+		if reopened {
+			h.undoReopen(ctx, order)
+		}
+		err = fmt.Errorf("regenerate payment link: %w", err)
+		return nil, err
+	}
+
+	log.Info().Ctx(ctx).
+		Str("order_id", order.ID).
+		Str("payment_link", link).
+		Msg("payment link regenerated")
+
+	return &RegeneratePaymentLinkResult{PaymentLink: link}, nil
+}
+
+// reopen 重新预扣订单商品的库存并重新打开订单，重新打开失败时释放刚预扣的库存
+func (h regeneratePaymentLinkHandler) reopen(ctx context.Context, order *domain.Order) (*domain.Order, error) {
+	items := make([]*entity.ItemWithQuantity, 0, len(order.Items))
+	for _, item := range order.Items {
+		items = append(items, &entity.ItemWithQuantity{ID: item.ID, Quantity: item.Quantity})
+	}
+
+	resp, err := h.stockGRPC.ReserveStock(ctx, convertor.NewItemWithQuantityConvertor().EntitiesToProtos(items))
+	if err != nil {
+		return nil, fmt.Errorf("reserve stock: %w", status.Convert(err).Err())
+	}
+	reserved := convertor.NewItemConvertor().ProtosToEntities(resp.Items)
+
+	if err = h.orderRepo.Reopen(ctx, order.ID, order.CustomerID, reserved); err != nil {
+		h.releaseStock(ctx, &domain.Order{ID: order.ID, Items: reserved})
+		return nil, fmt.Errorf("reopen order %s: %w", order.ID, err)
+	}
+
+	return h.orderRepo.Get(ctx, order.ID, order.CustomerID)
+}
+
+// undoReopen 生成支付链接失败时释放重新预扣的库存，并将订单恢复为支付过期，失败只记录日志
+func (h regeneratePaymentLinkHandler) undoReopen(ctx context.Context, order *domain.Order) {
+	h.releaseStock(ctx, order)
+
+	if err := h.orderRepo.Update(ctx, &domain.Order{
+		ID:         order.ID,
+		CustomerID: order.CustomerID,
+		Status:     consts.OrderStatusPaymentExpired,
+	}); err != nil {
+		log.Error().Ctx(ctx).Err(err).Str("order_id", order.ID).Msg("restore expired order after failed regenerate failed")
+	}
+}
+
+// releaseStock 释放订单占用的库存，失败只记录日志
+func (h regeneratePaymentLinkHandler) releaseStock(ctx context.Context, order *domain.Order) {
+	items, backorderIDs := order.ReservedStock()
+	if _, err := h.stockGRPC.ReleaseStockReservation(
+		ctx,
+		convertor.NewItemWithQuantityConvertor().EntitiesToProtos(items),
+		backorderIDs,
+	); err != nil {
+		log.Error().Ctx(ctx).Err(err).Str("order_id", order.ID).Msg("release reopened stock failed")
+	}
+}
+
+func getPaymentLinkRateLimitKey(orderID string) string {
+	return "regenerate_payment_link_" + orderID
+}
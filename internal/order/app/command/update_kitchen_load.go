@@ -0,0 +1,53 @@
+package command
+
+import (
+	"context"
+	"errors"
+
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/entity"
+	"github.com/furutachiKurea/gorder/common/logging"
+	"github.com/furutachiKurea/gorder/order/domain/intake"
+
+	"github.com/rs/zerolog"
+)
+
+// UpdateKitchenLoad 厨房发布了新的负载
+type UpdateKitchenLoad struct {
+	Load *entity.KitchenLoad
+}
+
+// UpdateKitchenLoadHandler 保存厨房最新的负载，CreateOrderHandler 据此决定是否接受新订单
+type UpdateKitchenLoadHandler decorator.CommandHandler[UpdateKitchenLoad, any]
+
+type updateKitchenLoadHandler struct {
+	loadRepo intake.Repository
+}
+
+func NewUpdateKitchenLoadHandler(
+	loadRepo intake.Repository,
+	logger zerolog.Logger,
+	metricsClient decorator.MetricsClient,
+) UpdateKitchenLoadHandler {
+	if loadRepo == nil {
+		panic("loadRepo is nil")
+	}
+
+	return decorator.ApplyCommandDecorators[UpdateKitchenLoad, any](
+		updateKitchenLoadHandler{loadRepo: loadRepo},
+		logger,
+		metricsClient,
+	)
+}
+
+func (h updateKitchenLoadHandler) Handle(ctx context.Context, cmd UpdateKitchenLoad) (any, error) {
+	var err error
+	defer logging.WhenCommandExecute(ctx, "UpdateKitchenLoadHandler", cmd, err)
+
+	if cmd.Load == nil {
+		return nil, errors.New("empty kitchen load")
+	}
+
+	err = h.loadRepo.Save(ctx, cmd.Load)
+	return nil, err
+}
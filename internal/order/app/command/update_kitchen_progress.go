@@ -0,0 +1,55 @@
+package command
+
+import (
+	"context"
+	"errors"
+
+	"github.com/furutachiKurea/gorder/common/consts"
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/entity"
+	"github.com/furutachiKurea/gorder/common/logging"
+	domain "github.com/furutachiKurea/gorder/order/domain/order"
+
+	"github.com/rs/zerolog"
+)
+
+// UpdateKitchenProgress 厨房的工单开始制作、完成制作或制作失败，Status 为对应的订单状态
+type UpdateKitchenProgress struct {
+	Event  *entity.TicketEvent
+	Status consts.OrderStatus
+}
+
+// UpdateKitchenProgressHandler 将订单推进到 Status，重复和乱序到达的事件被忽略
+type UpdateKitchenProgressHandler decorator.CommandHandler[UpdateKitchenProgress, any]
+
+type updateKitchenProgressHandler struct {
+	orderRepo domain.Repository
+}
+
+func NewUpdateKitchenProgressHandler(
+	orderRepo domain.Repository,
+	logger zerolog.Logger,
+	metricsClient decorator.MetricsClient,
+) UpdateKitchenProgressHandler {
+	if orderRepo == nil {
+		panic("orderRepo is nil")
+	}
+
+	return decorator.ApplyCommandDecorators[UpdateKitchenProgress, any](
+		updateKitchenProgressHandler{orderRepo: orderRepo},
+		logger,
+		metricsClient,
+	)
+}
+
+func (h updateKitchenProgressHandler) Handle(ctx context.Context, cmd UpdateKitchenProgress) (any, error) {
+	var err error
+	defer logging.WhenCommandExecute(ctx, "UpdateKitchenProgressHandler", cmd, err)
+
+	if cmd.Event == nil {
+		return nil, errors.New("empty ticket event")
+	}
+
+	err = h.orderRepo.UpdateKitchenProgress(ctx, cmd.Event.OrderID, cmd.Event.CustomerID, cmd.Status)
+	return nil, err
+}
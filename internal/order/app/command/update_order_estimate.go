@@ -0,0 +1,53 @@
+package command
+
+import (
+	"context"
+	"errors"
+
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/entity"
+	"github.com/furutachiKurea/gorder/common/logging"
+	domain "github.com/furutachiKurea/gorder/order/domain/order"
+
+	"github.com/rs/zerolog"
+)
+
+// UpdateOrderEstimate 厨房估计的订单完成时间发生变化
+type UpdateOrderEstimate struct {
+	ETA *entity.OrderETA
+}
+
+// UpdateOrderEstimateHandler 保存订单最新的预计完成时间，未支付或已完成的订单以及乱序到达的旧估计被忽略
+type UpdateOrderEstimateHandler decorator.CommandHandler[UpdateOrderEstimate, any]
+
+type updateOrderEstimateHandler struct {
+	orderRepo domain.Repository
+}
+
+func NewUpdateOrderEstimateHandler(
+	orderRepo domain.Repository,
+	logger zerolog.Logger,
+	metricsClient decorator.MetricsClient,
+) UpdateOrderEstimateHandler {
+	if orderRepo == nil {
+		panic("orderRepo is nil")
+	}
+
+	return decorator.ApplyCommandDecorators[UpdateOrderEstimate, any](
+		updateOrderEstimateHandler{orderRepo: orderRepo},
+		logger,
+		metricsClient,
+	)
+}
+
+func (h updateOrderEstimateHandler) Handle(ctx context.Context, cmd UpdateOrderEstimate) (any, error) {
+	var err error
+	defer logging.WhenCommandExecute(ctx, "UpdateOrderEstimateHandler", cmd, err)
+
+	if cmd.ETA == nil {
+		return nil, errors.New("empty eta")
+	}
+
+	err = h.orderRepo.UpdateEstimate(ctx, cmd.ETA.OrderID, cmd.ETA.CustomerID, cmd.ETA.EstimatedReadyAt, cmd.ETA.EstimatedAt)
+	return nil, err
+}
@@ -8,6 +8,20 @@ type CreateOrderResp struct {
 	RedirectURL string `json:"redirect_url"`
 }
 
+type RegeneratePaymentLinkResp struct {
+	CustomerID  string `json:"customer_id"`
+	OrderID     string `json:"order_id"`
+	PaymentLink string `json:"payment_link"`
+}
+
 type GetCustomerOrderResp struct {
 	Order *oapi.Order `json:"order"`
 }
+
+type GetProductsAvailabilityResp struct {
+	Products []oapi.ProductAvailability `json:"products"`
+}
+
+type CheckAvailabilityResp struct {
+	Result *oapi.AvailabilityCheckResult `json:"result"`
+}
@@ -0,0 +1,60 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/furutachiKurea/gorder/common/convertor"
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/entity"
+	"github.com/furutachiKurea/gorder/common/genproto/stockpb"
+	"github.com/furutachiKurea/gorder/common/tracing"
+	"github.com/furutachiKurea/gorder/order/app/client"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/status"
+)
+
+type CheckAvailability struct {
+	Items []*entity.ItemWithQuantity
+}
+
+// CheckAvailabilityHandler 在下单前校验购物车库存，不会预占库存
+type CheckAvailabilityHandler decorator.QueryHandler[CheckAvailability, *stockpb.CheckAvailabilityResponse]
+
+type checkAvailabilityHandler struct {
+	stockGRPC client.StockService
+}
+
+func NewCheckAvailabilityHandler(
+	stockGRPC client.StockService,
+	logger zerolog.Logger,
+	metricsClient decorator.MetricsClient,
+) CheckAvailabilityHandler {
+	if stockGRPC == nil {
+		panic("stockGRPC is nil")
+	}
+
+	return decorator.ApplyQueryDecorators[CheckAvailability, *stockpb.CheckAvailabilityResponse](
+		checkAvailabilityHandler{stockGRPC: stockGRPC},
+		logger,
+		metricsClient,
+	)
+}
+
+func (c checkAvailabilityHandler) Handle(ctx context.Context, query CheckAvailability) (*stockpb.CheckAvailabilityResponse, error) {
+	ctx, span := tracing.Start(ctx, "checkAvailabilityHandler")
+	defer span.End()
+
+	if len(query.Items) == 0 {
+		return nil, errors.New("must have at least one item")
+	}
+
+	resp, err := c.stockGRPC.CheckAvailability(ctx, convertor.NewItemWithQuantityConvertor().EntitiesToProtos(query.Items))
+	if err != nil {
+		return nil, fmt.Errorf("check availability: %w", status.Convert(err).Err())
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,131 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/genproto/stockpb"
+	"github.com/furutachiKurea/gorder/common/tracing"
+	"github.com/furutachiKurea/gorder/order/app/client"
+
+	"github.com/rs/zerolog"
+)
+
+type GetProductAvailability struct {
+	ProductIDs []string
+}
+
+// GetProductAvailabilityHandler 查询商品可售库存，结果会在进程内缓存 ttl 时长，
+// 用于前台展示库存紧张或售罄的商品
+type GetProductAvailabilityHandler decorator.QueryHandler[GetProductAvailability, []*stockpb.StockLevel]
+
+type getProductAvailabilityHandler struct {
+	stockGRPC client.StockService
+	cache     *stockLevelCache
+}
+
+func NewGetProductAvailabilityHandler(
+	stockGRPC client.StockService,
+	ttl time.Duration,
+	logger zerolog.Logger,
+	metricsClient decorator.MetricsClient,
+) GetProductAvailabilityHandler {
+	if stockGRPC == nil {
+		panic("stockGRPC is nil")
+	}
+
+	return decorator.ApplyQueryDecorators[GetProductAvailability, []*stockpb.StockLevel](
+		getProductAvailabilityHandler{
+			stockGRPC: stockGRPC,
+			cache:     newStockLevelCache(ttl),
+		},
+		logger,
+		metricsClient,
+	)
+}
+
+func (g getProductAvailabilityHandler) Handle(ctx context.Context, query GetProductAvailability) ([]*stockpb.StockLevel, error) {
+	ctx, span := tracing.Start(ctx, "getProductAvailabilityHandler")
+	defer span.End()
+
+	levels, missed := g.cache.get(query.ProductIDs)
+	if len(missed) == 0 {
+		span.AddEvent("product_availability_cache_hit")
+		return levels, nil
+	}
+
+	fetched, err := g.stockGRPC.GetStock(ctx, missed)
+	if err != nil {
+		return nil, fmt.Errorf("get stock: %w", err)
+	}
+	g.cache.set(missed, fetched)
+
+	return append(levels, fetched...), nil
+}
+
+// stockLevelCache 按商品缓存库存，查询不到的商品同样会被缓存，避免穿透到 stock 服务
+type stockLevelCache struct {
+	mu      sync.RWMutex
+	ttl     time.Duration
+	entries map[string]stockLevelEntry
+}
+
+type stockLevelEntry struct {
+	level    *stockpb.StockLevel // nil 表示商品不存在
+	expireAt time.Time
+}
+
+func newStockLevelCache(ttl time.Duration) *stockLevelCache {
+	return &stockLevelCache{
+		ttl:     ttl,
+		entries: make(map[string]stockLevelEntry),
+	}
+}
+
+// get 返回命中的库存以及未命中的商品 ID
+func (c *stockLevelCache) get(ids []string) (hit []*stockpb.StockLevel, missed []string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+	for _, id := range ids {
+		e, ok := c.entries[id]
+		if !ok || now.After(e.expireAt) {
+			missed = append(missed, id)
+			continue
+		}
+		if e.level != nil {
+			hit = append(hit, e.level)
+		}
+	}
+
+	return hit, missed
+}
+
+func (c *stockLevelCache) set(ids []string, levels []*stockpb.StockLevel) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expireAt := time.Now().Add(c.ttl)
+	for _, id := range ids {
+		c.entries[id] = stockLevelEntry{expireAt: expireAt}
+	}
+	for _, level := range levels {
+		c.entries[level.ProductId] = stockLevelEntry{level: level, expireAt: expireAt}
+	}
+
+	// 顺带清理过期的条目，防止 map 无限增长
+	now := time.Now()
+	for id, e := range c.entries {
+		if now.After(e.expireAt) {
+			delete(c.entries, id)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package intake
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/consts"
+	"github.com/furutachiKurea/gorder/common/entity"
+)
+
+// Policy 根据厨房发布的负载决定是否接受新订单：
+//   - 厨房暂停接单时拒绝新订单
+//   - 厨房积压的工单达到 MaxBacklog 时拒绝新订单，MaxBacklog 为 0 时不限制
+//   - 厨房繁忙时限流
+//
+// 超过 StaleAfter 未更新的负载视为未知，此时接受新订单，避免厨房服务不可用时无法下单
+type Policy struct {
+	MaxBacklog int
+	StaleAfter time.Duration
+}
+
+// Check 检查 now 时是否接受新订单，throttle 为 true 时新订单需要限流
+func (p Policy) Check(load *entity.KitchenLoad, now time.Time) (throttle bool, err error) {
+	if load == nil || (p.StaleAfter > 0 && now.Sub(load.PublishedAt) > p.StaleAfter) {
+		return false, nil
+	}
+
+	if load.Level == consts.KitchenPaused {
+		return false, KitchenPausedError{Reason: load.Reason}
+	}
+	if p.MaxBacklog > 0 && load.Backlog >= p.MaxBacklog {
+		return false, KitchenBusyError{Backlog: load.Backlog}
+	}
+
+	return load.Level == consts.KitchenBusy, nil
+}
+
+// Repository 保存厨房最近一次发布的负载
+type Repository interface {
+	// Get 获取最近一次发布的负载，未收到过时返回 nil
+	Get(ctx context.Context) (*entity.KitchenLoad, error)
+	// Save 保存负载，早于已保存负载的旧负载被忽略
+	Save(ctx context.Context, load *entity.KitchenLoad) error
+}
+
+// KitchenPausedError 厨房暂停接单
+type KitchenPausedError struct {
+	Reason string
+}
+
+func (e KitchenPausedError) Error() string {
+	if e.Reason == "" {
+		return "kitchen paused, not accepting orders"
+	}
+	return "kitchen paused, not accepting orders: " + e.Reason
+}
+
+// KitchenBusyError 厨房积压的工单过多或繁忙时被限流
+type KitchenBusyError struct {
+	Backlog int
+}
+
+func (e KitchenBusyError) Error() string {
+	return fmt.Sprintf("kitchen busy with %d tickets, try again later", e.Backlog)
+}
@@ -0,0 +1,68 @@
+package intake
+
+import (
+	"testing"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/consts"
+	"github.com/furutachiKurea/gorder/common/entity"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy_Check(t *testing.T) {
+	now := time.Now()
+	policy := Policy{MaxBacklog: 10, StaleAfter: time.Minute}
+
+	tests := []struct {
+		name     string
+		load     *entity.KitchenLoad
+		throttle bool
+		err      error
+	}{
+		{
+			name: "unknown",
+		},
+		{
+			name: "open",
+			load: &entity.KitchenLoad{Level: consts.KitchenOpen, Backlog: 1, PublishedAt: now},
+		},
+		{
+			name:     "busy",
+			load:     &entity.KitchenLoad{Level: consts.KitchenBusy, Backlog: 5, PublishedAt: now},
+			throttle: true,
+		},
+		{
+			name: "max_backlog",
+			load: &entity.KitchenLoad{Level: consts.KitchenBusy, Backlog: 10, PublishedAt: now},
+			err:  KitchenBusyError{Backlog: 10},
+		},
+		{
+			name: "paused",
+			load: &entity.KitchenLoad{Level: consts.KitchenPaused, Reason: "cleaning", PublishedAt: now},
+			err:  KitchenPausedError{Reason: "cleaning"},
+		},
+		{
+			// 厨房服务不可用时负载不再更新，接受新订单
+			name: "stale",
+			load: &entity.KitchenLoad{Level: consts.KitchenPaused, PublishedAt: now.Add(-2 * time.Minute)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			throttle, err := policy.Check(tt.load, now)
+			assert.Equal(t, tt.throttle, throttle)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestPolicy_Check_Unlimited(t *testing.T) {
+	now := time.Now()
+	load := &entity.KitchenLoad{Level: consts.KitchenOpen, Backlog: 1000, PublishedAt: now.Add(-time.Hour)}
+
+	throttle, err := Policy{}.Check(load, now)
+	assert.NoError(t, err)
+	assert.False(t, throttle)
+}
@@ -3,12 +3,13 @@ package order
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/furutachiKurea/gorder/common/consts"
 	"github.com/furutachiKurea/gorder/common/entity"
 
 	"github.com/rs/zerolog/log"
-	"github.com/stripe/stripe-go/v84"
 )
 
 type Order struct {
@@ -17,25 +18,37 @@ type Order struct {
 	Status      consts.OrderStatus
 	PaymentLink string
 	Items       []*entity.Item
+	// Express 加急订单，厨房优先制作
+	Express bool
+	// PickupAt 预约取餐时间，为 nil 时尽快制作
+	PickupAt *time.Time
+	// EstimatedReadyAt 厨房预计的完成时间，EstimatedAt 为厨房做出该估计的时间
+	EstimatedReadyAt *time.Time
+	EstimatedAt      *time.Time
 }
 
 func (o *Order) ToProto() *entity.Order {
 	items := make([]*entity.Item, len(o.Items))
 	for i, item := range o.Items {
 		items[i] = &entity.Item{
-			ID:       item.ID,
-			Name:     item.Name,
-			Quantity: item.Quantity,
-			PriceID:  item.PriceID,
+			ID:               item.ID,
+			Name:             item.Name,
+			Quantity:         item.Quantity,
+			PriceID:          item.PriceID,
+			FulfilmentStatus: item.FulfilmentStatus,
+			BackorderID:      item.BackorderID,
 		}
 	}
 
 	return &entity.Order{
-		ID:          o.ID,
-		CustomerID:  o.CustomerID,
-		Status:      o.Status,
-		PaymentLink: o.PaymentLink,
-		Items:       items,
+		ID:               o.ID,
+		CustomerID:       o.CustomerID,
+		Status:           o.Status,
+		PaymentLink:      o.PaymentLink,
+		Items:            items,
+		Express:          o.Express,
+		PickupAt:         o.PickupAt,
+		EstimatedReadyAt: o.EstimatedReadyAt,
 	}
 }
 
@@ -83,7 +96,32 @@ func NewPendingOrder(customerID string, items []*entity.Item) (*Order, error) {
 	}, nil
 }
 
-// UpdateTo 使用 order 的值更新 o, ID, CustomerID, Items 不可变
+// Schedule 设置订单是否加急以及预约取餐时间，预约时间必须晚于 now
+func (o *Order) Schedule(express bool, pickupAt *time.Time, now time.Time) error {
+	if pickupAt != nil && !pickupAt.After(now) {
+		return fmt.Errorf("pickup time %s must be in the future", pickupAt.Format(time.RFC3339))
+	}
+
+	o.Express = express
+	o.PickupAt = pickupAt
+	return nil
+}
+
+// UpdateEstimate 更新厨房预计的完成时间，只有已支付和制作中的订单接受估计，早于当前估计做出的估计被忽略，返回是否更新
+func (o *Order) UpdateEstimate(readyAt, estimatedAt time.Time) bool {
+	if o.Status != consts.OrderStatusPaid && o.Status != consts.OrderStatusPreparing {
+		return false
+	}
+	if o.EstimatedAt != nil && !estimatedAt.After(*o.EstimatedAt) {
+		return false
+	}
+
+	o.EstimatedReadyAt = &readyAt
+	o.EstimatedAt = &estimatedAt
+	return true
+}
+
+// UpdateTo 使用 order 的值更新 o, ID, CustomerID 以及 Items 的内容不可变，商品只会更新履约状态
 func (o *Order) UpdateTo(order *Order) (err error) {
 	if order.Status != "" {
 		err = o.UpdateStatusTo(order.Status)
@@ -92,6 +130,14 @@ func (o *Order) UpdateTo(order *Order) (err error) {
 		}
 	}
 
+	for _, item := range order.Items {
+		if item.FulfilmentStatus == consts.FulfilmentAllocated && item.BackorderID != "" {
+			if _, err = o.AllocateBackorder(item.BackorderID); err != nil {
+				return err
+			}
+		}
+	}
+
 	err = o.UpdatePaymentLink(order.PaymentLink)
 	if err != nil {
 		return err
@@ -109,8 +155,36 @@ func (o *Order) UpdateStatusTo(status consts.OrderStatus) error {
 	notAllowedTrans := map[consts.OrderStatus][]consts.OrderStatus{
 		consts.OrderStatusPending:           {},
 		consts.OrderStatusWaitingForPayment: {consts.OrderStatusPending},
-		consts.OrderStatusPaid:              {consts.OrderStatusPending, consts.OrderStatusWaitingForPayment},
-		consts.OrderStatusReady:             {consts.OrderStatusPending, consts.OrderStatusWaitingForPayment, consts.OrderStatusPaid},
+		consts.OrderStatusPaid: {
+			consts.OrderStatusPending, consts.OrderStatusWaitingForPayment,
+			consts.OrderStatusPaymentFailed, consts.OrderStatusPaymentExpired,
+		},
+		consts.OrderStatusPreparing: {
+			consts.OrderStatusPending, consts.OrderStatusWaitingForPayment, consts.OrderStatusPaid,
+			consts.OrderStatusPaymentFailed, consts.OrderStatusPaymentExpired,
+		},
+		consts.OrderStatusReady: {
+			consts.OrderStatusPending, consts.OrderStatusWaitingForPayment, consts.OrderStatusPaid,
+			consts.OrderStatusPreparing, consts.OrderStatusPaymentFailed, consts.OrderStatusPaymentExpired,
+			consts.OrderStatusKitchenFailed,
+		},
+		// 支付失败和过期的订单已释放库存，不能再流转到其他状态
+		consts.OrderStatusPaymentFailed: {
+			consts.OrderStatusPending, consts.OrderStatusWaitingForPayment, consts.OrderStatusPaid,
+			consts.OrderStatusPreparing, consts.OrderStatusReady, consts.OrderStatusPaymentExpired,
+			consts.OrderStatusKitchenFailed,
+		},
+		consts.OrderStatusPaymentExpired: {
+			consts.OrderStatusPending, consts.OrderStatusWaitingForPayment, consts.OrderStatusPaid,
+			consts.OrderStatusPreparing, consts.OrderStatusReady, consts.OrderStatusPaymentFailed,
+			consts.OrderStatusKitchenFailed,
+		},
+		// 厨房无法完成制作的订单不能再流转到其他状态
+		consts.OrderStatusKitchenFailed: {
+			consts.OrderStatusPending, consts.OrderStatusWaitingForPayment, consts.OrderStatusPaid,
+			consts.OrderStatusPreparing, consts.OrderStatusReady, consts.OrderStatusPaymentFailed,
+			consts.OrderStatusPaymentExpired,
+		},
 	}
 
 	invalidStatus, ok := notAllowedTrans[o.Status]
@@ -136,6 +210,106 @@ func (o *Order) UpdateStatusTo(status consts.OrderStatus) error {
 	return nil
 }
 
+// ApplyKitchenProgress 根据厨房的工单事件推进订单状态，status 为 preparing、ready 或 kitchen_failed，返回是否更新。
+// 工单事件可能重复或乱序到达，订单已经处于相同或之后的状态时忽略事件
+func (o *Order) ApplyKitchenProgress(status consts.OrderStatus) (bool, error) {
+	var from, skip []consts.OrderStatus
+	switch status {
+	case consts.OrderStatusPreparing:
+		from = []consts.OrderStatus{consts.OrderStatusPaid}
+		skip = []consts.OrderStatus{consts.OrderStatusPreparing, consts.OrderStatusReady, consts.OrderStatusKitchenFailed}
+	case consts.OrderStatusReady, consts.OrderStatusKitchenFailed:
+		from = []consts.OrderStatus{consts.OrderStatusPaid, consts.OrderStatusPreparing}
+		skip = []consts.OrderStatus{status}
+	default:
+		return false, fmt.Errorf("kitchen progress %s not supported, order_id=%s", status, o.ID)
+	}
+
+	if slices.Contains(skip, o.Status) {
+		return false, nil
+	}
+	if !slices.Contains(from, o.Status) {
+		return false, fmt.Errorf("update order status to %s from %s not allowed, order_id=%s", status, o.Status, o.ID)
+	}
+
+	o.Status = status
+	return true, nil
+}
+
+// AllocateBackorder 将缺货预订或预售的商品标记为已分配库存，返回被分配的商品。
+// 商品已经分配过时返回 nil，用于忽略重复的分配事件
+func (o *Order) AllocateBackorder(backorderID string) (*entity.Item, error) {
+	for _, item := range o.Items {
+		if item.BackorderID != backorderID {
+			continue
+		}
+
+		switch item.FulfilmentStatus {
+		case consts.FulfilmentAllocated:
+			return nil, nil
+		case consts.FulfilmentBackordered, consts.FulfilmentPreordered:
+			item.FulfilmentStatus = consts.FulfilmentAllocated
+			return item, nil
+		default:
+			return nil, fmt.Errorf("item %s of order %s is not backordered, status=%s", item.ID, o.ID, item.FulfilmentStatus)
+		}
+	}
+
+	return nil, fmt.Errorf("backorder %s not found in order %s", backorderID, o.ID)
+}
+
+// IsPaymentClosed 订单是否因支付失败或过期而关闭
+func (o *Order) IsPaymentClosed() bool {
+	return o.Status == consts.OrderStatusPaymentFailed || o.Status == consts.OrderStatusPaymentExpired
+}
+
+// IsPaymentCompleted 订单是否已经完成支付，包括已经交给厨房处理的订单
+func (o *Order) IsPaymentCompleted() bool {
+	return o.Status.IsPaid()
+}
+
+// CanRegeneratePaymentLink 检查订单是否可以重新生成支付链接：等待支付和支付过期的订单可以，
+// 已支付、已完成以及支付失败被取消的订单不可以，尚未生成支付链接的订单也不可以
+func (o *Order) CanRegeneratePaymentLink() error {
+	if o.Status == consts.OrderStatusWaitingForPayment || o.Status == consts.OrderStatusPaymentExpired {
+		return nil
+	}
+
+	return PaymentLinkNotAllowedError{OrderID: o.ID, Status: o.Status}
+}
+
+// Reopen 重新打开支付过期的订单，items 为重新预扣库存后的商品，订单回到等待支付并清除失效的支付链接
+func (o *Order) Reopen(items []*entity.Item) error {
+	if o.Status != consts.OrderStatusPaymentExpired {
+		return fmt.Errorf("reopen order with status %s not allowed, order_id=%s", o.Status, o.ID)
+	}
+
+	if len(items) == 0 {
+		return errors.New("items cannot be nil or empty")
+	}
+
+	o.Status = consts.OrderStatusWaitingForPayment
+	o.PaymentLink = ""
+	o.Items = items
+	return nil
+}
+
+// ReservedStock 返回订单占用的库存：缺货预订和预售的商品返回预订 ID，由库存服务根据预订是否已分配决定释放的库存，
+// 其余已占用库存的商品返回商品和数量
+func (o *Order) ReservedStock() (items []*entity.ItemWithQuantity, backorderIDs []string) {
+	for _, item := range o.Items {
+		if item.BackorderID != "" {
+			backorderIDs = append(backorderIDs, item.BackorderID)
+			continue
+		}
+		if item.FulfilmentStatus.IsReserved() {
+			items = append(items, &entity.ItemWithQuantity{ID: item.ID, Quantity: item.Quantity})
+		}
+	}
+
+	return items, backorderIDs
+}
+
 // UpdatePaymentLink 更新订单的支付链接，
 func (o *Order) UpdatePaymentLink(paymentLink string) error {
 	// 由于 domain.Repository 现在的设计会将传入的 updates 全盘更新给 order，
@@ -151,7 +325,7 @@ func (o *Order) UpdatePaymentLink(paymentLink string) error {
 }
 
 func (o *Order) IsPaid() error {
-	if o.Status == consts.OrderStatus(stripe.CheckoutSessionPaymentStatusPaid) {
+	if o.Status == consts.OrderStatusPaid {
 		return nil
 	}
 
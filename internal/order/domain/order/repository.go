@@ -1,12 +1,29 @@
 package order
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/consts"
+	"github.com/furutachiKurea/gorder/common/entity"
+)
 
 type Repository interface {
 	Create(context.Context, *Order) (*Order, error)
 	Get(ctx context.Context, orderID, customerID string) (*Order, error)
+	// GetByBackorderID 获取包含指定缺货预订商品的订单
+	GetByBackorderID(ctx context.Context, backorderID string) (*Order, error)
+	// AllocateBackorder 只将订单中对应缺货预订的商品标记为已分配，返回更新后的订单和被分配的商品，
+	// 商品已经分配过时返回的商品为 nil
+	AllocateBackorder(ctx context.Context, backorderID string) (*Order, *entity.Item, error)
 	// Update 更新订单
 	Update(ctx context.Context, updates *Order) error
+	// Reopen 使用重新预扣库存后的商品重新打开支付过期的订单
+	Reopen(ctx context.Context, orderID, customerID string, items []*entity.Item) error
+	// UpdateEstimate 更新厨房预计的完成时间
+	UpdateEstimate(ctx context.Context, orderID, customerID string, readyAt, estimatedAt time.Time) error
+	// UpdateKitchenProgress 根据厨房的工单事件推进订单状态
+	UpdateKitchenProgress(ctx context.Context, orderID, customerID string, status consts.OrderStatus) error
 }
 
 type NotFoundError struct {
@@ -16,3 +33,21 @@ type NotFoundError struct {
 func (e NotFoundError) Error() string {
 	return "order " + e.OrderID + " not found"
 }
+
+type BackorderNotFoundError struct {
+	BackorderID string
+}
+
+func (e BackorderNotFoundError) Error() string {
+	return "order with backorder " + e.BackorderID + " not found"
+}
+
+// PaymentLinkNotAllowedError 订单的状态不允许重新生成支付链接
+type PaymentLinkNotAllowedError struct {
+	OrderID string
+	Status  consts.OrderStatus
+}
+
+func (e PaymentLinkNotAllowedError) Error() string {
+	return "order " + e.OrderID + " with status " + string(e.Status) + " cannot regenerate payment link"
+}
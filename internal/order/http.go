@@ -1,7 +1,10 @@
 package main
 
 import (
+	stderrors "errors"
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/furutachiKurea/gorder/common"
 	oapi "github.com/furutachiKurea/gorder/common/client/order"
@@ -12,6 +15,9 @@ import (
 	"github.com/furutachiKurea/gorder/order/app/command"
 	"github.com/furutachiKurea/gorder/order/app/dto"
 	"github.com/furutachiKurea/gorder/order/app/query"
+	"github.com/furutachiKurea/gorder/order/domain/intake"
+	domain "github.com/furutachiKurea/gorder/order/domain/order"
+	"github.com/furutachiKurea/gorder/order/ports"
 
 	"github.com/gin-gonic/gin"
 )
@@ -19,6 +25,8 @@ import (
 type HTTPServer struct {
 	common.BaseResponse
 	app app.Application
+	// availabilityTTL 库存查询结果允许浏览器与 CDN 复用的时长，与服务端缓存一致
+	availabilityTTL time.Duration
 }
 
 func (H HTTPServer) PostCustomerCustomerIdOrders(c *gin.Context, customerID string) {
@@ -40,12 +48,29 @@ func (H HTTPServer) PostCustomerCustomerIdOrders(c *gin.Context, customerID stri
 		err = errors.NewWithError(consts.ErrnoRequestValidateError, err)
 		return
 	}
-	result, err := H.app.Commands.CreateOrder.Handle(c.Request.Context(), command.CreateOrder{
+	cmd := command.CreateOrder{
 		CustomerID: customerID,
 		Items:      convertor.NewItemWithQuantityConvertor().OAPIsToEntities(req.Items),
-	})
+		Express:    req.Express != nil && *req.Express,
+	}
+	if req.PickupAt != nil {
+		pickupAt := time.Unix(*req.PickupAt, 0)
+		cmd.PickupAt = &pickupAt
+	}
+	result, err := H.app.Commands.CreateOrder.Handle(c.Request.Context(), cmd)
 	if err != nil {
-		err = errors.NewWithError(consts.ErrnoInternalError, err)
+		var (
+			paused intake.KitchenPausedError
+			busy   intake.KitchenBusyError
+		)
+		switch {
+		case stderrors.As(err, &paused):
+			err = errors.NewWithError(consts.ErrnoKitchenPaused, err)
+		case stderrors.As(err, &busy):
+			err = errors.NewWithError(consts.ErrnoKitchenBusy, err)
+		default:
+			err = errors.NewWithError(consts.ErrnoInternalError, err)
+		}
 		return
 	}
 	resp = dto.CreateOrderResp{
@@ -76,18 +101,134 @@ func (H HTTPServer) GetCustomerCustomerIdOrdersOrderId(c *gin.Context, customerI
 	}
 
 	resp = dto.GetCustomerOrderResp{
-		Order: &oapi.Order{
-			CustomerId:  order.CustomerID,
-			Id:          order.ID,
-			Items:       convertor.NewItemConvertor().EntitiesToOAPIs(order.Items),
-			PaymentLink: order.PaymentLink,
-			Status:      string(order.Status),
-		},
+		Order: convertor.NewOrderConvertor().EntityToOAPI(order.ToProto()),
+	}
+}
+
+func (H HTTPServer) PostCustomerCustomerIdOrdersOrderIdPaymentLink(c *gin.Context, customerID string, orderID string) {
+	var (
+		resp dto.RegeneratePaymentLinkResp
+		err  error
+	)
+
+	defer func() {
+		H.Response(c, err, resp)
+	}()
+
+	result, err := H.app.Commands.RegeneratePaymentLink.Handle(c.Request.Context(), command.RegeneratePaymentLink{
+		CustomerID: customerID,
+		OrderID:    orderID,
+	})
+	if err != nil {
+		var notAllowed domain.PaymentLinkNotAllowedError
+		switch {
+		case stderrors.Is(err, command.ErrPaymentLinkRateLimited):
+			err = errors.NewWithError(consts.ErrnoTooManyRequests, err)
+		case stderrors.As(err, &notAllowed):
+			err = errors.NewWithError(consts.ErrnoOrderStatusNotAllowed, err)
+		default:
+			err = errors.NewWithError(consts.ErrnoInternalError, err)
+		}
+		return
+	}
+
+	resp = dto.RegeneratePaymentLinkResp{
+		CustomerID:  customerID,
+		OrderID:     orderID,
+		PaymentLink: result.PaymentLink,
+	}
+}
+
+func (H HTTPServer) GetProductsAvailability(c *gin.Context, params ports.GetProductsAvailabilityParams) {
+	var (
+		resp dto.GetProductsAvailabilityResp
+		err  error
+	)
+
+	defer func() {
+		H.Response(c, err, resp)
+	}()
+
+	if len(params.ProductIds) == 0 {
+		err = errors.NewWithError(consts.ErrnoRequestValidateError, fmt.Errorf("product_ids cannot be empty"))
+		return
+	}
+
+	levels, err := H.app.Queries.GetProductAvailability.Handle(c.Request.Context(), query.GetProductAvailability{
+		ProductIDs: params.ProductIds,
+	})
+	if err != nil {
+		err = errors.NewWithError(consts.ErrnoInternalError, err)
+		return
+	}
+
+	// 库存数据允许短暂的不一致，让浏览器与 CDN 在同样的时长内复用结果
+	c.Header("Cache-Control", "public, max-age="+strconv.Itoa(int(H.availabilityTTL.Seconds())))
+
+	resp.Products = make([]oapi.ProductAvailability, 0, len(levels))
+	for _, level := range levels {
+		resp.Products = append(resp.Products, oapi.ProductAvailability{
+			ProductId: level.ProductId,
+			Available: level.Available,
+			InStock:   level.Available > 0,
+		})
+	}
+}
+
+func (H HTTPServer) PostProductsAvailabilityCheck(c *gin.Context) {
+	var (
+		req  oapi.CheckAvailabilityRequest
+		resp dto.CheckAvailabilityResp
+		err  error
+	)
+
+	defer func() {
+		H.Response(c, err, resp)
+	}()
+
+	if err = c.ShouldBind(&req); err != nil {
+		err = errors.NewWithError(consts.ErrnoBindRequestError, err)
+		return
+	}
+	if err = validateItems(req.Items); err != nil {
+		err = errors.NewWithError(consts.ErrnoRequestValidateError, err)
+		return
+	}
+
+	result, err := H.app.Queries.CheckAvailability.Handle(c.Request.Context(), query.CheckAvailability{
+		Items: convertor.NewItemWithQuantityConvertor().OAPIsToEntities(req.Items),
+	})
+	if err != nil {
+		err = errors.NewWithError(consts.ErrnoInternalError, err)
+		return
+	}
+
+	resp.Result = &oapi.AvailabilityCheckResult{
+		Available: result.Available,
+		Shortages: make([]oapi.Shortage, 0, len(result.Shortages)),
+	}
+	for _, s := range result.Shortages {
+		resp.Result.Shortages = append(resp.Result.Shortages, oapi.Shortage{
+			ProductId: s.ProductId,
+			Want:      s.Want,
+			Have:      s.Have,
+		})
 	}
 }
 
 func (H HTTPServer) validateCreateOrderRequest(req oapi.CreateOrderRequest) error {
-	for _, i := range req.Items {
+	if err := validateItems(req.Items); err != nil {
+		return err
+	}
+	if req.PickupAt != nil && *req.PickupAt <= time.Now().Unix() {
+		return fmt.Errorf("pickup_at must be in the future, got %d", *req.PickupAt)
+	}
+
+	return nil
+}
+
+func validateItems(items []oapi.ItemWithQuantity) error {
+	for _, i := range items {
 		if i.Quantity <= 0 {
 			return fmt.Errorf("quantity must be positive, got %d from %s", i.Quantity, i.Id)
 		}
@@ -4,90 +4,230 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/furutachiKurea/gorder/common/broker"
+	"github.com/furutachiKurea/gorder/common/consts"
+	"github.com/furutachiKurea/gorder/common/entity"
 	"github.com/furutachiKurea/gorder/common/tracing"
 	"github.com/furutachiKurea/gorder/order/app"
 	"github.com/furutachiKurea/gorder/order/app/command"
 	domain "github.com/furutachiKurea/gorder/order/domain/order"
-	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/rs/zerolog/log"
 )
 
 type Consumer struct {
 	app app.Application
+	// publisher 用于重新发布处理失败的消息
+	publisher broker.Publisher
+	// retry 处理失败的消息的重试策略
+	retry broker.RetryPolicy
 }
 
-func NewConsumer(app app.Application) *Consumer {
+func NewConsumer(app app.Application, publisher broker.Publisher, retry broker.RetryPolicy) *Consumer {
 	return &Consumer{
-		app: app,
+		app:       app,
+		publisher: publisher,
+		retry:     retry,
 	}
 }
 
-func (c *Consumer) Listen(ch *amqp.Channel) {
-	q, err := ch.QueueDeclare(broker.EventOrderPaid, true, false, true, false, nil)
-	if err != nil {
-		log.Fatal().Err(err).Msg("")
-	}
-
-	if err = ch.QueueBind(q.Name, "", broker.EventOrderPaid, false, nil); err != nil {
-		log.Fatal().Err(err).Msg("")
-	}
-
-	msgs, err := ch.Consume(q.Name, "", false, false, false, false, nil)
-	if err != nil {
-		log.Warn().Err(err).Str("queue", q.Name).Msg("failed to consume queue")
-	}
+func (c *Consumer) Listen(sub broker.Subscriber) {
+	paidMsgs := subscribe(sub, broker.EventOrderPaid)
+	allocatedMsgs := subscribe(sub, broker.EventStockBackorderAllocated)
+	failedMsgs := subscribe(sub, broker.EventOrderPaymentFailed)
+	expiredMsgs := subscribe(sub, broker.EventOrderPaymentExpired)
+	etaMsgs := subscribe(sub, broker.EventKitchenETAUpdated)
+	loadMsgs := subscribeBroadcast(sub, broker.EventKitchenLoadUpdated)
+	startedMsgs := subscribe(sub, broker.EventKitchenTicketStarted)
+	readyMsgs := subscribe(sub, broker.EventKitchenTicketReady)
+	ticketFailedMsgs := subscribe(sub, broker.EventKitchenTicketFailed)
 
 	var forever chan struct{}
 	go func() {
-		for msg := range msgs {
-			c.handleMessage(ch, msg, q)
+		for msg := range paidMsgs {
+			c.handleMessage(msg)
+		}
+	}()
+	go func() {
+		for msg := range allocatedMsgs {
+			c.handleBackorderAllocated(msg)
+		}
+	}()
+	go func() {
+		for msg := range failedMsgs {
+			c.handlePaymentClosed(msg)
+		}
+	}()
+	go func() {
+		for msg := range expiredMsgs {
+			c.handlePaymentClosed(msg)
+		}
+	}()
+	go func() {
+		for msg := range etaMsgs {
+			c.handleETAUpdated(msg)
+		}
+	}()
+	go func() {
+		for msg := range loadMsgs {
+			c.handleLoadUpdated(msg)
+		}
+	}()
+	go func() {
+		for msg := range startedMsgs {
+			c.handleTicketEvent(msg, consts.OrderStatusPreparing)
+		}
+	}()
+	go func() {
+		for msg := range readyMsgs {
+			c.handleTicketEvent(msg, consts.OrderStatusReady)
+		}
+	}()
+	go func() {
+		for msg := range ticketFailedMsgs {
+			c.handleTicketEvent(msg, consts.OrderStatusKitchenFailed)
 		}
 	}()
 
 	<-forever
 }
 
+// subscribe 订阅 fanout exchange 对应的持久化队列，多个实例竞争消费，所有实例停止期间的消息保留在队列中。
+// 队列命名为 order.<exchange>，如 order.order_paid，与以前和 exchange 同名的自动删除队列区分，避免声明参数不一致导致订阅失败，
+// 旧队列在旧版本的实例全部停止后自动删除
+func subscribe(sub broker.Subscriber, exchange string) <-chan *broker.Delivery {
+	queue := "order." + strings.ReplaceAll(exchange, ".", "_")
+	msgs, err := sub.Subscribe(context.Background(), broker.Subscription{
+		Queue:    queue,
+		Exchange: exchange,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Str("queue", queue).Str("exchange", exchange).Msg("failed to subscribe")
+	}
+	return msgs
+}
+
+// subscribeBroadcast 为每个实例订阅独占的临时队列，每个实例都会收到 fanout exchange 的所有消息
+func subscribeBroadcast(sub broker.Subscriber, exchange string) <-chan *broker.Delivery {
+	msgs, err := sub.Subscribe(context.Background(), broker.Subscription{Exchange: exchange})
+	if err != nil {
+		log.Fatal().Err(err).Str("exchange", exchange).Msg("failed to subscribe")
+	}
+	return msgs
+}
+
 // handleMessage 处理接收到的订单支付消息，更新订单状态并更新库存
-func (c *Consumer) handleMessage(ch *amqp.Channel, msg amqp.Delivery, q amqp.Queue) {
-	log.Info().
-		Str("msg", string(msg.Body)).
-		Msgf("order received message from %s", q.Name)
+func (c *Consumer) handleMessage(msg *broker.Delivery) {
+	consume(c, msg, consumeOptions{event: "order.paid_confirmed"}, func(ctx context.Context, o *domain.Order) error {
+		log.Debug().Any("unmarshalled_order", o).Msg("unmarshalled order from message")
+		if _, err := c.app.Commands.ConfirmOrderPaid.Handle(ctx, command.ConfirmOrderPaid{Order: o}); err != nil {
+			return fmt.Errorf("confirm order paid: %w", err)
+		}
+		return nil
+	})
+}
+
+// handleBackorderAllocated 处理补货后缺货预订被分配的消息，更新订单商品的履约状态
+func (c *Consumer) handleBackorderAllocated(msg *broker.Delivery) {
+	consume(c, msg, consumeOptions{event: "order.backorder_allocated"}, func(ctx context.Context, allocation *entity.BackorderAllocation) error {
+		if _, err := c.app.Commands.AllocateBackorder.Handle(ctx, command.AllocateBackorder{Allocation: allocation}); err != nil {
+			return fmt.Errorf("allocate backorder: %w", err)
+		}
+		return nil
+	})
+}
+
+// handlePaymentClosed 处理支付失败或过期的消息，关闭订单并释放订单占用的库存
+func (c *Consumer) handlePaymentClosed(msg *broker.Delivery) {
+	consume(c, msg, consumeOptions{event: "order.payment_closed"}, func(ctx context.Context, o *domain.Order) error {
+		if _, err := c.app.Commands.CloseOrderPayment.Handle(ctx, command.CloseOrderPayment{Order: o}); err != nil {
+			return fmt.Errorf("close order payment: %w", err)
+		}
+		return nil
+	})
+}
+
+// handleETAUpdated 处理厨房预计完成时间变化的消息，保存订单最新的预计完成时间
+func (c *Consumer) handleETAUpdated(msg *broker.Delivery) {
+	consume(c, msg, consumeOptions{event: "order.eta_updated"}, func(ctx context.Context, eta *entity.OrderETA) error {
+		if _, err := c.app.Commands.UpdateOrderEstimate.Handle(ctx, command.UpdateOrderEstimate{ETA: eta}); err != nil {
+			return fmt.Errorf("update order estimate: %w", err)
+		}
+		return nil
+	})
+}
+
+// handleTicketEvent 处理厨房的工单事件，将订单推进到 status
+func (c *Consumer) handleTicketEvent(msg *broker.Delivery, status consts.OrderStatus) {
+	consume(c, msg, consumeOptions{event: fmt.Sprintf("order.%s", status)}, func(ctx context.Context, event *entity.TicketEvent) error {
+		if _, err := c.app.Commands.UpdateKitchenProgress.Handle(ctx, command.UpdateKitchenProgress{Event: event, Status: status}); err != nil {
+			return fmt.Errorf("update kitchen progress to %s: %w", status, err)
+		}
+		return nil
+	})
+}
+
+// handleLoadUpdated 处理厨房发布的负载，负载会定期重新发布，处理失败时不重试
+func (c *Consumer) handleLoadUpdated(msg *broker.Delivery) {
+	consume(c, msg, consumeOptions{event: "order.kitchen_load_updated", periodic: true}, func(ctx context.Context, load *entity.KitchenLoad) error {
+		if _, err := c.app.Commands.UpdateKitchenLoad.Handle(ctx, command.UpdateKitchenLoad{Load: load}); err != nil {
+			return fmt.Errorf("update kitchen load: %w", err)
+		}
+		return nil
+	})
+}
+
+// consumeOptions 消息的处理方式
+type consumeOptions struct {
+	// event 处理成功时记录在 span 上的事件
+	event string
+	// periodic 消息会定期重新发布，处理失败时不重试，也不记录收到和处理成功的日志，避免日志被定期消息刷屏
+	periodic bool
+}
+
+// consume 将消息体解析为 T 后交给 handle 处理并确认消息，解析失败的消息直接丢弃，handle 失败时按重试策略重新投递
+func consume[T any](c *Consumer, msg *broker.Delivery, opts consumeOptions, handle func(ctx context.Context, body *T) error) {
+	if !opts.periodic {
+		log.Info().
+			Str("msg", string(msg.Body)).
+			Msgf("order received message from %s", msg.Queue)
+	}
 
 	ctx := broker.ExtractRabbitMQHeaders(context.Background(), msg.Headers)
-	ctx, span := tracing.Start(ctx, fmt.Sprintf("rabbitmq.%s.consume", q.Name))
+	ctx, span := tracing.Start(ctx, fmt.Sprintf("rabbitmq.%s.consume", msg.Queue))
 	defer span.End()
 
 	var err error
 	defer func() {
+		_ = broker.Settle(msg, err)
 		if err != nil {
-			_ = msg.Nack(false, false)
 			log.Warn().Ctx(ctx).
 				Err(err).
-				Str("from", q.Name).
+				Str("from", msg.Queue).
 				Str("msg", string(msg.Body)).
 				Msg("consume failed")
-		} else {
-			_ = msg.Ack(false)
-			span.AddEvent("order.paid_confirmed")
+			return
+		}
+
+		span.AddEvent(opts.event)
+		if !opts.periodic {
 			log.Info().Ctx(ctx).Msg("consume success")
 		}
 	}()
 
-	o := &domain.Order{}
-	if err = json.Unmarshal(msg.Body, o); err != nil {
+	body := new(T)
+	if err = json.Unmarshal(msg.Body, body); err != nil {
 		err = fmt.Errorf("unmarshal msg to body: %w", err)
 		return
 	}
 
-	log.Debug().Any("unmarshalled_order", o).Msg("unmarshalled order from message")
-	_, err = c.app.Commands.ConfirmOrderPaid.Handle(ctx, command.ConfirmOrderPaid{Order: o})
-	if err != nil {
-		err = fmt.Errorf("confirm order paid: %w", err)
-		if err = broker.HandlerRetry(ctx, ch, &msg); err != nil {
-			err = fmt.Errorf("handle retry, messageId=%s: %w", msg.MessageId, err)
-		}
+	if err = handle(ctx, body); err == nil || opts.periodic {
 		return
 	}
+
+	log.Warn().Ctx(ctx).Err(err).Str("from", msg.Queue).Msg("handle message failed, retry later")
+	if err = broker.HandlerRetry(ctx, c.publisher, msg, c.retry); err != nil {
+		err = fmt.Errorf("handle retry, messageId=%s: %w", msg.MessageID, err)
+	}
 }
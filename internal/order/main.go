@@ -10,6 +10,7 @@ import (
 	"github.com/furutachiKurea/gorder/common/discovery"
 	"github.com/furutachiKurea/gorder/common/genproto/orderpb"
 	"github.com/furutachiKurea/gorder/common/logging"
+	"github.com/furutachiKurea/gorder/common/metrics"
 	"github.com/furutachiKurea/gorder/common/server"
 	"github.com/furutachiKurea/gorder/common/tracing"
 	"github.com/furutachiKurea/gorder/order/infrastructure/consumer"
@@ -40,27 +41,36 @@ func main() {
 		_ = shutdown(ctx)
 	}()
 
-	app, cleanup := service.NewApplication(ctx)
-	defer cleanup()
-
-	deregisterFn, err := discovery.RegisterToConsul(ctx, serviceName)
-	if err != nil {
-		log.Fatal().Err(err).Msgf("failed to register service %s to consul", serviceName)
-	}
-	defer func() { _ = deregisterFn() }()
-
-	ch, closeCoon := broker.Connect(
+	metricsClient := metrics.NewPrometheusMetricsClient(
+		&metrics.PrometheusMetricsClientConfig{
+			Host:        viper.GetString("order.metrics-export-addr"),
+			ServiceName: serviceName,
+		})
+	mq, closeMQ := broker.Connect(
 		viper.GetString("rabbitmq.user"),
 		viper.GetString("rabbitmq.password"),
 		viper.GetString("rabbitmq.host"),
 		viper.GetString("rabbitmq.port"),
+		metricsClient,
 	)
 	defer func() {
-		_ = ch.Close()
-		_ = closeCoon()
+		_ = closeMQ()
 	}()
 
-	go consumer.NewConsumer(app).Listen(ch)
+	app, cleanup := service.NewApplication(ctx, mq, metricsClient)
+	defer cleanup()
+
+	deregisterFn, err := discovery.RegisterToConsul(ctx, serviceName, mq.Health)
+	if err != nil {
+		log.Fatal().Err(err).Msgf("failed to register service %s to consul", serviceName)
+	}
+	defer func() { _ = deregisterFn() }()
+
+	retry, err := broker.RetryPolicyFromConfig("order.retry")
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to parse order retry policy")
+	}
+	go consumer.NewConsumer(app, mq, retry).Listen(mq)
 
 	go server.RunGRPCServer(serviceName, func(server *grpc.Server) {
 		svc := ports.NewGRPCServer(app)
@@ -68,9 +78,11 @@ func main() {
 	})
 
 	go server.RunHTTPServer(serviceName, func(router *gin.Engine) {
+		server.RegisterHealthRoutes(router, map[string]server.HealthCheck{"rabbitmq": mq.Health})
 		router.StaticFile("/success", "../../public/success.html")
 		ports.RegisterHandlersWithOptions(router, HTTPServer{
-			app: app,
+			app:             app,
+			availabilityTTL: viper.GetDuration("order.availability-cache-ttl"),
 		}, ports.GinServerOptions{
 			BaseURL:      "/api",
 			Middlewares:  nil,
@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"errors"
 
 	"github.com/furutachiKurea/gorder/common/consts"
 	"github.com/furutachiKurea/gorder/common/convertor"
@@ -9,6 +10,7 @@ import (
 	"github.com/furutachiKurea/gorder/order/app"
 	"github.com/furutachiKurea/gorder/order/app/command"
 	"github.com/furutachiKurea/gorder/order/app/query"
+	"github.com/furutachiKurea/gorder/order/domain/intake"
 	domain "github.com/furutachiKurea/gorder/order/domain/order"
 	"github.com/rs/zerolog/log"
 	"google.golang.org/grpc/codes"
@@ -30,7 +32,18 @@ func (G GRPCServer) CreateOrder(ctx context.Context, request *orderpb.CreateOrde
 		Items:      convertor.NewItemWithQuantityConvertor().ProtosToEntities(request.Items),
 	})
 	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+		var (
+			paused intake.KitchenPausedError
+			busy   intake.KitchenBusyError
+		)
+		switch {
+		case errors.As(err, &paused):
+			return nil, status.Error(codes.Unavailable, err.Error())
+		case errors.As(err, &busy):
+			return nil, status.Error(codes.ResourceExhausted, err.Error())
+		default:
+			return nil, status.Error(codes.Internal, err.Error())
+		}
 	}
 
 	return &emptypb.Empty{}, nil
@@ -43,16 +56,10 @@ func (G GRPCServer) GetOrder(ctx context.Context, request *orderpb.GetOrderReque
 	})
 
 	if err != nil {
-		return nil, status.Errorf(codes.NotFound, err.Error())
+		return nil, status.Error(codes.NotFound, err.Error())
 	}
 
-	return &orderpb.Order{
-		Id:          order.ID,
-		CustomerId:  order.CustomerID,
-		Status:      string(order.Status),
-		PaymentLink: order.PaymentLink,
-		Items:       convertor.NewItemConvertor().EntitiesToProtos(order.Items),
-	}, nil
+	return convertor.NewOrderConvertor().EntityToProto(order.ToProto()), nil
 }
 
 func (G GRPCServer) UpdateOrder(ctx context.Context, request *orderpb.Order) (*emptypb.Empty, error) {
@@ -19,6 +19,15 @@ type ServerInterface interface {
 
 	// (GET /customer/{customer_id}/orders/{order_id})
 	GetCustomerCustomerIdOrdersOrderId(c *gin.Context, customerId string, orderId string)
+
+	// (POST /customer/{customer_id}/orders/{order_id}/payment-link)
+	PostCustomerCustomerIdOrdersOrderIdPaymentLink(c *gin.Context, customerId string, orderId string)
+
+	// (GET /products/availability)
+	GetProductsAvailability(c *gin.Context, params GetProductsAvailabilityParams)
+
+	// (POST /products/availability/check)
+	PostProductsAvailabilityCheck(c *gin.Context)
 }
 
 // ServerInterfaceWrapper converts contexts to parameters.
@@ -87,6 +96,85 @@ func (siw *ServerInterfaceWrapper) GetCustomerCustomerIdOrdersOrderId(c *gin.Con
 	siw.Handler.GetCustomerCustomerIdOrdersOrderId(c, customerId, orderId)
 }
 
+// PostCustomerCustomerIdOrdersOrderIdPaymentLink operation middleware
+func (siw *ServerInterfaceWrapper) PostCustomerCustomerIdOrdersOrderIdPaymentLink(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "customer_id" -------------
+	var customerId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "customer_id", c.Param("customer_id"), &customerId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter customer_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "order_id" -------------
+	var orderId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "order_id", c.Param("order_id"), &orderId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter order_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostCustomerCustomerIdOrdersOrderIdPaymentLink(c, customerId, orderId)
+}
+
+// GetProductsAvailability operation middleware
+func (siw *ServerInterfaceWrapper) GetProductsAvailability(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetProductsAvailabilityParams
+
+	// ------------- Required query parameter "product_ids" -------------
+
+	if paramValue := c.Query("product_ids"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument product_ids is required, but not found"), http.StatusBadRequest)
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", false, true, "product_ids", c.Request.URL.Query(), &params.ProductIds)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter product_ids: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetProductsAvailability(c, params)
+}
+
+// PostProductsAvailabilityCheck operation middleware
+func (siw *ServerInterfaceWrapper) PostProductsAvailabilityCheck(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostProductsAvailabilityCheck(c)
+}
+
 // GinServerOptions provides options for the Gin server.
 type GinServerOptions struct {
 	BaseURL      string
@@ -116,4 +204,7 @@ func RegisterHandlersWithOptions(router gin.IRouter, si ServerInterface, options
 
 	router.POST(options.BaseURL+"/customer/:customer_id/orders", wrapper.PostCustomerCustomerIdOrders)
 	router.GET(options.BaseURL+"/customer/:customer_id/orders/:order_id", wrapper.GetCustomerCustomerIdOrdersOrderId)
+	router.POST(options.BaseURL+"/customer/:customer_id/orders/:order_id/payment-link", wrapper.PostCustomerCustomerIdOrdersOrderIdPaymentLink)
+	router.GET(options.BaseURL+"/products/availability", wrapper.GetProductsAvailability)
+	router.POST(options.BaseURL+"/products/availability/check", wrapper.PostProductsAvailabilityCheck)
 }
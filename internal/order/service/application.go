@@ -6,15 +6,16 @@ import (
 
 	"github.com/furutachiKurea/gorder/common/broker"
 	grpcclient "github.com/furutachiKurea/gorder/common/client"
-	"github.com/furutachiKurea/gorder/common/metrics"
+	"github.com/furutachiKurea/gorder/common/decorator"
 	"github.com/furutachiKurea/gorder/order/adapter"
 	"github.com/furutachiKurea/gorder/order/adapter/grpc"
 	"github.com/furutachiKurea/gorder/order/app"
 	"github.com/furutachiKurea/gorder/order/app/client"
 	"github.com/furutachiKurea/gorder/order/app/command"
 	"github.com/furutachiKurea/gorder/order/app/query"
+	"github.com/furutachiKurea/gorder/order/domain/intake"
+	domain "github.com/furutachiKurea/gorder/order/domain/order"
 
-	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/viper"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -22,43 +23,46 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
-func NewApplication(ctx context.Context) (app app.Application, close func()) {
+func NewApplication(ctx context.Context, publisher broker.Publisher, metricsClient decorator.MetricsClient) (app app.Application, close func()) {
 	stockClient, closeStockClient, err := grpcclient.NewStockGRPCClient(ctx)
 	if err != nil {
 		panic(err)
 	}
 	stockGRPC := grpc.NewStockGRPC(stockClient)
+	paymentGRPC := grpc.NewPaymentGRPC(ctx)
 
-	ch, closeCoon := broker.Connect(
-		viper.GetString("rabbitmq.user"),
-		viper.GetString("rabbitmq.password"),
-		viper.GetString("rabbitmq.host"),
-		viper.GetString("rabbitmq.port"),
-	)
 	mongoClient, disconnectMongo := newMongoClient(ctx)
-	return newApplication(ctx, stockGRPC, mongoClient, ch), func() {
+	orderRepo := adapter.NewOrderRepositoryMongo(mongoClient)
+	return newApplication(ctx, orderRepo, stockGRPC, paymentGRPC, publisher, metricsClient), func() {
 		_ = closeStockClient()
-		_ = ch.Close()
-		_ = closeCoon()
+		_ = paymentGRPC.Close()
 		_ = disconnectMongo(ctx)
 	}
 
 }
 
-func newApplication(_ context.Context, stockClient client.StockService, mongoClient *mongo.Client, ch *amqp.Channel) app.Application {
-	orderRepo := adapter.NewOrderRepositoryMongo(mongoClient)
+func newApplication(
+	_ context.Context,
+	orderRepo domain.Repository,
+	stockClient client.StockService,
+	paymentClient client.PaymentService,
+	publisher broker.Publisher,
+	metricsClient decorator.MetricsClient,
+) app.Application {
+	loadRepo := adapter.NewMemoryKitchenLoadRepository()
 	logger := log.Logger
-	metricsClient := metrics.NewPrometheusMetricsClient(
-		&metrics.PrometheusMetricsClientConfig{
-			Host:        viper.GetString("order.metrics-export-addr"),
-			ServiceName: viper.GetString("order.service-name"),
-		})
 	return app.Application{
 		Commands: app.Commands{
 			CreateOrder: command.NewCreateOrderHandler(
 				orderRepo,
 				stockClient,
-				ch,
+				publisher,
+				loadRepo,
+				intake.Policy{
+					MaxBacklog: viper.GetInt("order.intake.max-backlog"),
+					StaleAfter: viper.GetDuration("order.intake.load-stale-after"),
+				},
+				viper.GetDuration("order.intake.throttle-interval"),
 				logger,
 				metricsClient,
 			),
@@ -73,6 +77,41 @@ func newApplication(_ context.Context, stockClient client.StockService, mongoCli
 				logger,
 				metricsClient,
 			),
+			AllocateBackorder: command.NewAllocateBackorderHandler(
+				orderRepo,
+				stockClient,
+				logger,
+				metricsClient,
+			),
+			UpdateOrderEstimate: command.NewUpdateOrderEstimateHandler(
+				orderRepo,
+				logger,
+				metricsClient,
+			),
+			UpdateKitchenLoad: command.NewUpdateKitchenLoadHandler(
+				loadRepo,
+				logger,
+				metricsClient,
+			),
+			UpdateKitchenProgress: command.NewUpdateKitchenProgressHandler(
+				orderRepo,
+				logger,
+				metricsClient,
+			),
+			CloseOrderPayment: command.NewCloseOrderPaymentHandler(
+				orderRepo,
+				stockClient,
+				logger,
+				metricsClient,
+			),
+			RegeneratePaymentLink: command.NewRegeneratePaymentLinkHandler(
+				orderRepo,
+				stockClient,
+				paymentClient,
+				viper.GetDuration("order.payment-link-regenerate-interval"),
+				logger,
+				metricsClient,
+			),
 		},
 		Queries: app.Queries{
 			GetCustomerOrder: query.NewGetCustomerOrderHandler(
@@ -80,6 +119,17 @@ func newApplication(_ context.Context, stockClient client.StockService, mongoCli
 				logger,
 				metricsClient,
 			),
+			GetProductAvailability: query.NewGetProductAvailabilityHandler(
+				stockClient,
+				viper.GetDuration("order.availability-cache-ttl"),
+				logger,
+				metricsClient,
+			),
+			CheckAvailability: query.NewCheckAvailabilityHandler(
+				stockClient,
+				logger,
+				metricsClient,
+			),
 		},
 	}
 
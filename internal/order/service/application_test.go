@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/broker"
+	"github.com/furutachiKurea/gorder/common/consts"
+	"github.com/furutachiKurea/gorder/common/entity"
+	"github.com/furutachiKurea/gorder/common/genproto/orderpb"
+	"github.com/furutachiKurea/gorder/common/genproto/stockpb"
+	"github.com/furutachiKurea/gorder/common/metrics"
+	"github.com/furutachiKurea/gorder/order/adapter"
+	"github.com/furutachiKurea/gorder/order/app/client"
+	"github.com/furutachiKurea/gorder/order/app/command"
+	domain "github.com/furutachiKurea/gorder/order/domain/order"
+	"github.com/furutachiKurea/gorder/order/infrastructure/consumer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStock 预扣时所有商品都有现货，记录支付后确认扣减的商品
+type fakeStock struct {
+	client.StockService
+
+	mu        sync.Mutex
+	confirmed []*orderpb.ItemWithQuantity
+}
+
+func (s *fakeStock) ReserveStock(_ context.Context, items []*orderpb.ItemWithQuantity) (*stockpb.ReserveStockResponse, error) {
+	resp := &stockpb.ReserveStockResponse{}
+	for _, item := range items {
+		resp.Items = append(resp.Items, &orderpb.Item{
+			Id:               item.Id,
+			Name:             item.Id,
+			Quantity:         item.Quantity,
+			PriceId:          "price_" + item.Id,
+			FulfilmentStatus: string(consts.FulfilmentInStock),
+		})
+	}
+	return resp, nil
+}
+
+func (s *fakeStock) ConfirmStockReservation(_ context.Context, items []*orderpb.ItemWithQuantity) (*stockpb.ConfirmStockReservationResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.confirmed = append(s.confirmed, items...)
+	return &stockpb.ConfirmStockReservationResponse{}, nil
+}
+
+func (s *fakeStock) confirmedCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.confirmed)
+}
+
+type noPayments struct {
+	client.PaymentService
+}
+
+// publishUntilRouted 等待消费者完成订阅后发布事件
+func publishUntilRouted(t *testing.T, mb *broker.MemoryBroker, exchange string, body any) {
+	t.Helper()
+
+	require.Eventually(t, func() bool {
+		return broker.PublishEvent(context.Background(), &broker.PublishEventReq{
+			Publisher: mb,
+			Routing:   broker.FanOut,
+			Exchange:  exchange,
+			Body:      body,
+			Mandatory: true,
+		}) == nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestApplication_OrderLifecycle(t *testing.T) {
+	ctx := context.Background()
+	mb := broker.NewMemoryBroker()
+	defer mb.Close()
+
+	var (
+		orderRepo = adapter.NewMemoryOrderRepository()
+		stock     = &fakeStock{}
+	)
+	application := newApplication(ctx, orderRepo, stock, noPayments{}, mb, metrics.TodoMetrics{})
+
+	created, err := application.Commands.CreateOrder.Handle(ctx, command.CreateOrder{
+		CustomerID: "customer",
+		Items:      []*entity.ItemWithQuantity{{ID: "item1", Quantity: 2}},
+	})
+	require.NoError(t, err)
+
+	// 订单创建事件投递到支付服务消费的队列
+	msgs, err := mb.Subscribe(ctx, broker.Subscription{Queue: broker.EventOrderCreated})
+	require.NoError(t, err)
+	select {
+	case d := <-msgs:
+		var o domain.Order
+		require.NoError(t, json.Unmarshal(d.Body, &o))
+		assert.Equal(t, created.OrderID, o.ID)
+		require.NoError(t, d.Ack())
+	case <-time.After(time.Second):
+		require.FailNow(t, "order created event not published")
+	}
+
+	go consumer.NewConsumer(application, mb, broker.RetryPolicy{MaxRetries: 1, BaseDelay: 10 * time.Millisecond}).Listen(mb)
+
+	statusOf := func() consts.OrderStatus {
+		o, err := orderRepo.Get(ctx, created.OrderID, "customer")
+		if err != nil {
+			return ""
+		}
+		return o.Status
+	}
+
+	order, err := orderRepo.Get(ctx, created.OrderID, "customer")
+	require.NoError(t, err)
+	paid := *order
+	paid.Status = consts.OrderStatusPaid
+	publishUntilRouted(t, mb, broker.EventOrderPaid, paid)
+
+	require.Eventually(t, func() bool { return statusOf() == consts.OrderStatusPaid }, time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool { return stock.confirmedCount() == 1 }, time.Second, 10*time.Millisecond)
+
+	publishUntilRouted(t, mb, broker.EventKitchenTicketStarted, entity.TicketEvent{
+		TicketID:   "ticket",
+		OrderID:    created.OrderID,
+		CustomerID: "customer",
+	})
+	require.Eventually(t, func() bool { return statusOf() == consts.OrderStatusPreparing }, time.Second, 10*time.Millisecond)
+
+	// 重复的 order.paid 不会让订单回退，也不会再次扣减库存
+	publishUntilRouted(t, mb, broker.EventOrderPaid, paid)
+	assert.Never(t, func() bool { return statusOf() != consts.OrderStatusPreparing }, 200*time.Millisecond, 10*time.Millisecond)
+	assert.Equal(t, 1, stock.confirmedCount())
+}
+
+func TestApplication_AllocateBackorder(t *testing.T) {
+	ctx := context.Background()
+	mb := broker.NewMemoryBroker()
+	defer mb.Close()
+
+	var (
+		orderRepo = adapter.NewMemoryOrderRepository()
+		stock     = &fakeStock{}
+	)
+	application := newApplication(ctx, orderRepo, stock, noPayments{}, mb, metrics.TodoMetrics{})
+
+	// 订单已经交给厨房，缺货预订的商品在支付确认时被跳过
+	created, err := orderRepo.Create(ctx, &domain.Order{
+		CustomerID: "customer",
+		Status:     consts.OrderStatusPreparing,
+		Items: []*entity.Item{
+			{ID: "item1", Quantity: 1, FulfilmentStatus: consts.FulfilmentInStock},
+			{ID: "item2", Quantity: 2, FulfilmentStatus: consts.FulfilmentBackordered, BackorderID: "backorder-allocate"},
+		},
+	})
+	require.NoError(t, err)
+
+	allocate := command.AllocateBackorder{Allocation: &entity.BackorderAllocation{
+		BackorderID: "backorder-allocate",
+		ProductID:   "item2",
+		Quantity:    2,
+	}}
+	_, err = application.Commands.AllocateBackorder.Handle(ctx, allocate)
+	require.NoError(t, err)
+
+	order, err := orderRepo.Get(ctx, created.ID, "customer")
+	require.NoError(t, err)
+	assert.Equal(t, consts.FulfilmentInStock, order.Items[0].FulfilmentStatus)
+	assert.Equal(t, consts.FulfilmentAllocated, order.Items[1].FulfilmentStatus)
+	assert.Equal(t, consts.OrderStatusPreparing, order.Status)
+	assert.Equal(t, 1, stock.confirmedCount())
+
+	// 重复的分配事件不会再次扣减库存
+	_, err = application.Commands.AllocateBackorder.Handle(ctx, allocate)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stock.confirmedCount())
+}
+
+func TestApplication_DuplicateOrderPaid(t *testing.T) {
+	ctx := context.Background()
+	mb := broker.NewMemoryBroker()
+	defer mb.Close()
+
+	var (
+		orderRepo = adapter.NewMemoryOrderRepository()
+		stock     = &fakeStock{}
+	)
+	application := newApplication(ctx, orderRepo, stock, noPayments{}, mb, metrics.TodoMetrics{})
+
+	created, err := orderRepo.Create(ctx, &domain.Order{
+		CustomerID: "customer",
+		Status:     consts.OrderStatusWaitingForPayment,
+		Items:      []*entity.Item{{ID: "item1", Quantity: 1, FulfilmentStatus: consts.FulfilmentInStock}},
+	})
+	require.NoError(t, err)
+
+	paid := *created
+	paid.Status = consts.OrderStatusPaid
+	// Webhook 重试和对账都可能让支付服务重复发布 order.paid，订单仍为已支付时也只扣减一次库存
+	for range 2 {
+		_, err = application.Commands.ConfirmOrderPaid.Handle(ctx, command.ConfirmOrderPaid{Order: &paid})
+		require.NoError(t, err)
+	}
+
+	order, err := orderRepo.Get(ctx, created.ID, "customer")
+	require.NoError(t, err)
+	assert.Equal(t, consts.OrderStatusPaid, order.Status)
+	assert.Equal(t, 1, stock.confirmedCount())
+}
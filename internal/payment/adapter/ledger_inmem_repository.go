@@ -0,0 +1,217 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/gorder/payment/domain"
+)
+
+type MemoryLedger struct {
+	lock     *sync.RWMutex
+	accounts map[string]*domain.Account
+	holds    map[string]*domain.Hold
+	entries  []*domain.Entry
+}
+
+func NewMemoryLedger() *MemoryLedger {
+	return &MemoryLedger{
+		lock:     &sync.RWMutex{},
+		accounts: make(map[string]*domain.Account),
+		holds:    make(map[string]*domain.Hold),
+	}
+}
+
+func (m *MemoryLedger) Issue(
+	_ context.Context,
+	accountType domain.AccountType,
+	customerID, currency string,
+	amount int64,
+) (*domain.Account, error) {
+	if err := validateIssue(accountType, customerID, currency, amount); err != nil {
+		return nil, err
+	}
+	currency = strings.ToLower(currency)
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	now := time.Now()
+	var account *domain.Account
+	if accountType == domain.AccountStoreCredit {
+		for _, a := range m.accounts {
+			if a.Type == accountType && a.CustomerID == customerID && a.Currency == currency {
+				account = a
+				break
+			}
+		}
+	}
+	if account == nil {
+		account = &domain.Account{
+			ID:         strconv.FormatInt(now.UnixNano(), 10),
+			Type:       accountType,
+			CustomerID: customerID,
+			Currency:   currency,
+			CreatedAt:  now,
+		}
+		if accountType == domain.AccountGiftCard {
+			account.Code = newGiftCardCode()
+		}
+		m.accounts[account.ID] = account
+	}
+	account.Balance += amount
+	account.UpdatedAt = now
+	m.appendEntry(&domain.Entry{AccountID: account.ID, Type: domain.EntryIssue, Amount: amount, CreatedAt: now})
+
+	got := *account
+	return &got, nil
+}
+
+func (m *MemoryLedger) GetAccount(_ context.Context, accountID string) (*domain.Account, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	a, ok := m.accounts[accountID]
+	if !ok {
+		return nil, domain.AccountNotFoundError{AccountID: accountID}
+	}
+
+	got := *a
+	return &got, nil
+}
+
+func (m *MemoryLedger) ListAccounts(_ context.Context, customerID string) ([]*domain.Account, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	var res []*domain.Account
+	for _, a := range m.accounts {
+		if a.CustomerID == customerID {
+			got := *a
+			res = append(res, &got)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].CreatedAt.Before(res[j].CreatedAt)
+	})
+
+	return res, nil
+}
+
+func (m *MemoryLedger) Hold(_ context.Context, accountID, orderID string, amount int64) (*domain.Hold, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("hold amount must be positive, got %d", amount)
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	a, ok := m.accounts[accountID]
+	if !ok {
+		return nil, domain.AccountNotFoundError{AccountID: accountID}
+	}
+	if a.Balance < amount {
+		return nil, domain.InsufficientBalanceError{AccountID: accountID, Want: amount}
+	}
+
+	now := time.Now()
+	a.Balance -= amount
+	a.Held += amount
+	a.UpdatedAt = now
+	hold := &domain.Hold{
+		ID:        strconv.FormatInt(now.UnixNano(), 10),
+		AccountID: accountID,
+		OrderID:   orderID,
+		Amount:    amount,
+		Currency:  a.Currency,
+		Status:    domain.HoldStatusHeld,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	m.holds[hold.ID] = hold
+	m.appendEntry(&domain.Entry{
+		AccountID: accountID,
+		HoldID:    hold.ID,
+		OrderID:   orderID,
+		Type:      domain.EntryHold,
+		Amount:    amount,
+		CreatedAt: now,
+	})
+
+	got := *hold
+	return &got, nil
+}
+
+func (m *MemoryLedger) ListHolds(_ context.Context, orderID string) ([]*domain.Hold, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	var res []*domain.Hold
+	for _, h := range m.holds {
+		if h.OrderID == orderID {
+			got := *h
+			res = append(res, &got)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].CreatedAt.Before(res[j].CreatedAt)
+	})
+
+	return res, nil
+}
+
+func (m *MemoryLedger) Capture(_ context.Context, holdID string) error {
+	return m.settle(holdID, domain.HoldStatusCaptured, domain.EntryCapture)
+}
+
+func (m *MemoryLedger) Release(_ context.Context, holdID string) error {
+	return m.settle(holdID, domain.HoldStatusReleased, domain.EntryRelease)
+}
+
+func (m *MemoryLedger) settle(holdID string, to domain.HoldStatus, entryType domain.EntryType) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	h, ok := m.holds[holdID]
+	if !ok {
+		return domain.HoldNotFoundError{HoldID: holdID}
+	}
+	switch h.Status {
+	case to:
+		return nil
+	case domain.HoldStatusHeld:
+	default:
+		return fmt.Errorf("%s hold %s from %s not allowed", entryType, holdID, h.Status)
+	}
+
+	now := time.Now()
+	h.Status = to
+	h.UpdatedAt = now
+	if a, ok := m.accounts[h.AccountID]; ok {
+		a.Held -= h.Amount
+		if to == domain.HoldStatusReleased {
+			a.Balance += h.Amount
+		}
+		a.UpdatedAt = now
+	}
+	m.appendEntry(&domain.Entry{
+		AccountID: h.AccountID,
+		HoldID:    holdID,
+		OrderID:   h.OrderID,
+		Type:      entryType,
+		Amount:    h.Amount,
+		CreatedAt: now,
+	})
+
+	return nil
+}
+
+func (m *MemoryLedger) appendEntry(entry *domain.Entry) {
+	entry.ID = strconv.Itoa(len(m.entries) + 1)
+	m.entries = append(m.entries, entry)
+}
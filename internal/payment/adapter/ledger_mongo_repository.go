@@ -0,0 +1,445 @@
+package adapter
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/logging"
+	"github.com/furutachiKurea/gorder/payment/domain"
+
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LedgerMongo 礼品卡和商店余额账本，账户、冻结和流水分别存放在三个 collection 中，每次余额变动在一个事务中完成
+type LedgerMongo struct {
+	db *mongo.Client
+}
+
+func NewLedgerMongo(db *mongo.Client) *LedgerMongo {
+	return &LedgerMongo{db: db}
+}
+
+// Issue 礼品卡每次发行一个新账户，商店余额累加到客户对应币种的账户，账户不存在时创建
+func (l *LedgerMongo) Issue(
+	ctx context.Context,
+	accountType domain.AccountType,
+	customerID, currency string,
+	amount int64,
+) (account *domain.Account, err error) {
+	_, deferlog := logging.WhenRequest(ctx, "LedgerMongo.Issue", map[string]any{
+		"type":        accountType,
+		"customer_id": customerID,
+		"currency":    currency,
+		"amount":      amount,
+	})
+	defer deferlog(account, &err)
+
+	if err = validateIssue(accountType, customerID, currency, amount); err != nil {
+		return nil, err
+	}
+	currency = strings.ToLower(currency)
+
+	err = l.withTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		now := time.Now()
+		read := &accountModel{}
+		if accountType == domain.AccountStoreCredit {
+			err := l.accounts().FindOneAndUpdate(sessCtx,
+				bson.M{"type": string(accountType), "customer_id": customerID, "currency": currency},
+				bson.M{
+					"$inc": bson.M{"balance": amount},
+					"$set": bson.M{"updated_at": now},
+					"$setOnInsert": bson.M{
+						"_id":        primitive.NewObjectID(),
+						"code":       "",
+						"held":       int64(0),
+						"created_at": now,
+					},
+				},
+				options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+			).Decode(read)
+			if err != nil {
+				return fmt.Errorf("issue store credit: %w", err)
+			}
+		} else {
+			read = &accountModel{
+				MongoID:    primitive.NewObjectID(),
+				Type:       string(accountType),
+				CustomerID: customerID,
+				Code:       newGiftCardCode(),
+				Currency:   currency,
+				Balance:    amount,
+				CreatedAt:  now,
+				UpdatedAt:  now,
+			}
+			if _, err := l.accounts().InsertOne(sessCtx, read); err != nil {
+				return fmt.Errorf("issue gift card: %w", err)
+			}
+		}
+
+		account = read.toDomain()
+		return l.appendEntry(sessCtx, &domain.Entry{
+			AccountID: account.ID,
+			Type:      domain.EntryIssue,
+			Amount:    amount,
+			CreatedAt: now,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+func (l *LedgerMongo) GetAccount(ctx context.Context, accountID string) (account *domain.Account, err error) {
+	_, deferlog := logging.WhenRequest(ctx, "LedgerMongo.GetAccount", map[string]any{
+		"account_id": accountID,
+	})
+	defer deferlog(account, &err)
+
+	return l.getAccount(ctx, accountID)
+}
+
+func (l *LedgerMongo) ListAccounts(ctx context.Context, customerID string) (accounts []*domain.Account, err error) {
+	_, deferlog := logging.WhenRequest(ctx, "LedgerMongo.ListAccounts", map[string]any{
+		"customer_id": customerID,
+	})
+	defer deferlog(accounts, &err)
+
+	cursor, err := l.accounts().Find(ctx, bson.M{"customer_id": customerID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	var read []*accountModel
+	if err = cursor.All(ctx, &read); err != nil {
+		return nil, fmt.Errorf("decode ledger accounts: %w", err)
+	}
+
+	for _, m := range read {
+		accounts = append(accounts, m.toDomain())
+	}
+	return accounts, nil
+}
+
+// Hold 可用余额足够时才扣减，余额检查和扣减在同一次更新中完成
+func (l *LedgerMongo) Hold(ctx context.Context, accountID, orderID string, amount int64) (hold *domain.Hold, err error) {
+	_, deferlog := logging.WhenRequest(ctx, "LedgerMongo.Hold", map[string]any{
+		"account_id": accountID,
+		"order_id":   orderID,
+		"amount":     amount,
+	})
+	defer deferlog(hold, &err)
+
+	if amount <= 0 {
+		return nil, fmt.Errorf("hold amount must be positive, got %d", amount)
+	}
+	mongoID, err := primitive.ObjectIDFromHex(accountID)
+	if err != nil {
+		return nil, domain.AccountNotFoundError{AccountID: accountID}
+	}
+
+	err = l.withTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		now := time.Now()
+		read := &accountModel{}
+		err := l.accounts().FindOneAndUpdate(sessCtx,
+			bson.M{"_id": mongoID, "balance": bson.M{"$gte": amount}},
+			bson.M{"$inc": bson.M{"balance": -amount, "held": amount}, "$set": bson.M{"updated_at": now}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(read)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				if _, err := l.getAccount(sessCtx, accountID); err != nil {
+					return err
+				}
+				return domain.InsufficientBalanceError{AccountID: accountID, Want: amount}
+			}
+			return err
+		}
+
+		write := &holdModel{
+			MongoID:   primitive.NewObjectID(),
+			AccountID: accountID,
+			OrderID:   orderID,
+			Amount:    amount,
+			Currency:  read.Currency,
+			Status:    string(domain.HoldStatusHeld),
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if _, err := l.holds().InsertOne(sessCtx, write); err != nil {
+			return fmt.Errorf("create hold: %w", err)
+		}
+
+		hold = write.toDomain()
+		return l.appendEntry(sessCtx, &domain.Entry{
+			AccountID: accountID,
+			HoldID:    hold.ID,
+			OrderID:   orderID,
+			Type:      domain.EntryHold,
+			Amount:    amount,
+			CreatedAt: now,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+func (l *LedgerMongo) ListHolds(ctx context.Context, orderID string) (holds []*domain.Hold, err error) {
+	_, deferlog := logging.WhenRequest(ctx, "LedgerMongo.ListHolds", map[string]any{
+		"order_id": orderID,
+	})
+	defer deferlog(holds, &err)
+
+	cursor, err := l.holds().Find(ctx, bson.M{"order_id": orderID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	var read []*holdModel
+	if err = cursor.All(ctx, &read); err != nil {
+		return nil, fmt.Errorf("decode ledger holds: %w", err)
+	}
+
+	for _, m := range read {
+		holds = append(holds, m.toDomain())
+	}
+	return holds, nil
+}
+
+func (l *LedgerMongo) Capture(ctx context.Context, holdID string) (err error) {
+	_, deferlog := logging.WhenRequest(ctx, "LedgerMongo.Capture", map[string]any{
+		"hold_id": holdID,
+	})
+	defer deferlog(nil, &err)
+
+	return l.settle(ctx, holdID, domain.HoldStatusCaptured, domain.EntryCapture)
+}
+
+func (l *LedgerMongo) Release(ctx context.Context, holdID string) (err error) {
+	_, deferlog := logging.WhenRequest(ctx, "LedgerMongo.Release", map[string]any{
+		"hold_id": holdID,
+	})
+	defer deferlog(nil, &err)
+
+	return l.settle(ctx, holdID, domain.HoldStatusReleased, domain.EntryRelease)
+}
+
+// settle 将冻结更新为 to 状态并减少账户的冻结金额，释放时冻结金额退回可用余额。
+// 已经是 to 状态的冻结直接返回，另一个终态的冻结返回错误
+func (l *LedgerMongo) settle(ctx context.Context, holdID string, to domain.HoldStatus, entryType domain.EntryType) error {
+	mongoID, err := primitive.ObjectIDFromHex(holdID)
+	if err != nil {
+		return domain.HoldNotFoundError{HoldID: holdID}
+	}
+
+	return l.withTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		read := &holdModel{}
+		if err := l.holds().FindOne(sessCtx, bson.M{"_id": mongoID}).Decode(read); err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return domain.HoldNotFoundError{HoldID: holdID}
+			}
+			return err
+		}
+		switch domain.HoldStatus(read.Status) {
+		case to:
+			return nil
+		case domain.HoldStatusHeld:
+		default:
+			return fmt.Errorf("%s hold %s from %s not allowed", entryType, holdID, read.Status)
+		}
+
+		now := time.Now()
+		if _, err := l.holds().UpdateOne(sessCtx,
+			bson.M{"_id": mongoID, "status": string(domain.HoldStatusHeld)},
+			bson.M{"$set": bson.M{"status": string(to), "updated_at": now}},
+		); err != nil {
+			return fmt.Errorf("update hold: %w", err)
+		}
+
+		inc := bson.M{"held": -read.Amount}
+		if to == domain.HoldStatusReleased {
+			inc["balance"] = read.Amount
+		}
+		accountID, _ := primitive.ObjectIDFromHex(read.AccountID)
+		if _, err := l.accounts().UpdateOne(sessCtx,
+			bson.M{"_id": accountID},
+			bson.M{"$inc": inc, "$set": bson.M{"updated_at": now}},
+		); err != nil {
+			return fmt.Errorf("update account: %w", err)
+		}
+
+		return l.appendEntry(sessCtx, &domain.Entry{
+			AccountID: read.AccountID,
+			HoldID:    holdID,
+			OrderID:   read.OrderID,
+			Type:      entryType,
+			Amount:    read.Amount,
+			CreatedAt: now,
+		})
+	})
+}
+
+func (l *LedgerMongo) getAccount(ctx context.Context, accountID string) (*domain.Account, error) {
+	mongoID, err := primitive.ObjectIDFromHex(accountID)
+	if err != nil {
+		return nil, domain.AccountNotFoundError{AccountID: accountID}
+	}
+
+	read := &accountModel{}
+	if err = l.accounts().FindOne(ctx, bson.M{"_id": mongoID}).Decode(read); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.AccountNotFoundError{AccountID: accountID}
+		}
+		return nil, err
+	}
+
+	return read.toDomain(), nil
+}
+
+func (l *LedgerMongo) appendEntry(ctx context.Context, entry *domain.Entry) error {
+	_, err := l.entries().InsertOne(ctx, &entryModel{
+		MongoID:   primitive.NewObjectID(),
+		AccountID: entry.AccountID,
+		HoldID:    entry.HoldID,
+		OrderID:   entry.OrderID,
+		Type:      string(entry.Type),
+		Amount:    entry.Amount,
+		CreatedAt: entry.CreatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("append ledger entry: %w", err)
+	}
+
+	return nil
+}
+
+func (l *LedgerMongo) withTransaction(ctx context.Context, fn func(sessCtx mongo.SessionContext) error) error {
+	session, err := l.db.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (any, error) {
+		return nil, fn(sessCtx)
+	})
+	return err
+}
+
+func (l *LedgerMongo) database() *mongo.Database {
+	return l.db.Database(viper.GetString("payment.mongo-db-name"))
+}
+
+func (l *LedgerMongo) accounts() *mongo.Collection {
+	return l.database().Collection(viper.GetString("payment.ledger-account-coll-name"))
+}
+
+func (l *LedgerMongo) holds() *mongo.Collection {
+	return l.database().Collection(viper.GetString("payment.ledger-hold-coll-name"))
+}
+
+func (l *LedgerMongo) entries() *mongo.Collection {
+	return l.database().Collection(viper.GetString("payment.ledger-entry-coll-name"))
+}
+
+func validateIssue(accountType domain.AccountType, customerID, currency string, amount int64) error {
+	if accountType != domain.AccountGiftCard && accountType != domain.AccountStoreCredit {
+		return fmt.Errorf("unknown account type %q", accountType)
+	}
+	if customerID == "" {
+		return errors.New("empty customerID")
+	}
+	if currency == "" {
+		return errors.New("empty currency")
+	}
+	if amount <= 0 {
+		return fmt.Errorf("issue amount must be positive, got %d", amount)
+	}
+
+	return nil
+}
+
+// newGiftCardCode 生成礼品卡兑换码，格式为 GC-XXXXXXXXXXXXXXXX
+func newGiftCardCode() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return "GC-" + strings.ToUpper(hex.EncodeToString(b))
+}
+
+// accountModel MongoDB 的余额账户模型
+type accountModel struct {
+	MongoID    primitive.ObjectID `bson:"_id"`
+	Type       string             `bson:"type"`
+	CustomerID string             `bson:"customer_id"`
+	Code       string             `bson:"code"`
+	Currency   string             `bson:"currency"`
+	Balance    int64              `bson:"balance"`
+	Held       int64              `bson:"held"`
+	CreatedAt  time.Time          `bson:"created_at"`
+	UpdatedAt  time.Time          `bson:"updated_at"`
+}
+
+func (m *accountModel) toDomain() *domain.Account {
+	return &domain.Account{
+		ID:         m.MongoID.Hex(),
+		Type:       domain.AccountType(m.Type),
+		CustomerID: m.CustomerID,
+		Code:       m.Code,
+		Currency:   m.Currency,
+		Balance:    m.Balance,
+		Held:       m.Held,
+		CreatedAt:  m.CreatedAt,
+		UpdatedAt:  m.UpdatedAt,
+	}
+}
+
+// holdModel MongoDB 的余额冻结模型
+type holdModel struct {
+	MongoID   primitive.ObjectID `bson:"_id"`
+	AccountID string             `bson:"account_id"`
+	OrderID   string             `bson:"order_id"`
+	Amount    int64              `bson:"amount"`
+	Currency  string             `bson:"currency"`
+	Status    string             `bson:"status"`
+	CreatedAt time.Time          `bson:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at"`
+}
+
+func (m *holdModel) toDomain() *domain.Hold {
+	return &domain.Hold{
+		ID:        m.MongoID.Hex(),
+		AccountID: m.AccountID,
+		OrderID:   m.OrderID,
+		Amount:    m.Amount,
+		Currency:  m.Currency,
+		Status:    domain.HoldStatus(m.Status),
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+// entryModel MongoDB 的账本流水模型
+type entryModel struct {
+	MongoID   primitive.ObjectID `bson:"_id"`
+	AccountID string             `bson:"account_id"`
+	HoldID    string             `bson:"hold_id"`
+	OrderID   string             `bson:"order_id"`
+	Type      string             `bson:"type"`
+	Amount    int64              `bson:"amount"`
+	CreatedAt time.Time          `bson:"created_at"`
+}
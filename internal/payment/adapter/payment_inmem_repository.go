@@ -0,0 +1,125 @@
+package adapter
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/gorder/payment/domain"
+)
+
+type MemoryPaymentRepository struct {
+	lock  *sync.RWMutex
+	store map[string]*domain.Payment
+}
+
+func NewMemoryPaymentRepository() *MemoryPaymentRepository {
+	return &MemoryPaymentRepository{
+		lock:  &sync.RWMutex{},
+		store: make(map[string]*domain.Payment),
+	}
+}
+
+func (m *MemoryPaymentRepository) Create(_ context.Context, payment *domain.Payment) (*domain.Payment, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	created := *payment
+	created.ID = strconv.FormatInt(time.Now().UnixNano(), 10)
+	m.store[created.ID] = &created
+
+	result := created
+	return &result, nil
+}
+
+func (m *MemoryPaymentRepository) Get(_ context.Context, paymentID string) (*domain.Payment, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	p, ok := m.store[paymentID]
+	if !ok {
+		return nil, domain.NotFoundError{PaymentID: paymentID}
+	}
+
+	got := *p
+	return &got, nil
+}
+
+func (m *MemoryPaymentRepository) GetBySession(_ context.Context, provider, sessionID string) (*domain.Payment, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	for _, p := range m.store {
+		if p.Provider == provider && p.ProviderSessionID == sessionID {
+			got := *p
+			return &got, nil
+		}
+	}
+
+	return nil, domain.NotFoundError{PaymentID: provider + "/" + sessionID}
+}
+
+func (m *MemoryPaymentRepository) ListByOrderID(_ context.Context, orderID string) ([]*domain.Payment, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	var res []*domain.Payment
+	for _, p := range m.store {
+		if p.OrderID == orderID {
+			got := *p
+			res = append(res, &got)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].CreatedAt.Before(res[j].CreatedAt)
+	})
+
+	return res, nil
+}
+
+func (m *MemoryPaymentRepository) ListPending(_ context.Context, createdBefore time.Time, limit int) ([]*domain.Payment, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	var res []*domain.Payment
+	for _, p := range m.store {
+		if p.IsPending() && p.CreatedAt.Before(createdBefore) {
+			got := *p
+			res = append(res, &got)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].CreatedAt.Before(res[j].CreatedAt)
+	})
+	if len(res) > limit {
+		res = res[:limit]
+	}
+
+	return res, nil
+}
+
+func (m *MemoryPaymentRepository) Update(
+	ctx context.Context,
+	paymentID string,
+	updateFn func(ctx context.Context, payment *domain.Payment) (*domain.Payment, error),
+) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	p, ok := m.store[paymentID]
+	if !ok {
+		return domain.NotFoundError{PaymentID: paymentID}
+	}
+
+	current := *p
+	updated, err := updateFn(ctx, &current)
+	if err != nil {
+		return err
+	}
+	updated.UpdatedAt = time.Now()
+	m.store[paymentID] = updated
+
+	return nil
+}
@@ -0,0 +1,237 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/entity"
+	"github.com/furutachiKurea/gorder/common/logging"
+	"github.com/furutachiKurea/gorder/payment/domain"
+
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type PaymentRepositoryMongo struct {
+	db *mongo.Client
+}
+
+func NewPaymentRepositoryMongo(db *mongo.Client) *PaymentRepositoryMongo {
+	return &PaymentRepositoryMongo{db: db}
+}
+
+func (r *PaymentRepositoryMongo) Create(ctx context.Context, payment *domain.Payment) (created *domain.Payment, err error) {
+	_, deferlog := logging.WhenRequest(ctx, "PaymentRepositoryMongo.Create", map[string]any{
+		"payment": payment,
+	})
+	defer deferlog(created, &err)
+
+	write := r.domainToMongo(payment)
+	write.MongoID = primitive.NewObjectID()
+	if _, err = r.collection().InsertOne(ctx, write); err != nil {
+		return nil, err
+	}
+
+	created = payment
+	created.ID = write.MongoID.Hex()
+	return created, nil
+}
+
+func (r *PaymentRepositoryMongo) Get(ctx context.Context, paymentID string) (got *domain.Payment, err error) {
+	_, deferlog := logging.WhenRequest(ctx, "PaymentRepositoryMongo.Get", map[string]any{
+		"payment_id": paymentID,
+	})
+	defer deferlog(got, &err)
+
+	mongoID, err := primitive.ObjectIDFromHex(paymentID)
+	if err != nil {
+		return nil, domain.NotFoundError{PaymentID: paymentID}
+	}
+
+	return r.findOne(ctx, bson.M{"_id": mongoID}, paymentID)
+}
+
+func (r *PaymentRepositoryMongo) GetBySession(ctx context.Context, provider, sessionID string) (got *domain.Payment, err error) {
+	_, deferlog := logging.WhenRequest(ctx, "PaymentRepositoryMongo.GetBySession", map[string]any{
+		"provider":   provider,
+		"session_id": sessionID,
+	})
+	defer deferlog(got, &err)
+
+	return r.findOne(ctx, bson.M{"provider": provider, "provider_session_id": sessionID}, provider+"/"+sessionID)
+}
+
+func (r *PaymentRepositoryMongo) ListByOrderID(ctx context.Context, orderID string) (got []*domain.Payment, err error) {
+	_, deferlog := logging.WhenRequest(ctx, "PaymentRepositoryMongo.ListByOrderID", map[string]any{
+		"order_id": orderID,
+	})
+	defer deferlog(got, &err)
+
+	cursor, err := r.collection().Find(ctx, bson.M{"order_id": orderID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	var read []*paymentModel
+	if err = cursor.All(ctx, &read); err != nil {
+		return nil, fmt.Errorf("decode payments: %w", err)
+	}
+
+	for _, m := range read {
+		got = append(got, r.unmarshal(m))
+	}
+	return got, nil
+}
+
+func (r *PaymentRepositoryMongo) ListPending(ctx context.Context, createdBefore time.Time, limit int) (got []*domain.Payment, err error) {
+	_, deferlog := logging.WhenRequest(ctx, "PaymentRepositoryMongo.ListPending", map[string]any{
+		"created_before": createdBefore,
+		"limit":          limit,
+	})
+	defer deferlog(got, &err)
+
+	cursor, err := r.collection().Find(ctx,
+		bson.M{"status": string(domain.StatusPending), "created_at": bson.M{"$lt": createdBefore}},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}).SetLimit(int64(limit)))
+	if err != nil {
+		return nil, err
+	}
+
+	var read []*paymentModel
+	if err = cursor.All(ctx, &read); err != nil {
+		return nil, fmt.Errorf("decode payments: %w", err)
+	}
+
+	for _, m := range read {
+		got = append(got, r.unmarshal(m))
+	}
+	return got, nil
+}
+
+// Update 在事务中读取支付记录，执行 updateFn 后写回
+func (r *PaymentRepositoryMongo) Update(
+	ctx context.Context,
+	paymentID string,
+	updateFn func(ctx context.Context, payment *domain.Payment) (*domain.Payment, error),
+) (err error) {
+	_, deferlog := logging.WhenRequest(ctx, "PaymentRepositoryMongo.Update", map[string]any{
+		"payment_id": paymentID,
+	})
+	defer deferlog(nil, &err)
+
+	session, err := r.db.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (any, error) {
+		payment, err := r.Get(sessCtx, paymentID)
+		if err != nil {
+			return nil, err
+		}
+
+		updated, err := updateFn(sessCtx, payment)
+		if err != nil {
+			return nil, err
+		}
+		updated.UpdatedAt = time.Now()
+
+		write := r.domainToMongo(updated)
+		write.MongoID, _ = primitive.ObjectIDFromHex(paymentID)
+		_, err = r.collection().ReplaceOne(sessCtx, bson.M{"_id": write.MongoID}, write)
+		return nil, err
+	})
+
+	return err
+}
+
+func (r *PaymentRepositoryMongo) findOne(ctx context.Context, cond bson.M, key string) (*domain.Payment, error) {
+	read := &paymentModel{}
+	if err := r.collection().FindOne(ctx, cond).Decode(read); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.NotFoundError{PaymentID: key}
+		}
+		return nil, err
+	}
+
+	return r.unmarshal(read), nil
+}
+
+// collection 获取支付记录 collection
+func (r *PaymentRepositoryMongo) collection() *mongo.Collection {
+	return r.db.Database(viper.GetString("payment.mongo-db-name")).Collection(viper.GetString("payment.mongo-coll-name"))
+}
+
+func (r *PaymentRepositoryMongo) domainToMongo(p *domain.Payment) *paymentModel {
+	return &paymentModel{
+		OrderID:           p.OrderID,
+		CustomerID:        p.CustomerID,
+		Provider:          p.Provider,
+		ProviderSessionID: p.ProviderSessionID,
+		ProviderIntentID:  p.ProviderIntentID,
+		Amount:            p.Amount,
+		Currency:          p.Currency,
+		Status:            string(p.Status),
+		PaymentLink:       p.PaymentLink,
+		Items:             p.Items,
+		CreatedAt:         p.CreatedAt,
+		UpdatedAt:         p.UpdatedAt,
+		PaidAt:            p.PaidAt,
+		FailureReason:     p.FailureReason,
+		Credit:            p.Credit,
+		Express:           p.Express,
+		PickupAt:          p.PickupAt,
+	}
+}
+
+func (r *PaymentRepositoryMongo) unmarshal(m *paymentModel) *domain.Payment {
+	return &domain.Payment{
+		ID:                m.MongoID.Hex(),
+		OrderID:           m.OrderID,
+		CustomerID:        m.CustomerID,
+		Provider:          m.Provider,
+		ProviderSessionID: m.ProviderSessionID,
+		ProviderIntentID:  m.ProviderIntentID,
+		Amount:            m.Amount,
+		Currency:          m.Currency,
+		Status:            domain.Status(m.Status),
+		PaymentLink:       m.PaymentLink,
+		Items:             m.Items,
+		CreatedAt:         m.CreatedAt,
+		UpdatedAt:         m.UpdatedAt,
+		PaidAt:            m.PaidAt,
+		FailureReason:     m.FailureReason,
+		Credit:            m.Credit,
+		Express:           m.Express,
+		PickupAt:          m.PickupAt,
+	}
+}
+
+// paymentModel MongoDB 的支付记录模型
+type paymentModel struct {
+	MongoID           primitive.ObjectID `bson:"_id"`
+	OrderID           string             `bson:"order_id"`
+	CustomerID        string             `bson:"customer_id"`
+	Provider          string             `bson:"provider"`
+	ProviderSessionID string             `bson:"provider_session_id"`
+	ProviderIntentID  string             `bson:"provider_intent_id"`
+	Amount            int64              `bson:"amount"`
+	Currency          string             `bson:"currency"`
+	Status            string             `bson:"status"`
+	PaymentLink       string             `bson:"payment_link"`
+	Items             []*entity.Item     `bson:"items"`
+	CreatedAt         time.Time          `bson:"created_at"`
+	UpdatedAt         time.Time          `bson:"updated_at"`
+	PaidAt            *time.Time         `bson:"paid_at"`
+	FailureReason     string             `bson:"failure_reason"`
+	Credit            int64              `bson:"credit"`
+	Express           bool               `bson:"express"`
+	PickupAt          *time.Time         `bson:"pickup_at"`
+}
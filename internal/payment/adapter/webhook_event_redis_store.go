@@ -0,0 +1,56 @@
+package adapter
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/handler/redis"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	webhookEventKeyPrefix  = "payment_webhook_event_"
+	webhookObjectKeyPrefix = "payment_webhook_object_"
+)
+
+// advanceScript 仅当新的时间不早于已记录的时间时写入，返回 1 表示事件按序到达
+var advanceScript = goredis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if current and tonumber(current) > tonumber(ARGV[1]) then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[1], 'EX', ARGV[2])
+return 1
+`)
+
+type WebhookEventStoreRedis struct {
+	client *goredis.Client
+	ttl    time.Duration
+}
+
+// NewWebhookEventStoreRedis ttl 为事件记录的保留时间，应覆盖渠道重试 Webhook 的时间窗口
+func NewWebhookEventStoreRedis(client *goredis.Client, ttl time.Duration) *WebhookEventStoreRedis {
+	return &WebhookEventStoreRedis{client: client, ttl: ttl}
+}
+
+func (s *WebhookEventStoreRedis) Claim(ctx context.Context, eventID string) (bool, error) {
+	return redis.SetIfAbsent(ctx, s.client, webhookEventKeyPrefix+eventID, "1", s.ttl)
+}
+
+func (s *WebhookEventStoreRedis) Release(ctx context.Context, eventID string) error {
+	return redis.Del(ctx, s.client, webhookEventKeyPrefix+eventID)
+}
+
+func (s *WebhookEventStoreRedis) Advance(ctx context.Context, objectID string, createdAt time.Time) (bool, error) {
+	ok, err := advanceScript.Run(ctx, s.client,
+		[]string{webhookObjectKeyPrefix + objectID},
+		createdAt.Unix(), strconv.Itoa(int(s.ttl.Seconds())),
+	).Int()
+	if err != nil {
+		return false, err
+	}
+
+	return ok == 1, nil
+}
@@ -1,11 +1,26 @@
 package app
 
-import "github.com/furutachiKurea/gorder/payment/app/command"
+import (
+	"github.com/furutachiKurea/gorder/payment/app/command"
+	"github.com/furutachiKurea/gorder/payment/app/query"
+)
 
 type Application struct {
 	Commands Commands
+	Queries  Queries
 }
 
 type Commands struct {
-	CreatePayment command.CreatePaymentHandler
+	CreatePayment         command.CreatePaymentHandler
+	CompleteCheckout      command.CompleteCheckoutHandler
+	CloseCheckout         command.CloseCheckoutHandler
+	ProcessWebhookEvent   command.ProcessWebhookEventHandler
+	ReconcilePayments     command.ReconcilePaymentsHandler
+	RegeneratePaymentLink command.RegeneratePaymentLinkHandler
+	IssueCredit           command.IssueCreditHandler
+}
+
+type Queries struct {
+	GetPayment        query.GetPaymentHandler
+	GetCreditAccounts query.GetCreditAccountsHandler
 }
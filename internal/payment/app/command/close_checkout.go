@@ -0,0 +1,201 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/broker"
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/logging"
+	"github.com/furutachiKurea/gorder/payment/domain"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+)
+
+// CloseCheckout 支付渠道通知支付失败或支付会话过期。
+// SessionID 为空时通过 OrderID 查找该渠道下待支付的记录，用于不携带会话信息的 payment_intent 事件，
+// 此时 Checkout 会话仍可支付，关闭记录后使渠道中的会话失效
+type CloseCheckout struct {
+	Provider  string
+	SessionID string
+	OrderID   string
+	// Outcome 支付的结果，只能为 domain.StatusFailed 或 domain.StatusExpired
+	Outcome  domain.Status
+	Reason   string
+	ClosedAt time.Time
+}
+
+type CloseCheckoutHandler decorator.CommandHandler[CloseCheckout, *domain.Payment]
+
+type closeCheckoutHandler struct {
+	processors  domain.ProcessorRegistry
+	paymentRepo domain.Repository
+	ledger      domain.Ledger
+	publisher   broker.Publisher
+}
+
+func NewCloseCheckoutHandler(
+	processors domain.ProcessorRegistry,
+	paymentRepo domain.Repository,
+	ledger domain.Ledger,
+	publisher broker.Publisher,
+	logger zerolog.Logger,
+	metricsClient decorator.MetricsClient,
+) CloseCheckoutHandler {
+	if processors == nil {
+		panic("processors is nil")
+	}
+
+	if paymentRepo == nil {
+		panic("paymentRepo is nil")
+	}
+
+	if ledger == nil {
+		panic("ledger is nil")
+	}
+
+	if publisher == nil {
+		panic("publisher is nil")
+	}
+
+	return decorator.ApplyCommandDecorators[CloseCheckout, *domain.Payment](
+		closeCheckoutHandler{
+			processors:  processors,
+			paymentRepo: paymentRepo,
+			ledger:      ledger,
+			publisher:   publisher,
+		},
+		logger,
+		metricsClient,
+	)
+}
+
+// Handle 将待支付的记录标记为失败或过期，事务提交后发布 order.payment_failed 或 order.payment_expired 事件，由订单服务释放库存。
+// 与 CompleteCheckout 相同，已经失败或过期的记录重新发布事件，用于补发上一次发布失败的事件；其他状态的记录直接返回。
+// 标记之前先释放订单冻结的礼品卡和商店余额，被替换的记录关闭时不释放，冻结由新的支付记录继续使用
+func (c closeCheckoutHandler) Handle(ctx context.Context, cmd CloseCheckout) (*domain.Payment, error) {
+	var err error
+	defer logging.WhenCommandExecute(ctx, "CloseCheckoutHandler", cmd, err)
+
+	if cmd.Outcome != domain.StatusFailed && cmd.Outcome != domain.StatusExpired {
+		return nil, fmt.Errorf("unsupported checkout outcome %s", cmd.Outcome)
+	}
+
+	payment, err := c.find(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	if payment.Status == domain.StatusFailed || payment.Status == domain.StatusExpired {
+		log.Info().Ctx(ctx).Str("payment_id", payment.ID).Msg("payment already closed, republish")
+		if err = c.publishClosed(ctx, payment); err != nil {
+			return nil, err
+		}
+		return payment, nil
+	}
+
+	if !payment.IsPending() {
+		log.Info().Ctx(ctx).
+			Str("payment_id", payment.ID).
+			Str("status", string(payment.Status)).
+			Str("outcome", string(cmd.Outcome)).
+			Msg("payment is not pending, skip")
+		return payment, nil
+	}
+
+	if err = releaseCredit(ctx, c.ledger, payment.OrderID); err != nil {
+		return nil, err
+	}
+
+	err = c.paymentRepo.Update(ctx, payment.ID, func(ctx context.Context, p *domain.Payment) (*domain.Payment, error) {
+		payment = p
+		if !p.IsPending() {
+			return p, nil
+		}
+
+		if cmd.Outcome == domain.StatusFailed {
+			return p, p.MarkFailed(cmd.Reason, cmd.ClosedAt)
+		}
+		return p, p.MarkExpired(cmd.ClosedAt)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("close payment %s: %w", payment.ID, err)
+	}
+
+	// 记录可能在读取之后被并发地标记为已支付或被替换，此时不发布关闭事件
+	if payment.Status != domain.StatusFailed && payment.Status != domain.StatusExpired {
+		return payment, nil
+	}
+	if cmd.SessionID == "" {
+		c.expireSession(ctx, payment)
+	}
+	if err = c.publishClosed(ctx, payment); err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+// expireSession 使支付失败后仍可支付的会话失效，失效失败只记录日志，会话之后完成支付时记录被标记为需要退款
+func (c closeCheckoutHandler) expireSession(ctx context.Context, p *domain.Payment) {
+	processor, err := c.processors.Get(p.Provider)
+	if err == nil {
+		err = processor.ExpireCheckoutSession(ctx, p.ProviderSessionID)
+	}
+	if err != nil {
+		log.Warn().Ctx(ctx).Err(err).
+			Str("payment_id", p.ID).
+			Str("session_id", p.ProviderSessionID).
+			Msg("expire checkout session of failed payment failed")
+	}
+}
+
+// find 通过支付会话或订单查找支付记录，按订单查找时取该渠道下待支付的记录
+func (c closeCheckoutHandler) find(ctx context.Context, cmd CloseCheckout) (*domain.Payment, error) {
+	if cmd.SessionID != "" {
+		return c.paymentRepo.GetBySession(ctx, cmd.Provider, cmd.SessionID)
+	}
+	if cmd.OrderID == "" {
+		return nil, errors.New("session_id or order_id is required")
+	}
+
+	payments, err := c.paymentRepo.ListByOrderID(ctx, cmd.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range payments {
+		if p.Provider == cmd.Provider && p.IsPending() {
+			return p, nil
+		}
+	}
+
+	return nil, domain.NotFoundError{PaymentID: "pending payment of order " + cmd.OrderID}
+}
+
+func (c closeCheckoutHandler) publishClosed(ctx context.Context, p *domain.Payment) error {
+	exchange := broker.EventOrderPaymentFailed
+	if p.Status == domain.StatusExpired {
+		exchange = broker.EventOrderPaymentExpired
+	}
+
+	ctx, span := otel.Tracer("rabbitmq").Start(ctx, fmt.Sprintf("rabbitmq.%s.publish", exchange))
+	defer span.End()
+
+	if err := broker.PublishEvent(ctx, &broker.PublishEventReq{
+		Publisher: c.publisher,
+		Routing:   broker.FanOut,
+		Queue:     "",
+		Exchange:  exchange,
+		Body:      p.ClosedOrder(),
+		Mandatory: true,
+	}); err != nil {
+		return fmt.Errorf("publish event error exchange=%s, err:%w", exchange, err)
+	}
+
+	log.Info().Ctx(ctx).Str("payment_id", p.ID).Msgf("message published to %s", exchange)
+	return nil
+}
@@ -0,0 +1,185 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/broker"
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/logging"
+	"github.com/furutachiKurea/gorder/payment/domain"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+)
+
+// CompleteCheckout 支付渠道通知支付会话已完成支付
+type CompleteCheckout struct {
+	Provider  string
+	SessionID string
+	IntentID  string
+	PaidAt    time.Time
+}
+
+type CompleteCheckoutHandler decorator.CommandHandler[CompleteCheckout, *domain.Payment]
+
+type completeCheckoutHandler struct {
+	paymentRepo domain.Repository
+	ledger      domain.Ledger
+	publisher   broker.Publisher
+}
+
+func NewCompleteCheckoutHandler(
+	paymentRepo domain.Repository,
+	ledger domain.Ledger,
+	publisher broker.Publisher,
+	logger zerolog.Logger,
+	metricsClient decorator.MetricsClient,
+) CompleteCheckoutHandler {
+	if paymentRepo == nil {
+		panic("paymentRepo is nil")
+	}
+
+	if ledger == nil {
+		panic("ledger is nil")
+	}
+
+	if publisher == nil {
+		panic("publisher is nil")
+	}
+
+	return decorator.ApplyCommandDecorators[CompleteCheckout, *domain.Payment](
+		completeCheckoutHandler{
+			paymentRepo: paymentRepo,
+			ledger:      ledger,
+			publisher:   publisher,
+		},
+		logger,
+		metricsClient,
+	)
+}
+
+// Handle 将支付会话对应的记录标记为已支付，事务提交后使用记录中的订单快照发布 order.paid 事件。
+// 事务可能因为冲突重新执行，事件不在事务内发布。发布失败时返回错误，渠道重试 Webhook 时记录已经是已支付，
+// 重新发布 order.paid，因此同一订单可能收到多次 order.paid：订单服务跳过已经支付的订单，不会重复扣减库存，厨房每个订单只创建一个工单。
+// 标记之前先扣除订单冻结的礼品卡和商店余额，扣除可重复执行。
+// 订单已经由其他记录支付时 (如重新生成支付链接后旧会话也完成了支付)，记录被标记为需要退款，不扣除余额也不发布 order.paid；
+// 已经失败或过期的记录同样标记为需要退款，订单已经释放库存，不能再被支付
+func (c completeCheckoutHandler) Handle(ctx context.Context, cmd CompleteCheckout) (*domain.Payment, error) {
+	var err error
+	defer logging.WhenCommandExecute(ctx, "CompleteCheckoutHandler", cmd, err)
+
+	payment, err := c.paymentRepo.GetBySession(ctx, cmd.Provider, cmd.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if payment.IsPaid() {
+		log.Info().Ctx(ctx).Str("payment_id", payment.ID).Msg("payment already paid, republish")
+		if err = c.publishPaid(ctx, payment); err != nil {
+			return nil, err
+		}
+		return payment, nil
+	}
+	if payment.Status == domain.StatusRefundRequired {
+		log.Info().Ctx(ctx).Str("payment_id", payment.ID).Msg("payment already requires refund, skip")
+		return payment, nil
+	}
+
+	if payment.Status == domain.StatusFailed || payment.Status == domain.StatusExpired {
+		log.Warn().Ctx(ctx).
+			Str("payment_id", payment.ID).
+			Str("status", string(payment.Status)).
+			Str("order_id", payment.OrderID).
+			Str("intent_id", cmd.IntentID).
+			Msg("closed payment completed, checkout requires refund")
+		return c.requireRefund(ctx, payment, cmd)
+	}
+
+	paidBy, err := c.paidByOther(ctx, payment)
+	if err != nil {
+		return nil, err
+	}
+	if paidBy != nil {
+		log.Warn().Ctx(ctx).
+			Str("payment_id", payment.ID).
+			Str("paid_by", paidBy.ID).
+			Str("order_id", payment.OrderID).
+			Str("intent_id", cmd.IntentID).
+			Msg("order already paid by another payment, checkout requires refund")
+		return c.requireRefund(ctx, payment, cmd)
+	}
+
+	if err = captureCredit(ctx, c.ledger, payment.OrderID); err != nil {
+		return nil, err
+	}
+
+	err = c.paymentRepo.Update(ctx, payment.ID, func(ctx context.Context, p *domain.Payment) (*domain.Payment, error) {
+		payment = p
+		if p.IsPaid() {
+			return p, nil
+		}
+		return p, p.MarkPaid(cmd.IntentID, cmd.PaidAt)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("complete payment %s: %w", payment.ID, err)
+	}
+
+	if err = c.publishPaid(ctx, payment); err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+// paidByOther 返回订单中除 payment 之外已支付的记录，没有时返回 nil
+func (c completeCheckoutHandler) paidByOther(ctx context.Context, payment *domain.Payment) (*domain.Payment, error) {
+	payments, err := c.paymentRepo.ListByOrderID(ctx, payment.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("list payments of order %s: %w", payment.OrderID, err)
+	}
+	for _, p := range payments {
+		if p.ID != payment.ID && p.IsPaid() {
+			return p, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// requireRefund 将不能作为订单支付的记录标记为需要退款
+func (c completeCheckoutHandler) requireRefund(ctx context.Context, payment *domain.Payment, cmd CompleteCheckout) (*domain.Payment, error) {
+	err := c.paymentRepo.Update(ctx, payment.ID, func(ctx context.Context, p *domain.Payment) (*domain.Payment, error) {
+		payment = p
+		if p.IsPaid() || p.Status == domain.StatusRefundRequired {
+			return p, nil
+		}
+		return p, p.MarkRefundRequired(cmd.IntentID, cmd.PaidAt)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("mark payment %s refund required: %w", payment.ID, err)
+	}
+
+	return payment, nil
+}
+
+func (c completeCheckoutHandler) publishPaid(ctx context.Context, p *domain.Payment) error {
+	ctx, span := otel.Tracer("rabbitmq").Start(ctx, fmt.Sprintf("rabbitmq.%s.publish", broker.EventOrderPaid))
+	defer span.End()
+
+	if err := broker.PublishEvent(ctx, &broker.PublishEventReq{
+		Publisher: c.publisher,
+		Routing:   broker.FanOut,
+		Queue:     "",
+		Exchange:  broker.EventOrderPaid,
+		Body:      p.PaidOrder(),
+		Mandatory: true,
+	}); err != nil {
+		return fmt.Errorf("publish event error exchange=%s, err:%w", broker.EventOrderPaid, err)
+	}
+
+	log.Info().Ctx(ctx).Str("payment_id", p.ID).Msgf("message published to %s", broker.EventOrderPaid)
+	return nil
+}
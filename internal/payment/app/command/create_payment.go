@@ -2,6 +2,8 @@ package command
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/furutachiKurea/gorder/common/consts"
 	"github.com/furutachiKurea/gorder/common/convertor"
@@ -23,27 +25,27 @@ type CreatePayment struct {
 type CreatePaymentHandler decorator.CommandHandler[CreatePayment, string]
 
 type createPaymentHandler struct {
-	processor domain.Processor
+	checkout  checkout
 	orderGRPC OrderService
 }
 
 func NewCreatePaymentHandler(
-	processor domain.Processor,
+	processors domain.ProcessorRegistry,
+	paymentRepo domain.Repository,
+	ledger domain.Ledger,
+	quoter domain.PriceQuoter,
+	completeCheckout CompleteCheckoutHandler,
 	orderGRPC OrderService,
 	logger zerolog.Logger,
 	metricsClient decorator.MetricsClient,
 ) CreatePaymentHandler {
-	if processor == nil {
-		panic("processor is nil")
-	}
-
 	if orderGRPC == nil {
 		panic("orderGRPC is nil")
 	}
 
 	return decorator.ApplyCommandDecorators[CreatePayment, string](
 		createPaymentHandler{
-			processor: processor,
+			checkout:  newCheckout(processors, paymentRepo, ledger, quoter, completeCheckout),
 			orderGRPC: orderGRPC,
 		},
 		logger,
@@ -51,7 +53,9 @@ func NewCreatePaymentHandler(
 	)
 }
 
-// Handle 创建支付链接并更新订单状态为等待支付，返回支付链接，如果更新订单是失败会同时返回 error
+// Handle 先使用客户的礼品卡和商店余额抵扣，只为剩余金额创建支付会话并保存支付记录，更新订单状态为等待支付，返回支付链接，
+// 如果更新订单是失败会同时返回 error。余额足够支付整个订单时直接完成支付并发布 order.paid，不生成支付链接。
+// 订单已有待支付的记录时直接复用，已完成支付时跳过，避免消息重投时重复创建支付会话
 func (c createPaymentHandler) Handle(ctx context.Context, cmd CreatePayment) (string, error) {
 	var err error
 	defer logging.WhenCommandExecute(ctx, "CreatePaymentHandler", cmd, err)
@@ -59,26 +63,170 @@ func (c createPaymentHandler) Handle(ctx context.Context, cmd CreatePayment) (st
 	ctx, span := tracing.Start(ctx, "createPaymentHandle")
 	defer span.End()
 
-	link, err := c.processor.CreatePaymentLink(ctx, cmd.Order)
+	payment, err := c.payment(ctx, cmd.Order)
 	if err != nil {
 		return "", err
 	}
 
+	if payment.IsPaid() {
+		log.Info().Ctx(ctx).
+			Str("payment_id", payment.ID).
+			Str("provider", payment.Provider).
+			Any("order_id", cmd.Order.ID).
+			Msg("order already paid, skip payment link")
+		return "", nil
+	}
+
 	log.Info().Ctx(ctx).
-		Str("payment_link", link).
+		Str("payment_id", payment.ID).
+		Str("payment_link", payment.PaymentLink).
+		Int64("credit", payment.Credit).
 		Any("order_id", cmd.Order.ID).
 		Msg("create payment link for order")
 
+	err = updateOrderPaymentLink(ctx, c.orderGRPC, cmd.Order, payment.PaymentLink)
+	return payment.PaymentLink, err
+}
+
+// payment 返回订单已支付或待支付的记录，不存在时创建新的支付记录；余额全额支付的待支付记录会被直接完成
+func (c createPaymentHandler) payment(ctx context.Context, order *entity.Order) (*domain.Payment, error) {
+	existing, err := c.checkout.paymentRepo.ListByOrderID(ctx, order.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list payments of order %s: %w", order.ID, err)
+	}
+	for _, p := range existing {
+		if p.IsPaid() {
+			return p, nil
+		}
+	}
+	for _, p := range existing {
+		if p.IsPending() {
+			if p.Provider == domain.ProviderLedger {
+				return c.checkout.completeLedger(ctx, p)
+			}
+			return p, nil
+		}
+	}
+
+	return c.checkout.createPayment(ctx, order, len(existing))
+}
+
+// checkout 创建支付记录，CreatePayment 和 RegeneratePaymentLink 共用
+type checkout struct {
+	processors       domain.ProcessorRegistry
+	paymentRepo      domain.Repository
+	ledger           domain.Ledger
+	quoter           domain.PriceQuoter
+	completeCheckout CompleteCheckoutHandler
+}
+
+func newCheckout(
+	processors domain.ProcessorRegistry,
+	paymentRepo domain.Repository,
+	ledger domain.Ledger,
+	quoter domain.PriceQuoter,
+	completeCheckout CompleteCheckoutHandler,
+) checkout {
+	if processors == nil {
+		panic("processors is nil")
+	}
+
+	if paymentRepo == nil {
+		panic("paymentRepo is nil")
+	}
+
+	if ledger == nil {
+		panic("ledger is nil")
+	}
+
+	if quoter == nil {
+		panic("quoter is nil")
+	}
+
+	if completeCheckout == nil {
+		panic("completeCheckout is nil")
+	}
+
+	return checkout{
+		processors:       processors,
+		paymentRepo:      paymentRepo,
+		ledger:           ledger,
+		quoter:           quoter,
+		completeCheckout: completeCheckout,
+	}
+}
+
+// createPayment 创建待支付的记录，余额足够支付整个订单时直接完成该记录并返回已支付的记录。
+// sequence 为订单已有的支付记录数，消息重投时不变，渠道据此返回同一个支付会话。
+// 完成失败时保留冻结的余额，由消息重投或重新生成支付链接再次完成
+func (c checkout) createPayment(ctx context.Context, order *entity.Order, sequence int) (*domain.Payment, error) {
+	payment, err := c.createPendingPayment(ctx, order, sequence)
+	if err != nil {
+		return nil, err
+	}
+
+	if payment.Provider == domain.ProviderLedger {
+		return c.completeLedger(ctx, payment)
+	}
+	return payment, nil
+}
+
+// createPendingPayment 冻结客户可用的余额，为剩余金额在支付渠道创建支付会话，并保存待支付的记录。
+// 余额足够支付整个订单时不创建支付会话，记录的渠道为 domain.ProviderLedger；失败时释放订单冻结的余额
+func (c checkout) createPendingPayment(ctx context.Context, order *entity.Order, sequence int) (payment *domain.Payment, err error) {
+	req, err := checkoutRequest(ctx, c.ledger, c.quoter, order, sequence)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err == nil || req.Credit == 0 {
+			return
+		}
+		if releaseErr := releaseCredit(ctx, c.ledger, order.ID); releaseErr != nil {
+			log.Warn().Ctx(ctx).Err(releaseErr).Str("order_id", order.ID).Msg("failed to release credit after create payment error")
+		}
+	}()
+
+	var session *domain.CheckoutSession
+	if req.Credit > 0 && req.Credit >= req.Amount {
+		session = domain.LedgerSession(order.ID, req.Currency)
+	} else if session, err = c.processors.CreateCheckoutSession(ctx, req); err != nil {
+		return nil, err
+	}
+
+	pending, err := domain.NewPendingPayment(order, session, req.Credit)
+	if err != nil {
+		return nil, err
+	}
+
+	payment, err = c.paymentRepo.Create(ctx, pending)
+	if err != nil {
+		return nil, fmt.Errorf("create payment: %w", err)
+	}
+
+	return payment, nil
+}
+
+// completeLedger 完成余额全额支付的记录，扣除冻结的余额并发布 order.paid
+func (c checkout) completeLedger(ctx context.Context, payment *domain.Payment) (*domain.Payment, error) {
+	return c.completeCheckout.Handle(ctx, CompleteCheckout{
+		Provider:  payment.Provider,
+		SessionID: payment.ProviderSessionID,
+		PaidAt:    time.Now(),
+	})
+}
+
+// updateOrderPaymentLink 将订单更新为等待支付，并保存新的支付链接
+func updateOrderPaymentLink(ctx context.Context, orderGRPC OrderService, order *entity.Order, paymentLink string) error {
 	newOrder := &orderpb.Order{
-		Id:          cmd.Order.ID,
-		CustomerId:  cmd.Order.CustomerID,
+		Id:          order.ID,
+		CustomerId:  order.CustomerID,
 		Status:      string(consts.OrderStatusWaitingForPayment),
-		Items:       convertor.NewItemConvertor().EntitiesToProtos(cmd.Order.Items),
-		PaymentLink: link,
+		Items:       convertor.NewItemConvertor().EntitiesToProtos(order.Items),
+		PaymentLink: paymentLink,
 	}
 
 	log.Debug().Any("new_order", newOrder).Msg("updating order with payment link")
 
-	err = c.orderGRPC.UpdateOrder(ctx, newOrder)
-	return link, err
+	return orderGRPC.UpdateOrder(ctx, newOrder)
 }
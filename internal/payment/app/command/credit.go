@@ -0,0 +1,182 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/furutachiKurea/gorder/common/entity"
+	"github.com/furutachiKurea/gorder/payment/domain"
+
+	"github.com/rs/zerolog/log"
+)
+
+// checkoutRequest 为订单冻结客户可用的礼品卡和商店余额，返回创建支付会话的请求。
+// 订单已有生效的冻结时直接复用，避免重新生成支付链接或消息重投时重复冻结；客户没有可用余额时不报价，Amount 为 0
+func checkoutRequest(
+	ctx context.Context,
+	ledger domain.Ledger,
+	quoter domain.PriceQuoter,
+	order *entity.Order,
+	sequence int,
+) (*domain.CheckoutRequest, error) {
+	req := &domain.CheckoutRequest{Order: order, Sequence: sequence}
+
+	holds, err := activeHolds(ctx, ledger, order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var accounts []*domain.Account
+	if len(holds) == 0 {
+		if accounts, err = ledger.ListAccounts(ctx, order.CustomerID); err != nil {
+			return nil, fmt.Errorf("list ledger accounts of customer %s: %w", order.CustomerID, err)
+		}
+		if !hasBalance(accounts) {
+			return req, nil
+		}
+	}
+
+	quote, err := quoter.Quote(ctx, order.Items)
+	if err != nil {
+		return nil, fmt.Errorf("quote order %s: %w", order.ID, err)
+	}
+	req.Amount, req.Currency = quote.Amount, quote.Currency
+
+	if len(holds) > 0 {
+		for _, h := range holds {
+			req.Credit += h.Amount
+		}
+		return req, nil
+	}
+
+	req.Credit, err = holdCredit(ctx, ledger, order.ID, quote, accounts)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// holdCredit 按礼品卡优先、创建时间从早到晚的顺序，从与报价币种相同的账户中冻结不超过订单总额的余额，返回冻结的总额。
+// 冻结失败时释放本次已冻结的余额
+func holdCredit(
+	ctx context.Context,
+	ledger domain.Ledger,
+	orderID string,
+	quote *domain.Quote,
+	accounts []*domain.Account,
+) (int64, error) {
+	sort.SliceStable(accounts, func(i, j int) bool {
+		return accounts[i].Type == domain.AccountGiftCard && accounts[j].Type != domain.AccountGiftCard
+	})
+
+	var (
+		credit int64
+		held   []*domain.Hold
+	)
+	for _, a := range accounts {
+		remaining := quote.Amount - credit
+		if remaining <= 0 {
+			break
+		}
+		if a.Currency != quote.Currency || a.Balance <= 0 {
+			continue
+		}
+
+		h, err := ledger.Hold(ctx, a.ID, orderID, min(a.Balance, remaining))
+		if err != nil {
+			releaseHolds(ctx, ledger, held)
+			return 0, fmt.Errorf("hold credit of account %s for order %s: %w", a.ID, orderID, err)
+		}
+		held = append(held, h)
+		credit += h.Amount
+	}
+
+	if credit > 0 {
+		log.Info().Ctx(ctx).
+			Str("order_id", orderID).
+			Int64("credit", credit).
+			Int64("amount", quote.Amount).
+			Msg("credit held for order")
+	}
+	return credit, nil
+}
+
+// captureCredit 扣除订单仍冻结中的余额，已扣除的冻结跳过，可重复调用。
+// 已释放的冻结说明支付会话在过期后仍完成了支付，余额不再扣除，只记录日志
+func captureCredit(ctx context.Context, ledger domain.Ledger, orderID string) error {
+	holds, err := ledger.ListHolds(ctx, orderID)
+	if err != nil {
+		return fmt.Errorf("list credit holds of order %s: %w", orderID, err)
+	}
+
+	for _, h := range holds {
+		switch h.Status {
+		case domain.HoldStatusHeld:
+			if err := ledger.Capture(ctx, h.ID); err != nil {
+				return fmt.Errorf("capture credit hold %s: %w", h.ID, err)
+			}
+		case domain.HoldStatusReleased:
+			log.Warn().Ctx(ctx).
+				Str("order_id", orderID).
+				Str("hold_id", h.ID).
+				Int64("amount", h.Amount).
+				Msg("credit hold already released, order paid without credit")
+		}
+	}
+
+	return nil
+}
+
+// releaseCredit 释放订单仍冻结中的余额
+func releaseCredit(ctx context.Context, ledger domain.Ledger, orderID string) error {
+	holds, err := activeHolds(ctx, ledger, orderID)
+	if err != nil {
+		return err
+	}
+
+	for _, h := range holds {
+		if h.Status != domain.HoldStatusHeld {
+			continue
+		}
+		if err := ledger.Release(ctx, h.ID); err != nil {
+			return fmt.Errorf("release credit hold %s: %w", h.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// releaseHolds 尽力释放 holds，失败只记录日志，用于出错后的补偿
+func releaseHolds(ctx context.Context, ledger domain.Ledger, holds []*domain.Hold) {
+	for _, h := range holds {
+		if err := ledger.Release(ctx, h.ID); err != nil {
+			log.Warn().Ctx(ctx).Err(err).Str("hold_id", h.ID).Msg("failed to release credit hold")
+		}
+	}
+}
+
+func activeHolds(ctx context.Context, ledger domain.Ledger, orderID string) ([]*domain.Hold, error) {
+	holds, err := ledger.ListHolds(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("list credit holds of order %s: %w", orderID, err)
+	}
+
+	var active []*domain.Hold
+	for _, h := range holds {
+		if h.IsActive() {
+			active = append(active, h)
+		}
+	}
+	return active, nil
+}
+
+func hasBalance(accounts []*domain.Account) bool {
+	for _, a := range accounts {
+		if a.Balance > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/furutachiKurea/gorder/common/entity"
+	"github.com/furutachiKurea/gorder/payment/adapter"
+	"github.com/furutachiKurea/gorder/payment/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixedQuoter 所有订单报价相同，记录报价次数
+type fixedQuoter struct {
+	quote  domain.Quote
+	quoted int
+}
+
+func (q *fixedQuoter) Quote(context.Context, []*entity.Item) (*domain.Quote, error) {
+	q.quoted++
+	quote := q.quote
+	return &quote, nil
+}
+
+// failingLedger 第 failAt 次冻结失败
+type failingLedger struct {
+	*adapter.MemoryLedger
+	failAt int
+	holds  int
+}
+
+func (l *failingLedger) Hold(ctx context.Context, accountID, orderID string, amount int64) (*domain.Hold, error) {
+	l.holds++
+	if l.holds == l.failAt {
+		return nil, errors.New("hold failed")
+	}
+	return l.MemoryLedger.Hold(ctx, accountID, orderID, amount)
+}
+
+func TestCheckoutRequest_NoBalance(t *testing.T) {
+	ctx := context.Background()
+	quoter := &fixedQuoter{quote: domain.Quote{Amount: 600, Currency: "usd"}}
+	order := &entity.Order{ID: "order", CustomerID: "customer"}
+
+	req, err := checkoutRequest(ctx, adapter.NewMemoryLedger(), quoter, order, 1)
+	require.NoError(t, err)
+	assert.Equal(t, &domain.CheckoutRequest{Order: order, Sequence: 1}, req)
+	assert.Zero(t, quoter.quoted)
+}
+
+func TestCheckoutRequest_HoldsGiftCardFirst(t *testing.T) {
+	ctx := context.Background()
+	ledger := adapter.NewMemoryLedger()
+	quoter := &fixedQuoter{quote: domain.Quote{Amount: 600, Currency: "usd"}}
+	order := &entity.Order{ID: "order", CustomerID: "customer"}
+
+	storeCredit, err := ledger.Issue(ctx, domain.AccountStoreCredit, "customer", "usd", 500)
+	require.NoError(t, err)
+	giftCard, err := ledger.Issue(ctx, domain.AccountGiftCard, "customer", "usd", 300)
+	require.NoError(t, err)
+	// 币种与报价不同的账户不参与抵扣
+	_, err = ledger.Issue(ctx, domain.AccountGiftCard, "customer", "eur", 1000)
+	require.NoError(t, err)
+
+	req, err := checkoutRequest(ctx, ledger, quoter, order, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(600), req.Amount)
+	assert.Equal(t, "usd", req.Currency)
+	assert.Equal(t, int64(600), req.Credit)
+
+	holds, err := ledger.ListHolds(ctx, "order")
+	require.NoError(t, err)
+	require.Len(t, holds, 2)
+	assert.Equal(t, giftCard.ID, holds[0].AccountID)
+	assert.Equal(t, int64(300), holds[0].Amount)
+	assert.Equal(t, storeCredit.ID, holds[1].AccountID)
+	assert.Equal(t, int64(300), holds[1].Amount)
+
+	// 重新生成支付链接时复用已有的冻结
+	req, err = checkoutRequest(ctx, ledger, quoter, order, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(600), req.Credit)
+	holds, err = ledger.ListHolds(ctx, "order")
+	require.NoError(t, err)
+	assert.Len(t, holds, 2)
+
+	account, err := ledger.GetAccount(ctx, storeCredit.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(200), account.Balance)
+	assert.Equal(t, int64(300), account.Held)
+}
+
+func TestHoldCredit_ReleaseOnFailure(t *testing.T) {
+	ctx := context.Background()
+	ledger := &failingLedger{MemoryLedger: adapter.NewMemoryLedger(), failAt: 2}
+
+	giftCard, err := ledger.Issue(ctx, domain.AccountGiftCard, "customer", "usd", 300)
+	require.NoError(t, err)
+	_, err = ledger.Issue(ctx, domain.AccountStoreCredit, "customer", "usd", 500)
+	require.NoError(t, err)
+	accounts, err := ledger.ListAccounts(ctx, "customer")
+	require.NoError(t, err)
+
+	_, err = holdCredit(ctx, ledger, "order", &domain.Quote{Amount: 600, Currency: "usd"}, accounts)
+	require.Error(t, err)
+
+	// 第一笔冻结被释放，余额回到礼品卡
+	holds, err := ledger.ListHolds(ctx, "order")
+	require.NoError(t, err)
+	require.Len(t, holds, 1)
+	assert.Equal(t, domain.HoldStatusReleased, holds[0].Status)
+
+	account, err := ledger.GetAccount(ctx, giftCard.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(300), account.Balance)
+	assert.Zero(t, account.Held)
+}
+
+func TestCaptureCredit(t *testing.T) {
+	ctx := context.Background()
+	ledger := adapter.NewMemoryLedger()
+
+	account, err := ledger.Issue(ctx, domain.AccountStoreCredit, "customer", "usd", 500)
+	require.NoError(t, err)
+	_, err = holdCredit(ctx, ledger, "order", &domain.Quote{Amount: 200, Currency: "usd"}, []*domain.Account{account})
+	require.NoError(t, err)
+
+	// 扣除可以重复执行
+	require.NoError(t, captureCredit(ctx, ledger, "order"))
+	require.NoError(t, captureCredit(ctx, ledger, "order"))
+
+	got, err := ledger.GetAccount(ctx, account.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(300), got.Balance)
+	assert.Zero(t, got.Held)
+
+	// 已扣除的冻结不会被释放
+	require.NoError(t, releaseCredit(ctx, ledger, "order"))
+	holds, err := ledger.ListHolds(ctx, "order")
+	require.NoError(t, err)
+	require.Len(t, holds, 1)
+	assert.Equal(t, domain.HoldStatusCaptured, holds[0].Status)
+}
@@ -0,0 +1,52 @@
+package command
+
+import (
+	"context"
+
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/logging"
+	"github.com/furutachiKurea/gorder/common/tracing"
+	"github.com/furutachiKurea/gorder/payment/domain"
+
+	"github.com/rs/zerolog"
+)
+
+// IssueCredit 发行礼品卡或为客户增加商店余额，Amount 以货币最小单位表示
+type IssueCredit struct {
+	Type       domain.AccountType
+	CustomerID string
+	Currency   string
+	Amount     int64
+}
+
+type IssueCreditHandler decorator.CommandHandler[IssueCredit, *domain.Account]
+
+type issueCreditHandler struct {
+	ledger domain.Ledger
+}
+
+func NewIssueCreditHandler(
+	ledger domain.Ledger,
+	logger zerolog.Logger,
+	metricsClient decorator.MetricsClient,
+) IssueCreditHandler {
+	if ledger == nil {
+		panic("ledger is nil")
+	}
+
+	return decorator.ApplyCommandDecorators[IssueCredit, *domain.Account](
+		issueCreditHandler{ledger: ledger},
+		logger,
+		metricsClient,
+	)
+}
+
+func (h issueCreditHandler) Handle(ctx context.Context, cmd IssueCredit) (*domain.Account, error) {
+	var err error
+	defer logging.WhenCommandExecute(ctx, "IssueCreditHandler", cmd, err)
+
+	ctx, span := tracing.Start(ctx, "issueCreditHandler")
+	defer span.End()
+
+	return h.ledger.Issue(ctx, cmd.Type, cmd.CustomerID, cmd.Currency, cmd.Amount)
+}
@@ -0,0 +1,103 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/logging"
+	"github.com/furutachiKurea/gorder/payment/domain"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// ProcessWebhookEvent 对支付渠道投递的一次 Webhook 事件去重后执行 Handle
+type ProcessWebhookEvent struct {
+	EventID   string
+	EventType string
+	// ObjectID 事件所属的渠道对象，如支付会话 ID，用于识别同一对象乱序到达的事件
+	ObjectID  string
+	CreatedAt time.Time
+	Handle    func(ctx context.Context) error `json:"-"`
+}
+
+type ProcessWebhookEventResult struct {
+	Duplicate  bool
+	OutOfOrder bool
+}
+
+type ProcessWebhookEventHandler decorator.CommandHandler[ProcessWebhookEvent, *ProcessWebhookEventResult]
+
+type processWebhookEventHandler struct {
+	eventStore    domain.WebhookEventStore
+	metricsClient decorator.MetricsClient
+}
+
+func NewProcessWebhookEventHandler(
+	eventStore domain.WebhookEventStore,
+	logger zerolog.Logger,
+	metricsClient decorator.MetricsClient,
+) ProcessWebhookEventHandler {
+	if eventStore == nil {
+		panic("eventStore is nil")
+	}
+
+	return decorator.ApplyCommandDecorators[ProcessWebhookEvent, *ProcessWebhookEventResult](
+		processWebhookEventHandler{
+			eventStore:    eventStore,
+			metricsClient: metricsClient,
+		},
+		logger,
+		metricsClient,
+	)
+}
+
+// Handle 已处理过的事件直接返回 Duplicate，不再执行 Handle；
+// 早于同一对象已处理事件的事件仍会执行，由支付记录的状态流转保证不会回退，只记录乱序指标。
+// Handle 失败时释放事件 ID，渠道重试时可以再次处理
+func (h processWebhookEventHandler) Handle(ctx context.Context, cmd ProcessWebhookEvent) (*ProcessWebhookEventResult, error) {
+	var err error
+	defer logging.WhenCommandExecute(ctx, "ProcessWebhookEventHandler", cmd, err)
+
+	if cmd.EventID == "" || cmd.Handle == nil {
+		return nil, errors.New("empty webhook event")
+	}
+
+	claimed, err := h.eventStore.Claim(ctx, cmd.EventID)
+	if err != nil {
+		return nil, fmt.Errorf("claim webhook event %s: %w", cmd.EventID, err)
+	}
+	if !claimed {
+		h.metricsClient.Inc("webhook.duplicate", 1)
+		log.Info().Ctx(ctx).Str("event_id", cmd.EventID).Str("event_type", cmd.EventType).Msg("duplicate webhook event, skip")
+		return &ProcessWebhookEventResult{Duplicate: true}, nil
+	}
+
+	result := &ProcessWebhookEventResult{}
+	if cmd.ObjectID != "" {
+		inOrder, err := h.eventStore.Advance(ctx, cmd.ObjectID, cmd.CreatedAt)
+		if err != nil {
+			log.Warn().Ctx(ctx).Err(err).Str("object_id", cmd.ObjectID).Msg("advance webhook object failed")
+		} else if !inOrder {
+			result.OutOfOrder = true
+			h.metricsClient.Inc("webhook.out_of_order", 1)
+			log.Warn().Ctx(ctx).
+				Str("event_id", cmd.EventID).
+				Str("event_type", cmd.EventType).
+				Str("object_id", cmd.ObjectID).
+				Msg("webhook event arrived out of order")
+		}
+	}
+
+	if err = cmd.Handle(ctx); err != nil {
+		if releaseErr := h.eventStore.Release(ctx, cmd.EventID); releaseErr != nil {
+			log.Warn().Ctx(ctx).Err(releaseErr).Str("event_id", cmd.EventID).Msg("release webhook event failed")
+		}
+		return nil, err
+	}
+
+	return result, nil
+}
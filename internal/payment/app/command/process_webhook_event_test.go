@@ -0,0 +1,125 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/metrics"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryEventStore 与 Redis 实现语义相同的内存事件存储
+type memoryEventStore struct {
+	mu      sync.Mutex
+	claimed map[string]struct{}
+	latest  map[string]time.Time
+}
+
+func newMemoryEventStore() *memoryEventStore {
+	return &memoryEventStore{
+		claimed: make(map[string]struct{}),
+		latest:  make(map[string]time.Time),
+	}
+}
+
+func (s *memoryEventStore) Claim(_ context.Context, eventID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.claimed[eventID]; ok {
+		return false, nil
+	}
+	s.claimed[eventID] = struct{}{}
+	return true, nil
+}
+
+func (s *memoryEventStore) Release(_ context.Context, eventID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.claimed, eventID)
+	return nil
+}
+
+func (s *memoryEventStore) Advance(_ context.Context, objectID string, createdAt time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if createdAt.Before(s.latest[objectID]) {
+		return false, nil
+	}
+	s.latest[objectID] = createdAt
+	return true, nil
+}
+
+func TestProcessWebhookEvent(t *testing.T) {
+	ctx := context.Background()
+	handler := NewProcessWebhookEventHandler(newMemoryEventStore(), log.Logger, metrics.TodoMetrics{})
+
+	var handled int
+	event := func(id string, createdAt time.Time, err error) ProcessWebhookEvent {
+		return ProcessWebhookEvent{
+			EventID:   id,
+			ObjectID:  "cs_test",
+			CreatedAt: createdAt,
+			Handle: func(context.Context) error {
+				handled++
+				return err
+			},
+		}
+	}
+
+	now := time.Now()
+	result, err := handler.Handle(ctx, event("evt_1", now, nil))
+	require.NoError(t, err)
+	assert.Equal(t, &ProcessWebhookEventResult{}, result)
+	assert.Equal(t, 1, handled)
+
+	// 重复投递的事件不再处理
+	result, err = handler.Handle(ctx, event("evt_1", now, nil))
+	require.NoError(t, err)
+	assert.True(t, result.Duplicate)
+	assert.Equal(t, 1, handled)
+
+	// 早于已处理事件的事件仍然处理，只标记为乱序
+	result, err = handler.Handle(ctx, event("evt_0", now.Add(-time.Minute), nil))
+	require.NoError(t, err)
+	assert.True(t, result.OutOfOrder)
+	assert.Equal(t, 2, handled)
+}
+
+func TestProcessWebhookEvent_ReleaseOnFailure(t *testing.T) {
+	ctx := context.Background()
+	handler := NewProcessWebhookEventHandler(newMemoryEventStore(), log.Logger, metrics.TodoMetrics{})
+
+	errHandle := errors.New("handle failed")
+	var handled int
+	cmd := ProcessWebhookEvent{
+		EventID: "evt_1",
+		Handle: func(context.Context) error {
+			handled++
+			if handled == 1 {
+				return errHandle
+			}
+			return nil
+		},
+	}
+
+	_, err := handler.Handle(ctx, cmd)
+	require.ErrorIs(t, err, errHandle)
+
+	// 处理失败后事件 ID 被释放，渠道重试时再次处理
+	result, err := handler.Handle(ctx, cmd)
+	require.NoError(t, err)
+	assert.False(t, result.Duplicate)
+	assert.Equal(t, 2, handled)
+
+	_, err = handler.Handle(ctx, ProcessWebhookEvent{EventID: "evt_2"})
+	assert.Error(t, err)
+}
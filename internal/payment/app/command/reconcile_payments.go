@@ -0,0 +1,175 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/logging"
+	"github.com/furutachiKurea/gorder/payment/domain"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// ReconcilePayments 对账创建时间超过 OlderThan 仍在等待支付的记录，每次最多处理 Limit 条
+type ReconcilePayments struct {
+	OlderThan time.Duration
+	Limit     int
+}
+
+// Discrepancy 本地支付记录与支付渠道状态不一致的记录
+type Discrepancy struct {
+	PaymentID      string
+	OrderID        string
+	SessionID      string
+	ProviderStatus domain.Status
+	// Resolved 是否已通过与 Webhook 相同的流程修正
+	Resolved bool
+	Err      error
+}
+
+// ReconcileReport 一次对账的结果
+type ReconcileReport struct {
+	StartedAt     time.Time
+	Checked       int
+	StillPending  int
+	Discrepancies []Discrepancy
+}
+
+type ReconcilePaymentsHandler decorator.CommandHandler[ReconcilePayments, *ReconcileReport]
+
+type reconcilePaymentsHandler struct {
+	paymentRepo      domain.Repository
+	processors       domain.ProcessorRegistry
+	completeCheckout CompleteCheckoutHandler
+	closeCheckout    CloseCheckoutHandler
+}
+
+func NewReconcilePaymentsHandler(
+	paymentRepo domain.Repository,
+	processors domain.ProcessorRegistry,
+	completeCheckout CompleteCheckoutHandler,
+	closeCheckout CloseCheckoutHandler,
+	logger zerolog.Logger,
+	metricsClient decorator.MetricsClient,
+) ReconcilePaymentsHandler {
+	if paymentRepo == nil {
+		panic("paymentRepo is nil")
+	}
+
+	if processors == nil {
+		panic("processors is nil")
+	}
+
+	if completeCheckout == nil || closeCheckout == nil {
+		panic("checkout handler is nil")
+	}
+
+	return decorator.ApplyCommandDecorators[ReconcilePayments, *ReconcileReport](
+		reconcilePaymentsHandler{
+			paymentRepo:      paymentRepo,
+			processors:       processors,
+			completeCheckout: completeCheckout,
+			closeCheckout:    closeCheckout,
+		},
+		logger,
+		metricsClient,
+	)
+}
+
+// Handle 向支付渠道查询待支付记录的会话状态，已支付的会话通过 CompleteCheckout 发布 order.paid，
+// 已过期的会话通过 CloseCheckout 发布 order.payment_expired，与 Webhook 走相同的流程。
+// 单条记录失败不会中断对账，失败原因记录在报告中
+func (h reconcilePaymentsHandler) Handle(ctx context.Context, cmd ReconcilePayments) (*ReconcileReport, error) {
+	var err error
+	defer logging.WhenCommandExecute(ctx, "ReconcilePaymentsHandler", cmd, err)
+
+	if cmd.Limit <= 0 {
+		return nil, errors.New("limit must be positive")
+	}
+
+	report := &ReconcileReport{StartedAt: time.Now()}
+	pending, err := h.paymentRepo.ListPending(ctx, report.StartedAt.Add(-cmd.OlderThan), cmd.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range pending {
+		report.Checked++
+
+		status, err := h.getPaymentStatus(ctx, p)
+		if err != nil {
+			report.Discrepancies = append(report.Discrepancies, Discrepancy{
+				PaymentID: p.ID,
+				OrderID:   p.OrderID,
+				SessionID: p.ProviderSessionID,
+				Err:       err,
+			})
+			continue
+		}
+		if status.Status == domain.StatusPending {
+			report.StillPending++
+			continue
+		}
+
+		d := Discrepancy{
+			PaymentID:      p.ID,
+			OrderID:        p.OrderID,
+			SessionID:      p.ProviderSessionID,
+			ProviderStatus: status.Status,
+		}
+		d.Err = h.resolve(ctx, p, status)
+		d.Resolved = d.Err == nil
+		report.Discrepancies = append(report.Discrepancies, d)
+
+		log.Warn().Ctx(ctx).
+			Str("payment_id", p.ID).
+			Str("order_id", p.OrderID).
+			Str("provider_status", string(status.Status)).
+			Bool("resolved", d.Resolved).
+			Err(d.Err).
+			Msg("payment status differs from provider")
+	}
+
+	return report, nil
+}
+
+// getPaymentStatus 向创建支付会话的渠道查询状态，余额全额支付的记录在冻结余额时已完成支付，只是未能标记为已支付
+func (h reconcilePaymentsHandler) getPaymentStatus(ctx context.Context, p *domain.Payment) (*domain.PaymentStatus, error) {
+	if p.Provider == domain.ProviderLedger {
+		return &domain.PaymentStatus{Status: domain.StatusPaid}, nil
+	}
+
+	processor, err := h.processors.Get(p.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	return processor.GetPaymentStatus(ctx, p.ProviderSessionID)
+}
+
+func (h reconcilePaymentsHandler) resolve(ctx context.Context, p *domain.Payment, status *domain.PaymentStatus) error {
+	now := time.Now()
+	var err error
+	switch status.Status {
+	case domain.StatusPaid:
+		_, err = h.completeCheckout.Handle(ctx, CompleteCheckout{
+			Provider:  p.Provider,
+			SessionID: p.ProviderSessionID,
+			IntentID:  status.IntentID,
+			PaidAt:    now,
+		})
+	case domain.StatusFailed, domain.StatusExpired:
+		_, err = h.closeCheckout.Handle(ctx, CloseCheckout{
+			Provider:  p.Provider,
+			SessionID: p.ProviderSessionID,
+			Outcome:   status.Status,
+			Reason:    "reconciled",
+			ClosedAt:  now,
+		})
+	}
+
+	return err
+}
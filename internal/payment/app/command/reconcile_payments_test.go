@@ -0,0 +1,154 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/entity"
+	"github.com/furutachiKurea/gorder/common/metrics"
+	"github.com/furutachiKurea/gorder/payment/adapter"
+	"github.com/furutachiKurea/gorder/payment/domain"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// statusProcessor 按会话 ID 返回预设的状态，没有预设的会话查询失败
+type statusProcessor struct {
+	domain.Processor
+	statuses map[string]domain.Status
+}
+
+func (p statusProcessor) GetPaymentStatus(_ context.Context, sessionID string) (*domain.PaymentStatus, error) {
+	status, ok := p.statuses[sessionID]
+	if !ok {
+		return nil, errors.New("session not found")
+	}
+	return &domain.PaymentStatus{SessionID: sessionID, Status: status, IntentID: "pi_" + sessionID}, nil
+}
+
+type singleProcessor struct {
+	domain.ProcessorRegistry
+	processor domain.Processor
+}
+
+func (r singleProcessor) Get(string) (domain.Processor, error) {
+	return r.processor, nil
+}
+
+type recordingComplete struct {
+	cmds []CompleteCheckout
+	err  error
+}
+
+func (h *recordingComplete) Handle(_ context.Context, cmd CompleteCheckout) (*domain.Payment, error) {
+	h.cmds = append(h.cmds, cmd)
+	return nil, h.err
+}
+
+type recordingClose struct {
+	cmds []CloseCheckout
+}
+
+func (h *recordingClose) Handle(_ context.Context, cmd CloseCheckout) (*domain.Payment, error) {
+	h.cmds = append(h.cmds, cmd)
+	return nil, nil
+}
+
+func TestReconcilePayments(t *testing.T) {
+	ctx := context.Background()
+	paymentRepo := adapter.NewMemoryPaymentRepository()
+
+	createdAt := time.Now().Add(-time.Hour)
+	for _, session := range []*domain.CheckoutSession{
+		{Provider: "stripe", SessionID: "cs_paid"},
+		{Provider: "stripe", SessionID: "cs_expired"},
+		{Provider: "stripe", SessionID: "cs_pending"},
+		{Provider: "stripe", SessionID: "cs_unknown"},
+		domain.LedgerSession("order_ledger", "usd"),
+	} {
+		p, err := domain.NewPendingPayment(&entity.Order{ID: "order_" + session.SessionID}, session, 0)
+		require.NoError(t, err)
+		p.CreatedAt = createdAt
+		createdAt = createdAt.Add(time.Second)
+		_, err = paymentRepo.Create(ctx, p)
+		require.NoError(t, err)
+	}
+
+	complete := &recordingComplete{}
+	closeCheckout := &recordingClose{}
+	handler := NewReconcilePaymentsHandler(
+		paymentRepo,
+		singleProcessor{processor: statusProcessor{statuses: map[string]domain.Status{
+			"cs_paid":    domain.StatusPaid,
+			"cs_expired": domain.StatusExpired,
+			"cs_pending": domain.StatusPending,
+		}}},
+		complete,
+		closeCheckout,
+		log.Logger,
+		metrics.TodoMetrics{},
+	)
+
+	report, err := handler.Handle(ctx, ReconcilePayments{OlderThan: time.Minute, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 5, report.Checked)
+	assert.Equal(t, 1, report.StillPending)
+
+	byStatus := make(map[string]Discrepancy)
+	for _, d := range report.Discrepancies {
+		byStatus[d.SessionID] = d
+	}
+	require.Len(t, byStatus, 4)
+	assert.True(t, byStatus["cs_paid"].Resolved)
+	assert.True(t, byStatus["cs_expired"].Resolved)
+	assert.Error(t, byStatus["cs_unknown"].Err)
+	assert.False(t, byStatus["cs_unknown"].Resolved)
+	// 余额全额支付的记录不查询渠道，直接补全支付
+	assert.Equal(t, domain.StatusPaid, byStatus["ledger_order_ledger"].ProviderStatus)
+
+	require.Len(t, complete.cmds, 2)
+	assert.Equal(t, "cs_paid", complete.cmds[0].SessionID)
+	assert.Equal(t, "pi_cs_paid", complete.cmds[0].IntentID)
+	assert.Equal(t, "ledger_order_ledger", complete.cmds[1].SessionID)
+
+	require.Len(t, closeCheckout.cmds, 1)
+	assert.Equal(t, "cs_expired", closeCheckout.cmds[0].SessionID)
+	assert.Equal(t, domain.StatusExpired, closeCheckout.cmds[0].Outcome)
+}
+
+func TestReconcilePayments_ResolveFailed(t *testing.T) {
+	ctx := context.Background()
+	paymentRepo := adapter.NewMemoryPaymentRepository()
+
+	p, err := domain.NewPendingPayment(&entity.Order{ID: "order"}, &domain.CheckoutSession{Provider: "stripe", SessionID: "cs_paid"}, 0)
+	require.NoError(t, err)
+	p.CreatedAt = time.Now().Add(-time.Hour)
+	_, err = paymentRepo.Create(ctx, p)
+	require.NoError(t, err)
+
+	// 修正失败记录在报告中，不中断对账
+	complete := &recordingComplete{err: errors.New("publish failed")}
+	handler := NewReconcilePaymentsHandler(
+		paymentRepo,
+		singleProcessor{processor: statusProcessor{statuses: map[string]domain.Status{"cs_paid": domain.StatusPaid}}},
+		complete,
+		&recordingClose{},
+		log.Logger,
+		metrics.TodoMetrics{},
+	)
+
+	report, err := handler.Handle(ctx, ReconcilePayments{OlderThan: time.Minute, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, report.Discrepancies, 1)
+	assert.False(t, report.Discrepancies[0].Resolved)
+	assert.ErrorContains(t, report.Discrepancies[0].Err, "publish failed")
+
+	// 创建时间未超过 OlderThan 的记录不对账
+	report, err = handler.Handle(ctx, ReconcilePayments{OlderThan: 2 * time.Hour, Limit: 10})
+	require.NoError(t, err)
+	assert.Zero(t, report.Checked)
+}
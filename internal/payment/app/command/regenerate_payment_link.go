@@ -0,0 +1,156 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/entity"
+	"github.com/furutachiKurea/gorder/common/logging"
+	"github.com/furutachiKurea/gorder/common/tracing"
+	"github.com/furutachiKurea/gorder/payment/domain"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// RegeneratePaymentLink 为订单重新生成支付链接，Order 为订单服务中等待支付的订单
+type RegeneratePaymentLink struct {
+	Order *entity.Order
+}
+
+type RegeneratePaymentLinkHandler decorator.CommandHandler[RegeneratePaymentLink, *domain.Payment]
+
+type regeneratePaymentLinkHandler struct {
+	checkout  checkout
+	orderGRPC OrderService
+}
+
+func NewRegeneratePaymentLinkHandler(
+	processors domain.ProcessorRegistry,
+	paymentRepo domain.Repository,
+	ledger domain.Ledger,
+	quoter domain.PriceQuoter,
+	completeCheckout CompleteCheckoutHandler,
+	orderGRPC OrderService,
+	logger zerolog.Logger,
+	metricsClient decorator.MetricsClient,
+) RegeneratePaymentLinkHandler {
+	if orderGRPC == nil {
+		panic("orderGRPC is nil")
+	}
+
+	return decorator.ApplyCommandDecorators[RegeneratePaymentLink, *domain.Payment](
+		regeneratePaymentLinkHandler{
+			checkout:  newCheckout(processors, paymentRepo, ledger, quoter, completeCheckout),
+			orderGRPC: orderGRPC,
+		},
+		logger,
+		metricsClient,
+	)
+}
+
+// Handle 使订单待支付的会话失效并创建新的支付会话，通过 OrderService.UpdateOrder 将新的支付链接保存到订单。
+// 订单已有支付完成的记录，或者待支付的会话已在支付渠道完成支付时返回 domain.AlreadyPaidError。
+// 新的支付会话复用订单已冻结的余额；余额足够支付整个订单时直接完成支付，返回的记录没有支付链接，订单通过 order.paid 更新
+func (h regeneratePaymentLinkHandler) Handle(ctx context.Context, cmd RegeneratePaymentLink) (*domain.Payment, error) {
+	var err error
+	defer logging.WhenCommandExecute(ctx, "RegeneratePaymentLinkHandler", cmd, err)
+
+	ctx, span := tracing.Start(ctx, "regeneratePaymentLinkHandler")
+	defer span.End()
+
+	if cmd.Order == nil || cmd.Order.ID == "" {
+		return nil, errors.New("empty order")
+	}
+
+	existing, err := h.checkout.paymentRepo.ListByOrderID(ctx, cmd.Order.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list payments of order %s: %w", cmd.Order.ID, err)
+	}
+	for _, p := range existing {
+		if p.IsPaid() {
+			return nil, domain.AlreadyPaidError{OrderID: cmd.Order.ID}
+		}
+	}
+	for _, p := range existing {
+		if p.IsPending() && p.Provider == domain.ProviderLedger {
+			return h.checkout.completeLedger(ctx, p)
+		}
+	}
+	for _, p := range existing {
+		if p.IsPending() {
+			if err = h.replace(ctx, p); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	payment, err := h.checkout.createPayment(ctx, cmd.Order, len(existing))
+	if err != nil {
+		return nil, err
+	}
+	if payment.IsPaid() {
+		log.Info().Ctx(ctx).
+			Str("payment_id", payment.ID).
+			Str("order_id", cmd.Order.ID).
+			Msg("order paid by credit, skip payment link")
+		return payment, nil
+	}
+
+	log.Info().Ctx(ctx).
+		Str("payment_id", payment.ID).
+		Str("payment_link", payment.PaymentLink).
+		Str("order_id", cmd.Order.ID).
+		Msg("regenerate payment link for order")
+
+	if err = updateOrderPaymentLink(ctx, h.orderGRPC, cmd.Order, payment.PaymentLink); err != nil {
+		return nil, fmt.Errorf("update order payment link: %w", err)
+	}
+
+	return payment, nil
+}
+
+// replace 先将待支付的记录标记为已替换，再使支付渠道中的会话失效，
+// 这样会话失效后渠道推送的过期事件不会关闭订单。会话失效失败时只记录日志，被替换的记录仍然可以完成支付
+func (h regeneratePaymentLinkHandler) replace(ctx context.Context, payment *domain.Payment) error {
+	processor, err := h.checkout.processors.Get(payment.Provider)
+	if err != nil {
+		return err
+	}
+
+	status, err := processor.GetPaymentStatus(ctx, payment.ProviderSessionID)
+	if err != nil {
+		return fmt.Errorf("get status of payment %s: %w", payment.ID, err)
+	}
+	if status.Status == domain.StatusPaid {
+		return domain.AlreadyPaidError{OrderID: payment.OrderID}
+	}
+
+	err = h.checkout.paymentRepo.Update(ctx, payment.ID, func(_ context.Context, p *domain.Payment) (*domain.Payment, error) {
+		if !p.IsPending() {
+			return p, nil
+		}
+		if err := p.MarkReplaced(time.Now()); err != nil {
+			return nil, err
+		}
+		return p, nil
+	})
+	if err != nil {
+		return fmt.Errorf("replace payment %s: %w", payment.ID, err)
+	}
+
+	if status.Status != domain.StatusPending {
+		return nil
+	}
+	if err = processor.ExpireCheckoutSession(ctx, payment.ProviderSessionID); err != nil {
+		log.Warn().Ctx(ctx).Err(err).
+			Str("payment_id", payment.ID).
+			Str("session_id", payment.ProviderSessionID).
+			Msg("expire replaced checkout session failed")
+	}
+
+	return nil
+}
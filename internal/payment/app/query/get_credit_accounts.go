@@ -0,0 +1,63 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/tracing"
+	"github.com/furutachiKurea/gorder/payment/domain"
+
+	"github.com/rs/zerolog"
+)
+
+// GetCreditAccounts 按账户 ID 或客户 ID 查询礼品卡和商店余额账户，AccountID 优先
+type GetCreditAccounts struct {
+	AccountID  string
+	CustomerID string
+}
+
+type GetCreditAccountsHandler decorator.QueryHandler[GetCreditAccounts, []*domain.Account]
+
+type getCreditAccountsHandler struct {
+	ledger domain.Ledger
+}
+
+func NewGetCreditAccountsHandler(
+	ledger domain.Ledger,
+	logger zerolog.Logger,
+	metricsClient decorator.MetricsClient,
+) GetCreditAccountsHandler {
+	if ledger == nil {
+		panic("ledger is nil")
+	}
+
+	return decorator.ApplyQueryDecorators[GetCreditAccounts, []*domain.Account](
+		getCreditAccountsHandler{ledger: ledger},
+		logger,
+		metricsClient,
+	)
+}
+
+func (g getCreditAccountsHandler) Handle(ctx context.Context, query GetCreditAccounts) ([]*domain.Account, error) {
+	ctx, span := tracing.Start(ctx, "getCreditAccountsHandler")
+	defer span.End()
+
+	switch {
+	case query.AccountID != "":
+		account, err := g.ledger.GetAccount(ctx, query.AccountID)
+		if err != nil {
+			return nil, fmt.Errorf("get ledger account: %w", err)
+		}
+		return []*domain.Account{account}, nil
+	case query.CustomerID != "":
+		accounts, err := g.ledger.ListAccounts(ctx, query.CustomerID)
+		if err != nil {
+			return nil, fmt.Errorf("list ledger accounts of customer: %w", err)
+		}
+		return accounts, nil
+	default:
+		return nil, errors.New("account_id or customer_id is required")
+	}
+}
@@ -0,0 +1,63 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/tracing"
+	"github.com/furutachiKurea/gorder/payment/domain"
+
+	"github.com/rs/zerolog"
+)
+
+// GetPayment 按支付 ID 或订单 ID 查询支付记录，PaymentID 优先
+type GetPayment struct {
+	PaymentID string
+	OrderID   string
+}
+
+type GetPaymentHandler decorator.QueryHandler[GetPayment, []*domain.Payment]
+
+type getPaymentHandler struct {
+	paymentRepo domain.Repository
+}
+
+func NewGetPaymentHandler(
+	paymentRepo domain.Repository,
+	logger zerolog.Logger,
+	metricsClient decorator.MetricsClient,
+) GetPaymentHandler {
+	if paymentRepo == nil {
+		panic("paymentRepo is nil")
+	}
+
+	return decorator.ApplyQueryDecorators[GetPayment, []*domain.Payment](
+		getPaymentHandler{paymentRepo: paymentRepo},
+		logger,
+		metricsClient,
+	)
+}
+
+func (g getPaymentHandler) Handle(ctx context.Context, query GetPayment) ([]*domain.Payment, error) {
+	ctx, span := tracing.Start(ctx, "getPaymentHandler")
+	defer span.End()
+
+	switch {
+	case query.PaymentID != "":
+		payment, err := g.paymentRepo.Get(ctx, query.PaymentID)
+		if err != nil {
+			return nil, fmt.Errorf("get payment: %w", err)
+		}
+		return []*domain.Payment{payment}, nil
+	case query.OrderID != "":
+		payments, err := g.paymentRepo.ListByOrderID(ctx, query.OrderID)
+		if err != nil {
+			return nil, fmt.Errorf("list payments of order: %w", err)
+		}
+		return payments, nil
+	default:
+		return nil, errors.New("payment_id or order_id is required")
+	}
+}
@@ -0,0 +1,119 @@
+// webhook-emitter 在本地构造并签名 Stripe 格式的 Webhook 事件投递到 payment 服务，不需要 Stripe CLI 和 Stripe 账号。
+//
+// 单个事件:
+//
+//	go run ./cmd/webhook-emitter -order <order_id> -customer <customer_id> -items prod_1:2:1000 -event checkout.session.completed
+//
+// 场景文件按顺序投递一组事件，用于模拟重复投递、乱序和签名错误，示例见 scenarios 目录:
+//
+//	go run ./cmd/webhook-emitter -order <order_id> -scenario ./cmd/webhook-emitter/scenarios/duplicate.yaml
+//
+// 未指定 -session 时通过 GET /api/payments?order_id= 查询订单待支付记录的支付会话；
+// fake 渠道的支付记录需要投递到 -url http://<payment.http-addr>/api/webhook/fake
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/furutachiKurea/gorder/common/config"
+	"github.com/furutachiKurea/gorder/common/logging"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+	"github.com/stripe/stripe-go/v84"
+)
+
+func init() {
+	logging.Init()
+}
+
+func main() {
+	var (
+		url          = flag.String("url", "http://"+viper.GetString("payment.http-addr")+"/api/webhook", "webhook endpoint of the payment service")
+		secret       = flag.String("secret", viper.GetString("endpoint-stripe-secret"), "webhook signing secret, defaults to endpoint-stripe-secret")
+		scenarioPath = flag.String("scenario", "", "scenario file, overrides -event")
+		eventType    = flag.String("event", string(stripe.EventTypeCheckoutSessionCompleted), "event type to send when no scenario is given")
+		orderID      = flag.String("order", "", "order id")
+		customerID   = flag.String("customer", "", "customer id")
+		sessionID    = flag.String("session", "", "checkout session id, looked up from the payment service when empty")
+		items        = flag.String("items", "", "comma separated items, id:quantity[:unit_amount]")
+		currency     = flag.String("currency", viper.GetString("payment.currency"), "currency of the amounts")
+	)
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	scenario := &Scenario{Steps: []Step{{Name: "event", Event: *eventType}}}
+	if *scenarioPath != "" {
+		var err error
+		if scenario, err = LoadScenario(*scenarioPath); err != nil {
+			log.Fatal().Err(err).Str("scenario", *scenarioPath).Msg("failed to load scenario")
+		}
+	}
+
+	parsed, err := parseItems(*items)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid -items")
+	}
+	scenario.Override(Target{
+		OrderID:    *orderID,
+		CustomerID: *customerID,
+		SessionID:  *sessionID,
+		Currency:   *currency,
+		Items:      parsed,
+	})
+
+	if *secret == "" {
+		log.Fatal().Msg("empty webhook secret, set ENDPOINT_STRIPE_SECRET or -secret")
+	}
+
+	emitter := &Emitter{
+		client:    &http.Client{Timeout: 10 * time.Second},
+		url:       *url,
+		secret:    *secret,
+		tolerance: viper.GetDuration("payment.webhook-tolerance"),
+	}
+	results, err := emitter.Run(ctx, scenario)
+	printResults(results)
+	if err != nil {
+		log.Fatal().Err(err).Msg("scenario failed")
+	}
+}
+
+// parseItems 解析 id:quantity[:unit_amount] 格式的商品列表
+func parseItems(s string) ([]Item, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var items []Item
+	for _, spec := range strings.Split(s, ",") {
+		parts := strings.Split(spec, ":")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" {
+			return nil, fmt.Errorf("item %q must be id:quantity[:unit_amount]", spec)
+		}
+
+		item := Item{ID: parts[0]}
+		var err error
+		if item.Quantity, err = strconv.ParseInt(parts[1], 10, 64); err != nil || item.Quantity <= 0 {
+			return nil, fmt.Errorf("invalid quantity in item %q", spec)
+		}
+		if len(parts) == 3 {
+			if item.UnitAmount, err = strconv.ParseInt(parts[2], 10, 64); err != nil {
+				return nil, fmt.Errorf("invalid unit amount in item %q: %w", spec, err)
+			}
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
@@ -0,0 +1,351 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/furutachiKurea/gorder/payment/infrastructure/stripeevent"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+	"github.com/stripe/stripe-go/v84"
+)
+
+// 签名方式: valid 使用配置的密钥和当前时间签名，invalid 使用错误的密钥，stale 的签名时间早于 payment.webhook-tolerance，missing 不携带签名
+const (
+	SignatureValid   = "valid"
+	SignatureInvalid = "invalid"
+	SignatureStale   = "stale"
+	SignatureMissing = "missing"
+)
+
+// Scenario 按顺序投递的一组事件，Target 中的字段可以被命令行参数覆盖
+type Scenario struct {
+	Target `mapstructure:",squash"`
+	Steps  []Step `mapstructure:"steps"`
+}
+
+// Target 事件对应的订单和支付会话
+type Target struct {
+	OrderID    string `mapstructure:"order-id"`
+	CustomerID string `mapstructure:"customer-id"`
+	SessionID  string `mapstructure:"session-id"`
+	// IntentID 为空时生成一个，同一场景中的支付完成和退款事件使用同一个 payment_intent
+	IntentID string `mapstructure:"intent-id"`
+	Currency string `mapstructure:"currency"`
+	Items    []Item `mapstructure:"items"`
+}
+
+type Item struct {
+	ID       string `mapstructure:"id"`
+	Quantity int64  `mapstructure:"quantity"`
+	// UnitAmount 以货币最小单位表示的单价，只用于计算事件中的金额
+	UnitAmount int64 `mapstructure:"unit-amount"`
+}
+
+// Step 场景中的一步：构造 Event 类型的新事件，或者用 Resend 重新投递之前某一步构造的同一个事件(相同的事件 ID 和内容)
+type Step struct {
+	Name  string `mapstructure:"name"`
+	Event string `mapstructure:"event"`
+	// Resend 之前某一步的 Name，用于模拟 Stripe 重试导致的重复投递
+	Resend string `mapstructure:"resend"`
+	// CreatedOffset 事件 created 相对当前时间的偏移，配合 Hold 构造乱序投递
+	CreatedOffset time.Duration `mapstructure:"created-offset"`
+	// Signature 签名方式，默认为 valid
+	Signature string `mapstructure:"signature"`
+	// Hold 只构造事件不投递，由后面的步骤 Resend
+	Hold bool `mapstructure:"hold"`
+	// Delay 投递前等待的时间
+	Delay time.Duration `mapstructure:"delay"`
+	// ExpectStatus 期望的响应状态码，为 0 时不检查
+	ExpectStatus int `mapstructure:"expect-status"`
+}
+
+// LoadScenario 读取 YAML 格式的场景文件
+func LoadScenario(path string) (*Scenario, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	scenario := &Scenario{}
+	if err := v.Unmarshal(scenario); err != nil {
+		return nil, fmt.Errorf("parse scenario: %w", err)
+	}
+	if len(scenario.Steps) == 0 {
+		return nil, errors.New("scenario has no steps")
+	}
+
+	return scenario, nil
+}
+
+// Override 使用非空的字段覆盖场景中的 Target
+func (s *Scenario) Override(t Target) {
+	if t.OrderID != "" {
+		s.OrderID = t.OrderID
+	}
+	if t.CustomerID != "" {
+		s.CustomerID = t.CustomerID
+	}
+	if t.SessionID != "" {
+		s.SessionID = t.SessionID
+	}
+	if t.Currency != "" && s.Currency == "" {
+		s.Currency = t.Currency
+	}
+	if len(t.Items) > 0 {
+		s.Items = t.Items
+	}
+}
+
+// Result 一步的投递结果
+type Result struct {
+	Step    string
+	EventID string
+	Type    string
+	Status  int
+	Body    string
+	Err     error
+}
+
+// Emitter 构造、签名并投递场景中的事件
+type Emitter struct {
+	client    *http.Client
+	url       string
+	secret    string
+	tolerance time.Duration
+}
+
+// Run 按顺序执行场景的每一步，响应状态码与 ExpectStatus 不一致或投递失败时继续执行后续步骤，最后返回错误
+func (e *Emitter) Run(ctx context.Context, s *Scenario) ([]Result, error) {
+	if s.OrderID == "" {
+		return nil, errors.New("order id is required")
+	}
+	if s.IntentID == "" {
+		s.IntentID = stripeevent.NewID("pi_test")
+	}
+	if s.SessionID == "" {
+		sessionID, err := e.lookupSession(ctx, s.OrderID)
+		if err != nil {
+			log.Warn().Err(err).Str("order_id", s.OrderID).Msg("lookup checkout session failed, use a random session id")
+			sessionID = stripeevent.NewID("cs_test")
+		}
+		s.SessionID = sessionID
+	}
+
+	var (
+		built   = make(map[string]*stripeevent.Event)
+		results []Result
+		failed  int
+	)
+	for i, step := range s.Steps {
+		if step.Name == "" {
+			step.Name = fmt.Sprintf("step-%d", i+1)
+		}
+
+		event, err := e.event(step, &s.Target, built)
+		if err != nil {
+			return results, fmt.Errorf("%s: %w", step.Name, err)
+		}
+		built[step.Name] = event
+		if step.Hold {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return results, ctx.Err()
+		case <-time.After(step.Delay):
+		}
+
+		res := Result{Step: step.Name, EventID: event.ID, Type: string(event.Type)}
+		var body []byte
+		res.Status, body, res.Err = stripeevent.Post(ctx, e.client, e.url, event)
+		res.Body = string(body)
+		if res.Err == nil && step.ExpectStatus != 0 && res.Status != step.ExpectStatus {
+			res.Err = fmt.Errorf("expect status %d, got %d", step.ExpectStatus, res.Status)
+		}
+		if res.Err != nil {
+			failed++
+		}
+		results = append(results, res)
+	}
+
+	if failed > 0 {
+		return results, fmt.Errorf("%d of %d steps failed", failed, len(results))
+	}
+	return results, nil
+}
+
+// event 构造一步要投递的事件并按 Signature 签名
+func (e *Emitter) event(step Step, t *Target, built map[string]*stripeevent.Event) (*stripeevent.Event, error) {
+	var event stripeevent.Event
+	if step.Resend != "" {
+		prev, ok := built[step.Resend]
+		if !ok {
+			return nil, fmt.Errorf("resend unknown step %q", step.Resend)
+		}
+		event = *prev
+	} else {
+		object, err := buildObject(stripe.EventType(step.Event), t)
+		if err != nil {
+			return nil, err
+		}
+		created, err := stripeevent.New(stripe.EventType(step.Event), object, e.secret, time.Now().Add(step.CreatedOffset))
+		if err != nil {
+			return nil, err
+		}
+		event = *created
+	}
+
+	// Stripe 重试投递时使用当前时间重新签名
+	now := time.Now()
+	switch step.Signature {
+	case "", SignatureValid:
+		event.Header = stripeevent.Sign(event.Payload, e.secret, now)
+	case SignatureInvalid:
+		event.Header = stripeevent.Sign(event.Payload, e.secret+"_invalid", now)
+	case SignatureStale:
+		event.Header = stripeevent.Sign(event.Payload, e.secret, now.Add(-e.tolerance-time.Minute))
+	case SignatureMissing:
+		event.Header = ""
+	default:
+		return nil, fmt.Errorf("unknown signature %q", step.Signature)
+	}
+
+	return &event, nil
+}
+
+// buildObject 按事件类型构造 data.object，字段与 Stripe 推送的事件一致
+func buildObject(eventType stripe.EventType, t *Target) (any, error) {
+	amount := t.amount()
+	metadata := map[string]string{"order_id": t.OrderID, "customer_id": t.CustomerID}
+	session := stripeevent.CheckoutSession{
+		ID:                t.SessionID,
+		Object:            "checkout.session",
+		ClientReferenceID: t.OrderID,
+		AmountTotal:       amount,
+		Currency:          t.Currency,
+		Metadata:          metadata,
+	}
+
+	switch eventType {
+	case stripe.EventTypeCheckoutSessionCompleted, stripe.EventTypeCheckoutSessionAsyncPaymentSucceeded:
+		session.Status = string(stripe.CheckoutSessionStatusComplete)
+		session.PaymentStatus = string(stripe.CheckoutSessionPaymentStatusPaid)
+		session.PaymentIntent = t.IntentID
+		return session, nil
+	case stripe.EventTypeCheckoutSessionAsyncPaymentFailed:
+		session.Status = string(stripe.CheckoutSessionStatusComplete)
+		session.PaymentStatus = string(stripe.CheckoutSessionPaymentStatusUnpaid)
+		session.PaymentIntent = t.IntentID
+		return session, nil
+	case stripe.EventTypeCheckoutSessionExpired:
+		session.Status = string(stripe.CheckoutSessionStatusExpired)
+		session.PaymentStatus = string(stripe.CheckoutSessionPaymentStatusUnpaid)
+		return session, nil
+	case stripe.EventTypePaymentIntentPaymentFailed:
+		return stripeevent.PaymentIntent{
+			ID:       t.IntentID,
+			Object:   "payment_intent",
+			Amount:   amount,
+			Currency: t.Currency,
+			Status:   string(stripe.PaymentIntentStatusRequiresPaymentMethod),
+			Metadata: map[string]string{"order_id": t.OrderID},
+			LastPaymentError: &stripeevent.PaymentError{
+				Type:    string(stripe.ErrorTypeCard),
+				Code:    string(stripe.ErrorCodeCardDeclined),
+				Message: "Your card was declined.",
+			},
+		}, nil
+	case stripe.EventTypeChargeRefunded:
+		return stripeevent.Charge{
+			ID:             stripeevent.NewID("ch_test"),
+			Object:         "charge",
+			Amount:         amount,
+			AmountRefunded: amount,
+			Currency:       t.Currency,
+			Paid:           true,
+			Refunded:       true,
+			PaymentIntent:  t.IntentID,
+			Status:         string(stripe.ChargeStatusSucceeded),
+			Metadata:       metadata,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported event type %q", eventType)
+	}
+}
+
+func (t *Target) amount() int64 {
+	var amount int64
+	for _, item := range t.Items {
+		amount += item.UnitAmount * item.Quantity
+	}
+	return amount
+}
+
+// lookupSession 通过 payment 服务查询订单最近一条待支付记录的支付会话，没有待支付记录时使用最近一条记录
+func (e *Emitter) lookupSession(ctx context.Context, orderID string) (string, error) {
+	endpoint, err := url.Parse(e.url)
+	if err != nil {
+		return "", err
+	}
+	endpoint.Path = "/api/payments"
+	endpoint.RawQuery = url.Values{"order_id": {orderID}}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("list payments responded %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Payments []struct {
+			Status            string `json:"status"`
+			ProviderSessionID string `json:"provider_session_id"`
+		} `json:"payments"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("decode payments: %w", err)
+	}
+	if len(body.Payments) == 0 {
+		return "", fmt.Errorf("order %s has no payment", orderID)
+	}
+
+	sessionID := body.Payments[len(body.Payments)-1].ProviderSessionID
+	for _, p := range body.Payments {
+		if p.Status == "pending" {
+			sessionID = p.ProviderSessionID
+		}
+	}
+	return sessionID, nil
+}
+
+func printResults(results []Result) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "STEP\tEVENT\tTYPE\tSTATUS\tRESULT")
+	for _, r := range results {
+		result := r.Body
+		if r.Err != nil {
+			result = "FAIL: " + r.Err.Error()
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", r.Step, r.EventID, r.Type, r.Status, result)
+	}
+	_ = w.Flush()
+}
@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// AccountType 余额账户类型
+type AccountType string
+
+const (
+	// AccountGiftCard 礼品卡，每次发行生成一个带兑换码的账户
+	AccountGiftCard AccountType = "gift_card"
+	// AccountStoreCredit 商店余额，每个客户每个币种一个账户，发行时累加余额
+	AccountStoreCredit AccountType = "store_credit"
+)
+
+// Account 礼品卡或商店余额账户，Balance 为可用余额，被冻结的金额从 Balance 转入 Held
+type Account struct {
+	ID         string
+	Type       AccountType
+	CustomerID string
+	// Code 礼品卡兑换码，商店余额为空
+	Code     string
+	Currency string
+	// Balance, Held 以货币最小单位表示
+	Balance   int64
+	Held      int64
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// HoldStatus 冻结状态
+type HoldStatus string
+
+const (
+	HoldStatusHeld     HoldStatus = "held"
+	HoldStatusCaptured HoldStatus = "captured"
+	HoldStatusReleased HoldStatus = "released"
+)
+
+// Hold 为订单冻结的账户余额，支付完成后扣除，支付失败或过期后释放
+type Hold struct {
+	ID        string
+	AccountID string
+	OrderID   string
+	Amount    int64
+	Currency  string
+	Status    HoldStatus
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// IsActive 冻结是否仍占用余额或已经扣除
+func (h *Hold) IsActive() bool {
+	return h.Status == HoldStatusHeld || h.Status == HoldStatusCaptured
+}
+
+// EntryType 账本流水类型
+type EntryType string
+
+const (
+	EntryIssue   EntryType = "issue"
+	EntryHold    EntryType = "hold"
+	EntryCapture EntryType = "capture"
+	EntryRelease EntryType = "release"
+)
+
+// Entry 账本流水，每次余额变动记录一条，用于对账
+type Entry struct {
+	ID        string
+	AccountID string
+	HoldID    string
+	OrderID   string
+	Type      EntryType
+	Amount    int64
+	CreatedAt time.Time
+}
+
+// Ledger 礼品卡和商店余额账本，余额变动与流水在同一事务中写入
+type Ledger interface {
+	// Issue 发行礼品卡或为客户增加商店余额，返回余额变动后的账户
+	Issue(ctx context.Context, accountType AccountType, customerID, currency string, amount int64) (*Account, error)
+	GetAccount(ctx context.Context, accountID string) (*Account, error)
+	// ListAccounts 获取客户的所有账户，按创建时间排序
+	ListAccounts(ctx context.Context, customerID string) ([]*Account, error)
+	// Hold 为订单冻结账户中的 amount，可用余额不足时返回 InsufficientBalanceError
+	Hold(ctx context.Context, accountID, orderID string, amount int64) (*Hold, error)
+	// ListHolds 获取订单的所有冻结，按创建时间排序
+	ListHolds(ctx context.Context, orderID string) ([]*Hold, error)
+	// Capture 扣除冻结的金额，已扣除的冻结直接返回，已释放的冻结返回错误
+	Capture(ctx context.Context, holdID string) error
+	// Release 将冻结的金额退回可用余额，已释放的冻结直接返回，已扣除的冻结返回错误
+	Release(ctx context.Context, holdID string) error
+}
+
+type AccountNotFoundError struct {
+	AccountID string
+}
+
+func (e AccountNotFoundError) Error() string {
+	return "ledger account " + e.AccountID + " not found"
+}
+
+type HoldNotFoundError struct {
+	HoldID string
+}
+
+func (e HoldNotFoundError) Error() string {
+	return "ledger hold " + e.HoldID + " not found"
+}
+
+type InsufficientBalanceError struct {
+	AccountID string
+	Want      int64
+}
+
+func (e InsufficientBalanceError) Error() string {
+	return fmt.Sprintf("ledger account %s balance is less than %d", e.AccountID, e.Want)
+}
@@ -2,13 +2,41 @@ package domain
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
+	"github.com/furutachiKurea/gorder/common/consts"
 	"github.com/furutachiKurea/gorder/common/entity"
 )
 
 type Processor interface {
-	CreatePaymentLink(ctx context.Context, order *entity.Order) (string, error)
+	// CreateCheckoutSession 在支付渠道创建支付会话，req.Credit 不为 0 时只收取抵扣后的剩余金额
+	CreateCheckoutSession(ctx context.Context, req *CheckoutRequest) (*CheckoutSession, error)
+	// GetPaymentStatus 从支付渠道查询支付会话的状态，用于补偿丢失的 Webhook
+	GetPaymentStatus(ctx context.Context, sessionID string) (*PaymentStatus, error)
+	// ExpireCheckoutSession 使支付渠道中仍可支付的会话失效
+	ExpireCheckoutSession(ctx context.Context, sessionID string) error
 }
+
+// PaymentStatus 支付渠道中支付会话的状态
+type PaymentStatus struct {
+	SessionID string
+	// Status 会话对应的支付状态，仍可支付的会话为 StatusPending
+	Status   Status
+	IntentID string
+}
+
+// CheckoutSession 支付渠道返回的支付会话
+type CheckoutSession struct {
+	Provider  string
+	SessionID string
+	URL       string
+	// Amount 以货币最小单位表示的金额
+	Amount   int64
+	Currency string
+}
+
 type Order struct {
 	ID          string
 	CustomerID  string
@@ -16,3 +44,193 @@ type Order struct {
 	PaymentLink string
 	Items       []*entity.Item
 }
+
+// ProviderLedger 订单全部由礼品卡和商店余额支付时，支付记录使用的渠道名称
+const ProviderLedger = "ledger"
+
+// Status 支付状态
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusPaid    Status = "paid"
+	// StatusFailed 支付失败，包括异步支付失败
+	StatusFailed Status = "failed"
+	// StatusExpired 支付会话过期未支付
+	StatusExpired Status = "expired"
+	// StatusReplaced 支付链接被重新生成，会话已在支付渠道失效
+	StatusReplaced Status = "replaced"
+	// StatusRefundRequired 订单已经由其他支付记录完成支付后，这条记录的会话又完成了支付，款项需要退还给客户
+	StatusRefundRequired Status = "refund_required"
+)
+
+// Payment 一次支付尝试，保存创建支付时的订单快照，Webhook 只更新支付状态，不再信任渠道回传的 metadata
+type Payment struct {
+	ID                string
+	OrderID           string
+	CustomerID        string
+	Provider          string
+	ProviderSessionID string
+	ProviderIntentID  string
+	Amount            int64
+	Currency          string
+	Status            Status
+	PaymentLink       string
+	Items             []*entity.Item
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	PaidAt            *time.Time
+	// FailureReason 支付失败的原因，仅在 StatusFailed 时有值
+	FailureReason string
+	// Credit 由礼品卡和商店余额抵扣的金额，Amount 为支付渠道收取的剩余金额
+	Credit int64
+	// Express, PickupAt 订单的加急标记和预约取餐时间，支付完成后随 order.paid 交给厨房
+	Express  bool
+	PickupAt *time.Time
+}
+
+// NewPendingPayment 使用订单和支付会话创建一个待支付的记录，credit 为余额抵扣的金额
+func NewPendingPayment(order *entity.Order, session *CheckoutSession, credit int64) (*Payment, error) {
+	if order == nil || order.ID == "" {
+		return nil, errors.New("empty order")
+	}
+	if session == nil || session.SessionID == "" {
+		return nil, errors.New("empty checkout session")
+	}
+
+	now := time.Now()
+	return &Payment{
+		OrderID:           order.ID,
+		CustomerID:        order.CustomerID,
+		Provider:          session.Provider,
+		ProviderSessionID: session.SessionID,
+		Amount:            session.Amount,
+		Currency:          session.Currency,
+		Status:            StatusPending,
+		PaymentLink:       session.URL,
+		Items:             order.Items,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+		Credit:            credit,
+		Express:           order.Express,
+		PickupAt:          order.PickupAt,
+	}, nil
+}
+
+// LedgerSession 订单全部由余额支付时使用的支付会话，不需要跳转支付链接
+func LedgerSession(orderID, currency string) *CheckoutSession {
+	return &CheckoutSession{
+		Provider:  ProviderLedger,
+		SessionID: ProviderLedger + "_" + orderID,
+		Currency:  currency,
+	}
+}
+
+// MarkPaid 将支付标记为已支付，只有待支付的记录可以被标记。
+// 被替换的记录在会话失效前仍可能完成支付，订单没有其他已支付的记录时这笔支付同样需要被认可，
+// 否则应使用 MarkRefundRequired
+func (p *Payment) MarkPaid(intentID string, paidAt time.Time) error {
+	if p.Status != StatusPending && p.Status != StatusReplaced {
+		return fmt.Errorf("mark payment %s paid from %s not allowed", p.ID, p.Status)
+	}
+
+	p.Status = StatusPaid
+	p.ProviderIntentID = intentID
+	p.PaidAt = &paidAt
+	p.UpdatedAt = paidAt
+	return nil
+}
+
+// MarkRefundRequired 将订单已经由其他记录支付，或支付已经失败、过期后又完成支付的记录标记为需要退款，
+// 这笔支付不会被认可为订单的支付
+func (p *Payment) MarkRefundRequired(intentID string, paidAt time.Time) error {
+	if p.Status == StatusPaid || p.Status == StatusRefundRequired {
+		return fmt.Errorf("mark payment %s refund required from %s not allowed", p.ID, p.Status)
+	}
+
+	p.Status = StatusRefundRequired
+	p.ProviderIntentID = intentID
+	p.PaidAt = &paidAt
+	p.UpdatedAt = paidAt
+	return nil
+}
+
+// MarkFailed 将支付标记为失败，只有待支付的记录可以被标记
+func (p *Payment) MarkFailed(reason string, failedAt time.Time) error {
+	if p.Status != StatusPending {
+		return fmt.Errorf("mark payment %s failed from %s not allowed", p.ID, p.Status)
+	}
+
+	p.Status = StatusFailed
+	p.FailureReason = reason
+	p.UpdatedAt = failedAt
+	return nil
+}
+
+// MarkExpired 将支付标记为过期，只有待支付的记录可以被标记
+func (p *Payment) MarkExpired(expiredAt time.Time) error {
+	if p.Status != StatusPending {
+		return fmt.Errorf("mark payment %s expired from %s not allowed", p.ID, p.Status)
+	}
+
+	p.Status = StatusExpired
+	p.UpdatedAt = expiredAt
+	return nil
+}
+
+// MarkReplaced 将支付标记为已被新的支付链接替换，只有待支付的记录可以被标记
+func (p *Payment) MarkReplaced(replacedAt time.Time) error {
+	if p.Status != StatusPending {
+		return fmt.Errorf("mark payment %s replaced from %s not allowed", p.ID, p.Status)
+	}
+
+	p.Status = StatusReplaced
+	p.UpdatedAt = replacedAt
+	return nil
+}
+
+// IsPending 支付是否仍在等待结果
+func (p *Payment) IsPending() bool {
+	return p.Status == StatusPending
+}
+
+// IsPaid 支付是否已经完成
+func (p *Payment) IsPaid() bool {
+	return p.Status == StatusPaid
+}
+
+// PaidOrder 返回支付完成后用于发布 order.paid 事件的订单
+func (p *Payment) PaidOrder() *entity.Order {
+	return &entity.Order{
+		ID:         p.OrderID,
+		CustomerID: p.CustomerID,
+		Status:     consts.OrderStatusPaid,
+		Items:      p.Items,
+		Express:    p.Express,
+		PickupAt:   p.PickupAt,
+	}
+}
+
+// ClosedOrder 返回支付失败或过期后用于发布事件的订单，订单状态对应支付的结果
+func (p *Payment) ClosedOrder() *entity.Order {
+	status := consts.OrderStatusPaymentFailed
+	if p.Status == StatusExpired {
+		status = consts.OrderStatusPaymentExpired
+	}
+
+	return &entity.Order{
+		ID:         p.OrderID,
+		CustomerID: p.CustomerID,
+		Status:     status,
+		Items:      p.Items,
+	}
+}
+
+// AlreadyPaidError 订单已经支付完成，不能再生成支付链接
+type AlreadyPaidError struct {
+	OrderID string
+}
+
+func (e AlreadyPaidError) Error() string {
+	return "order " + e.OrderID + " already paid"
+}
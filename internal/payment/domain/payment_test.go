@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/consts"
+	"github.com/furutachiKurea/gorder/common/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPayment(t *testing.T, status Status) *Payment {
+	t.Helper()
+
+	p, err := NewPendingPayment(&entity.Order{ID: "order"}, &CheckoutSession{SessionID: "cs_test"}, 0)
+	require.NoError(t, err)
+	p.Status = status
+	return p
+}
+
+func TestPayment_MarkPaid(t *testing.T) {
+	for _, status := range []Status{StatusPending, StatusReplaced} {
+		p := newTestPayment(t, status)
+		paidAt := time.Now()
+		require.NoError(t, p.MarkPaid("pi_test", paidAt), status)
+		assert.Equal(t, StatusPaid, p.Status)
+		assert.Equal(t, "pi_test", p.ProviderIntentID)
+		assert.Equal(t, &paidAt, p.PaidAt)
+	}
+
+	for _, status := range []Status{StatusPaid, StatusFailed, StatusExpired, StatusRefundRequired} {
+		assert.Error(t, newTestPayment(t, status).MarkPaid("pi_test", time.Now()), status)
+	}
+}
+
+func TestPayment_MarkRefundRequired(t *testing.T) {
+	for _, status := range []Status{StatusPending, StatusReplaced, StatusFailed, StatusExpired} {
+		p := newTestPayment(t, status)
+		require.NoError(t, p.MarkRefundRequired("pi_test", time.Now()), status)
+		assert.Equal(t, StatusRefundRequired, p.Status)
+		assert.Equal(t, "pi_test", p.ProviderIntentID)
+		assert.False(t, p.IsPaid())
+	}
+
+	for _, status := range []Status{StatusPaid, StatusRefundRequired} {
+		assert.Error(t, newTestPayment(t, status).MarkRefundRequired("pi_test", time.Now()), status)
+	}
+}
+
+func TestPayment_CloseOnlyPending(t *testing.T) {
+	for _, status := range []Status{StatusPaid, StatusReplaced, StatusRefundRequired} {
+		assert.Error(t, newTestPayment(t, status).MarkFailed("declined", time.Now()), status)
+		assert.Error(t, newTestPayment(t, status).MarkExpired(time.Now()), status)
+		assert.Error(t, newTestPayment(t, status).MarkReplaced(time.Now()), status)
+	}
+
+	p := newTestPayment(t, StatusPending)
+	require.NoError(t, p.MarkFailed("declined", time.Now()))
+	assert.Equal(t, "declined", p.FailureReason)
+	assert.Equal(t, consts.OrderStatusPaymentFailed, p.ClosedOrder().Status)
+}
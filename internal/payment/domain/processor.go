@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/furutachiKurea/gorder/common/entity"
+)
+
+// Capabilities 支付渠道声明的能力
+type Capabilities struct {
+	// Refunds 是否支持退款
+	Refunds bool
+	// AsyncMethods 是否支持银行转账等异步到账的支付方式
+	AsyncMethods bool
+	// Currencies 支持的币种，小写的 ISO 4217 代码，为空表示不限制币种
+	Currencies []string
+}
+
+// SupportsCurrency 渠道是否支持 currency
+func (c Capabilities) SupportsCurrency(currency string) bool {
+	if len(c.Currencies) == 0 || currency == "" {
+		return true
+	}
+
+	return slices.Contains(c.Currencies, strings.ToLower(currency))
+}
+
+// CheckoutRequest 创建支付会话的请求
+type CheckoutRequest struct {
+	Order *entity.Order
+	// Amount 订单总额，以货币最小单位表示，未报价时为 0
+	Amount   int64
+	Currency string
+	// Credit 已由礼品卡和商店余额抵扣的金额，渠道只收取 Amount - Credit
+	Credit int64
+	// Sequence 订单之前已创建的支付记录数，与订单 ID 一起派生渠道请求的幂等键
+	Sequence int
+}
+
+// PriceQuoter 计算订单商品的总额
+type PriceQuoter interface {
+	Quote(ctx context.Context, items []*entity.Item) (*Quote, error)
+}
+
+// Quote 订单总额，以货币最小单位表示
+type Quote struct {
+	Amount   int64
+	Currency string
+}
+
+// ProcessorRegistry 按名称注册的支付渠道
+type ProcessorRegistry interface {
+	// CreateCheckoutSession 按路由策略为订单选择支付渠道创建支付会话，主渠道失败时依次切换到备用渠道，
+	// 返回的 CheckoutSession.Provider 为实际创建会话的渠道
+	CreateCheckoutSession(ctx context.Context, req *CheckoutRequest) (*CheckoutSession, error)
+	// Get 返回名称为 provider 的支付渠道，用于查询或关闭已创建的支付会话
+	Get(provider string) (Processor, error)
+}
+
+// RouteRequest 选择支付渠道时可用的订单信息
+type RouteRequest struct {
+	OrderID    string
+	CustomerID string
+	Currency   string
+	// Amount 以货币最小单位表示的金额，订单不携带价格时为 0
+	Amount int64
+}
+
+// RoutingPolicy 为订单选择支付渠道
+type RoutingPolicy interface {
+	// Route 返回按优先级排列的渠道名称，第一个为主渠道，其余为主渠道失败时依次尝试的备用渠道
+	Route(ctx context.Context, req RouteRequest) ([]string, error)
+}
+
+// UnknownProviderError 支付渠道没有注册
+type UnknownProviderError struct {
+	Provider string
+}
+
+func (e UnknownProviderError) Error() string {
+	return "payment provider " + e.Provider + " not registered"
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	Create(ctx context.Context, payment *Payment) (*Payment, error)
+	Get(ctx context.Context, paymentID string) (*Payment, error)
+	// GetBySession 通过支付渠道的会话 ID 获取支付记录
+	GetBySession(ctx context.Context, provider, sessionID string) (*Payment, error)
+	// ListByOrderID 获取订单的所有支付记录，按创建时间排序
+	ListByOrderID(ctx context.Context, orderID string) ([]*Payment, error)
+	// ListPending 获取创建时间早于 createdBefore 的待支付记录，按创建时间排序，最多返回 limit 条
+	ListPending(ctx context.Context, createdBefore time.Time, limit int) ([]*Payment, error)
+	// Update 获取支付记录并执行 updateFn，updateFn 返回的记录会被写回存储
+	Update(
+		ctx context.Context,
+		paymentID string,
+		updateFn func(ctx context.Context, payment *Payment) (*Payment, error),
+	) error
+}
+
+type NotFoundError struct {
+	PaymentID string
+}
+
+func (e NotFoundError) Error() string {
+	return "payment " + e.PaymentID + " not found"
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// WebhookEventStore 记录已处理的 Webhook 事件，用于丢弃渠道重复投递的事件和识别乱序到达的事件
+type WebhookEventStore interface {
+	// Claim 占用事件 ID，事件已被占用 (已处理或正在处理) 时返回 false
+	Claim(ctx context.Context, eventID string) (bool, error)
+	// Release 释放事件 ID，处理失败后调用，使渠道重试时事件可以被再次处理
+	Release(ctx context.Context, eventID string) error
+	// Advance 将对象最近一次事件的创建时间推进到 createdAt，createdAt 早于已记录的时间时返回 false
+	Advance(ctx context.Context, objectID string, createdAt time.Time) (bool, error)
+}
+
+// ErrStaleWebhook Webhook 签名时间超过允许的时间差，视为重放
+var ErrStaleWebhook = errors.New("webhook event is too old")
+
+// WebhookParser 校验并解析某个支付渠道推送的 Webhook，转换为与渠道无关的 WebhookEvent，
+// 新增支付渠道只需要提供对应的 WebhookParser
+type WebhookParser interface {
+	// Provider 渠道名称，与 CheckoutSession.Provider 一致
+	Provider() string
+	// Parse 校验签名并解析事件，签名过期时返回 ErrStaleWebhook，不需要处理的事件类型返回 nil
+	Parse(header http.Header, payload []byte) (*WebhookEvent, error)
+}
+
+// WebhookEvent 与渠道无关的支付事件
+type WebhookEvent struct {
+	ID       string
+	Type     string
+	Provider string
+	// ObjectID 事件所属的渠道对象，用于识别同一对象乱序到达的事件
+	ObjectID  string
+	CreatedAt time.Time
+	// Outcome 事件对应的支付结果，StatusPending 表示支付仍在处理，不需要更新支付记录
+	Outcome   Status
+	SessionID string
+	IntentID  string
+	// OrderID 事件不携带支付会话时，通过订单定位待支付的记录
+	OrderID string
+	// Reason 支付失败的原因
+	Reason string
+}
@@ -2,47 +2,73 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 
-	"github.com/furutachiKurea/gorder/common/broker"
-	"github.com/furutachiKurea/gorder/common/consts"
-	"github.com/furutachiKurea/gorder/common/entity"
-	"github.com/furutachiKurea/gorder/common/tracing"
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/payment/app"
+	"github.com/furutachiKurea/gorder/payment/app/command"
+	"github.com/furutachiKurea/gorder/payment/app/query"
+	"github.com/furutachiKurea/gorder/payment/domain"
+	"github.com/furutachiKurea/gorder/payment/infrastructure/processor"
+	"github.com/furutachiKurea/gorder/payment/ports"
+
 	"github.com/gin-gonic/gin"
-	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/rs/zerolog/log"
-	"github.com/spf13/viper"
-	"github.com/stripe/stripe-go/v84"
-	"github.com/stripe/stripe-go/v84/webhook"
 )
 
 type PaymentHandler struct {
-	channel *amqp.Channel
+	app app.Application
+	// webhooks 各支付渠道的 Webhook 解析器，按渠道名称索引
+	webhooks      map[string]domain.WebhookParser
+	metricsClient decorator.MetricsClient
 }
 
-func NewPaymentHandler(ch *amqp.Channel) *PaymentHandler {
-	return &PaymentHandler{channel: ch}
+func NewPaymentHandler(app app.Application, webhooks []domain.WebhookParser, metricsClient decorator.MetricsClient) *PaymentHandler {
+	byProvider := make(map[string]domain.WebhookParser, len(webhooks))
+	for _, w := range webhooks {
+		byProvider[w.Provider()] = w
+	}
+
+	return &PaymentHandler{app: app, webhooks: byProvider, metricsClient: metricsClient}
 }
 
 func (h PaymentHandler) RegisterRoutes(router *gin.Engine) {
-	router.POST("/api/webhook", h.handleWebhook)
+	// Stripe 控制台中已配置的 Webhook 地址不带渠道名称
+	router.POST("/api/webhook", func(c *gin.Context) {
+		h.handleWebhook(c, processor.ProviderStripe)
+	})
+	router.POST("/api/webhook/:provider", func(c *gin.Context) {
+		h.handleWebhook(c, c.Param("provider"))
+	})
+	router.GET("/api/payments", h.listPayments)
+	router.GET("/api/payments/:payment_id", h.getPayment)
+	router.POST("/api/ledger/accounts", h.issueCredit)
+	router.GET("/api/ledger/accounts", h.listCreditAccounts)
+	router.GET("/api/ledger/accounts/:account_id", h.getCreditAccount)
 }
 
-// handleWebhook handles Stripe webhook events，并将支付成功的订单信息发布到消息队列
-func (h PaymentHandler) handleWebhook(c *gin.Context) {
+// handleWebhook 使用渠道的 WebhookParser 校验并解析事件，更新支付会话对应的支付记录，由支付记录发布 order.paid、order.payment_failed 等事件。
+// 签名时间超过 payment.webhook-tolerance 的事件视为重放并拒绝，重复投递的事件直接确认并丢弃
+func (h PaymentHandler) handleWebhook(c *gin.Context, provider string) {
 	var err error
 	defer func() {
 		if err != nil {
-			log.Error().Ctx(c.Request.Context()).Err(err).Msg("handlerWebhook error")
+			log.Error().Ctx(c.Request.Context()).Err(err).Str("provider", provider).Msg("handlerWebhook error")
 		} else {
-			log.Info().Ctx(c.Request.Context()).Msg("handlerWebhook success")
+			log.Info().Ctx(c.Request.Context()).Str("provider", provider).Msg("handlerWebhook success")
 		}
 	}()
 
+	parser, ok := h.webhooks[provider]
+	if !ok {
+		err = domain.UnknownProviderError{Provider: provider}
+		c.JSON(http.StatusNotFound, err.Error())
+		return
+	}
+
 	const MaxBodyBytes = int64(65536)
 	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, MaxBodyBytes)
 	payload, err := io.ReadAll(c.Request.Body)
@@ -52,58 +78,203 @@ func (h PaymentHandler) handleWebhook(c *gin.Context) {
 		return
 	}
 
-	event, err := webhook.ConstructEvent(payload, c.Request.Header.Get("Stripe-Signature"),
-		viper.GetString("endpoint-stripe-secret"))
+	event, err := parser.Parse(c.Request.Header, payload)
 	if err != nil {
-		err = fmt.Errorf("verifying webhook signature: %w", err)
+		if errors.Is(err, domain.ErrStaleWebhook) {
+			h.metricsClient.Inc("webhook.stale", 1)
+		}
 		c.JSON(http.StatusBadRequest, err.Error()) // Return a 400 error on a bad signature
 		return
 	}
+	if event == nil {
+		log.Warn().Ctx(c.Request.Context()).Str("provider", provider).Msg("Unhandled event type")
+		c.JSON(http.StatusOK, nil)
+		return
+	}
 
-	if err = json.Unmarshal(payload, &event); err != nil {
-		err = fmt.Errorf("unmarshalling webhook body json: %w", err)
-		c.JSON(http.StatusBadRequest, err.Error())
+	_, err = h.app.Commands.ProcessWebhookEvent.Handle(c.Request.Context(), command.ProcessWebhookEvent{
+		EventID:   event.ID,
+		EventType: event.Type,
+		ObjectID:  event.ObjectID,
+		CreatedAt: event.CreatedAt,
+		Handle:    h.eventHandler(event),
+	})
+	if err != nil {
+		// 返回 5xx 让渠道稍后重试 Webhook
+		c.JSON(http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	// Unmarshal the event data into an appropriate struct depending on its Type
-	switch event.Type {
-	case stripe.EventTypeCheckoutSessionCompleted:
-		var session stripe.CheckoutSession
-		if err = json.Unmarshal(event.Data.Raw, &session); err != nil {
-			err = fmt.Errorf("unmarshalling event.Data.Raw into session: %w", err)
-			c.JSON(http.StatusBadRequest, err.Error())
+	c.JSON(http.StatusOK, nil)
+}
+
+// eventHandler 按事件对应的支付结果更新支付记录：已支付通过 CompleteCheckout，失败和过期通过 CloseCheckout，
+// 仍在处理的异步支付等待后续事件
+func (h PaymentHandler) eventHandler(event *domain.WebhookEvent) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		var err error
+		switch event.Outcome {
+		case domain.StatusPaid:
+			_, err = h.app.Commands.CompleteCheckout.Handle(ctx, command.CompleteCheckout{
+				Provider:  event.Provider,
+				SessionID: event.SessionID,
+				IntentID:  event.IntentID,
+				PaidAt:    event.CreatedAt,
+			})
+		case domain.StatusFailed, domain.StatusExpired:
+			_, err = h.app.Commands.CloseCheckout.Handle(ctx, command.CloseCheckout{
+				Provider:  event.Provider,
+				SessionID: event.SessionID,
+				OrderID:   event.OrderID,
+				Outcome:   event.Outcome,
+				Reason:    event.Reason,
+				ClosedAt:  event.CreatedAt,
+			})
+		default:
+			log.Info().Ctx(ctx).
+				Str("provider", event.Provider).
+				Str("session_id", event.SessionID).
+				Str("event_type", event.Type).
+				Msg("payment is still processing, wait for async payment")
+		}
+
+		return ignoreNotFound(ctx, err)
+	}
+}
+
+// ignoreNotFound 未知的支付记录重试也不会成功，忽略该错误避免渠道反复投递
+func ignoreNotFound(ctx context.Context, err error) error {
+	var notFound domain.NotFoundError
+	if errors.As(err, &notFound) {
+		log.Warn().Ctx(ctx).Err(err).Msg("payment of webhook event not found, skip")
+		return nil
+	}
+
+	return err
+}
+
+// getPayment 按支付 ID 查询支付记录
+func (h PaymentHandler) getPayment(c *gin.Context) {
+	h.respondPayments(c, query.GetPayment{PaymentID: c.Param("payment_id")})
+}
+
+// listPayments 按订单 ID 查询支付记录，GET /api/payments?order_id=
+func (h PaymentHandler) listPayments(c *gin.Context) {
+	orderID := c.Query("order_id")
+	if orderID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "order_id is required"})
+		return
+	}
+
+	h.respondPayments(c, query.GetPayment{OrderID: orderID})
+}
+
+func (h PaymentHandler) respondPayments(c *gin.Context, q query.GetPayment) {
+	payments, err := h.app.Queries.GetPayment.Handle(c.Request.Context(), q)
+	if err != nil {
+		var notFound domain.NotFoundError
+		if errors.As(err, &notFound) {
+			c.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
 			return
 		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
 
-		if session.PaymentStatus == stripe.CheckoutSessionPaymentStatusPaid {
-			ctx, cancel := context.WithCancel(c.Request.Context())
-			defer cancel()
-
-			var items []*entity.Item
-			_ = json.Unmarshal([]byte(session.Metadata["items"]), &items)
-
-			mqCtx, span := tracing.Start(ctx, fmt.Sprintf("rabbitmq.%s.publish", broker.EventOrderPaid))
-			defer span.End()
-
-			_ = broker.PublishEvent(mqCtx, &broker.PublishEventReq{
-				Channel:  h.channel,
-				Routing:  broker.FanOut,
-				Queue:    "",
-				Exchange: broker.EventOrderPaid,
-				Body: &entity.Order{
-					ID:         session.Metadata["order_id"],
-					CustomerID: session.Metadata["customer_id"],
-					Status:     consts.OrderStatusPaid,
-					Items:      items,
-				},
-			})
-			log.Info().Ctx(mqCtx).Msgf("message published to %s", broker.EventOrderPaid)
+	resp := make([]any, 0, len(payments))
+	for _, p := range payments {
+		resp = append(resp, ports.PaymentToProto(p))
+	}
+	c.JSON(http.StatusOK, gin.H{"payments": resp})
+}
+
+type issueCreditReq struct {
+	Type       domain.AccountType `json:"type" binding:"required,oneof=gift_card store_credit"`
+	CustomerID string             `json:"customer_id" binding:"required"`
+	Currency   string             `json:"currency" binding:"required"`
+	Amount     int64              `json:"amount" binding:"required,gt=0"`
+}
+
+// issueCredit 发行礼品卡或为客户增加商店余额，POST /api/ledger/accounts
+func (h PaymentHandler) issueCredit(c *gin.Context) {
+	var req issueCreditReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	account, err := h.app.Commands.IssueCredit.Handle(c.Request.Context(), command.IssueCredit{
+		Type:       req.Type,
+		CustomerID: req.CustomerID,
+		Currency:   req.Currency,
+		Amount:     req.Amount,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"account": newCreditAccountResp(account)})
+}
+
+// getCreditAccount 按账户 ID 查询余额账户
+func (h PaymentHandler) getCreditAccount(c *gin.Context) {
+	h.respondCreditAccounts(c, query.GetCreditAccounts{AccountID: c.Param("account_id")})
+}
+
+// listCreditAccounts 按客户 ID 查询余额账户，GET /api/ledger/accounts?customer_id=
+func (h PaymentHandler) listCreditAccounts(c *gin.Context) {
+	customerID := c.Query("customer_id")
+	if customerID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "customer_id is required"})
+		return
+	}
+
+	h.respondCreditAccounts(c, query.GetCreditAccounts{CustomerID: customerID})
+}
+
+func (h PaymentHandler) respondCreditAccounts(c *gin.Context, q query.GetCreditAccounts) {
+	accounts, err := h.app.Queries.GetCreditAccounts.Handle(c.Request.Context(), q)
+	if err != nil {
+		var notFound domain.AccountNotFoundError
+		if errors.As(err, &notFound) {
+			c.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
+			return
 		}
-	default:
-		log.Warn().Ctx(c.Request.Context()).Str("event type", string(event.Type)).Msg("Unhandled event type")
-		err = errors.New("unhandled event type")
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
 	}
 
-	c.JSON(http.StatusOK, nil)
+	resp := make([]*creditAccountResp, 0, len(accounts))
+	for _, a := range accounts {
+		resp = append(resp, newCreditAccountResp(a))
+	}
+	c.JSON(http.StatusOK, gin.H{"accounts": resp})
+}
+
+// creditAccountResp 余额账户的响应，金额以货币最小单位表示
+type creditAccountResp struct {
+	ID         string `json:"id"`
+	Type       string `json:"type"`
+	CustomerID string `json:"customer_id"`
+	Code       string `json:"code,omitempty"`
+	Currency   string `json:"currency"`
+	Balance    int64  `json:"balance"`
+	Held       int64  `json:"held"`
+	CreatedAt  int64  `json:"created_at"`
+	UpdatedAt  int64  `json:"updated_at"`
+}
+
+func newCreditAccountResp(a *domain.Account) *creditAccountResp {
+	return &creditAccountResp{
+		ID:         a.ID,
+		Type:       string(a.Type),
+		CustomerID: a.CustomerID,
+		Code:       a.Code,
+		Currency:   a.Currency,
+		Balance:    a.Balance,
+		Held:       a.Held,
+		CreatedAt:  a.CreatedAt.Unix(),
+		UpdatedAt:  a.UpdatedAt.Unix(),
+	}
 }
@@ -11,35 +11,35 @@ import (
 	"github.com/furutachiKurea/gorder/payment/app/command"
 	"go.opentelemetry.io/otel"
 
-	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/rs/zerolog/log"
 )
 
 type Consumer struct {
 	app app.Application
+	// publisher 用于重新发布处理失败的消息
+	publisher broker.Publisher
+	// retry 处理失败的消息的重试策略
+	retry broker.RetryPolicy
 }
 
-func NewConsumer(app app.Application) *Consumer {
+func NewConsumer(app app.Application, publisher broker.Publisher, retry broker.RetryPolicy) *Consumer {
 	return &Consumer{
-		app: app,
+		app:       app,
+		publisher: publisher,
+		retry:     retry,
 	}
 }
 
-func (c *Consumer) Listen(ch *amqp.Channel) {
-	q, err := ch.QueueDeclare(broker.EventOrderCreated, true, false, false, false, nil)
+func (c *Consumer) Listen(sub broker.Subscriber) {
+	msgs, err := sub.Subscribe(context.Background(), broker.Subscription{Queue: broker.EventOrderCreated})
 	if err != nil {
-		log.Fatal().Err(err).Msg("")
-	}
-
-	msgs, err := ch.Consume(q.Name, "", false, false, false, false, nil)
-	if err != nil {
-		log.Warn().Err(err).Str("queue", q.Name).Msg("failed to consume queue")
+		log.Fatal().Err(err).Str("queue", broker.EventOrderCreated).Msg("failed to subscribe")
 	}
 
 	var forever chan struct{}
 	go func() {
 		for msg := range msgs {
-			c.handleMessage(ch, msg, q)
+			c.handleMessage(msg)
 		}
 	}()
 
@@ -47,27 +47,26 @@ func (c *Consumer) Listen(ch *amqp.Channel) {
 }
 
 // handleMessage 处理接收到的订单创建消息，创建支付链接
-func (c *Consumer) handleMessage(ch *amqp.Channel, msg amqp.Delivery, q amqp.Queue) {
+func (c *Consumer) handleMessage(msg *broker.Delivery) {
 	log.Info().
 		Str("msg", string(msg.Body)).
-		Msgf("payment received message from %s", q.Name)
+		Msgf("payment received message from %s", msg.Queue)
 
 	ctx := broker.ExtractRabbitMQHeaders(context.Background(), msg.Headers)
 	t := otel.Tracer("rabbitmq")
-	ctx, span := t.Start(ctx, fmt.Sprintf("rabbitmq.%s.consume", q.Name))
+	ctx, span := t.Start(ctx, fmt.Sprintf("rabbitmq.%s.consume", msg.Queue))
 	defer span.End()
 
 	var err error
 	defer func() {
+		_ = broker.Settle(msg, err)
 		if err != nil {
-			_ = msg.Nack(false, false)
 			log.Warn().Ctx(ctx).
 				Err(err).
-				Str("from", q.Name).
+				Str("from", msg.Queue).
 				Str("msg", string(msg.Body)).
 				Msg("consume failed")
 		} else {
-			_ = msg.Ack(false)
 			span.AddEvent("payment.created")
 			log.Info().Ctx(ctx).Msg("consume success")
 		}
@@ -83,8 +82,8 @@ func (c *Consumer) handleMessage(ch *amqp.Channel, msg amqp.Delivery, q amqp.Que
 	_, err = c.app.Commands.CreatePayment.Handle(ctx, command.CreatePayment{Order: o})
 	if err != nil {
 		err = fmt.Errorf("create payment: %w", err)
-		if err = broker.HandlerRetry(ctx, ch, &msg); err != nil {
-			err = fmt.Errorf("handle retry, messageId=%s: %w", msg.MessageId, err)
+		if err = broker.HandlerRetry(ctx, c.publisher, msg, c.retry); err != nil {
+			err = fmt.Errorf("handle retry, messageId=%s: %w", msg.MessageID, err)
 			return
 		}
 	}
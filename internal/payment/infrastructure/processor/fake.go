@@ -0,0 +1,254 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/entity"
+	"github.com/furutachiKurea/gorder/payment/domain"
+	"github.com/furutachiKurea/gorder/payment/infrastructure/stripeevent"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"github.com/stripe/stripe-go/v84"
+)
+
+const (
+	ProviderFake = "fake"
+
+	fakeCheckoutPath = "/fake/checkout/"
+	fakeCurrency     = "usd"
+)
+
+// 模拟支付页面上可选的操作
+const (
+	fakeActionPay     = "pay"
+	fakeActionDecline = "decline"
+	fakeActionAbandon = "abandon"
+)
+
+// FakeCapabilities 模拟支付页面可以模拟异步支付失败，不支持退款，只使用 fakeCurrency 结算
+var FakeCapabilities = domain.Capabilities{
+	AsyncMethods: true,
+	Currencies:   []string{fakeCurrency},
+}
+
+// FakeProcessor 离线的模拟支付渠道，支付链接指向 payment 服务自身提供的模拟支付页面，
+// 页面上的操作会生成本地签名的 Stripe 格式事件并投递到 Webhook，与 Stripe 走相同的处理流程
+type FakeProcessor struct {
+	baseURL    string
+	webhookURL string
+	secret     string
+	client     *http.Client
+
+	lock     sync.Mutex
+	sessions map[string]*fakeSession
+}
+
+type fakeSession struct {
+	session    stripeevent.CheckoutSession
+	successURL string
+	items      []*entity.Item
+}
+
+// NewFakeProcessor baseURL 为 payment 服务的 HTTP 地址，secret 为 Webhook 签名密钥
+func NewFakeProcessor(baseURL, secret string) *FakeProcessor {
+	return &FakeProcessor{
+		baseURL:    baseURL,
+		webhookURL: baseURL + "/api/webhook/" + ProviderFake,
+		secret:     secret,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		sessions:   make(map[string]*fakeSession),
+	}
+}
+
+// CreateCheckoutSession 模拟支付页面展示的金额为抵扣余额后的剩余金额，未报价时为 0
+func (f *FakeProcessor) CreateCheckoutSession(_ context.Context, req *domain.CheckoutRequest) (*domain.CheckoutSession, error) {
+	order := req.Order
+	id := stripeevent.NewID("cs_fake")
+	amount := req.Amount - req.Credit
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.sessions[id] = &fakeSession{
+		session: stripeevent.CheckoutSession{
+			ID:                id,
+			Object:            "checkout.session",
+			ClientReferenceID: order.ID,
+			Status:            string(stripe.CheckoutSessionStatusOpen),
+			PaymentStatus:     string(stripe.CheckoutSessionPaymentStatusUnpaid),
+			AmountTotal:       amount,
+			Currency:          fakeCurrency,
+			Metadata: map[string]string{
+				"order_id":    order.ID,
+				"customer_id": order.CustomerID,
+			},
+		},
+		successURL: fmt.Sprintf("%s?order_id=%s&customer_id=%s", successURL, order.ID, order.CustomerID),
+		items:      order.Items,
+	}
+
+	return &domain.CheckoutSession{
+		Provider:  ProviderFake,
+		SessionID: id,
+		URL:       f.baseURL + fakeCheckoutPath + id,
+		Amount:    amount,
+		Currency:  fakeCurrency,
+	}, nil
+}
+
+func (f *FakeProcessor) GetPaymentStatus(_ context.Context, sessionID string) (*domain.PaymentStatus, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	s, ok := f.sessions[sessionID]
+	if !ok {
+		return nil, fmt.Errorf("fake checkout session %s not found", sessionID)
+	}
+
+	status := &domain.PaymentStatus{SessionID: sessionID, Status: domain.StatusPending, IntentID: s.session.PaymentIntent}
+	switch {
+	case s.session.PaymentStatus == string(stripe.CheckoutSessionPaymentStatusPaid):
+		status.Status = domain.StatusPaid
+	case s.session.Status == string(stripe.CheckoutSessionStatusExpired):
+		status.Status = domain.StatusExpired
+	}
+
+	return status, nil
+}
+
+// ExpireCheckoutSession 将仍可支付的会话标记为过期，不投递 Webhook，被替换的支付记录不会再处理过期事件
+func (f *FakeProcessor) ExpireCheckoutSession(_ context.Context, sessionID string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	s, ok := f.sessions[sessionID]
+	if !ok {
+		return fmt.Errorf("fake checkout session %s not found", sessionID)
+	}
+	if s.session.Status != string(stripe.CheckoutSessionStatusOpen) {
+		return fmt.Errorf("fake checkout session %s is already %s", sessionID, s.session.Status)
+	}
+
+	s.session.Status = string(stripe.CheckoutSessionStatusExpired)
+	return nil
+}
+
+// RegisterRoutes 注册模拟支付页面
+func (f *FakeProcessor) RegisterRoutes(router *gin.Engine) {
+	router.GET(fakeCheckoutPath+":session_id", f.checkoutPage)
+	router.POST(fakeCheckoutPath+":session_id", f.checkoutAction)
+}
+
+func (f *FakeProcessor) checkoutPage(c *gin.Context) {
+	f.lock.Lock()
+	s, ok := f.sessions[c.Param("session_id")]
+	var page fakeCheckoutPage
+	if ok {
+		page = fakeCheckoutPage{Session: s.session, Items: s.items}
+	}
+	f.lock.Unlock()
+
+	if !ok {
+		c.String(http.StatusNotFound, "checkout session not found")
+		return
+	}
+
+	c.Status(http.StatusOK)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	_ = fakeCheckoutTemplate.Execute(c.Writer, page)
+}
+
+// checkoutAction 根据页面上选择的操作更新会话状态，并将对应的事件投递到 Webhook：
+// pay 对应 checkout.session.completed，decline 对应 checkout.session.async_payment_failed，
+// abandon 对应 checkout.session.expired。投递失败时会话保持 open，可以重新操作
+func (f *FakeProcessor) checkoutAction(c *gin.Context) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	s, ok := f.sessions[c.Param("session_id")]
+	if !ok {
+		c.String(http.StatusNotFound, "checkout session not found")
+		return
+	}
+	if s.session.Status != string(stripe.CheckoutSessionStatusOpen) {
+		c.String(http.StatusConflict, "checkout session is already %s", s.session.Status)
+		return
+	}
+
+	next := s.session
+	var eventType stripe.EventType
+	switch action := c.PostForm("action"); action {
+	case fakeActionPay:
+		eventType = stripe.EventTypeCheckoutSessionCompleted
+		next.Status = string(stripe.CheckoutSessionStatusComplete)
+		next.PaymentStatus = string(stripe.CheckoutSessionPaymentStatusPaid)
+		next.PaymentIntent = stripeevent.NewID("pi_fake")
+	case fakeActionDecline:
+		eventType = stripe.EventTypeCheckoutSessionAsyncPaymentFailed
+		next.Status = string(stripe.CheckoutSessionStatusComplete)
+	case fakeActionAbandon:
+		eventType = stripe.EventTypeCheckoutSessionExpired
+		next.Status = string(stripe.CheckoutSessionStatusExpired)
+	default:
+		c.String(http.StatusBadRequest, "unknown action %q", action)
+		return
+	}
+
+	event, err := stripeevent.New(eventType, next, f.secret, time.Now())
+	if err == nil {
+		err = stripeevent.Send(c.Request.Context(), f.client, f.webhookURL, event)
+	}
+	if err != nil {
+		log.Error().Ctx(c.Request.Context()).Err(err).Str("session_id", next.ID).Msg("fake checkout deliver event failed")
+		c.String(http.StatusBadGateway, "deliver %s: %s", eventType, err.Error())
+		return
+	}
+
+	s.session = next
+	log.Info().Ctx(c.Request.Context()).
+		Str("session_id", next.ID).
+		Str("event_id", event.ID).
+		Str("event_type", string(eventType)).
+		Msg("fake checkout event delivered")
+
+	if eventType == stripe.EventTypeCheckoutSessionCompleted {
+		c.Redirect(http.StatusSeeOther, s.successURL)
+		return
+	}
+	c.String(http.StatusOK, "checkout session %s: %s", next.ID, eventType)
+}
+
+type fakeCheckoutPage struct {
+	Session stripeevent.CheckoutSession
+	Items   []*entity.Item
+}
+
+var fakeCheckoutTemplate = template.Must(template.New("checkout").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Fake checkout</title></head>
+<body>
+<h1>Fake checkout</h1>
+<p>Session: {{.Session.ID}}</p>
+<p>Order: {{.Session.ClientReferenceID}}</p>
+<p>Status: {{.Session.Status}}</p>
+<p>Amount due: {{.Session.AmountTotal}} {{.Session.Currency}}</p>
+<table>
+<tr><th>Item</th><th>Price</th><th>Quantity</th></tr>
+{{range .Items}}<tr><td>{{.Name}}</td><td>{{.PriceID}}</td><td>{{.Quantity}}</td></tr>
+{{end}}</table>
+{{if eq .Session.Status "open"}}
+<form method="post">
+<button name="action" value="pay">Pay</button>
+<button name="action" value="decline">Decline</button>
+<button name="action" value="abandon">Abandon</button>
+</form>
+{{end}}
+</body>
+</html>
+`))
@@ -3,15 +3,33 @@ package processor
 import (
 	"context"
 
-	"github.com/furutachiKurea/gorder/common/entity"
+	"github.com/furutachiKurea/gorder/payment/domain"
 )
 
+const ProviderInmem = "inmem"
+
+var InmemCapabilities = domain.Capabilities{}
+
 type InmemProcessor struct{}
 
 func NewInmemProcessor() *InmemProcessor {
 	return &InmemProcessor{}
 }
 
-func (i InmemProcessor) CreatePaymentLink(ctx context.Context, order *entity.Order) (string, error) {
-	return "inmem_payment_link_for_order", nil
+func (i InmemProcessor) CreateCheckoutSession(_ context.Context, req *domain.CheckoutRequest) (*domain.CheckoutSession, error) {
+	return &domain.CheckoutSession{
+		Provider:  ProviderInmem,
+		SessionID: "inmem_session_" + req.Order.ID,
+		URL:       "inmem_payment_link_for_order",
+		Amount:    req.Amount - req.Credit,
+		Currency:  req.Currency,
+	}, nil
+}
+
+func (i InmemProcessor) GetPaymentStatus(_ context.Context, sessionID string) (*domain.PaymentStatus, error) {
+	return &domain.PaymentStatus{SessionID: sessionID, Status: domain.StatusPending}, nil
+}
+
+func (i InmemProcessor) ExpireCheckoutSession(_ context.Context, _ string) error {
+	return nil
 }
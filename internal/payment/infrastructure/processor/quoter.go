@@ -0,0 +1,73 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/furutachiKurea/gorder/common/entity"
+	"github.com/furutachiKurea/gorder/common/resilience"
+	"github.com/furutachiKurea/gorder/payment/domain"
+
+	"github.com/stripe/stripe-go/v84"
+)
+
+// StripeQuoter 使用商品的 Stripe 价格计算订单总额，所有商品的价格需要使用相同的币种
+type StripeQuoter struct {
+	client *stripe.Client
+	caller *resilience.Caller
+}
+
+func NewStripeQuoter(client *stripe.Client, caller *resilience.Caller) *StripeQuoter {
+	if client == nil {
+		panic("client is nil")
+	}
+
+	if caller == nil {
+		panic("caller is nil")
+	}
+
+	return &StripeQuoter{client: client, caller: caller}
+}
+
+func (q StripeQuoter) Quote(ctx context.Context, items []*entity.Item) (*domain.Quote, error) {
+	quote := &domain.Quote{}
+	for _, item := range items {
+		var p *stripe.Price
+		err := q.caller.Do(ctx, "get_price", func(ctx context.Context) (err error) {
+			p, err = q.client.V1Prices.Retrieve(ctx, item.PriceID, &stripe.PriceRetrieveParams{})
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("get price %s: %w", item.PriceID, err)
+		}
+
+		currency := strings.ToLower(string(p.Currency))
+		if quote.Currency != "" && quote.Currency != currency {
+			return nil, fmt.Errorf("items priced in different currencies: %s and %s", quote.Currency, currency)
+		}
+		quote.Currency = currency
+		quote.Amount += p.UnitAmount * item.Quantity
+	}
+
+	return quote, nil
+}
+
+// FixedQuoter 所有商品使用相同的单价，用于没有 Stripe 价格的离线环境
+type FixedQuoter struct {
+	unitAmount int64
+	currency   string
+}
+
+func NewFixedQuoter(unitAmount int64, currency string) *FixedQuoter {
+	return &FixedQuoter{unitAmount: unitAmount, currency: currency}
+}
+
+func (q FixedQuoter) Quote(_ context.Context, items []*entity.Item) (*domain.Quote, error) {
+	quote := &domain.Quote{Currency: q.currency}
+	for _, item := range items {
+		quote.Amount += q.unitAmount * item.Quantity
+	}
+
+	return quote, nil
+}
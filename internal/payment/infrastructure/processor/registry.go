@@ -0,0 +1,144 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/furutachiKurea/gorder/payment/domain"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Registry 按名称注册支付渠道，由 RoutingPolicy 为每个订单选择渠道，主渠道创建会话失败时切换到备用渠道
+type Registry struct {
+	policy   domain.RoutingPolicy
+	currency string
+
+	processors map[string]registered
+}
+
+type registered struct {
+	processor    domain.Processor
+	capabilities domain.Capabilities
+}
+
+// NewRegistry currency 为订单结算使用的币种，不支持该币种的渠道不会被选择
+func NewRegistry(policy domain.RoutingPolicy, currency string) *Registry {
+	if policy == nil {
+		panic("policy is nil")
+	}
+
+	return &Registry{
+		policy:     policy,
+		currency:   currency,
+		processors: make(map[string]registered),
+	}
+}
+
+// Register 以 name 注册支付渠道，name 需要与渠道创建的 CheckoutSession.Provider 一致
+func (r *Registry) Register(name string, processor domain.Processor, capabilities domain.Capabilities) error {
+	if name == "" {
+		return errors.New("empty provider name")
+	}
+	if processor == nil {
+		return fmt.Errorf("processor of provider %s is nil", name)
+	}
+	if _, ok := r.processors[name]; ok {
+		return fmt.Errorf("provider %s already registered", name)
+	}
+
+	r.processors[name] = registered{processor: processor, capabilities: capabilities}
+	log.Info().Str("provider", name).Any("capabilities", capabilities).Msg("payment provider registered")
+	return nil
+}
+
+func (r *Registry) Get(provider string) (domain.Processor, error) {
+	p, ok := r.processors[provider]
+	if !ok {
+		return nil, domain.UnknownProviderError{Provider: provider}
+	}
+
+	return p.processor, nil
+}
+
+// Capabilities 返回渠道声明的能力
+func (r *Registry) Capabilities(provider string) (domain.Capabilities, error) {
+	p, ok := r.processors[provider]
+	if !ok {
+		return domain.Capabilities{}, domain.UnknownProviderError{Provider: provider}
+	}
+
+	return p.capabilities, nil
+}
+
+// Providers 返回已注册的渠道名称
+func (r *Registry) Providers() []string {
+	names := make([]string, 0, len(r.processors))
+	for name := range r.processors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Currency 订单结算使用的币种
+func (r *Registry) Currency() string {
+	return r.currency
+}
+
+func (r *Registry) CreateCheckoutSession(ctx context.Context, req *domain.CheckoutRequest) (*domain.CheckoutSession, error) {
+	order := req.Order
+	currency := req.Currency
+	if currency == "" {
+		currency = r.currency
+	}
+
+	candidates, err := r.policy.Route(ctx, domain.RouteRequest{
+		OrderID:    order.ID,
+		CustomerID: order.CustomerID,
+		Currency:   currency,
+		Amount:     req.Amount - req.Credit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("route order %s: %w", order.ID, err)
+	}
+
+	var errs []error
+	for i, name := range candidates {
+		p, ok := r.processors[name]
+		if !ok {
+			errs = append(errs, domain.UnknownProviderError{Provider: name})
+			continue
+		}
+		if !p.capabilities.SupportsCurrency(currency) {
+			log.Debug().Ctx(ctx).Str("provider", name).Str("currency", currency).Msg("provider does not support currency, skip")
+			continue
+		}
+
+		session, err := p.processor.CreateCheckoutSession(ctx, req)
+		if err != nil {
+			log.Warn().Ctx(ctx).Err(err).
+				Str("provider", name).
+				Str("order_id", order.ID).
+				Msg("create checkout session failed, try next provider")
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+
+		if i > 0 {
+			log.Warn().Ctx(ctx).
+				Str("provider", name).
+				Str("primary", candidates[0]).
+				Str("order_id", order.ID).
+				Msg("checkout session created by secondary provider")
+		}
+		return session, nil
+	}
+
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("no payment provider available for order %s, candidates=%v", order.ID, candidates)
+	}
+	return nil, fmt.Errorf("create checkout session for order %s: %w", order.ID, errors.Join(errs...))
+}
@@ -0,0 +1,62 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/furutachiKurea/gorder/payment/domain"
+)
+
+// RoutingRule 命中规则的订单使用 Providers 中的渠道，条件为空表示不限制
+type RoutingRule struct {
+	CustomerIDs []string `mapstructure:"customer-ids"`
+	Currencies  []string `mapstructure:"currencies"`
+	// MinAmount, MaxAmount 金额区间，以货币最小单位表示，为 0 表示不限制
+	MinAmount int64    `mapstructure:"min-amount"`
+	MaxAmount int64    `mapstructure:"max-amount"`
+	Providers []string `mapstructure:"providers"`
+}
+
+func (r RoutingRule) match(req domain.RouteRequest) bool {
+	if len(r.CustomerIDs) > 0 && !slices.Contains(r.CustomerIDs, req.CustomerID) {
+		return false
+	}
+	if len(r.Currencies) > 0 && !slices.ContainsFunc(r.Currencies, func(c string) bool {
+		return strings.EqualFold(c, req.Currency)
+	}) {
+		return false
+	}
+	if r.MinAmount > 0 && req.Amount < r.MinAmount {
+		return false
+	}
+	if r.MaxAmount > 0 && req.Amount > r.MaxAmount {
+		return false
+	}
+
+	return true
+}
+
+// RulePolicy 按顺序匹配路由规则，第一个命中的规则决定候选渠道，都未命中时使用默认渠道
+type RulePolicy struct {
+	rules    []RoutingRule
+	defaults []string
+}
+
+func NewRulePolicy(rules []RoutingRule, defaults []string) *RulePolicy {
+	return &RulePolicy{rules: rules, defaults: defaults}
+}
+
+func (p *RulePolicy) Route(_ context.Context, req domain.RouteRequest) ([]string, error) {
+	for _, rule := range p.rules {
+		if rule.match(req) && len(rule.Providers) > 0 {
+			return rule.Providers, nil
+		}
+	}
+
+	if len(p.defaults) == 0 {
+		return nil, errors.New("no routing rule matched and no default provider")
+	}
+	return p.defaults, nil
+}
@@ -2,60 +2,165 @@ package processor
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
-	"github.com/furutachiKurea/gorder/common/entity"
+	"github.com/furutachiKurea/gorder/common/resilience"
+	"github.com/furutachiKurea/gorder/payment/domain"
+
 	"github.com/stripe/stripe-go/v84"
-	"github.com/stripe/stripe-go/v84/checkout/session"
 )
 
 const (
+	ProviderStripe = "stripe"
+
 	successURL = "http://localhost:8082/success"
 )
 
+// StripeCapabilities Stripe 支持退款和异步支付方式，币种由 Stripe 账户决定，这里不做限制
+var StripeCapabilities = domain.Capabilities{
+	Refunds:      true,
+	AsyncMethods: true,
+}
+
+// StripeProcessor 所有请求通过 resilience.Caller 发出，创建类请求使用由订单 ID 派生的幂等键，
+// 消息重投或重试时 Stripe 返回第一次创建的结果，不会重复创建支付会话
 type StripeProcessor struct {
-	apiKey string
+	client *stripe.Client
+	caller *resilience.Caller
 }
 
-func NewStripeProcessor(apiKey string) *StripeProcessor {
-	if apiKey == "" {
-		panic("empty API key")
+func NewStripeProcessor(client *stripe.Client, caller *resilience.Caller) *StripeProcessor {
+	if client == nil {
+		panic("client is nil")
 	}
 
-	stripe.Key = apiKey
+	if caller == nil {
+		panic("caller is nil")
+	}
 
-	return &StripeProcessor{apiKey: apiKey}
+	return &StripeProcessor{client: client, caller: caller}
 }
 
-func (s StripeProcessor) CreatePaymentLink(ctx context.Context, order *entity.Order) (string, error) {
-	var items []*stripe.CheckoutSessionLineItemParams
+// CreateCheckoutSession 订单有余额抵扣时创建一次性的满减优惠券，Stripe 收取的金额为抵扣后的剩余金额
+func (s StripeProcessor) CreateCheckoutSession(ctx context.Context, req *domain.CheckoutRequest) (*domain.CheckoutSession, error) {
+	order := req.Order
+	var items []*stripe.CheckoutSessionCreateLineItemParams
 	for _, item := range order.Items {
-		items = append(items, &stripe.CheckoutSessionLineItemParams{
+		items = append(items, &stripe.CheckoutSessionCreateLineItemParams{
 			Price:    stripe.String(item.PriceID),
 			Quantity: stripe.Int64(item.Quantity),
 		})
 	}
-	marshalledItems, _ := json.Marshal(order.Items)
 
+	// metadata 仅用于在 Stripe 控制台中定位订单，支付结果以 payment 服务的支付记录为准
 	metadata := map[string]string{
 		"order_id":    order.ID,
 		"customer_id": order.CustomerID,
-		"status":      string(order.Status),
-		"items":       string(marshalledItems),
 	}
 
-	params := &stripe.CheckoutSessionParams{
-		Metadata:   metadata,
-		LineItems:  items,
+	params := &stripe.CheckoutSessionCreateParams{
+		Metadata:          metadata,
+		ClientReferenceID: stripe.String(order.ID),
+		LineItems:         items,
+		// payment_intent 事件不携带支付会话，通过 order_id 定位订单待支付的记录
+		PaymentIntentData: &stripe.CheckoutSessionCreatePaymentIntentDataParams{
+			Metadata: map[string]string{"order_id": order.ID},
+		},
 		Mode:       stripe.String(stripe.CheckoutSessionModePayment),
 		SuccessURL: stripe.String(fmt.Sprintf("%s?order_id=%s&customer_id=%s", successURL, order.ID, order.CustomerID)),
 	}
+	params.SetIdempotencyKey(idempotencyKey("checkout-session", req))
+
+	if req.Credit > 0 {
+		c, err := s.creditCoupon(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		params.Discounts = []*stripe.CheckoutSessionCreateDiscountParams{{Coupon: stripe.String(c.ID)}}
+	}
+
+	var result *stripe.CheckoutSession
+	err := s.caller.Do(ctx, "create_checkout_session", func(ctx context.Context) (err error) {
+		result, err = s.client.V1CheckoutSessions.Create(ctx, params)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create payment link: %w", err)
+	}
+
+	return &domain.CheckoutSession{
+		Provider:  ProviderStripe,
+		SessionID: result.ID,
+		URL:       result.URL,
+		Amount:    result.AmountTotal,
+		Currency:  string(result.Currency),
+	}, nil
+}
+
+// creditCoupon 创建只能使用一次的优惠券，金额为余额抵扣的部分
+func (s StripeProcessor) creditCoupon(ctx context.Context, req *domain.CheckoutRequest) (*stripe.Coupon, error) {
+	params := &stripe.CouponCreateParams{
+		Name:           stripe.String("Gift card and store credit"),
+		AmountOff:      stripe.Int64(req.Credit),
+		Currency:       stripe.String(req.Currency),
+		Duration:       stripe.String(string(stripe.CouponDurationOnce)),
+		MaxRedemptions: stripe.Int64(1),
+		Metadata:       map[string]string{"order_id": req.Order.ID},
+	}
+	params.SetIdempotencyKey(idempotencyKey("credit-coupon", req))
+
+	var c *stripe.Coupon
+	err := s.caller.Do(ctx, "create_coupon", func(ctx context.Context) (err error) {
+		c, err = s.client.V1Coupons.Create(ctx, params)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create credit coupon for order %s: %w", req.Order.ID, err)
+	}
+
+	return c, nil
+}
 
-	result, err := session.New(params)
+func (s StripeProcessor) GetPaymentStatus(ctx context.Context, sessionID string) (*domain.PaymentStatus, error) {
+	var result *stripe.CheckoutSession
+	err := s.caller.Do(ctx, "get_checkout_session", func(ctx context.Context) (err error) {
+		result, err = s.client.V1CheckoutSessions.Retrieve(ctx, sessionID, &stripe.CheckoutSessionRetrieveParams{})
+		return err
+	})
 	if err != nil {
-		return "", fmt.Errorf("create payment link: %w", err)
+		return nil, fmt.Errorf("get checkout session %s: %w", sessionID, err)
 	}
 
-	return result.URL, nil
+	status := &domain.PaymentStatus{SessionID: result.ID, Status: domain.StatusPending}
+	if result.PaymentIntent != nil {
+		status.IntentID = result.PaymentIntent.ID
+	}
+	switch {
+	case result.PaymentStatus == stripe.CheckoutSessionPaymentStatusPaid:
+		status.Status = domain.StatusPaid
+	case result.Status == stripe.CheckoutSessionStatusExpired:
+		status.Status = domain.StatusExpired
+	}
+
+	return status, nil
+}
+
+func (s StripeProcessor) ExpireCheckoutSession(ctx context.Context, sessionID string) error {
+	params := &stripe.CheckoutSessionExpireParams{}
+	params.SetIdempotencyKey("expire-checkout-session-" + sessionID)
+
+	err := s.caller.Do(ctx, "expire_checkout_session", func(ctx context.Context) error {
+		_, err := s.client.V1CheckoutSessions.Expire(ctx, sessionID, params)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("expire checkout session %s: %w", sessionID, err)
+	}
+
+	return nil
+}
+
+// idempotencyKey 由订单 ID 和订单的第几个支付会话派生幂等键，重新生成支付链接时序号不同，会创建新的会话
+func idempotencyKey(prefix string, req *domain.CheckoutRequest) string {
+	return fmt.Sprintf("%s-%s-%d", prefix, req.Order.ID, req.Sequence)
 }
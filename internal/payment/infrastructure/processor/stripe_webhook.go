@@ -0,0 +1,97 @@
+package processor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/furutachiKurea/gorder/payment/domain"
+	"github.com/furutachiKurea/gorder/payment/infrastructure/stripeevent"
+
+	"github.com/stripe/stripe-go/v84"
+	"github.com/stripe/stripe-go/v84/webhook"
+)
+
+// StripeWebhookParser 解析 Stripe 格式的 Webhook，fake 渠道投递的事件格式与 Stripe 相同，使用不同的 provider 注册
+type StripeWebhookParser struct {
+	provider  string
+	secret    string
+	tolerance time.Duration
+}
+
+// NewStripeWebhookParser secret 为 Webhook 签名密钥，签名时间与当前时间相差超过 tolerance 的事件视为重放
+func NewStripeWebhookParser(provider, secret string, tolerance time.Duration) *StripeWebhookParser {
+	return &StripeWebhookParser{provider: provider, secret: secret, tolerance: tolerance}
+}
+
+func (p *StripeWebhookParser) Provider() string {
+	return p.provider
+}
+
+// Parse checkout.session 事件：completed 且已支付、async_payment_succeeded 为已支付，completed 但未支付表示异步支付仍在处理；
+// async_payment_failed 和 payment_intent.payment_failed 为失败，expired 为过期。payment_intent.payment_failed 不携带支付会话，通过 metadata 中的订单定位
+func (p *StripeWebhookParser) Parse(header http.Header, payload []byte) (*domain.WebhookEvent, error) {
+	event, err := webhook.ConstructEventWithTolerance(payload, header.Get(stripeevent.SignatureHeader), p.secret, p.tolerance)
+	if err != nil {
+		if errors.Is(err, webhook.ErrTooOld) {
+			return nil, fmt.Errorf("%w: %w", domain.ErrStaleWebhook, err)
+		}
+		return nil, fmt.Errorf("verifying webhook signature: %w", err)
+	}
+
+	parsed := &domain.WebhookEvent{
+		ID:        event.ID,
+		Type:      string(event.Type),
+		Provider:  p.provider,
+		CreatedAt: time.Unix(event.Created, 0),
+	}
+
+	switch event.Type {
+	case stripe.EventTypeCheckoutSessionCompleted,
+		stripe.EventTypeCheckoutSessionAsyncPaymentSucceeded,
+		stripe.EventTypeCheckoutSessionAsyncPaymentFailed,
+		stripe.EventTypeCheckoutSessionExpired:
+		var session stripe.CheckoutSession
+		if err = json.Unmarshal(event.Data.Raw, &session); err != nil {
+			return nil, fmt.Errorf("unmarshalling event.Data.Raw into session: %w", err)
+		}
+
+		parsed.ObjectID = session.ID
+		parsed.SessionID = session.ID
+		switch event.Type {
+		case stripe.EventTypeCheckoutSessionAsyncPaymentFailed:
+			parsed.Outcome = domain.StatusFailed
+			parsed.Reason = string(event.Type)
+		case stripe.EventTypeCheckoutSessionExpired:
+			parsed.Outcome = domain.StatusExpired
+		default:
+			parsed.Outcome = domain.StatusPending
+			if session.PaymentStatus == stripe.CheckoutSessionPaymentStatusPaid {
+				parsed.Outcome = domain.StatusPaid
+			}
+			if session.PaymentIntent != nil {
+				parsed.IntentID = session.PaymentIntent.ID
+			}
+		}
+	case stripe.EventTypePaymentIntentPaymentFailed:
+		var intent stripe.PaymentIntent
+		if err = json.Unmarshal(event.Data.Raw, &intent); err != nil {
+			return nil, fmt.Errorf("unmarshalling event.Data.Raw into payment intent: %w", err)
+		}
+
+		parsed.ObjectID = intent.ID
+		parsed.IntentID = intent.ID
+		parsed.OrderID = intent.Metadata["order_id"]
+		parsed.Outcome = domain.StatusFailed
+		parsed.Reason = string(event.Type)
+		if intent.LastPaymentError != nil && intent.LastPaymentError.Msg != "" {
+			parsed.Reason = intent.LastPaymentError.Msg
+		}
+	default:
+		return nil, nil
+	}
+
+	return parsed, nil
+}
@@ -0,0 +1,144 @@
+package processor
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/furutachiKurea/gorder/payment/domain"
+	"github.com/furutachiKurea/gorder/payment/infrastructure/stripeevent"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stripe/stripe-go/v84"
+)
+
+const testWebhookSecret = "whsec_test"
+
+func signedHeader(t *testing.T, eventType stripe.EventType, object any) (http.Header, []byte) {
+	t.Helper()
+
+	event, err := stripeevent.New(eventType, object, testWebhookSecret, time.Now())
+	require.NoError(t, err)
+
+	header := http.Header{}
+	header.Set(stripeevent.SignatureHeader, event.Header)
+	return header, event.Payload
+}
+
+func TestStripeWebhookParser_Parse(t *testing.T) {
+	session := func(paymentStatus string) stripeevent.CheckoutSession {
+		return stripeevent.CheckoutSession{
+			ID:            "cs_test",
+			Object:        "checkout.session",
+			Status:        "complete",
+			PaymentStatus: paymentStatus,
+			PaymentIntent: "pi_test",
+		}
+	}
+
+	tests := []struct {
+		name      string
+		eventType stripe.EventType
+		object    any
+		expected  *domain.WebhookEvent
+	}{
+		{
+			name:      "completed_paid",
+			eventType: stripe.EventTypeCheckoutSessionCompleted,
+			object:    session("paid"),
+			expected:  &domain.WebhookEvent{ObjectID: "cs_test", SessionID: "cs_test", IntentID: "pi_test", Outcome: domain.StatusPaid},
+		},
+		{
+			name:      "completed_async_processing",
+			eventType: stripe.EventTypeCheckoutSessionCompleted,
+			object:    session("unpaid"),
+			expected:  &domain.WebhookEvent{ObjectID: "cs_test", SessionID: "cs_test", IntentID: "pi_test", Outcome: domain.StatusPending},
+		},
+		{
+			name:      "async_payment_succeeded",
+			eventType: stripe.EventTypeCheckoutSessionAsyncPaymentSucceeded,
+			object:    session("paid"),
+			expected:  &domain.WebhookEvent{ObjectID: "cs_test", SessionID: "cs_test", IntentID: "pi_test", Outcome: domain.StatusPaid},
+		},
+		{
+			name:      "async_payment_failed",
+			eventType: stripe.EventTypeCheckoutSessionAsyncPaymentFailed,
+			object:    session("unpaid"),
+			expected: &domain.WebhookEvent{
+				ObjectID:  "cs_test",
+				SessionID: "cs_test",
+				Outcome:   domain.StatusFailed,
+				Reason:    string(stripe.EventTypeCheckoutSessionAsyncPaymentFailed),
+			},
+		},
+		{
+			name:      "expired",
+			eventType: stripe.EventTypeCheckoutSessionExpired,
+			object:    session("unpaid"),
+			expected:  &domain.WebhookEvent{ObjectID: "cs_test", SessionID: "cs_test", Outcome: domain.StatusExpired},
+		},
+		{
+			name:      "payment_intent_failed",
+			eventType: stripe.EventTypePaymentIntentPaymentFailed,
+			object: stripeevent.PaymentIntent{
+				ID:               "pi_test",
+				Object:           "payment_intent",
+				Status:           "requires_payment_method",
+				Metadata:         map[string]string{"order_id": "order"},
+				LastPaymentError: &stripeevent.PaymentError{Type: "card_error", Message: "Your card was declined."},
+			},
+			expected: &domain.WebhookEvent{
+				ObjectID: "pi_test",
+				IntentID: "pi_test",
+				OrderID:  "order",
+				Outcome:  domain.StatusFailed,
+				Reason:   "Your card was declined.",
+			},
+		},
+	}
+
+	parser := NewStripeWebhookParser(ProviderStripe, testWebhookSecret, time.Minute)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, payload := signedHeader(t, tt.eventType, tt.object)
+
+			got, err := parser.Parse(header, payload)
+			require.NoError(t, err)
+			require.NotNil(t, got)
+			assert.NotEmpty(t, got.ID)
+			assert.Equal(t, string(tt.eventType), got.Type)
+			assert.Equal(t, ProviderStripe, got.Provider)
+			assert.False(t, got.CreatedAt.IsZero())
+
+			got.ID, got.Type, got.Provider, got.CreatedAt = "", "", "", time.Time{}
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestStripeWebhookParser_Parse_IgnoredEvent(t *testing.T) {
+	parser := NewStripeWebhookParser(ProviderStripe, testWebhookSecret, time.Minute)
+	header, payload := signedHeader(t, stripe.EventTypeChargeRefunded, stripeevent.Charge{ID: "ch_test", Object: "charge"})
+
+	got, err := parser.Parse(header, payload)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestStripeWebhookParser_Parse_Signature(t *testing.T) {
+	parser := NewStripeWebhookParser(ProviderStripe, testWebhookSecret, time.Minute)
+	_, payload := signedHeader(t, stripe.EventTypeCheckoutSessionExpired, stripeevent.CheckoutSession{ID: "cs_test"})
+
+	// 使用其他密钥签名
+	header := http.Header{}
+	header.Set(stripeevent.SignatureHeader, stripeevent.Sign(payload, "whsec_other", time.Now()))
+	_, err := parser.Parse(header, payload)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, domain.ErrStaleWebhook)
+
+	// 签名时间超过允许的时间差视为重放
+	header.Set(stripeevent.SignatureHeader, stripeevent.Sign(payload, testWebhookSecret, time.Now().Add(-time.Hour)))
+	_, err = parser.Parse(header, payload)
+	assert.ErrorIs(t, err, domain.ErrStaleWebhook)
+}
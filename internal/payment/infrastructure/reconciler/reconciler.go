@@ -0,0 +1,85 @@
+package reconciler
+
+import (
+	"context"
+	"time"
+
+	"github.com/furutachiKurea/gorder/payment/app"
+	"github.com/furutachiKurea/gorder/payment/app/command"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Reconciler 定期与支付渠道对账，补偿因服务宕机、签名密钥轮换等原因丢失的 Webhook
+type Reconciler struct {
+	app       app.Application
+	interval  time.Duration
+	olderThan time.Duration
+	batchSize int
+}
+
+// NewReconciler interval 为对账间隔，olderThan 为待支付记录创建多久后才参与对账，batchSize 为每次对账的最大记录数
+func NewReconciler(app app.Application, interval, olderThan time.Duration, batchSize int) *Reconciler {
+	return &Reconciler{
+		app:       app,
+		interval:  interval,
+		olderThan: olderThan,
+		batchSize: batchSize,
+	}
+}
+
+// Run 按 interval 执行对账直到 ctx 结束
+func (r *Reconciler) Run(ctx context.Context) {
+	if r.interval <= 0 {
+		log.Warn().Dur("interval", r.interval).Msg("payment reconciler disabled")
+		return
+	}
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.runOnce(ctx)
+		}
+	}
+}
+
+func (r *Reconciler) runOnce(ctx context.Context) {
+	report, err := r.app.Commands.ReconcilePayments.Handle(ctx, command.ReconcilePayments{
+		OlderThan: r.olderThan,
+		Limit:     r.batchSize,
+	})
+	if err != nil {
+		log.Error().Ctx(ctx).Err(err).Msg("payment reconcile failed")
+		return
+	}
+
+	var resolved, unresolved int
+	for _, d := range report.Discrepancies {
+		if d.Resolved {
+			resolved++
+			continue
+		}
+		unresolved++
+		log.Error().Ctx(ctx).
+			Err(d.Err).
+			Str("payment_id", d.PaymentID).
+			Str("order_id", d.OrderID).
+			Str("session_id", d.SessionID).
+			Str("provider_status", string(d.ProviderStatus)).
+			Msg("payment discrepancy unresolved")
+	}
+
+	log.Info().Ctx(ctx).
+		Time("started_at", report.StartedAt).
+		Int("checked", report.Checked).
+		Int("still_pending", report.StillPending).
+		Int("discrepancies", len(report.Discrepancies)).
+		Int("resolved", resolved).
+		Int("unresolved", unresolved).
+		Msg("payment reconcile report")
+}
@@ -0,0 +1,153 @@
+// Package stripeevent 在本地构造并签名与 Stripe 格式一致的 Webhook 事件，
+// 签名可以通过 webhook.ConstructEvent 校验，用于在没有 Stripe 账号和网络的环境中驱动 Webhook 处理流程
+package stripeevent
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/stripe/stripe-go/v84"
+	"github.com/stripe/stripe-go/v84/webhook"
+)
+
+// SignatureHeader Stripe 签名所在的请求头
+const SignatureHeader = "Stripe-Signature"
+
+// CheckoutSession 事件中 checkout.session 对象的字段，只包含 Webhook 处理需要的部分
+type CheckoutSession struct {
+	ID                string            `json:"id"`
+	Object            string            `json:"object"`
+	ClientReferenceID string            `json:"client_reference_id,omitempty"`
+	Status            string            `json:"status"`
+	PaymentStatus     string            `json:"payment_status"`
+	PaymentIntent     string            `json:"payment_intent,omitempty"`
+	AmountTotal       int64             `json:"amount_total"`
+	Currency          string            `json:"currency,omitempty"`
+	Metadata          map[string]string `json:"metadata,omitempty"`
+}
+
+// PaymentIntent 事件中 payment_intent 对象的字段
+type PaymentIntent struct {
+	ID               string            `json:"id"`
+	Object           string            `json:"object"`
+	Amount           int64             `json:"amount"`
+	Currency         string            `json:"currency,omitempty"`
+	Status           string            `json:"status"`
+	Metadata         map[string]string `json:"metadata,omitempty"`
+	LastPaymentError *PaymentError     `json:"last_payment_error,omitempty"`
+}
+
+// PaymentError payment_intent.last_payment_error 的字段
+type PaymentError struct {
+	Type    string `json:"type"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Charge 事件中 charge 对象的字段，用于 charge.refunded 等退款事件
+type Charge struct {
+	ID             string            `json:"id"`
+	Object         string            `json:"object"`
+	Amount         int64             `json:"amount"`
+	AmountRefunded int64             `json:"amount_refunded"`
+	Currency       string            `json:"currency,omitempty"`
+	Paid           bool              `json:"paid"`
+	Refunded       bool              `json:"refunded"`
+	PaymentIntent  string            `json:"payment_intent,omitempty"`
+	Status         string            `json:"status"`
+	Metadata       map[string]string `json:"metadata,omitempty"`
+}
+
+// Event 签名后的事件，Payload 为请求体，Header 为 Stripe-Signature 请求头的值
+type Event struct {
+	ID      string
+	Type    stripe.EventType
+	Payload []byte
+	Header  string
+}
+
+// NewID 生成带前缀的随机 ID，格式与 Stripe 对象 ID 一致，如 evt_xxx、cs_xxx
+func NewID(prefix string) string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return prefix + "_" + hex.EncodeToString(b)
+}
+
+// New 构造 eventType 类型的事件并使用 secret 签名，object 为事件的 data.object
+func New(eventType stripe.EventType, object any, secret string, createdAt time.Time) (*Event, error) {
+	raw, err := json.Marshal(object)
+	if err != nil {
+		return nil, fmt.Errorf("marshal event object: %w", err)
+	}
+
+	id := NewID("evt")
+	payload, err := json.Marshal(map[string]any{
+		"id":               id,
+		"object":           "event",
+		"api_version":      stripe.APIVersion,
+		"created":          createdAt.Unix(),
+		"type":             eventType,
+		"livemode":         false,
+		"pending_webhooks": 1,
+		"data": map[string]json.RawMessage{
+			"object": raw,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal event: %w", err)
+	}
+
+	return &Event{
+		ID:      id,
+		Type:    eventType,
+		Payload: payload,
+		Header:  Sign(payload, secret, time.Now()),
+	}, nil
+}
+
+// Sign 使用 Stripe 的 v1 签名方式计算 Stripe-Signature 请求头
+func Sign(payload []byte, secret string, t time.Time) string {
+	return fmt.Sprintf("t=%d,v1=%s", t.Unix(), hex.EncodeToString(webhook.ComputeSignature(t, payload, secret)))
+}
+
+// Send 将事件投递到 Webhook 地址，非 2xx 响应视为失败
+func Send(ctx context.Context, client *http.Client, url string, event *Event) error {
+	status, body, err := Post(ctx, client, url, event)
+	if err != nil {
+		return err
+	}
+
+	if status < 200 || status >= 300 {
+		return fmt.Errorf("send event %s: webhook responded %d: %s", event.ID, status, body)
+	}
+
+	return nil
+}
+
+// Post 将事件投递到 Webhook 地址，返回响应状态码和最多 1KB 的响应体；event.Header 为空时不携带签名请求头
+func Post(ctx context.Context, client *http.Client, url string, event *Event) (status int, body []byte, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(event.Payload))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if event.Header != "" {
+		req.Header.Set(SignatureHeader, event.Header)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("send event %s: %w", event.ID, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, _ = io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return resp.StatusCode, body, nil
+}
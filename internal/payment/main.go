@@ -2,18 +2,30 @@ package main
 
 import (
 	"context"
+	"os"
+	"os/signal"
 
 	"github.com/furutachiKurea/gorder/common/broker"
 	_ "github.com/furutachiKurea/gorder/common/config"
+	"github.com/furutachiKurea/gorder/common/discovery"
+	"github.com/furutachiKurea/gorder/common/genproto/paymentpb"
 	"github.com/furutachiKurea/gorder/common/logging"
+	"github.com/furutachiKurea/gorder/common/metrics"
+	"github.com/furutachiKurea/gorder/common/resilience"
 	"github.com/furutachiKurea/gorder/common/server"
 	"github.com/furutachiKurea/gorder/common/tracing"
+	"github.com/furutachiKurea/gorder/payment/domain"
 	"github.com/furutachiKurea/gorder/payment/infrastructure/consumer"
+	"github.com/furutachiKurea/gorder/payment/infrastructure/processor"
+	"github.com/furutachiKurea/gorder/payment/infrastructure/reconciler"
+	"github.com/furutachiKurea/gorder/payment/ports"
 	"github.com/furutachiKurea/gorder/payment/service"
 
+	"github.com/gin-gonic/gin"
 	_ "github.com/joho/godotenv/autoload"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/viper"
+	"google.golang.org/grpc"
 )
 
 func init() {
@@ -21,7 +33,6 @@ func init() {
 }
 func main() {
 	serviceName := viper.GetString("payment.service-name")
-	serverType := viper.GetString("payment.server-to-run")
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -34,30 +45,127 @@ func main() {
 		_ = shutdown(ctx)
 	}()
 
-	app, cleanup := service.NewApplication(ctx)
-	defer cleanup()
-
-	ch, closeCoon := broker.Connect(
+	metricsClient := metrics.NewPrometheusMetricsClient(
+		&metrics.PrometheusMetricsClientConfig{
+			Host:        viper.GetString("payment.metrics-export-addr"),
+			ServiceName: serviceName,
+		})
+	stripeCaller, err := resilience.NewStripeCallerFromConfig(metricsClient)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to create stripe caller")
+	}
+	registry, webhooks, registerProcessorRoutes := newRegistry(stripeCaller)
+	mq, closeMQ := broker.Connect(
 		viper.GetString("rabbitmq.user"),
 		viper.GetString("rabbitmq.password"),
 		viper.GetString("rabbitmq.host"),
 		viper.GetString("rabbitmq.port"),
+		metricsClient,
 	)
 	defer func() {
-		_ = ch.Close()
-		_ = closeCoon()
+		_ = closeMQ()
 	}()
 
-	go consumer.NewConsumer(app).Listen(ch)
+	app, cleanup := service.NewApplication(ctx, mq, registry, newQuoter(registry.Currency(), stripeCaller), metricsClient)
+	defer cleanup()
+
+	deregisterFn, err := discovery.RegisterToConsul(ctx, serviceName, mq.Health)
+	if err != nil {
+		log.Fatal().Err(err).Msgf("failed to register service %s to consul", serviceName)
+	}
+	defer func() { _ = deregisterFn() }()
+
+	retry, err := broker.RetryPolicyFromConfig("payment.retry")
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to parse payment retry policy")
+	}
+	go consumer.NewConsumer(app, mq, retry).Listen(mq)
+
+	go reconciler.NewReconciler(
+		app,
+		viper.GetDuration("payment.reconcile-interval"),
+		viper.GetDuration("payment.reconcile-after"),
+		viper.GetInt("payment.reconcile-batch-size"),
+	).Run(ctx)
+
+	go server.RunGRPCServer(serviceName, func(server *grpc.Server) {
+		svc := ports.NewGRPCServer(app)
+		paymentpb.RegisterPaymentServiceServer(server, svc)
+	})
+
+	go server.RunHTTPServer(serviceName, func(router *gin.Engine) {
+		server.RegisterHealthRoutes(router, map[string]server.HealthCheck{"rabbitmq": mq.Health})
+		NewPaymentHandler(app, webhooks, metricsClient).RegisterRoutes(router)
+		registerProcessorRoutes(router)
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer stop()
 
-	paymentHandler := NewPaymentHandler(ch)
+	<-ctx.Done()
+}
+
+// newRegistry 注册 payment.processors 中启用的支付渠道，返回渠道注册表、各渠道的 Webhook 解析器以及渠道需要额外注册的 HTTP 路由。
+// fake 渠道不依赖 Stripe 账号和网络，支付链接指向本服务提供的模拟支付页面
+func newRegistry(stripeCaller *resilience.Caller) (*processor.Registry, []domain.WebhookParser, func(router *gin.Engine)) {
+	var rules []processor.RoutingRule
+	if err := viper.UnmarshalKey("payment.routing.rules", &rules); err != nil {
+		log.Fatal().Err(err).Msg("failed to parse payment routing rules")
+	}
+
+	providers := viper.GetStringSlice("payment.processors")
+	if len(providers) == 0 {
+		providers = []string{processor.ProviderStripe}
+	}
+	defaults := viper.GetStringSlice("payment.routing.default")
+	if len(defaults) == 0 {
+		defaults = providers
+	}
+
+	registry := processor.NewRegistry(processor.NewRulePolicy(rules, defaults), viper.GetString("payment.currency"))
+	secret := viper.GetString("endpoint-stripe-secret")
+	tolerance := viper.GetDuration("payment.webhook-tolerance")
+
+	var (
+		webhooks []domain.WebhookParser
+		routes   []func(router *gin.Engine)
+		err      error
+	)
+	for _, provider := range providers {
+		switch provider {
+		case processor.ProviderStripe:
+			err = registry.Register(provider, processor.NewStripeProcessor(resilience.NewStripeClient(viper.GetString("stripe-key")), stripeCaller), processor.StripeCapabilities)
+		case processor.ProviderFake:
+			fake := processor.NewFakeProcessor("http://"+viper.GetString("payment.http-addr"), secret)
+			err = registry.Register(provider, fake, processor.FakeCapabilities)
+			routes = append(routes, fake.RegisterRoutes)
+		default:
+			log.Fatal().Str("processor", provider).Msg("unsupported payment processor")
+		}
+		if err != nil {
+			log.Fatal().Err(err).Str("processor", provider).Msg("failed to register payment processor")
+		}
+
+		// fake 渠道投递的事件与 Stripe 格式相同
+		webhooks = append(webhooks, processor.NewStripeWebhookParser(provider, secret, tolerance))
+	}
+
+	return registry, webhooks, func(router *gin.Engine) {
+		for _, register := range routes {
+			register(router)
+		}
+	}
+}
 
-	switch serverType {
-	case "grpc":
-		log.Panic().Str("serverType", serverType).Msg("unsupported server type")
-	case "http":
-		server.RunHTTPServer(serviceName, paymentHandler.RegisterRoutes)
+// newQuoter 按 payment.pricing 选择订单报价方式: stripe 使用商品的 Stripe 价格，fixed 所有商品使用 payment.fixed-unit-amount 的单价
+func newQuoter(currency string, stripeCaller *resilience.Caller) domain.PriceQuoter {
+	switch pricing := viper.GetString("payment.pricing"); pricing {
+	case "", "stripe":
+		return processor.NewStripeQuoter(resilience.NewStripeClient(viper.GetString("stripe-key")), stripeCaller)
+	case "fixed":
+		return processor.NewFixedQuoter(viper.GetInt64("payment.fixed-unit-amount"), currency)
 	default:
-		log.Panic().Str("serverType", serverType).Msg("unsupported server type")
+		log.Fatal().Str("pricing", pricing).Msg("unsupported payment pricing")
+		return nil
 	}
 }
@@ -0,0 +1,95 @@
+package ports
+
+import (
+	"context"
+	"errors"
+
+	"github.com/furutachiKurea/gorder/common/convertor"
+	"github.com/furutachiKurea/gorder/common/genproto/paymentpb"
+	"github.com/furutachiKurea/gorder/payment/app"
+	"github.com/furutachiKurea/gorder/payment/app/command"
+	"github.com/furutachiKurea/gorder/payment/app/query"
+	"github.com/furutachiKurea/gorder/payment/domain"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type GRPCServer struct {
+	app app.Application
+}
+
+func NewGRPCServer(app app.Application) *GRPCServer {
+	return &GRPCServer{app: app}
+}
+
+func (G GRPCServer) GetPayment(ctx context.Context, request *paymentpb.GetPaymentRequest) (*paymentpb.GetPaymentResponse, error) {
+	if request.PaymentId == "" && request.OrderId == "" {
+		return nil, status.Error(codes.InvalidArgument, "payment_id or order_id is required")
+	}
+
+	payments, err := G.app.Queries.GetPayment.Handle(ctx, query.GetPayment{
+		PaymentID: request.PaymentId,
+		OrderID:   request.OrderId,
+	})
+	if err != nil {
+		var notFound domain.NotFoundError
+		if errors.As(err, &notFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	res := &paymentpb.GetPaymentResponse{}
+	for _, p := range payments {
+		res.Payments = append(res.Payments, PaymentToProto(p))
+	}
+	return res, nil
+}
+
+func (G GRPCServer) RegeneratePaymentLink(ctx context.Context, request *paymentpb.RegeneratePaymentLinkRequest) (*paymentpb.RegeneratePaymentLinkResponse, error) {
+	if request.Order == nil || request.Order.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "order is required")
+	}
+
+	payment, err := G.app.Commands.RegeneratePaymentLink.Handle(ctx, command.RegeneratePaymentLink{
+		Order: convertor.NewOrderConvertor().ProtoToEntity(request.Order),
+	})
+	if err != nil {
+		var alreadyPaid domain.AlreadyPaidError
+		if errors.As(err, &alreadyPaid) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &paymentpb.RegeneratePaymentLinkResponse{
+		PaymentId:   payment.ID,
+		PaymentLink: payment.PaymentLink,
+	}, nil
+}
+
+// PaymentToProto 将支付记录转换为 protobuf 结构，时间使用 unix 秒，未支付时 paid_at 为 0
+func PaymentToProto(p *domain.Payment) *paymentpb.Payment {
+	pb := &paymentpb.Payment{
+		Id:                p.ID,
+		OrderId:           p.OrderID,
+		CustomerId:        p.CustomerID,
+		Provider:          p.Provider,
+		ProviderSessionId: p.ProviderSessionID,
+		ProviderIntentId:  p.ProviderIntentID,
+		Amount:            p.Amount,
+		Currency:          p.Currency,
+		Status:            string(p.Status),
+		PaymentLink:       p.PaymentLink,
+		Items:             convertor.NewItemConvertor().EntitiesToProtos(p.Items),
+		CreatedAt:         p.CreatedAt.Unix(),
+		UpdatedAt:         p.UpdatedAt.Unix(),
+		FailureReason:     p.FailureReason,
+		Credit:            p.Credit,
+	}
+	if p.PaidAt != nil {
+		pb.PaidAt = p.PaidAt.Unix()
+	}
+	return pb
+}
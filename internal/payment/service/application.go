@@ -2,48 +2,150 @@ package service
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/furutachiKurea/gorder/common/broker"
 	grpcclient "github.com/furutachiKurea/gorder/common/client"
-	"github.com/furutachiKurea/gorder/common/metrics"
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/handler/redis"
 	"github.com/furutachiKurea/gorder/payment/adapter"
 	"github.com/furutachiKurea/gorder/payment/app"
 	"github.com/furutachiKurea/gorder/payment/app/command"
+	"github.com/furutachiKurea/gorder/payment/app/query"
 	"github.com/furutachiKurea/gorder/payment/domain"
-	"github.com/furutachiKurea/gorder/payment/infrastructure/processor"
+
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
-func NewApplication(ctx context.Context) (app app.Application, close func()) {
+func NewApplication(
+	ctx context.Context,
+	publisher broker.Publisher,
+	processors domain.ProcessorRegistry,
+	quoter domain.PriceQuoter,
+	metricsClient decorator.MetricsClient,
+) (app app.Application, close func()) {
 	orderClient, closeOrderClient, err := grpcclient.NewOrderGRPCClient(ctx)
 	if err != nil {
 		panic(err)
 	}
 
 	orderGRPC := adapter.NewOderGRPC(orderClient)
-	// memoryProcessor := processor.NewInmemProcessor()
-	stripeProcessor := processor.NewStripeProcessor(viper.GetString("stripe-key"))
+	mongoClient, disconnectMongo := newMongoClient(ctx)
+	paymentRepo := adapter.NewPaymentRepositoryMongo(mongoClient)
+	ledger := adapter.NewLedgerMongo(mongoClient)
+	eventStore := adapter.NewWebhookEventStoreRedis(redis.LocalClient(), viper.GetDuration("payment.webhook-event-ttl"))
 
-	return newApplication(ctx, stripeProcessor, orderGRPC), func() {
+	return newApplication(ctx, processors, quoter, paymentRepo, ledger, eventStore, orderGRPC, publisher, metricsClient), func() {
 		_ = closeOrderClient()
+		_ = disconnectMongo(ctx)
 	}
 }
 
-func newApplication(_ context.Context, processor domain.Processor, orderGRPC command.OrderService) app.Application {
+func newApplication(
+	_ context.Context,
+	processors domain.ProcessorRegistry,
+	quoter domain.PriceQuoter,
+	paymentRepo domain.Repository,
+	ledger domain.Ledger,
+	eventStore domain.WebhookEventStore,
+	orderGRPC command.OrderService,
+	publisher broker.Publisher,
+	metricsClient decorator.MetricsClient,
+) app.Application {
 	logger := log.Logger
-	metricsClient := metrics.NewPrometheusMetricsClient(
-		&metrics.PrometheusMetricsClientConfig{
-			Host:        viper.GetString("payment.metrics-export-addr"),
-			ServiceName: viper.GetString("payment.service-name"),
-		})
+	completeCheckout := command.NewCompleteCheckoutHandler(
+		paymentRepo,
+		ledger,
+		publisher,
+		logger,
+		metricsClient,
+	)
+	closeCheckout := command.NewCloseCheckoutHandler(
+		processors,
+		paymentRepo,
+		ledger,
+		publisher,
+		logger,
+		metricsClient,
+	)
 	return app.Application{
 		Commands: app.Commands{
 			CreatePayment: command.NewCreatePaymentHandler(
-				processor,
+				processors,
+				paymentRepo,
+				ledger,
+				quoter,
+				completeCheckout,
+				orderGRPC,
+				logger,
+				metricsClient,
+			),
+			CompleteCheckout: completeCheckout,
+			CloseCheckout:    closeCheckout,
+			ProcessWebhookEvent: command.NewProcessWebhookEventHandler(
+				eventStore,
+				logger,
+				metricsClient,
+			),
+			ReconcilePayments: command.NewReconcilePaymentsHandler(
+				paymentRepo,
+				processors,
+				completeCheckout,
+				closeCheckout,
+				logger,
+				metricsClient,
+			),
+			RegeneratePaymentLink: command.NewRegeneratePaymentLinkHandler(
+				processors,
+				paymentRepo,
+				ledger,
+				quoter,
+				completeCheckout,
 				orderGRPC,
 				logger,
 				metricsClient,
 			),
+			IssueCredit: command.NewIssueCreditHandler(
+				ledger,
+				logger,
+				metricsClient,
+			),
+		},
+		Queries: app.Queries{
+			GetPayment: query.NewGetPaymentHandler(
+				paymentRepo,
+				logger,
+				metricsClient,
+			),
+			GetCreditAccounts: query.NewGetCreditAccountsHandler(
+				ledger,
+				logger,
+				metricsClient,
+			),
 		},
 	}
 }
+
+func newMongoClient(ctx context.Context) (*mongo.Client, func(ctx context.Context) error) {
+	uri := fmt.Sprintf("mongodb://%s:%s@%s:%s",
+		viper.GetString("mongo.user"),
+		viper.GetString("mongo.password"),
+		viper.GetString("mongo.host"),
+		viper.GetString("mongo.port"),
+	)
+
+	c, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		panic(err)
+	}
+
+	if err = c.Ping(ctx, readpref.Primary()); err != nil {
+		panic(err)
+	}
+
+	return c, c.Disconnect
+}
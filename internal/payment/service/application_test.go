@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/broker"
+	"github.com/furutachiKurea/gorder/common/consts"
+	"github.com/furutachiKurea/gorder/common/entity"
+	"github.com/furutachiKurea/gorder/common/metrics"
+	"github.com/furutachiKurea/gorder/payment/adapter"
+	"github.com/furutachiKurea/gorder/payment/app/command"
+	"github.com/furutachiKurea/gorder/payment/domain"
+	"github.com/furutachiKurea/gorder/payment/infrastructure/processor"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 测试只处理已有的支付记录，不会创建支付会话、报价、去重 Webhook 或回调订单服务
+type (
+	noProcessors struct{ domain.ProcessorRegistry }
+	noQuoter     struct{ domain.PriceQuoter }
+	noEventStore struct{ domain.WebhookEventStore }
+	noOrders     struct{ command.OrderService }
+)
+
+// expiringProcessors 记录被失效的支付会话
+type expiringProcessors struct {
+	domain.ProcessorRegistry
+	processor *expiringProcessor
+}
+
+func (r expiringProcessors) Get(string) (domain.Processor, error) {
+	return r.processor, nil
+}
+
+type expiringProcessor struct {
+	domain.Processor
+	expired []string
+}
+
+func (p *expiringProcessor) ExpireCheckoutSession(_ context.Context, sessionID string) error {
+	p.expired = append(p.expired, sessionID)
+	return nil
+}
+
+func TestApplication_CheckoutOutcomes(t *testing.T) {
+	ctx := context.Background()
+	mb := broker.NewMemoryBroker()
+	defer mb.Close()
+
+	paymentRepo := adapter.NewMemoryPaymentRepository()
+	application := newApplication(
+		ctx,
+		noProcessors{},
+		noQuoter{},
+		paymentRepo,
+		adapter.NewMemoryLedger(),
+		noEventStore{},
+		noOrders{},
+		mb,
+		metrics.TodoMetrics{},
+	)
+
+	pending, err := domain.NewPendingPayment(
+		&entity.Order{ID: "order", CustomerID: "customer", Status: consts.OrderStatusWaitingForPayment},
+		&domain.CheckoutSession{Provider: processor.ProviderStripe, SessionID: "cs_test", Amount: 100, Currency: "usd"},
+		0,
+	)
+	require.NoError(t, err)
+	created, err := paymentRepo.Create(ctx, pending)
+	require.NoError(t, err)
+
+	// 没有服务订阅 order.paid 时记录已经标记为已支付，返回发布失败的错误
+	complete := command.CompleteCheckout{Provider: processor.ProviderStripe, SessionID: "cs_test", IntentID: "pi_test", PaidAt: time.Now()}
+	_, err = application.Commands.CompleteCheckout.Handle(ctx, complete)
+	var returnErr broker.ReturnError
+	require.ErrorAs(t, err, &returnErr)
+	got, err := paymentRepo.Get(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusPaid, got.Status)
+
+	// 渠道重试 Webhook 时重新发布 order.paid
+	subCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	msgs, err := mb.Subscribe(subCtx, broker.Subscription{Queue: "test", Exchange: broker.EventOrderPaid})
+	require.NoError(t, err)
+
+	_, err = application.Commands.CompleteCheckout.Handle(ctx, complete)
+	require.NoError(t, err)
+	select {
+	case d := <-msgs:
+		var o entity.Order
+		require.NoError(t, json.Unmarshal(d.Body, &o))
+		assert.Equal(t, "order", o.ID)
+		assert.Equal(t, consts.OrderStatusPaid, o.Status)
+		require.NoError(t, d.Ack())
+	case <-time.After(time.Second):
+		require.FailNow(t, "order paid event not published")
+	}
+
+	// 已支付的记录不会被关闭
+	closed, err := application.Commands.CloseCheckout.Handle(ctx, command.CloseCheckout{
+		Provider:  processor.ProviderStripe,
+		SessionID: "cs_test",
+		Outcome:   domain.StatusExpired,
+		ClosedAt:  time.Now(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusPaid, closed.Status)
+}
+
+func TestApplication_ReplacedCheckoutCompletedAfterOrderPaid(t *testing.T) {
+	ctx := context.Background()
+	mb := broker.NewMemoryBroker()
+	defer mb.Close()
+
+	paymentRepo := adapter.NewMemoryPaymentRepository()
+	application := newApplication(
+		ctx,
+		noProcessors{},
+		noQuoter{},
+		paymentRepo,
+		adapter.NewMemoryLedger(),
+		noEventStore{},
+		noOrders{},
+		mb,
+		metrics.TodoMetrics{},
+	)
+
+	order := &entity.Order{ID: "order", CustomerID: "customer", Status: consts.OrderStatusWaitingForPayment}
+	create := func(sessionID string) *domain.Payment {
+		pending, err := domain.NewPendingPayment(
+			order,
+			&domain.CheckoutSession{Provider: processor.ProviderStripe, SessionID: sessionID, Amount: 100, Currency: "usd"},
+			0,
+		)
+		require.NoError(t, err)
+		created, err := paymentRepo.Create(ctx, pending)
+		require.NoError(t, err)
+		return created
+	}
+
+	// 重新生成支付链接后旧记录被替换，新会话完成支付
+	replaced := create("cs_old")
+	require.NoError(t, paymentRepo.Update(ctx, replaced.ID, func(_ context.Context, p *domain.Payment) (*domain.Payment, error) {
+		return p, p.MarkReplaced(time.Now())
+	}))
+	create("cs_new")
+
+	subCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	msgs, err := mb.Subscribe(subCtx, broker.Subscription{Queue: "test", Exchange: broker.EventOrderPaid})
+	require.NoError(t, err)
+
+	_, err = application.Commands.CompleteCheckout.Handle(ctx, command.CompleteCheckout{
+		Provider: processor.ProviderStripe, SessionID: "cs_new", IntentID: "pi_new", PaidAt: time.Now(),
+	})
+	require.NoError(t, err)
+	select {
+	case d := <-msgs:
+		require.NoError(t, d.Ack())
+	case <-time.After(time.Second):
+		require.FailNow(t, "order paid event not published")
+	}
+
+	// 旧会话失效前也完成了支付，标记为需要退款且不会再次发布 order.paid
+	got, err := application.Commands.CompleteCheckout.Handle(ctx, command.CompleteCheckout{
+		Provider: processor.ProviderStripe, SessionID: "cs_old", IntentID: "pi_old", PaidAt: time.Now(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusRefundRequired, got.Status)
+	assert.Equal(t, "pi_old", got.ProviderIntentID)
+	select {
+	case <-msgs:
+		assert.Fail(t, "order paid event published twice")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestApplication_PaymentIntentFailed(t *testing.T) {
+	ctx := context.Background()
+	mb := broker.NewMemoryBroker()
+	defer mb.Close()
+
+	expiring := &expiringProcessor{}
+	paymentRepo := adapter.NewMemoryPaymentRepository()
+	application := newApplication(
+		ctx,
+		expiringProcessors{processor: expiring},
+		noQuoter{},
+		paymentRepo,
+		adapter.NewMemoryLedger(),
+		noEventStore{},
+		noOrders{},
+		mb,
+		metrics.TodoMetrics{},
+	)
+
+	pending, err := domain.NewPendingPayment(
+		&entity.Order{ID: "order", CustomerID: "customer", Status: consts.OrderStatusWaitingForPayment},
+		&domain.CheckoutSession{Provider: processor.ProviderStripe, SessionID: "cs_test", Amount: 100, Currency: "usd"},
+		0,
+	)
+	require.NoError(t, err)
+	created, err := paymentRepo.Create(ctx, pending)
+	require.NoError(t, err)
+
+	subCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	msgs, err := mb.Subscribe(subCtx, broker.Subscription{Queue: "test", Exchange: broker.EventOrderPaymentFailed})
+	require.NoError(t, err)
+
+	// payment_intent 事件不携带会话，按订单关闭待支付的记录并发布 order.payment_failed
+	closed, err := application.Commands.CloseCheckout.Handle(ctx, command.CloseCheckout{
+		Provider: processor.ProviderStripe,
+		OrderID:  "order",
+		Outcome:  domain.StatusFailed,
+		Reason:   "Your card was declined.",
+		ClosedAt: time.Now(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusFailed, closed.Status)
+	assert.Equal(t, "Your card was declined.", closed.FailureReason)
+	assert.Equal(t, []string{"cs_test"}, expiring.expired)
+	select {
+	case d := <-msgs:
+		var o entity.Order
+		require.NoError(t, json.Unmarshal(d.Body, &o))
+		assert.Equal(t, consts.OrderStatusPaymentFailed, o.Status)
+		require.NoError(t, d.Ack())
+	case <-time.After(time.Second):
+		require.FailNow(t, "order payment failed event not published")
+	}
+
+	// 会话失效前完成了支付，订单已经释放库存，记录标记为需要退款
+	got, err := application.Commands.CompleteCheckout.Handle(ctx, command.CompleteCheckout{
+		Provider: processor.ProviderStripe, SessionID: "cs_test", IntentID: "pi_test", PaidAt: time.Now(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusRefundRequired, got.Status)
+	got, err = paymentRepo.Get(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusRefundRequired, got.Status)
+}
@@ -42,7 +42,7 @@ type MemoryStockRepository struct {
 }
 
 // Deprecated: use StockRepositoryMySQL.ReserveStock.
-func (m MemoryStockRepository) ReserveStock(ctx context.Context, items []*entity.ItemWithQuantity) error {
+func (m MemoryStockRepository) ReserveStock(ctx context.Context, items []*entity.ItemWithQuantity) ([]*domain.Reservation, error) {
 	// TODO implement me
 	panic("implement me")
 }
@@ -81,7 +81,7 @@ func (m MemoryStockRepository) GetItems(ctx context.Context, ids []string) ([]*e
 }
 
 // Deprecated: use StockRepositoryMySQL.GetStock.
-func (m MemoryStockRepository) GetStock(ctx context.Context, ids []string) ([]*entity.ItemWithQuantity, error) {
+func (m MemoryStockRepository) GetStock(ctx context.Context, ids []string) ([]*domain.Stock, error) {
 	// TODO implement me
 	panic("implement me")
 }
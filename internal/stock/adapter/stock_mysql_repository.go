@@ -3,7 +3,12 @@ package adapter
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/furutachiKurea/gorder/common/consts"
 	"github.com/furutachiKurea/gorder/common/entity"
 	domain "github.com/furutachiKurea/gorder/stock/domain/stock"
 	"github.com/furutachiKurea/gorder/stock/infrastructure/persistent"
@@ -26,45 +31,90 @@ func (s StockRepositoryMySQL) GetItems(ctx context.Context, ids []string) ([]*en
 	panic("implement me")
 }
 
-func (s StockRepositoryMySQL) GetStock(ctx context.Context, ids []string) ([]*entity.ItemWithQuantity, error) {
-	data, err := s.db.BatchGetStockByID(ctx, ids)
+// GetStock 获取商品库存，组合商品的库存由组成商品计算
+func (s StockRepositoryMySQL) GetStock(ctx context.Context, ids []string) ([]*domain.Stock, error) {
+	bundles, err := s.getBundles(ctx, s.db, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	toQuery := ids
+	for _, b := range bundles {
+		for _, c := range b.Components {
+			toQuery = append(toQuery, c.ProductID)
+		}
+	}
+
+	data, err := s.db.BatchGetStockByID(ctx, toQuery)
 	if err != nil {
 		return nil, fmt.Errorf("batch get stock by id: %w", err)
 	}
 
-	var result []*entity.ItemWithQuantity
+	stocks := make(map[string]*domain.Stock, len(data))
 	for _, d := range data {
-		result = append(result, &entity.ItemWithQuantity{
-			ID:       d.ProductID,
-			Quantity: d.Quantity,
-		})
+		stocks[d.ProductID] = toDomainStock(&d)
+	}
+
+	var result []*domain.Stock
+	for _, id := range ids {
+		if b, ok := bundles[id]; ok {
+			result = append(result, b.Stock(stocks))
+		} else if stock, ok := stocks[id]; ok {
+			result = append(result, stock)
+		}
 	}
 
 	return result, nil
 }
 
 // ReserveStock 预占库存，使用悲观锁保证一致性
-func (s StockRepositoryMySQL) ReserveStock(ctx context.Context, items []*entity.ItemWithQuantity) error {
-	return s.db.StartTransaction(func(tx *gorm.DB) (err error) {
+func (s StockRepositoryMySQL) ReserveStock(ctx context.Context, items []*entity.ItemWithQuantity) ([]*domain.Reservation, error) {
+	var reservations []*domain.Reservation
+	err := s.db.StartTransaction(func(tx *gorm.DB) (err error) {
 		defer func() {
 			if err != nil {
 				log.Warn().Ctx(ctx).Err(err).Msg("reserve stock transaction failed")
 			}
 		}()
 
-		stocks, err := s.getAndLockStock(ctx, tx, items)
+		bundles, err := s.getBundles(ctx, persistent.NewMySQLWithDB(tx), getIDsFromItems(items))
+		if err != nil {
+			return err
+		}
+		expanded, bundleComponents := domain.ExpandBundles(items, bundles)
+
+		stocks, err := s.getAndLockStock(ctx, tx, expanded)
 		if err != nil {
 			return err
 		}
 
 		// 如果获取到的商品库存记录数量少于请求数量，说明请求了不存在的商品
-		if missingIDs := findMissingProductIDs(items, stocks); len(missingIDs) > 0 {
+		if missingIDs := findMissingProductIDs(expanded, stocks); len(missingIDs) > 0 {
 			return domain.NotFoundError{Missing: missingIDs}
 		}
 
-		err = s.tryReserveStock(ctx, tx, items)
-		return
+		reservations, err = s.tryReserveStock(ctx, tx, expanded, stocks, bundleComponents)
+		if err != nil {
+			return err
+		}
+
+		// 组合商品只能由现货满足，组成商品全部预占成功即为现货
+		for _, item := range items {
+			if _, ok := bundles[item.ID]; ok {
+				reservations = append(reservations, &domain.Reservation{
+					ProductID: item.ID,
+					Quantity:  item.Quantity,
+					Status:    consts.FulfilmentInStock,
+				})
+			}
+		}
+		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reservations, nil
 }
 
 func (s StockRepositoryMySQL) ConfirmStockReservation(ctx context.Context, items []*entity.ItemWithQuantity) error {
@@ -75,18 +125,192 @@ func (s StockRepositoryMySQL) ConfirmStockReservation(ctx context.Context, items
 			}
 		}()
 
-		_, err = s.getAndLockStock(ctx, tx, items)
+		bundles, err := s.getBundles(ctx, persistent.NewMySQLWithDB(tx), getIDsFromItems(items))
+		if err != nil {
+			return err
+		}
+		expanded, _ := domain.ExpandBundles(items, bundles)
+
+		stocks, err := s.getAndLockStock(ctx, tx, expanded)
 		if err != nil {
 			return err
 		}
 
-		err = s.tryConfirmStockReservation(ctx, tx, items)
+		err = s.tryConfirmStockReservation(ctx, tx, expanded, stocks)
 
 		return
 	})
 }
 
-// getAndLockStock 通过 item.id 获取库存并锁定库存记录
+// ReleaseStockReservation 释放预占库存并取消缺货预订：待分配的预订减少缺货预订数量，已分配的预订减少预占库存，
+// 已取消的预订会被忽略，重复释放同一批预订不会重复扣减。释放后按先后顺序将库存分配给涉及商品的待处理缺货预订
+func (s StockRepositoryMySQL) ReleaseStockReservation(
+	ctx context.Context,
+	items []*entity.ItemWithQuantity,
+	backorderIDs []string,
+) ([]*domain.Allocation, error) {
+	var allocations []*domain.Allocation
+	err := s.db.StartTransaction(func(tx *gorm.DB) (err error) {
+		defer func() {
+			if err != nil {
+				log.Warn().Ctx(ctx).Err(err).Msg("release stock reservation transaction failed")
+			}
+		}()
+		// 死锁重试时事务会重新执行，丢弃上一次执行得到的分配结果
+		allocations = nil
+
+		bundles, err := s.getBundles(ctx, persistent.NewMySQLWithDB(tx), getIDsFromItems(items))
+		if err != nil {
+			return err
+		}
+		toRelease, _ := domain.ExpandBundles(items, bundles)
+
+		// 先读取预订确定涉及的商品，按与补货相同的顺序先锁库存再锁预订，避免死锁
+		var backorders []*persistent.BackorderModel
+		if len(backorderIDs) > 0 {
+			if err = tx.WithContext(ctx).Where("id IN ?", backorderIDs).Find(&backorders).Error; err != nil {
+				return fmt.Errorf("get backorders from db: %w", err)
+			}
+		}
+
+		toLock := append([]*entity.ItemWithQuantity(nil), toRelease...)
+		for _, b := range backorders {
+			toLock = append(toLock, &entity.ItemWithQuantity{ID: b.ProductID, Quantity: b.Quantity})
+		}
+		if len(toLock) == 0 {
+			return nil
+		}
+
+		stocks, err := s.getAndLockStock(ctx, tx, toLock)
+		if err != nil {
+			return err
+		}
+		if missingIDs := findMissingProductIDs(toLock, stocks); len(missingIDs) > 0 {
+			return domain.NotFoundError{Missing: missingIDs}
+		}
+
+		var toUnbackorder []*entity.ItemWithQuantity
+		if len(backorders) > 0 {
+			if err = tx.WithContext(ctx).
+				Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).
+				Where("id IN ?", backorderIDs).
+				Order("id").
+				Find(&backorders).Error; err != nil {
+				return fmt.Errorf("lock backorders in db: %w", err)
+			}
+		}
+
+		var cancelled []int64
+		for _, b := range backorders {
+			item := &entity.ItemWithQuantity{ID: b.ProductID, Quantity: b.Quantity}
+			switch b.Status {
+			case persistent.BackorderStatusPending:
+				toUnbackorder = append(toUnbackorder, item)
+			case persistent.BackorderStatusAllocated:
+				toRelease = append(toRelease, item)
+			default:
+				continue
+			}
+			cancelled = append(cancelled, b.ID)
+		}
+
+		if err = s.tryReleaseStock(ctx, tx, "reserved", mergeDeltas(toRelease), stocks); err != nil {
+			return err
+		}
+		if err = s.tryReleaseStock(ctx, tx, "backordered", mergeDeltas(toUnbackorder), stocks); err != nil {
+			return err
+		}
+
+		if len(cancelled) > 0 {
+			if err = tx.WithContext(ctx).
+				Model(persistent.BackorderModel{}).
+				Where("id IN ?", cancelled).
+				Update("status", persistent.BackorderStatusCancelled).Error; err != nil {
+				return fmt.Errorf("cancel backorders in db: %w", err)
+			}
+		}
+
+		allocations, err = s.allocateBackorders(ctx, tx, productIDsOf(mergeDeltas(toLock)))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return allocations, nil
+}
+
+// tryReleaseStock 将 column (reserved 或 backordered) 按商品减去 deltas，所有商品在一条 UPDATE 中完成，任一商品不足则整体失败
+func (s StockRepositoryMySQL) tryReleaseStock(
+	ctx context.Context,
+	tx *gorm.DB,
+	column string,
+	deltas []productDelta,
+	locked []*persistent.StockModel,
+) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+
+	current := make(map[string]int64, len(locked))
+	for _, m := range locked {
+		if column == "reserved" {
+			current[m.ProductID] = m.Reserved
+		} else {
+			current[m.ProductID] = m.Backordered
+		}
+	}
+
+	var failedOn []string
+	for _, d := range deltas {
+		if current[d.ProductID] < d.Delta {
+			failedOn = append(failedOn, d.ProductID)
+		}
+	}
+	if len(failedOn) > 0 {
+		return fmt.Errorf("release %s stock failed for product_id=%s", column, strings.Join(failedOn, ","))
+	}
+
+	result := tx.WithContext(ctx).
+		Model(persistent.StockModel{}).
+		Where("product_id IN ?", productIDsOf(deltas)).
+		Where(conditionExpr(rowCondition{cond: column + " >= ?", deltas: deltas})).
+		Update(column, caseExpr(column, "-", deltas))
+	if result.Error != nil {
+		return fmt.Errorf("update stock in db: %w", result.Error)
+	}
+	if result.RowsAffected != int64(len(deltas)) {
+		return fmt.Errorf("release %s stock: expected %d rows updated, got %d", column, len(deltas), result.RowsAffected)
+	}
+
+	return nil
+}
+
+// getBundles 获取请求中组合商品的物料清单，返回以组合商品 ID 为 key 的 map。
+// 事务中需要传入使用 tx 的 db，保证物料清单与库存在同一事务中读取
+func (s StockRepositoryMySQL) getBundles(ctx context.Context, db *persistent.MySQL, ids []string) (map[string]*domain.Bundle, error) {
+	boms, err := db.BatchGetBOMByBundleID(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("get bom by bundle ids from db: %w", err)
+	}
+
+	bundles := make(map[string]*domain.Bundle)
+	for _, bom := range boms {
+		b, ok := bundles[bom.BundleID]
+		if !ok {
+			b = &domain.Bundle{ProductID: bom.BundleID}
+			bundles[bom.BundleID] = b
+		}
+		b.Components = append(b.Components, domain.Component{
+			ProductID: bom.ComponentID,
+			Quantity:  bom.Quantity,
+		})
+	}
+
+	return bundles, nil
+}
+
+// getAndLockStock 通过 item.id 获取库存并锁定库存记录，按 product_id 顺序加锁以避免并发事务间的死锁
 func (s StockRepositoryMySQL) getAndLockStock(
 	ctx context.Context,
 	tx *gorm.DB,
@@ -98,6 +322,7 @@ func (s StockRepositoryMySQL) getAndLockStock(
 		Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).
 		Model(persistent.StockModel{}).
 		Where("product_id IN (?)", getIDsFromItems(items)).
+		Order("product_id").
 		Find(&stocks).Error
 	if err != nil {
 		return nil, fmt.Errorf("get stock by ids from db: %w", err)
@@ -106,106 +331,399 @@ func (s StockRepositoryMySQL) getAndLockStock(
 	return stocks, nil
 }
 
-// tryReserveStock 尝试预占库存
+// tryReserveStock 尝试预占库存，现货不足时按库存策略记录缺货预订，任一商品无法满足则整体失败并返回所有商品的缺口。
+// inStockOnly 中的商品来自组合商品，只能由现货满足。同一商品既单独购买又作为组合商品的组成商品时，
+// 预订按商品合并，合并后的总量都必须由现货满足，即使单独购买的部分允许缺货预订。
+// 所有商品的变更在一条 UPDATE 中完成，条件检查作为已加锁快照之外的兜底
 func (s StockRepositoryMySQL) tryReserveStock(
 	ctx context.Context,
 	tx *gorm.DB,
 	toReserve []*entity.ItemWithQuantity,
-) error {
+	locked []*persistent.StockModel,
+	inStockOnly map[string]struct{},
+) ([]*domain.Reservation, error) {
 
-	requiredQuantities := make(map[string]int64)
-	for _, item := range toReserve {
-		requiredQuantities[item.ID] += item.Quantity
+	stocks := make(map[string]*domain.Stock)
+	for _, m := range locked {
+		stocks[m.ProductID] = toDomainStock(m)
 	}
 
 	var (
-		failedOn []struct {
+		reserveDeltas   []productDelta
+		backorderDeltas []productDelta
+		backorders      []*persistent.BackorderModel
+		reservations    []*domain.Reservation
+		failedOn        []struct {
 			ID   string
 			Want int64
 			Have int64
 		}
-		failedProductIDs []string
 	)
-	for productID, required := range requiredQuantities {
-		if required == 0 {
-			continue
+	for _, d := range mergeDeltas(toReserve) {
+		stock := stocks[d.ProductID]
+		status, ok := stock.Fulfil(d.Delta)
+		if _, bundled := inStockOnly[d.ProductID]; bundled && status != consts.FulfilmentInStock {
+			ok = false
 		}
 
-		result := tx.Model(persistent.StockModel{}).
-			Where("product_id = ? AND quantity - reserved >= ?", productID, required).
-			Update("reserved", gorm.Expr("reserved + ?", required))
-
-		if result.Error != nil {
-			return fmt.Errorf("update stock in db: %w", result.Error)
-		}
-
-		// 未更新成功，说明库存不足，记录失败信息
-		if result.RowsAffected == 0 {
-			failedProductIDs = append(failedProductIDs, productID)
+		// 库存不足，记录失败信息
+		if !ok {
 			failedOn = append(failedOn, struct {
 				ID   string
 				Want int64
 				Have int64
-			}{ID: productID, Want: required, Have: 0})
+			}{ID: d.ProductID, Want: d.Delta, Have: stock.InStock()})
+			continue
 		}
-	}
 
-	// 如果存在失败商品
-	if len(failedProductIDs) > 0 {
-		var failed []persistent.StockModel
-		if err := tx.WithContext(ctx).
-			Model(persistent.StockModel{}).
-			Where("product_id IN (?)", failedProductIDs).
-			Find(&failed).Error; err != nil {
-			return fmt.Errorf("get failed stocks from db: %w", err)
+		reservations = append(reservations, &domain.Reservation{
+			ProductID: d.ProductID,
+			Quantity:  d.Delta,
+			Status:    status,
+		})
+		if status == consts.FulfilmentInStock {
+			reserveDeltas = append(reserveDeltas, d)
+			continue
 		}
 
-		have := make(map[string]int64)
-		for _, item := range failed {
-			have[item.ProductID] += item.Quantity - item.Reserved
-		}
+		backorderDeltas = append(backorderDeltas, d)
+		backorders = append(backorders, &persistent.BackorderModel{
+			ProductID: d.ProductID,
+			Quantity:  d.Delta,
+			Status:    persistent.BackorderStatusPending,
+		})
+		reservations[len(reservations)-1].AvailableAt = stock.Policy.AvailableAt
+	}
 
-		for i, fail := range failedOn {
-			failedOn[i].Have = have[fail.ID]
+	if len(failedOn) > 0 {
+		return nil, domain.ExceedStockError{FailedOn: failedOn}
+	}
+
+	updates := make(map[string]any)
+	if len(reserveDeltas) > 0 {
+		updates["reserved"] = caseExpr("reserved", "+", reserveDeltas)
+	}
+	if len(backorderDeltas) > 0 {
+		updates["backordered"] = caseExpr("backordered", "+", backorderDeltas)
+	}
+	if len(updates) == 0 {
+		return reservations, nil
+	}
+
+	all := append(append([]productDelta(nil), reserveDeltas...), backorderDeltas...)
+	cond := conditionExpr(
+		rowCondition{cond: "backordered = 0 AND quantity - reserved >= ?", deltas: reserveDeltas},
+		rowCondition{cond: "backordered + ? <= backorder_limit", deltas: backorderDeltas},
+	)
+	result := tx.WithContext(ctx).
+		Model(persistent.StockModel{}).
+		Where("product_id IN ?", productIDsOf(all)).
+		Where(cond).
+		Updates(updates)
+	if result.Error != nil {
+		return nil, fmt.Errorf("update stock in db: %w", result.Error)
+	}
+	if result.RowsAffected != int64(len(all)) {
+		return nil, fmt.Errorf("reserve stock: expected %d rows updated, got %d", len(all), result.RowsAffected)
+	}
+
+	if len(backorders) > 0 {
+		if err := tx.WithContext(ctx).Create(backorders).Error; err != nil {
+			return nil, fmt.Errorf("create backorder in db: %w", err)
+		}
+		byProduct := make(map[string]string, len(backorders))
+		for _, b := range backorders {
+			byProduct[b.ProductID] = strconv.FormatInt(b.ID, 10)
+		}
+		for _, r := range reservations {
+			r.BackorderID = byProduct[r.ProductID]
 		}
-		return domain.ExceedStockError{FailedOn: failedOn}
 	}
-	return nil
+
+	return reservations, nil
 }
 
+// tryConfirmStockReservation 扣减实际库存和预占库存，所有商品在一条 UPDATE 中完成，任一商品预占不足则整体失败
 func (s StockRepositoryMySQL) tryConfirmStockReservation(
 	ctx context.Context,
 	tx *gorm.DB,
 	toConfirm []*entity.ItemWithQuantity,
+	locked []*persistent.StockModel,
 ) error {
 
-	confirmedReservation := make(map[string]int64)
-	for _, item := range toConfirm {
-		confirmedReservation[item.ID] += item.Quantity
+	stocks := make(map[string]*persistent.StockModel, len(locked))
+	for _, m := range locked {
+		stocks[m.ProductID] = m
 	}
 
-	var ()
+	var (
+		deltas   []productDelta
+		failedOn []string
+	)
+	for _, d := range mergeDeltas(toConfirm) {
+		stock, ok := stocks[d.ProductID]
+		if !ok || stock.Reserved < d.Delta || stock.Quantity < stock.Reserved {
+			failedOn = append(failedOn, d.ProductID)
+			continue
+		}
+		deltas = append(deltas, d)
+	}
+	if len(failedOn) > 0 {
+		return fmt.Errorf("confirm stock reservation failed for product_id=%s", strings.Join(failedOn, ","))
+	}
+	if len(deltas) == 0 {
+		return nil
+	}
 
-	for productID, quantity := range confirmedReservation {
+	result := tx.WithContext(ctx).
+		Model(persistent.StockModel{}).
+		Where("product_id IN ?", productIDsOf(deltas)).
+		Where(conditionExpr(rowCondition{cond: "quantity >= reserved AND reserved >= ?", deltas: deltas})).
+		Updates(map[string]any{
+			"quantity": caseExpr("quantity", "-", deltas),
+			"reserved": caseExpr("reserved", "-", deltas),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("update stock in db: %w", result.Error)
+	}
+	if result.RowsAffected != int64(len(deltas)) {
+		return fmt.Errorf("confirm stock reservation: expected %d rows updated, got %d", len(deltas), result.RowsAffected)
+	}
+
+	return nil
+}
+
+// productDelta 单个商品的库存变化量
+type productDelta struct {
+	ProductID string
+	Delta     int64
+}
+
+// mergeDeltas 合并相同商品的数量并按商品 ID 排序，忽略数量为 0 的商品
+func mergeDeltas(items []*entity.ItemWithQuantity) []productDelta {
+	merged := make(map[string]int64)
+	for _, item := range items {
+		merged[item.ID] += item.Quantity
+	}
+
+	deltas := make([]productDelta, 0, len(merged))
+	for id, quantity := range merged {
 		if quantity == 0 {
 			continue
 		}
+		deltas = append(deltas, productDelta{ProductID: id, Delta: quantity})
+	}
+	sort.Slice(deltas, func(i, j int) bool {
+		return deltas[i].ProductID < deltas[j].ProductID
+	})
+
+	return deltas
+}
+
+func productIDsOf(deltas []productDelta) []string {
+	ids := make([]string, 0, len(deltas))
+	for _, d := range deltas {
+		ids = append(ids, d.ProductID)
+	}
+
+	return ids
+}
+
+// caseExpr 生成按商品更新列的表达式: CASE product_id WHEN ? THEN column op ? ... ELSE column END
+func caseExpr(column, op string, deltas []productDelta) clause.Expr {
+	var (
+		sb   strings.Builder
+		args = make([]any, 0, 2*len(deltas))
+	)
+	sb.WriteString("CASE product_id")
+	for _, d := range deltas {
+		sb.WriteString(" WHEN ? THEN " + column + " " + op + " ?")
+		args = append(args, d.ProductID, d.Delta)
+	}
+	sb.WriteString(" ELSE " + column + " END")
+
+	return gorm.Expr(sb.String(), args...)
+}
+
+// rowCondition 一组商品需要满足的条件，cond 中的 ? 为商品的变化量
+type rowCondition struct {
+	cond   string
+	deltas []productDelta
+}
+
+// conditionExpr 生成按商品检查的条件: CASE product_id WHEN ? THEN <cond> ... ELSE FALSE END
+func conditionExpr(conds ...rowCondition) clause.Expr {
+	var (
+		sb   strings.Builder
+		args []any
+	)
+	sb.WriteString("CASE product_id")
+	for _, c := range conds {
+		for _, d := range c.deltas {
+			sb.WriteString(" WHEN ? THEN " + c.cond)
+			args = append(args, d.ProductID, d.Delta)
+		}
+	}
+	sb.WriteString(" ELSE FALSE END")
 
-		result := tx.WithContext(ctx).Model(persistent.StockModel{}).
-			Where("product_id = ? AND quantity >= reserved AND reserved >= ?", productID, quantity).
-			Updates(map[string]any{
-				"quantity": gorm.Expr("quantity - ?", quantity),
-				"reserved": gorm.Expr("reserved - ?", quantity),
+	return gorm.Expr(sb.String(), args...)
+}
+
+func (s StockRepositoryMySQL) UpdatePolicy(ctx context.Context, productID string, policy domain.Policy) error {
+	affected, err := s.db.UpdateStockPolicy(ctx, productID, string(policy.Type), policy.BackorderLimit, policy.AvailableAt)
+	if err != nil {
+		return fmt.Errorf("update stock policy in db: %w", err)
+	}
+	if affected == 0 {
+		return domain.NotFoundError{Missing: []string{productID}}
+	}
+
+	return nil
+}
+
+// Restock 增加库存后按 ID 顺序分配待处理的缺货预订
+func (s StockRepositoryMySQL) Restock(ctx context.Context, productID string, quantity int64) ([]*domain.Allocation, error) {
+	var allocations []*domain.Allocation
+	err := s.db.StartTransaction(func(tx *gorm.DB) (err error) {
+		defer func() {
+			if err != nil {
+				log.Warn().Ctx(ctx).Err(err).Msg("restock transaction failed")
+			}
+		}()
+		// 死锁重试时事务会重新执行，丢弃上一次执行得到的分配结果
+		allocations = nil
+
+		stocks, err := s.getAndLockStock(ctx, tx, []*entity.ItemWithQuantity{{ID: productID, Quantity: quantity}})
+		if err != nil {
+			return err
+		}
+		if len(stocks) == 0 {
+			return domain.NotFoundError{Missing: []string{productID}}
+		}
+
+		if err = tx.WithContext(ctx).
+			Model(persistent.StockModel{}).
+			Where("product_id = ?", productID).
+			Update("quantity", gorm.Expr("quantity + ?", quantity)).Error; err != nil {
+			return fmt.Errorf("update stock in db: %w", err)
+		}
+
+		allocations, err = s.allocateBackorders(ctx, tx, []string{productID})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return allocations, nil
+}
+
+// allocateBackorders 按 ID 顺序将商品的可售库存分配给待处理的缺货预订，排在前面的预订无法满足时不会跳过它分配后面的预订。
+// 调用方需要已经在 tx 中锁定这些商品的库存记录
+func (s StockRepositoryMySQL) allocateBackorders(ctx context.Context, tx *gorm.DB, productIDs []string) ([]*domain.Allocation, error) {
+	var stocks []*persistent.StockModel
+	if err := tx.WithContext(ctx).
+		Where("product_id IN ? AND backordered > 0", productIDs).
+		Order("product_id").
+		Find(&stocks).Error; err != nil {
+		return nil, fmt.Errorf("get stock by ids from db: %w", err)
+	}
+	if len(stocks) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(stocks))
+	for _, m := range stocks {
+		ids = append(ids, m.ProductID)
+	}
+	var pending []*persistent.BackorderModel
+	if err := tx.WithContext(ctx).
+		Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).
+		Where("product_id IN ? AND status = ?", ids, persistent.BackorderStatusPending).
+		Order("id").
+		Find(&pending).Error; err != nil {
+		return nil, fmt.Errorf("get pending backorders from db: %w", err)
+	}
+
+	byProduct := make(map[string][]*persistent.BackorderModel, len(stocks))
+	for _, b := range pending {
+		byProduct[b.ProductID] = append(byProduct[b.ProductID], b)
+	}
+
+	var (
+		allocations []*domain.Allocation
+		allocated   []int64
+		deltas      []productDelta
+	)
+	for _, m := range stocks {
+		var (
+			available = m.Quantity - m.Reserved
+			delta     int64
+		)
+		for _, b := range byProduct[m.ProductID] {
+			if b.Quantity > available {
+				break
+			}
+
+			available -= b.Quantity
+			delta += b.Quantity
+			allocated = append(allocated, b.ID)
+			allocations = append(allocations, &domain.Allocation{
+				BackorderID: strconv.FormatInt(b.ID, 10),
+				ProductID:   m.ProductID,
+				Quantity:    b.Quantity,
 			})
-		if result.Error != nil {
-			return fmt.Errorf("update stock in db: %w", result.Error)
 		}
-		if result.RowsAffected == 0 {
-			return fmt.Errorf("confirm stock reservation failed for product_id=%s", productID)
+		if delta > 0 {
+			deltas = append(deltas, productDelta{ProductID: m.ProductID, Delta: delta})
 		}
 	}
+	if len(deltas) == 0 {
+		return nil, nil
+	}
 
-	return nil
+	if err := tx.WithContext(ctx).
+		Model(persistent.BackorderModel{}).
+		Where("id IN ?", allocated).
+		Updates(map[string]any{
+			"status":       persistent.BackorderStatusAllocated,
+			"allocated_at": time.Now(),
+		}).Error; err != nil {
+		return nil, fmt.Errorf("allocate backorders in db: %w", err)
+	}
+
+	if err := tx.WithContext(ctx).
+		Model(persistent.StockModel{}).
+		Where("product_id IN ?", productIDsOf(deltas)).
+		Updates(map[string]any{
+			"reserved":    caseExpr("reserved", "+", deltas),
+			"backordered": caseExpr("backordered", "-", deltas),
+		}).Error; err != nil {
+		return nil, fmt.Errorf("update stock in db: %w", err)
+	}
+
+	return allocations, nil
+}
+
+// toDomainStock 将数据库记录转换为领域对象，无法识别的策略按 deny 处理
+func toDomainStock(m *persistent.StockModel) *domain.Stock {
+	policyType, err := domain.ParsePolicyType(m.Policy)
+	if err != nil {
+		log.Warn().Err(err).Str("product_id", m.ProductID).Msg("unknown stock policy, fallback to deny")
+		policyType = domain.PolicyDeny
+	}
+
+	return &domain.Stock{
+		ProductID:   m.ProductID,
+		Quantity:    m.Quantity,
+		Reserved:    m.Reserved,
+		Backordered: m.Backordered,
+		Policy: domain.Policy{
+			Type:           policyType,
+			BackorderLimit: m.BackorderLimit,
+			AvailableAt:    m.AvailableAt,
+		},
+	}
 }
 
 // findMissingProductIDs 比较期望的商品列表和实际从数据库获取的库存列表，返回缺失的商品 ID 列表
@@ -3,13 +3,18 @@ package adapter
 import (
 	"context"
 	"fmt"
+	"math"
+	"math/rand/v2"
 	"sync"
 	"testing"
 
 	_ "github.com/furutachiKurea/gorder/common/config"
+	"github.com/furutachiKurea/gorder/common/consts"
 	"github.com/furutachiKurea/gorder/common/entity"
+	domain "github.com/furutachiKurea/gorder/stock/domain/stock"
 	"github.com/furutachiKurea/gorder/stock/infrastructure/persistent"
 
+	mysqldriver "github.com/go-sql-driver/mysql"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -18,7 +23,12 @@ import (
 	"gorm.io/gorm"
 )
 
-func setupTestDB(t *testing.T) *persistent.MySQL {
+func setupTestDB(t testing.TB) *persistent.MySQL {
+	return persistent.NewMySQLWithDB(setupTestGormDB(t))
+}
+
+// setupTestGormDB 创建测试数据库并执行迁移，返回底层连接用于注册测试回调
+func setupTestGormDB(t testing.TB) *gorm.DB {
 	cfg := viper.Sub("mysql")
 
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
@@ -44,9 +54,13 @@ func setupTestDB(t *testing.T) *persistent.MySQL {
 	)
 	db, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
 	assert.NoError(t, err)
-	assert.NoError(t, db.AutoMigrate(persistent.StockModel{}))
 
-	return persistent.NewMySQLWithDB(db)
+	migrator, err := persistent.NewMigrator(persistent.NewMySQLWithDB(db))
+	require.NoError(t, err)
+	_, err = migrator.Up(context.Background())
+	require.NoError(t, err)
+
+	return db
 }
 
 func TestStockRepositoryMySQL_ReserveStock_Race(t *testing.T) {
@@ -72,7 +86,7 @@ func TestStockRepositoryMySQL_ReserveStock_Race(t *testing.T) {
 
 	for range concurrentGoroutines {
 		g.Go(func() error {
-			err := repo.ReserveStock(ctx, []*entity.ItemWithQuantity{{ID: testItem, Quantity: 1}})
+			_, err := repo.ReserveStock(ctx, []*entity.ItemWithQuantity{{ID: testItem, Quantity: 1}})
 			return err
 		})
 	}
@@ -112,7 +126,7 @@ func TestStockRepositoryMySQL_ReserveStock_OverSell(t *testing.T) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			_ = repo.ReserveStock(ctx, []*entity.ItemWithQuantity{{ID: testItem, Quantity: 1}})
+			_, _ = repo.ReserveStock(ctx, []*entity.ItemWithQuantity{{ID: testItem, Quantity: 1}})
 		}()
 	}
 	wg.Wait()
@@ -233,7 +247,7 @@ func TestStockRepositoryMySQL_ReserveStock(t *testing.T) {
 			require.NoError(t, err)
 
 			repo := NewStockRepositoryMySQL(db)
-			err = repo.ReserveStock(ctx, tt.toUpdate)
+			_, err = repo.ReserveStock(ctx, tt.toUpdate)
 
 			if tt.wantErr {
 				require.Error(t, err)
@@ -397,3 +411,344 @@ func TestStockRepositoryMySQL_ConfirmStockReservation(t *testing.T) {
 		})
 	}
 }
+
+func TestStockRepositoryMySQL_ReserveStock_Backorder(t *testing.T) {
+	tests := []struct {
+		name                string
+		stock               *persistent.StockModel
+		want                int64
+		expectedStatus      consts.FulfilmentStatus
+		expectedReserved    int64
+		expectedBackordered int64
+		wantErr             bool
+	}{
+		{
+			name:             "in_stock",
+			stock:            &persistent.StockModel{ProductID: "item-1", Quantity: 5, Policy: "backorder", BackorderLimit: 10},
+			want:             5,
+			expectedStatus:   consts.FulfilmentInStock,
+			expectedReserved: 5,
+		},
+		{
+			name:                "backordered",
+			stock:               &persistent.StockModel{ProductID: "item-1", Quantity: 5, Policy: "backorder", BackorderLimit: 10},
+			want:                6,
+			expectedStatus:      consts.FulfilmentBackordered,
+			expectedBackordered: 6,
+		},
+		{
+			name:    "exceed_backorder_limit",
+			stock:   &persistent.StockModel{ProductID: "item-1", Quantity: 5, Backordered: 8, Policy: "backorder", BackorderLimit: 10},
+			want:    6,
+			wantErr: true,
+			// 失败时不应修改已有的缺货预订
+			expectedBackordered: 8,
+		},
+		{
+			name:                "preordered",
+			stock:               &persistent.StockModel{ProductID: "item-1", Policy: "preorder", BackorderLimit: 100},
+			want:                3,
+			expectedStatus:      consts.FulfilmentPreordered,
+			expectedBackordered: 3,
+		},
+		{
+			name:    "deny",
+			stock:   &persistent.StockModel{ProductID: "item-1", Quantity: 5, BackorderLimit: 100},
+			want:    6,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupTestDB(t)
+			ctx := context.Background()
+			require.NoError(t, db.CreateBatch(ctx, []*persistent.StockModel{tt.stock}))
+
+			repo := NewStockRepositoryMySQL(db)
+			reservations, err := repo.ReserveStock(ctx, []*entity.ItemWithQuantity{{ID: tt.stock.ProductID, Quantity: tt.want}})
+			if tt.wantErr {
+				var exceed domain.ExceedStockError
+				require.ErrorAs(t, err, &exceed)
+			} else {
+				require.NoError(t, err)
+				require.Len(t, reservations, 1)
+				assert.Equal(t, tt.expectedStatus, reservations[0].Status)
+				assert.Equal(t, tt.expectedStatus != consts.FulfilmentInStock, reservations[0].BackorderID != "")
+			}
+
+			stocks, err := db.BatchGetStockByID(ctx, []string{tt.stock.ProductID})
+			require.NoError(t, err)
+			require.Len(t, stocks, 1)
+			assert.Equal(t, tt.expectedReserved, stocks[0].Reserved)
+			assert.Equal(t, tt.expectedBackordered, stocks[0].Backordered)
+		})
+	}
+}
+
+func TestStockRepositoryMySQL_Restock_AllocatesFIFO(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	testItem := "test-restock-item"
+
+	require.NoError(t, db.CreateBatch(ctx, []*persistent.StockModel{
+		{ProductID: testItem, Policy: "backorder", BackorderLimit: 100},
+	}))
+
+	repo := NewStockRepositoryMySQL(db)
+	var backorderIDs []string
+	for _, want := range []int64{3, 5, 1} {
+		reservations, err := repo.ReserveStock(ctx, []*entity.ItemWithQuantity{{ID: testItem, Quantity: want}})
+		require.NoError(t, err)
+		backorderIDs = append(backorderIDs, reservations[0].BackorderID)
+	}
+
+	// 补货 7 件：第一个预订 (3) 可以满足，第二个预订 (5) 无法满足，第三个预订 (1) 不能插队
+	allocations, err := repo.Restock(ctx, testItem, 7)
+	require.NoError(t, err)
+	require.Len(t, allocations, 1)
+	assert.Equal(t, backorderIDs[0], allocations[0].BackorderID)
+
+	allocations, err = repo.Restock(ctx, testItem, 2)
+	require.NoError(t, err)
+	require.Len(t, allocations, 2)
+	assert.Equal(t, backorderIDs[1], allocations[0].BackorderID)
+	assert.Equal(t, backorderIDs[2], allocations[1].BackorderID)
+
+	stocks, err := db.BatchGetStockByID(ctx, []string{testItem})
+	require.NoError(t, err)
+	assert.Equal(t, int64(9), stocks[0].Quantity)
+	assert.Equal(t, int64(9), stocks[0].Reserved)
+	assert.Equal(t, int64(0), stocks[0].Backordered)
+}
+
+func TestStockRepositoryMySQL_Restock_RetryAfterDeadlock(t *testing.T) {
+	gormDB := setupTestGormDB(t)
+	db := persistent.NewMySQLWithDB(gormDB)
+	ctx := context.Background()
+	testItem := "test-restock-retry-item"
+
+	require.NoError(t, db.CreateBatch(ctx, []*persistent.StockModel{
+		{ProductID: testItem, Policy: "backorder", BackorderLimit: 100},
+	}))
+
+	repo := NewStockRepositoryMySQL(db)
+	reservations, err := repo.ReserveStock(ctx, []*entity.ItemWithQuantity{{ID: testItem, Quantity: 2}})
+	require.NoError(t, err)
+
+	// 第一次执行在分配预订之后、更新预占库存时报告死锁，事务回滚后重试
+	var (
+		stockUpdates int
+		deadlocked   bool
+	)
+	require.NoError(t, gormDB.Callback().Update().Before("gorm:update").Register("test:deadlock", func(tx *gorm.DB) {
+		if tx.Statement.Table != persistent.SockModelTable || deadlocked {
+			return
+		}
+		// 第一次更新增加实际库存，第二次更新分配预订占用的库存
+		if stockUpdates++; stockUpdates == 2 {
+			deadlocked = true
+			_ = tx.AddError(&mysqldriver.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"})
+		}
+	}))
+	t.Cleanup(func() { _ = gormDB.Callback().Update().Remove("test:deadlock") })
+
+	allocations, err := repo.Restock(ctx, testItem, 5)
+	require.NoError(t, err)
+	require.True(t, deadlocked)
+	require.Len(t, allocations, 1)
+	assert.Equal(t, reservations[0].BackorderID, allocations[0].BackorderID)
+
+	stocks, err := db.BatchGetStockByID(ctx, []string{testItem})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), stocks[0].Quantity)
+	assert.Equal(t, int64(2), stocks[0].Reserved)
+	assert.Equal(t, int64(0), stocks[0].Backordered)
+}
+
+func TestStockRepositoryMySQL_ReleaseStockReservation(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	require.NoError(t, db.CreateBatch(ctx, []*persistent.StockModel{
+		{ProductID: "release-in-stock", Quantity: 10},
+		{ProductID: "release-backorder", Quantity: 1, Policy: "backorder", BackorderLimit: 100},
+	}))
+
+	repo := NewStockRepositoryMySQL(db)
+	_, err := repo.ReserveStock(ctx, []*entity.ItemWithQuantity{{ID: "release-in-stock", Quantity: 4}})
+	require.NoError(t, err)
+
+	var backorderIDs []string
+	for range 2 {
+		reservations, err := repo.ReserveStock(ctx, []*entity.ItemWithQuantity{{ID: "release-backorder", Quantity: 2}})
+		require.NoError(t, err)
+		backorderIDs = append(backorderIDs, reservations[0].BackorderID)
+	}
+	// 补货后第一个预订已分配，占用预占库存；第二个预订仍在等待分配
+	_, err = repo.Restock(ctx, "release-backorder", 1)
+	require.NoError(t, err)
+
+	release := func() error {
+		_, err := repo.ReleaseStockReservation(ctx, []*entity.ItemWithQuantity{{ID: "release-in-stock", Quantity: 4}}, backorderIDs)
+		return err
+	}
+	require.NoError(t, release())
+
+	got, err := db.BatchGetStockByID(ctx, []string{"release-in-stock", "release-backorder"})
+	require.NoError(t, err)
+	expected := map[string][2]int64{"release-in-stock": {0, 0}, "release-backorder": {0, 0}}
+	for _, stock := range got {
+		assert.Equal(t, expected[stock.ProductID], [2]int64{stock.Reserved, stock.Backordered}, stock.ProductID)
+	}
+
+	// 预订已取消，重复释放不会再扣减缺货预订；预占库存已经释放，再次释放会失败
+	require.Error(t, release())
+	_, err = repo.ReleaseStockReservation(ctx, nil, backorderIDs)
+	require.NoError(t, err)
+}
+
+func TestStockRepositoryMySQL_ReleaseStockReservation_AllocatesPending(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	testItem := "release-allocate-item"
+
+	require.NoError(t, db.CreateBatch(ctx, []*persistent.StockModel{
+		{ProductID: testItem, Quantity: 3, Policy: "backorder", BackorderLimit: 100},
+	}))
+
+	repo := NewStockRepositoryMySQL(db)
+	_, err := repo.ReserveStock(ctx, []*entity.ItemWithQuantity{{ID: testItem, Quantity: 2}})
+	require.NoError(t, err)
+
+	// 现货只剩 1 件，第一个预订整单缺货；之后的订单虽然现货足够，也要排在缺货预订之后
+	var backorderIDs []string
+	for _, want := range []int64{2, 1} {
+		reservations, err := repo.ReserveStock(ctx, []*entity.ItemWithQuantity{{ID: testItem, Quantity: want}})
+		require.NoError(t, err)
+		require.Len(t, reservations, 1)
+		assert.Equal(t, consts.FulfilmentBackordered, reservations[0].Status)
+		backorderIDs = append(backorderIDs, reservations[0].BackorderID)
+	}
+
+	// 释放第一个订单的现货后按顺序分配两个预订
+	allocations, err := repo.ReleaseStockReservation(ctx, []*entity.ItemWithQuantity{{ID: testItem, Quantity: 2}}, nil)
+	require.NoError(t, err)
+	require.Len(t, allocations, 2)
+	assert.Equal(t, backorderIDs[0], allocations[0].BackorderID)
+	assert.Equal(t, backorderIDs[1], allocations[1].BackorderID)
+
+	stocks, err := db.BatchGetStockByID(ctx, []string{testItem})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stocks[0].Quantity)
+	assert.Equal(t, int64(3), stocks[0].Reserved)
+	assert.Equal(t, int64(0), stocks[0].Backordered)
+}
+
+func TestStockRepositoryMySQL_Bundle(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	require.NoError(t, db.CreateBatch(ctx, []*persistent.StockModel{
+		{ProductID: "burger", Quantity: 10},
+		{ProductID: "fries", Quantity: 4},
+	}))
+	require.NoError(t, db.CreateBOMBatch(ctx, []*persistent.BOMModel{
+		{BundleID: "combo", ComponentID: "burger", Quantity: 1},
+		{BundleID: "combo", ComponentID: "fries", Quantity: 2},
+	}))
+
+	repo := NewStockRepositoryMySQL(db)
+
+	// 套餐的库存由最稀缺的薯条决定
+	stocks, err := repo.GetStock(ctx, []string{"combo"})
+	require.NoError(t, err)
+	require.Len(t, stocks, 1)
+	assert.Equal(t, int64(2), stocks[0].Available())
+
+	reservations, err := repo.ReserveStock(ctx, []*entity.ItemWithQuantity{{ID: "combo", Quantity: 2}})
+	require.NoError(t, err)
+	assert.Contains(t, reservations, &domain.Reservation{ProductID: "combo", Quantity: 2, Status: consts.FulfilmentInStock})
+
+	// 薯条已经耗尽，整体失败且汉堡不会被预占
+	_, err = repo.ReserveStock(ctx, []*entity.ItemWithQuantity{{ID: "combo", Quantity: 1}})
+	require.Error(t, err)
+
+	require.NoError(t, repo.ConfirmStockReservation(ctx, []*entity.ItemWithQuantity{{ID: "combo", Quantity: 2}}))
+
+	got, err := db.BatchGetStockByID(ctx, []string{"burger", "fries"})
+	require.NoError(t, err)
+	expected := map[string][2]int64{"burger": {8, 0}, "fries": {0, 0}}
+	for _, stock := range got {
+		assert.Equal(t, expected[stock.ProductID], [2]int64{stock.Quantity, stock.Reserved}, stock.ProductID)
+	}
+}
+
+func TestStockRepositoryMySQL_Bundle_SharedComponent(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	require.NoError(t, db.CreateBatch(ctx, []*persistent.StockModel{
+		{ProductID: "burger", Quantity: 2, Policy: "backorder", BackorderLimit: 10},
+		{ProductID: "fries", Quantity: 10},
+	}))
+	require.NoError(t, db.CreateBOMBatch(ctx, []*persistent.BOMModel{
+		{BundleID: "combo", ComponentID: "burger", Quantity: 1},
+		{BundleID: "combo", ComponentID: "fries", Quantity: 1},
+	}))
+
+	repo := NewStockRepositoryMySQL(db)
+
+	// 单独购买的汉堡允许缺货预订，但与套餐合并后超出现货，整体失败
+	_, err := repo.ReserveStock(ctx, []*entity.ItemWithQuantity{
+		{ID: "burger", Quantity: 2},
+		{ID: "combo", Quantity: 1},
+	})
+	var exceed domain.ExceedStockError
+	require.ErrorAs(t, err, &exceed)
+
+	got, err := db.BatchGetStockByID(ctx, []string{"burger", "fries"})
+	require.NoError(t, err)
+	for _, stock := range got {
+		assert.Zero(t, stock.Reserved, stock.ProductID)
+		assert.Zero(t, stock.Backordered, stock.ProductID)
+	}
+
+	// 合并后仍由现货满足时可以同时购买
+	reservations, err := repo.ReserveStock(ctx, []*entity.ItemWithQuantity{
+		{ID: "burger", Quantity: 1},
+		{ID: "combo", Quantity: 1},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, reservations, &domain.Reservation{ProductID: "burger", Quantity: 2, Status: consts.FulfilmentInStock})
+}
+
+// BenchmarkStockRepositoryMySQL_ReserveStock_HotProduct 并发预占同一批热门商品，每次请求中的商品顺序随机，
+// 用于观察按商品 ID 顺序加锁和单条 UPDATE 在高竞争下的吞吐
+func BenchmarkStockRepositoryMySQL_ReserveStock_HotProduct(b *testing.B) {
+	db := setupTestDB(b)
+	ctx := context.Background()
+
+	hot := []string{"hot-item-a", "hot-item-b", "hot-item-c"}
+	var stocks []*persistent.StockModel
+	for _, id := range hot {
+		stocks = append(stocks, &persistent.StockModel{ProductID: id, Quantity: math.MaxInt32})
+	}
+	require.NoError(b, db.CreateBatch(ctx, stocks))
+
+	repo := NewStockRepositoryMySQL(db)
+	b.SetParallelism(8)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			items := make([]*entity.ItemWithQuantity, 0, len(hot))
+			for _, i := range rand.Perm(len(hot)) {
+				items = append(items, &entity.ItemWithQuantity{ID: hot[i], Quantity: 1})
+			}
+			if _, err := repo.ReserveStock(ctx, items); err != nil {
+				b.Error(err)
+			}
+		}
+	})
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "orders/s")
+}
@@ -13,8 +13,13 @@ type Application struct {
 type Commands struct {
 	ReserveStock            command.ReserveStockHandler
 	ConfirmStockReservation command.ConfirmStockReservationHandler
+	UpdateStockPolicy       command.UpdateStockPolicyHandler
+	Restock                 command.RestockHandler
+	ReleaseStockReservation command.ReleaseStockReservationHandler
 }
 
 type Queries struct {
-	GetItems query.GetItemsHandler
+	GetItems          query.GetItemsHandler
+	GetStock          query.GetStockHandler
+	CheckAvailability query.CheckAvailabilityHandler
 }
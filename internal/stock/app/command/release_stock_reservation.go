@@ -0,0 +1,68 @@
+package command
+
+import (
+	"context"
+
+	"github.com/furutachiKurea/gorder/common/broker"
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/entity"
+	"github.com/furutachiKurea/gorder/common/logging"
+	domain "github.com/furutachiKurea/gorder/stock/domain/stock"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+type ReleaseStockReservation struct {
+	Items        []*entity.ItemWithQuantity
+	BackorderIDs []string
+}
+
+// ReleaseStockReservationHandler 订单支付失败或过期后释放订单占用的库存并取消缺货预订，
+// 释放出的库存分配给排队中的缺货预订，每个分配成功的预订发布一条 stock.backorder_allocated 事件
+type ReleaseStockReservationHandler decorator.CommandHandler[ReleaseStockReservation, any]
+
+type releaseStockReservationHandler struct {
+	stockRepo domain.Repository
+	publisher broker.Publisher
+}
+
+func NewReleaseStockReservationHandler(
+	stockRepo domain.Repository,
+	publisher broker.Publisher,
+	logger zerolog.Logger,
+	metricsClient decorator.MetricsClient,
+) ReleaseStockReservationHandler {
+	if stockRepo == nil {
+		panic("stockRepo is nil")
+	}
+	if publisher == nil {
+		panic("publisher is nil")
+	}
+
+	return decorator.ApplyCommandDecorators[ReleaseStockReservation, any](
+		releaseStockReservationHandler{
+			stockRepo: stockRepo,
+			publisher: publisher,
+		},
+		logger,
+		metricsClient,
+	)
+}
+
+func (h releaseStockReservationHandler) Handle(ctx context.Context, command ReleaseStockReservation) (any, error) {
+	var err error
+	defer logging.WhenCommandExecute(ctx, "ReleaseStockReservationHandler", command, err)
+
+	allocations, err := h.stockRepo.ReleaseStockReservation(ctx, packItems(command.Items), command.BackorderIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// 库存已经释放，返回错误会让订单服务重试并重复释放，发布失败只记录日志
+	if publishErr := publishAllocations(ctx, h.publisher, allocations); publishErr != nil {
+		log.Error().Ctx(ctx).Err(publishErr).Msg("publish allocations of released stock failed")
+	}
+
+	return nil, nil
+}
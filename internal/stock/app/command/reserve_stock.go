@@ -83,10 +83,22 @@ func (h reserveStockHandler) Handle(ctx context.Context, command ReserveStock) (
 
 	// 预扣库存
 	items := packItems(command.Items)
-	if err := h.stockRepo.ReserveStock(ctx, items); err != nil {
+	reservations, err := h.stockRepo.ReserveStock(ctx, items)
+	if err != nil {
 		return nil, err
 	}
 
+	byProduct := make(map[string]*domain.Reservation, len(reservations))
+	for _, r := range reservations {
+		byProduct[r.ProductID] = r
+	}
+	for _, item := range res {
+		if r, ok := byProduct[item.ID]; ok {
+			item.FulfilmentStatus = r.Status
+			item.BackorderID = r.BackorderID
+		}
+	}
+
 	return res, nil
 }
 
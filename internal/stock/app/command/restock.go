@@ -0,0 +1,108 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/furutachiKurea/gorder/common/broker"
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/entity"
+	"github.com/furutachiKurea/gorder/common/logging"
+	domain "github.com/furutachiKurea/gorder/stock/domain/stock"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+)
+
+type Restock struct {
+	ProductID string
+	Quantity  int64
+}
+
+// RestockHandler 补货并按先后顺序分配缺货预订，每个分配成功的预订发布一条 stock.backorder_allocated 事件
+type RestockHandler decorator.CommandHandler[Restock, []*domain.Allocation]
+
+type restockHandler struct {
+	stockRepo domain.Repository
+	publisher broker.Publisher
+}
+
+func NewRestockHandler(
+	stockRepo domain.Repository,
+	publisher broker.Publisher,
+	logger zerolog.Logger,
+	metricsClient decorator.MetricsClient,
+) RestockHandler {
+	if stockRepo == nil {
+		panic("stockRepo is nil")
+	}
+	if publisher == nil {
+		panic("publisher is nil")
+	}
+
+	return decorator.ApplyCommandDecorators[Restock, []*domain.Allocation](
+		restockHandler{
+			stockRepo: stockRepo,
+			publisher: publisher,
+		},
+		logger,
+		metricsClient,
+	)
+}
+
+func (h restockHandler) Handle(ctx context.Context, command Restock) ([]*domain.Allocation, error) {
+	var err error
+	defer logging.WhenCommandExecute(ctx, "RestockHandler", command, err)
+
+	if command.Quantity <= 0 {
+		return nil, errors.New("restock quantity must be positive")
+	}
+
+	allocations, err := h.stockRepo.Restock(ctx, command.ProductID, command.Quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = publishAllocations(ctx, h.publisher, allocations); err != nil {
+		return allocations, err
+	}
+
+	return allocations, nil
+}
+
+// publishAllocations 为每个分配成功的缺货预订发布一条 stock.backorder_allocated 事件。
+// 分配结果已经提交，发布失败不回滚，继续发布剩余事件后返回错误
+func publishAllocations(ctx context.Context, publisher broker.Publisher, allocations []*domain.Allocation) error {
+	if len(allocations) == 0 {
+		return nil
+	}
+
+	t := otel.Tracer("rabbitmq")
+	ctx, span := t.Start(ctx, fmt.Sprintf("rabbitmq.%s.publish", broker.EventStockBackorderAllocated))
+	defer span.End()
+
+	var publishErr error
+	for _, a := range allocations {
+		if err := broker.PublishEvent(ctx, &broker.PublishEventReq{
+			Publisher: publisher,
+			Routing:   broker.FanOut,
+			Exchange:  broker.EventStockBackorderAllocated,
+			Mandatory: true,
+			Body: entity.BackorderAllocation{
+				BackorderID: a.BackorderID,
+				ProductID:   a.ProductID,
+				Quantity:    a.Quantity,
+			},
+		}); err != nil {
+			log.Warn().Ctx(ctx).Err(err).Str("backorder_id", a.BackorderID).Msg("publish backorder allocated event failed")
+			publishErr = errors.Join(publishErr, err)
+		}
+	}
+	if publishErr != nil {
+		return fmt.Errorf("publish event error exchange=%s: %w", broker.EventStockBackorderAllocated, publishErr)
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package command
+
+import (
+	"context"
+
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/logging"
+	domain "github.com/furutachiKurea/gorder/stock/domain/stock"
+
+	"github.com/rs/zerolog"
+)
+
+type UpdateStockPolicy struct {
+	ProductID string
+	Policy    domain.Policy
+}
+
+// UpdateStockPolicyHandler 更新商品缺货时的处理策略
+type UpdateStockPolicyHandler decorator.CommandHandler[UpdateStockPolicy, any]
+
+type updateStockPolicyHandler struct {
+	stockRepo domain.Repository
+}
+
+func NewUpdateStockPolicyHandler(
+	stockRepo domain.Repository,
+	logger zerolog.Logger,
+	metricsClient decorator.MetricsClient,
+) UpdateStockPolicyHandler {
+	if stockRepo == nil {
+		panic("stockRepo is nil")
+	}
+
+	return decorator.ApplyCommandDecorators[UpdateStockPolicy, any](
+		updateStockPolicyHandler{
+			stockRepo: stockRepo,
+		},
+		logger,
+		metricsClient,
+	)
+}
+
+func (h updateStockPolicyHandler) Handle(ctx context.Context, command UpdateStockPolicy) (any, error) {
+	var err error
+	defer logging.WhenCommandExecute(ctx, "UpdateStockPolicyHandler", command, err)
+
+	if err = h.stockRepo.UpdatePolicy(ctx, command.ProductID, command.Policy); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,81 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/entity"
+	domain "github.com/furutachiKurea/gorder/stock/domain/stock"
+
+	"github.com/rs/zerolog"
+)
+
+type CheckAvailability struct {
+	Items []*entity.ItemWithQuantity
+}
+
+// CheckAvailabilityHandler 校验购物车中的商品库存是否充足，只读不预占，返回所有库存缺口
+type CheckAvailabilityHandler decorator.QueryHandler[CheckAvailability, []domain.Shortage]
+
+type checkAvailabilityHandler struct {
+	stockRepo domain.Repository
+}
+
+func NewCheckAvailabilityHandler(
+	stockRepo domain.Repository,
+	logger zerolog.Logger,
+	metricsClient decorator.MetricsClient,
+) CheckAvailabilityHandler {
+	if stockRepo == nil {
+		panic("stockRepo is nil")
+	}
+
+	return decorator.ApplyQueryDecorators[CheckAvailability, []domain.Shortage](
+		checkAvailabilityHandler{stockRepo: stockRepo},
+		logger,
+		metricsClient,
+	)
+}
+
+func (c checkAvailabilityHandler) Handle(ctx context.Context, query CheckAvailability) ([]domain.Shortage, error) {
+	// 按商品合并数量，保持请求中的顺序
+	var (
+		ids      []string
+		required = make(map[string]int64)
+	)
+	for _, item := range query.Items {
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("quantity must be positive, got %d from %s", item.Quantity, item.ID)
+		}
+		if _, ok := required[item.ID]; !ok {
+			ids = append(ids, item.ID)
+		}
+		required[item.ID] += item.Quantity
+	}
+
+	stocks, err := c.stockRepo.GetStock(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("get stock: %w", err)
+	}
+
+	byID := make(map[string]*domain.Stock, len(stocks))
+	for _, s := range stocks {
+		byID[s.ProductID] = s
+	}
+
+	// 与预占使用相同的规则，策略允许缺货预订或预售的商品不算缺口；不存在的商品视为可售库存为 0
+	var shortages []domain.Shortage
+	for _, id := range ids {
+		s, ok := byID[id]
+		if !ok {
+			shortages = append(shortages, domain.Shortage{ID: id, Want: required[id]})
+			continue
+		}
+		if _, ok = s.Fulfil(required[id]); !ok {
+			shortages = append(shortages, domain.Shortage{ID: id, Want: required[id], Have: s.InStock()})
+		}
+	}
+
+	return shortages, nil
+}
@@ -0,0 +1,48 @@
+package query
+
+import (
+	"context"
+	"testing"
+
+	"github.com/furutachiKurea/gorder/common/entity"
+	"github.com/furutachiKurea/gorder/common/metrics"
+	domain "github.com/furutachiKurea/gorder/stock/domain/stock"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stockRepository 查询时返回固定的库存，其他方法不会被调用
+type stockRepository struct {
+	domain.Repository
+	stocks []*domain.Stock
+}
+
+func (r stockRepository) GetStock(context.Context, []string) ([]*domain.Stock, error) {
+	return r.stocks, nil
+}
+
+func TestCheckAvailability(t *testing.T) {
+	handler := NewCheckAvailabilityHandler(stockRepository{stocks: []*domain.Stock{
+		{ProductID: "in-stock", Quantity: 5},
+		{ProductID: "deny", Quantity: 5, Reserved: 4},
+		{ProductID: "backorder", Quantity: 1, Policy: domain.Policy{Type: domain.PolicyBackorder, BackorderLimit: 10}},
+	}}, zerolog.Nop(), metrics.TodoMetrics{})
+
+	shortages, err := handler.Handle(context.Background(), CheckAvailability{Items: []*entity.ItemWithQuantity{
+		{ID: "in-stock", Quantity: 2},
+		{ID: "in-stock", Quantity: 3},
+		{ID: "deny", Quantity: 2},
+		{ID: "backorder", Quantity: 4},
+		{ID: "missing", Quantity: 1},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Shortage{
+		{ID: "deny", Want: 2, Have: 1},
+		{ID: "missing", Want: 1},
+	}, shortages)
+
+	_, err = handler.Handle(context.Background(), CheckAvailability{Items: []*entity.ItemWithQuantity{{ID: "in-stock", Quantity: 0}}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,41 @@
+package query
+
+import (
+	"context"
+
+	"github.com/furutachiKurea/gorder/common/decorator"
+	domain "github.com/furutachiKurea/gorder/stock/domain/stock"
+
+	"github.com/rs/zerolog"
+)
+
+type GetStock struct {
+	ProductIDs []string
+}
+
+// GetStockHandler 查询商品的库存、预占与可售数量
+type GetStockHandler decorator.QueryHandler[GetStock, []*domain.Stock]
+
+type getStockHandler struct {
+	stockRepo domain.Repository
+}
+
+func NewGetStockHandler(
+	stockRepo domain.Repository,
+	logger zerolog.Logger,
+	metricsClient decorator.MetricsClient,
+) GetStockHandler {
+	if stockRepo == nil {
+		panic("stockRepo is nil")
+	}
+
+	return decorator.ApplyQueryDecorators[GetStock, []*domain.Stock](
+		getStockHandler{stockRepo: stockRepo},
+		logger,
+		metricsClient,
+	)
+}
+
+func (g getStockHandler) Handle(ctx context.Context, query GetStock) ([]*domain.Stock, error) {
+	return g.stockRepo.GetStock(ctx, query.ProductIDs)
+}
@@ -0,0 +1,72 @@
+package stock
+
+import "github.com/furutachiKurea/gorder/common/entity"
+
+// Component 组合商品中的一个组成商品，Quantity 为每份组合商品消耗的数量
+type Component struct {
+	ProductID string
+	Quantity  int64
+}
+
+// Bundle 组合商品（如套餐），自身没有库存，预占和扣减都作用在组成商品上。
+// 组成商品必须是普通商品，不支持嵌套组合
+type Bundle struct {
+	ProductID  string
+	Components []Component
+}
+
+// Expand 将 quantity 份组合商品展开为组成商品的需求
+func (b Bundle) Expand(quantity int64) []*entity.ItemWithQuantity {
+	items := make([]*entity.ItemWithQuantity, 0, len(b.Components))
+	for _, c := range b.Components {
+		items = append(items, &entity.ItemWithQuantity{
+			ID:       c.ProductID,
+			Quantity: c.Quantity * quantity,
+		})
+	}
+
+	return items
+}
+
+// Stock 由最稀缺的组成商品计算组合商品的库存，缺失的组成商品视为无库存。
+// 组合商品不支持缺货预订，策略总是 PolicyDeny
+func (b Bundle) Stock(components map[string]*Stock) *Stock {
+	var available int64
+	for i, c := range b.Components {
+		var n int64
+		if s, ok := components[c.ProductID]; ok && c.Quantity > 0 {
+			n = s.InStock() / c.Quantity
+		}
+		if i == 0 || n < available {
+			available = n
+		}
+	}
+
+	return &Stock{
+		ProductID: b.ProductID,
+		Quantity:  available,
+		Policy:    Policy{Type: PolicyDeny},
+	}
+}
+
+// ExpandBundles 将请求中的组合商品替换为组成商品，返回展开后的需求和所有来自组合商品的组成商品 ID
+func ExpandBundles(items []*entity.ItemWithQuantity, bundles map[string]*Bundle) ([]*entity.ItemWithQuantity, map[string]struct{}) {
+	var (
+		expanded   []*entity.ItemWithQuantity
+		components = make(map[string]struct{})
+	)
+	for _, item := range items {
+		b, ok := bundles[item.ID]
+		if !ok {
+			expanded = append(expanded, item)
+			continue
+		}
+
+		for _, c := range b.Expand(item.Quantity) {
+			components[c.ID] = struct{}{}
+			expanded = append(expanded, c)
+		}
+	}
+
+	return expanded, components
+}
@@ -10,11 +10,19 @@ import (
 
 type Repository interface {
 	GetItems(ctx context.Context, ids []string) ([]*entity.Item, error)
-	GetStock(ctx context.Context, ids []string) ([]*entity.ItemWithQuantity, error)
-	// ReserveStock 预扣库存
-	ReserveStock(ctx context.Context, items []*entity.ItemWithQuantity) error
+	// GetStock 获取商品库存，不存在的商品不会出现在结果中
+	GetStock(ctx context.Context, ids []string) ([]*Stock, error)
+	// ReserveStock 预扣库存，现货不足的商品按库存策略转为缺货预订或预售
+	ReserveStock(ctx context.Context, items []*entity.ItemWithQuantity) ([]*Reservation, error)
 	// ConfirmStockReservation 订单支付成功后，更新实际库存和预扣库存
 	ConfirmStockReservation(ctx context.Context, items []*entity.ItemWithQuantity) error
+	// ReleaseStockReservation 释放未支付订单占用的库存，items 为已占用库存的商品，backorderIDs 为订单中的缺货预订。
+	// 释放出的库存按先后顺序分配给待处理的缺货预订，返回分配结果
+	ReleaseStockReservation(ctx context.Context, items []*entity.ItemWithQuantity, backorderIDs []string) ([]*Allocation, error)
+	// UpdatePolicy 更新商品的库存策略
+	UpdatePolicy(ctx context.Context, productID string, policy Policy) error
+	// Restock 增加商品库存，并按先后顺序将缺货预订分配到新到的库存
+	Restock(ctx context.Context, productID string, quantity int64) ([]*Allocation, error)
 }
 
 type NotFoundError struct {
@@ -0,0 +1,128 @@
+package stock
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/consts"
+)
+
+// Stock 商品的库存状态
+type Stock struct {
+	ProductID string
+	Quantity  int64
+	Reserved  int64
+	// Backordered 尚未分配到库存的缺货预订数量
+	Backordered int64
+	Policy      Policy
+}
+
+// Available 返回当前可售库存，即实际库存减去预占库存
+func (s Stock) Available() int64 {
+	return max(s.Quantity-s.Reserved, 0)
+}
+
+// InStock 返回可以直接以现货售出的数量，存在待分配的缺货预订时现货要先留给排在前面的预订，返回 0
+func (s Stock) InStock() int64 {
+	if s.Backordered > 0 {
+		return 0
+	}
+	return s.Available()
+}
+
+// Fulfil 根据库存策略决定 required 数量的需求如何被满足，无法满足时返回 false。
+// 现货充足且没有待分配的缺货预订时直接预占，否则在策略允许且未超过预订上限时整单转为缺货预订或预售，
+// 剩余的现货由补货或释放库存时按先后顺序分配给缺货预订
+func (s Stock) Fulfil(required int64) (consts.FulfilmentStatus, bool) {
+	if s.InStock() >= required {
+		return consts.FulfilmentInStock, true
+	}
+
+	switch s.Policy.Type {
+	case PolicyBackorder:
+		if s.Backordered+required <= s.Policy.BackorderLimit {
+			return consts.FulfilmentBackordered, true
+		}
+	case PolicyPreorder:
+		if s.Backordered+required <= s.Policy.BackorderLimit {
+			return consts.FulfilmentPreordered, true
+		}
+	}
+
+	return "", false
+}
+
+// PolicyType 商品缺货时的处理策略
+type PolicyType string
+
+const (
+	// PolicyDeny 缺货时拒绝下单
+	PolicyDeny PolicyType = "deny"
+	// PolicyBackorder 缺货时允许在上限内预订，补货后分配
+	PolicyBackorder PolicyType = "backorder"
+	// PolicyPreorder 商品尚未到货，允许在上限内预售，到货日期为 AvailableAt
+	PolicyPreorder PolicyType = "preorder"
+)
+
+// ParsePolicyType 解析库存策略，空字符串视为 PolicyDeny
+func ParsePolicyType(s string) (PolicyType, error) {
+	switch PolicyType(s) {
+	case "", PolicyDeny:
+		return PolicyDeny, nil
+	case PolicyBackorder, PolicyPreorder:
+		return PolicyType(s), nil
+	default:
+		return "", fmt.Errorf("unknown stock policy %q", s)
+	}
+}
+
+// Policy 商品的库存策略
+type Policy struct {
+	Type PolicyType
+	// BackorderLimit 同时存在的缺货预订或预售数量上限
+	BackorderLimit int64
+	// AvailableAt 预售商品的预计到货日期
+	AvailableAt *time.Time
+}
+
+// NewPolicy 创建并校验库存策略
+func NewPolicy(policyType PolicyType, backorderLimit int64, availableAt *time.Time) (Policy, error) {
+	if backorderLimit < 0 {
+		return Policy{}, fmt.Errorf("backorder limit must not be negative, got %d", backorderLimit)
+	}
+	if policyType == PolicyPreorder && availableAt == nil {
+		return Policy{}, fmt.Errorf("preorder policy requires an availability date")
+	}
+	if policyType != PolicyPreorder {
+		availableAt = nil
+	}
+
+	return Policy{
+		Type:           policyType,
+		BackorderLimit: backorderLimit,
+		AvailableAt:    availableAt,
+	}, nil
+}
+
+// Reservation 一次预占的结果，缺货预订或预售时 BackorderID 为预订记录的 ID
+type Reservation struct {
+	ProductID   string
+	Quantity    int64
+	Status      consts.FulfilmentStatus
+	BackorderID string
+	AvailableAt *time.Time
+}
+
+// Allocation 补货后分配到库存的缺货预订
+type Allocation struct {
+	BackorderID string
+	ProductID   string
+	Quantity    int64
+}
+
+// Shortage 商品库存缺口，Want 为需要的数量，Have 为当前可售库存
+type Shortage struct {
+	ID   string
+	Want int64
+	Have int64
+}
@@ -0,0 +1,75 @@
+package stock
+
+import (
+	"testing"
+
+	"github.com/furutachiKurea/gorder/common/consts"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStock_Fulfil(t *testing.T) {
+	backorder := Policy{Type: PolicyBackorder, BackorderLimit: 10}
+	tests := []struct {
+		name     string
+		stock    Stock
+		required int64
+		want     consts.FulfilmentStatus
+		ok       bool
+	}{
+		{name: "in stock", stock: Stock{Quantity: 5, Reserved: 2}, required: 3, want: consts.FulfilmentInStock, ok: true},
+		{name: "deny", stock: Stock{Quantity: 5, Reserved: 2}, required: 4},
+		{name: "backordered", stock: Stock{Quantity: 5, Reserved: 2, Policy: backorder}, required: 4, want: consts.FulfilmentBackordered, ok: true},
+		{
+			name:     "pending backorders first",
+			stock:    Stock{Quantity: 5, Reserved: 2, Backordered: 4, Policy: backorder},
+			required: 1,
+			want:     consts.FulfilmentBackordered,
+			ok:       true,
+		},
+		{name: "pending backorders deny", stock: Stock{Quantity: 5, Backordered: 4}, required: 1},
+		{name: "backorder limit", stock: Stock{Backordered: 8, Policy: backorder}, required: 3},
+		{
+			name:     "preordered",
+			stock:    Stock{Policy: Policy{Type: PolicyPreorder, BackorderLimit: 10}},
+			required: 3,
+			want:     consts.FulfilmentPreordered,
+			ok:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.stock.Fulfil(tt.required)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBundle_Stock(t *testing.T) {
+	b := Bundle{
+		ProductID: "combo",
+		Components: []Component{
+			{ProductID: "burger", Quantity: 1},
+			{ProductID: "fries", Quantity: 2},
+		},
+	}
+
+	got := b.Stock(map[string]*Stock{
+		"burger": {Quantity: 10},
+		"fries":  {Quantity: 5, Reserved: 1},
+	})
+	assert.Equal(t, int64(2), got.Quantity)
+	assert.Equal(t, PolicyDeny, got.Policy.Type)
+
+	// 组成商品有待分配的缺货预订时组合商品没有现货
+	got = b.Stock(map[string]*Stock{
+		"burger": {Quantity: 10, Backordered: 1},
+		"fries":  {Quantity: 5},
+	})
+	assert.Equal(t, int64(0), got.Quantity)
+
+	got = b.Stock(map[string]*Stock{"burger": {Quantity: 10}})
+	assert.Equal(t, int64(0), got.Quantity, "missing component")
+}
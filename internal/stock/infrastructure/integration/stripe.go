@@ -4,30 +4,42 @@ import (
 	"context"
 
 	_ "github.com/furutachiKurea/gorder/common/config"
+	"github.com/furutachiKurea/gorder/common/resilience"
 	"github.com/furutachiKurea/gorder/stock/app/dto"
+
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/viper"
 	"github.com/stripe/stripe-go/v84"
-	"github.com/stripe/stripe-go/v84/product"
 )
 
+// StripeAPI 使用独立 API Key 的 Stripe 客户端查询商品，请求通过 resilience.Caller 发出，SDK 自带的重试已关闭
 type StripeAPI struct {
-	apiKey string
+	client *stripe.Client
+	caller *resilience.Caller
 }
 
-func NewStripeAPI() *StripeAPI {
+func NewStripeAPI(caller *resilience.Caller) *StripeAPI {
 	key := viper.GetString("stripe-key")
 	if key == "" {
 		log.Panic().Msg("stripe key is empty")
 	}
+
+	if caller == nil {
+		panic("caller is nil")
+	}
+
 	return &StripeAPI{
-		apiKey: key,
+		client: resilience.NewStripeClient(key),
+		caller: caller,
 	}
 }
 
-func (s *StripeAPI) GetProductByID(_ context.Context, pid string) (*dto.Product, error) {
-	stripe.Key = s.apiKey
-	got, err := product.Get(pid, &stripe.ProductParams{})
+func (s *StripeAPI) GetProductByID(ctx context.Context, pid string) (*dto.Product, error) {
+	var got *stripe.Product
+	err := s.caller.Do(ctx, "get_product", func(ctx context.Context) (err error) {
+		got, err = s.client.V1Products.Retrieve(ctx, pid, &stripe.ProductRetrieveParams{})
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,321 @@
+package persistent
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+const (
+	MigrationTable = "schema_migrations"
+
+	// migrationLockName MySQL 命名锁，同一时间只允许一个实例执行迁移
+	migrationLockName    = "gorder_stock_schema_migrations"
+	migrationLockTimeout = 30 * time.Second
+)
+
+//go:embed migrations/*.sql
+var migrationFS embed.FS
+
+// migrationFileName 迁移脚本文件名格式: <version>_<name>.<up|down>.sql
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration 一个版本的迁移脚本
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus 迁移版本的执行状态
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	Dirty     bool
+	AppliedAt *time.Time
+}
+
+// migrationModel 已执行的迁移记录，Dirty 表示脚本执行中途失败，需要人工修复后才能继续迁移
+type migrationModel struct {
+	Version   int64     `gorm:"column:version;primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"column:name"`
+	Dirty     bool      `gorm:"column:dirty"`
+	AppliedAt time.Time `gorm:"column:applied_at"`
+}
+
+func (m migrationModel) TableName() string {
+	return MigrationTable
+}
+
+// Migrator 执行内嵌在二进制中的版本化迁移脚本
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+func NewMigrator(d *MySQL) (*Migrator, error) {
+	migrations, err := loadMigrations(migrationFS, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: d.db, migrations: migrations}, nil
+}
+
+// Up 按版本顺序执行所有未执行的迁移，返回本次执行的迁移
+func (m *Migrator) Up(ctx context.Context) (applied []Migration, err error) {
+	err = m.withLock(ctx, func(conn *gorm.DB) error {
+		done, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, mig := range m.migrations {
+			if _, ok := done[mig.Version]; ok {
+				continue
+			}
+
+			log.Info().Ctx(ctx).Int64("version", mig.Version).Str("name", mig.Name).Msg("applying migration")
+			if err := m.run(ctx, conn, mig, mig.Up, true); err != nil {
+				return err
+			}
+			applied = append(applied, mig)
+		}
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down 按版本倒序回滚最近执行的 steps 个迁移，返回本次回滚的迁移
+func (m *Migrator) Down(ctx context.Context, steps int) (reverted []Migration, err error) {
+	if steps <= 0 {
+		return nil, fmt.Errorf("steps must be positive, got %d", steps)
+	}
+
+	err = m.withLock(ctx, func(conn *gorm.DB) error {
+		done, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			mig := m.migrations[i]
+			if _, ok := done[mig.Version]; !ok {
+				continue
+			}
+
+			log.Info().Ctx(ctx).Int64("version", mig.Version).Str("name", mig.Name).Msg("reverting migration")
+			if err := m.run(ctx, conn, mig, mig.Down, false); err != nil {
+				return err
+			}
+			reverted = append(reverted, mig)
+		}
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Status 返回所有内嵌迁移的执行状态
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	if err := m.ensureTable(ctx, m.db); err != nil {
+		return nil, err
+	}
+
+	var records []migrationModel
+	if err := m.db.WithContext(ctx).Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("get applied migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]migrationModel, len(records))
+	for _, r := range records {
+		byVersion[r.Version] = r
+	}
+
+	status := make([]MigrationStatus, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		s := MigrationStatus{Version: mig.Version, Name: mig.Name}
+		if r, ok := byVersion[mig.Version]; ok {
+			s.Applied = true
+			s.Dirty = r.Dirty
+			s.AppliedAt = &r.AppliedAt
+		}
+		status = append(status, s)
+	}
+
+	return status, nil
+}
+
+// run 执行一个迁移脚本并更新迁移记录。
+// MySQL 的 DDL 会隐式提交，无法放进事务，因此执行前先将记录标记为 dirty，全部语句成功后再清除
+func (m *Migrator) run(ctx context.Context, conn *gorm.DB, mig Migration, script string, up bool) error {
+	record := migrationModel{Version: mig.Version, Name: mig.Name, Dirty: true, AppliedAt: time.Now()}
+	if err := conn.WithContext(ctx).Save(&record).Error; err != nil {
+		return fmt.Errorf("mark migration %d dirty: %w", mig.Version, err)
+	}
+
+	for _, stmt := range splitStatements(script) {
+		if err := conn.WithContext(ctx).Exec(stmt).Error; err != nil {
+			return fmt.Errorf("run migration %d_%s: %w", mig.Version, mig.Name, err)
+		}
+	}
+
+	var err error
+	if up {
+		err = conn.WithContext(ctx).Model(&record).Update("dirty", false).Error
+	} else {
+		err = conn.WithContext(ctx).Delete(&record).Error
+	}
+	if err != nil {
+		return fmt.Errorf("update migration record %d: %w", mig.Version, err)
+	}
+
+	return nil
+}
+
+// appliedVersions 返回已执行的迁移版本，存在 dirty 记录时拒绝继续迁移
+func (m *Migrator) appliedVersions(ctx context.Context, conn *gorm.DB) (map[int64]struct{}, error) {
+	if err := m.ensureTable(ctx, conn); err != nil {
+		return nil, err
+	}
+
+	var records []migrationModel
+	if err := conn.WithContext(ctx).Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("get applied migrations: %w", err)
+	}
+
+	done := make(map[int64]struct{}, len(records))
+	for _, r := range records {
+		if r.Dirty {
+			return nil, fmt.Errorf("migration %d_%s is dirty, fix the schema manually and remove the record from %s", r.Version, r.Name, MigrationTable)
+		}
+		done[r.Version] = struct{}{}
+	}
+
+	return done, nil
+}
+
+func (m *Migrator) ensureTable(ctx context.Context, conn *gorm.DB) error {
+	err := conn.WithContext(ctx).Exec("CREATE TABLE IF NOT EXISTS `" + MigrationTable + "` (" +
+		"version BIGINT NOT NULL PRIMARY KEY, " +
+		"name VARCHAR(255) NOT NULL, " +
+		"dirty BOOLEAN NOT NULL DEFAULT FALSE, " +
+		"applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci").Error
+	if err != nil {
+		return fmt.Errorf("create migration table: %w", err)
+	}
+
+	return nil
+}
+
+// withLock 在固定的数据库连接上持有 MySQL 命名锁执行 fn，命名锁与连接绑定，必须在同一连接上释放
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *gorm.DB) error) error {
+	return m.db.WithContext(ctx).Connection(func(conn *gorm.DB) (err error) {
+		var got sql.NullInt64
+		if err = conn.Raw("SELECT GET_LOCK(?, ?)", migrationLockName, int(migrationLockTimeout.Seconds())).
+			Row().Scan(&got); err != nil {
+			return fmt.Errorf("acquire migration lock: %w", err)
+		}
+		if !got.Valid || got.Int64 != 1 {
+			return errors.New("acquire migration lock: timeout, another instance may be migrating")
+		}
+		defer func() {
+			if releaseErr := conn.Exec("SELECT RELEASE_LOCK(?)", migrationLockName).Error; releaseErr != nil {
+				log.Warn().Ctx(ctx).Err(releaseErr).Msg("release migration lock failed")
+			}
+		}()
+
+		return fn(conn)
+	})
+}
+
+// loadMigrations 从 fsys 的 dir 目录读取迁移脚本，每个版本必须同时有 up 和 down 脚本
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, e := range entries {
+		match := migrationFileName.FindStringSubmatch(e.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", e.Name())
+		}
+
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		content, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s: %w", e.Name(), err)
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = mig
+		} else if mig.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d has conflicting names %q and %q", version, mig.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			mig.Up = string(content)
+		} else {
+			mig.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" || mig.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down scripts", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// splitStatements 按行尾的分号拆分脚本中的语句，并去掉 -- 开头的注释行
+func splitStatements(script string) []string {
+	var (
+		stmts   []string
+		current strings.Builder
+	)
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			stmts = append(stmts, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		stmts = append(stmts, rest)
+	}
+
+	return stmts
+}
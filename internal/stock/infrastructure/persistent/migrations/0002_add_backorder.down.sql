@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS `o_stock_backorder`;
+
+ALTER TABLE `o_stock`
+    DROP COLUMN available_at,
+    DROP COLUMN backorder_limit,
+    DROP COLUMN policy,
+    DROP COLUMN backordered;
@@ -0,0 +1,16 @@
+ALTER TABLE `o_stock`
+    ADD COLUMN backordered BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '未分配的缺货预订数量' AFTER reserved,
+    ADD COLUMN policy VARCHAR(16) NOT NULL DEFAULT 'deny' COMMENT '缺货策略: deny, backorder, preorder' AFTER backordered,
+    ADD COLUMN backorder_limit BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '缺货预订数量上限' AFTER policy,
+    ADD COLUMN available_at TIMESTAMP NULL DEFAULT NULL COMMENT '预售到货日期' AFTER backorder_limit;
+
+CREATE TABLE `o_stock_backorder` (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    product_id VARCHAR(255) NOT NULL COMMENT '商品ID',
+    quantity BIGINT UNSIGNED NOT NULL COMMENT '预订数量',
+    status VARCHAR(16) NOT NULL DEFAULT 'pending' COMMENT '预订状态: pending, allocated',
+    allocated_at TIMESTAMP NULL DEFAULT NULL COMMENT '分配到库存的时间',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    KEY idx_backorder_product_status(product_id, status) COMMENT '按商品查询待分配预订'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='缺货预订表';
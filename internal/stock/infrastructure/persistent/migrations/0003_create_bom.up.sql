@@ -0,0 +1,9 @@
+CREATE TABLE `o_stock_bom` (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    bundle_id VARCHAR(255) NOT NULL COMMENT '组合商品ID',
+    component_id VARCHAR(255) NOT NULL COMMENT '组成商品ID',
+    quantity BIGINT UNSIGNED NOT NULL COMMENT '每份组合商品消耗的组成商品数量',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_bom_bundle_component(bundle_id, component_id) COMMENT '组合商品与组成商品唯一'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='组合商品物料清单';
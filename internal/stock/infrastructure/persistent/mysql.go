@@ -2,10 +2,13 @@ package persistent
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/rand/v2"
 	"time"
 
 	"github.com/furutachiKurea/gorder/common/logging"
+	mysqldriver "github.com/go-sql-driver/mysql"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/viper"
 	"gorm.io/driver/mysql"
@@ -14,22 +17,69 @@ import (
 )
 
 const (
-	SockModelTable = "o_stock"
+	SockModelTable      = "o_stock"
+	BackorderModelTable = "o_stock_backorder"
+	BOMModelTable       = "o_stock_bom"
+)
+
+const (
+	maxTxAttempts  = 3
+	txRetryBackoff = 20 * time.Millisecond
+)
+
+const (
+	BackorderStatusPending   = "pending"
+	BackorderStatusAllocated = "allocated"
+	// BackorderStatusCancelled 订单未支付，预订被取消
+	BackorderStatusCancelled = "cancelled"
 )
 
 type StockModel struct {
-	ID        int64     `gorm:"column:id"`
-	ProductID string    `gorm:"column:product_id"`
-	Quantity  int64     `gorm:"column:quantity"`
-	Reserved  int64     `gorm:"column:reserved"`
-	CreatedAt time.Time `gorm:"column:created_at"`
-	UpdatedAt time.Time `gorm:"column:updated_at"`
+	ID             int64      `gorm:"column:id"`
+	ProductID      string     `gorm:"column:product_id"`
+	Quantity       int64      `gorm:"column:quantity"`
+	Reserved       int64      `gorm:"column:reserved"`
+	Backordered    int64      `gorm:"column:backordered"`
+	Policy         string     `gorm:"column:policy;type:varchar(16);default:deny"`
+	BackorderLimit int64      `gorm:"column:backorder_limit"`
+	AvailableAt    *time.Time `gorm:"column:available_at"`
+	CreatedAt      time.Time  `gorm:"column:created_at"`
+	UpdatedAt      time.Time  `gorm:"column:updated_at"`
 }
 
 func (s StockModel) TableName() string {
 	return SockModelTable
 }
 
+// BackorderModel 缺货预订记录，按 ID 顺序分配补货
+type BackorderModel struct {
+	ID          int64      `gorm:"column:id"`
+	ProductID   string     `gorm:"column:product_id;type:varchar(255);index:idx_backorder_product_status"`
+	Quantity    int64      `gorm:"column:quantity"`
+	Status      string     `gorm:"column:status;type:varchar(16);index:idx_backorder_product_status"`
+	AllocatedAt *time.Time `gorm:"column:allocated_at"`
+	CreatedAt   time.Time  `gorm:"column:created_at"`
+	UpdatedAt   time.Time  `gorm:"column:updated_at"`
+}
+
+func (b BackorderModel) TableName() string {
+	return BackorderModelTable
+}
+
+// BOMModel 组合商品的物料清单，一行表示组合商品中的一个组成商品
+type BOMModel struct {
+	ID          int64     `gorm:"column:id"`
+	BundleID    string    `gorm:"column:bundle_id;type:varchar(255);uniqueIndex:uk_bom_bundle_component"`
+	ComponentID string    `gorm:"column:component_id;type:varchar(255);uniqueIndex:uk_bom_bundle_component"`
+	Quantity    int64     `gorm:"column:quantity"`
+	CreatedAt   time.Time `gorm:"column:created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at"`
+}
+
+func (b BOMModel) TableName() string {
+	return BOMModelTable
+}
+
 type MySQL struct {
 	db *gorm.DB
 }
@@ -56,8 +106,28 @@ func NewMySQLWithDB(db *gorm.DB) *MySQL {
 	return &MySQL{db: db}
 }
 
-func (d MySQL) StartTransaction(fc func(tx *gorm.DB) error) error {
-	return d.db.Transaction(fc)
+// StartTransaction 在事务中执行 fc，遇到死锁或锁等待超时时回滚并重试整个事务，fc 需要可以安全地重复执行
+func (d MySQL) StartTransaction(fc func(tx *gorm.DB) error) (err error) {
+	for attempt := 1; ; attempt++ {
+		err = d.db.Transaction(fc)
+		if !isRetryableTxError(err) || attempt >= maxTxAttempts {
+			return err
+		}
+
+		backoff := time.Duration(attempt)*txRetryBackoff + time.Duration(rand.Int64N(int64(txRetryBackoff)))
+		log.Warn().Err(err).Int("attempt", attempt).Dur("backoff", backoff).Msg("transaction conflict, retrying")
+		time.Sleep(backoff)
+	}
+}
+
+// isRetryableTxError 死锁 (1213) 和锁等待超时 (1205) 时 InnoDB 已经回滚，整个事务可以安全重试
+func isRetryableTxError(err error) bool {
+	var mysqlErr *mysqldriver.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return false
+	}
+
+	return mysqlErr.Number == 1213 || mysqlErr.Number == 1205
 }
 
 // BatchGetStockByID 从数据库中使用 product IDs 批量获取库存信息
@@ -77,6 +147,30 @@ func (d MySQL) BatchGetStockByID(ctx context.Context, productIDs []string) (res
 	return res, nil
 }
 
+// BatchGetBOMByBundleID 获取组合商品的物料清单，不是组合商品的 ID 不会出现在结果中
+func (d MySQL) BatchGetBOMByBundleID(ctx context.Context, bundleIDs []string) (res []BOMModel, err error) {
+	_, deferlog := logging.WhenMySQL(ctx, "BatchGetBOMByBundleID", bundleIDs)
+	defer func() { deferlog(res, &err) }()
+
+	err = d.db.WithContext(ctx).
+		Model(BOMModel{}).
+		Where("bundle_id IN ?", bundleIDs).
+		Order("id").
+		Find(&res).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (d MySQL) CreateBOMBatch(ctx context.Context, create []*BOMModel) (err error) {
+	_, deferlog := logging.WhenMySQL(ctx, "CreateBOMBatch", create)
+	defer deferlog(nil, &err)
+	err = d.db.WithContext(ctx).Create(create).Error
+	return err
+}
+
 func (d MySQL) CreateBatch(ctx context.Context, create []*StockModel) (err error) {
 	var returning StockModel
 	_, deferlog := logging.WhenMySQL(ctx, "CreateBatch", create)
@@ -84,3 +178,24 @@ func (d MySQL) CreateBatch(ctx context.Context, create []*StockModel) (err error
 	err = d.db.WithContext(ctx).Model(&returning).Clauses(clause.Returning{}).Create(create).Error
 	return err
 }
+
+// UpdateStockPolicy 更新商品的库存策略，返回受影响的记录数
+func (d MySQL) UpdateStockPolicy(
+	ctx context.Context,
+	productID, policy string,
+	backorderLimit int64,
+	availableAt *time.Time,
+) (affected int64, err error) {
+	_, deferlog := logging.WhenMySQL(ctx, "UpdateStockPolicy", productID, policy, backorderLimit, availableAt)
+	defer func() { deferlog(affected, &err) }()
+
+	result := d.db.WithContext(ctx).
+		Model(StockModel{}).
+		Where("product_id = ?", productID).
+		Updates(map[string]any{
+			"policy":          policy,
+			"backorder_limit": backorderLimit,
+			"available_at":    availableAt,
+		})
+	return result.RowsAffected, result.Error
+}
@@ -2,11 +2,14 @@ package main
 
 import (
 	"context"
+	"os"
 
+	"github.com/furutachiKurea/gorder/common/broker"
 	_ "github.com/furutachiKurea/gorder/common/config"
 	"github.com/furutachiKurea/gorder/common/discovery"
 	"github.com/furutachiKurea/gorder/common/genproto/stockpb"
 	"github.com/furutachiKurea/gorder/common/logging"
+	"github.com/furutachiKurea/gorder/common/metrics"
 	"github.com/furutachiKurea/gorder/common/server"
 	"github.com/furutachiKurea/gorder/common/tracing"
 	"github.com/furutachiKurea/gorder/stock/ports"
@@ -29,6 +32,19 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(ctx, os.Args[2:]); err != nil {
+			log.Fatal().Err(err).Msg("migrate failed")
+		}
+		return
+	}
+
+	if viper.GetBool("stock.auto-migrate") {
+		if err := runMigrate(ctx, []string{"up"}); err != nil {
+			log.Fatal().Err(err).Msg("auto migrate failed")
+		}
+	}
+
 	shutdown, err := tracing.InitJaegerProvider(viper.GetString("jaeger.url"), serviceName)
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to init jaeger provider")
@@ -37,9 +53,26 @@ func main() {
 		_ = shutdown(ctx)
 	}()
 
-	app := service.NewApplication(ctx)
+	metricsClient := metrics.NewPrometheusMetricsClient(
+		&metrics.PrometheusMetricsClientConfig{
+			Host:        viper.GetString("stock.metrics-export-addr"),
+			ServiceName: serviceName,
+		})
+	mq, closeMQ := broker.Connect(
+		viper.GetString("rabbitmq.user"),
+		viper.GetString("rabbitmq.password"),
+		viper.GetString("rabbitmq.host"),
+		viper.GetString("rabbitmq.port"),
+		metricsClient,
+	)
+	defer func() {
+		_ = closeMQ()
+	}()
+
+	app, cleanup := service.NewApplication(ctx, mq, metricsClient)
+	defer cleanup()
 
-	deregisterFn, err := discovery.RegisterToConsul(ctx, serviceName)
+	deregisterFn, err := discovery.RegisterToConsul(ctx, serviceName, mq.Health)
 	if err != nil {
 		log.Fatal().Err(err).Msgf("failed to register service %s to consul", serviceName)
 	}
@@ -2,12 +2,14 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/furutachiKurea/gorder/common/convertor"
 	"github.com/furutachiKurea/gorder/common/genproto/stockpb"
 	"github.com/furutachiKurea/gorder/stock/app"
 	"github.com/furutachiKurea/gorder/stock/app/command"
 	"github.com/furutachiKurea/gorder/stock/app/query"
+	domain "github.com/furutachiKurea/gorder/stock/domain/stock"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -32,6 +34,52 @@ func (G GRPCServer) GetItems(ctx context.Context, request *stockpb.GetItemsReque
 	}, nil
 }
 
+func (G GRPCServer) GetStock(ctx context.Context, request *stockpb.GetStockRequest) (*stockpb.GetStockResponse, error) {
+	stocks, err := G.app.Queries.GetStock.Handle(ctx, query.GetStock{ProductIDs: request.ProductIds})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	resp := &stockpb.GetStockResponse{}
+	for _, s := range stocks {
+		level := &stockpb.StockLevel{
+			ProductId:      s.ProductID,
+			Quantity:       s.Quantity,
+			Reserved:       s.Reserved,
+			Available:      s.Available(),
+			Backordered:    s.Backordered,
+			Policy:         string(s.Policy.Type),
+			BackorderLimit: s.Policy.BackorderLimit,
+		}
+		if s.Policy.AvailableAt != nil {
+			level.AvailableAt = s.Policy.AvailableAt.Format(time.RFC3339)
+		}
+		resp.Stocks = append(resp.Stocks, level)
+	}
+
+	return resp, nil
+}
+
+func (G GRPCServer) CheckAvailability(ctx context.Context, request *stockpb.CheckAvailabilityRequest) (*stockpb.CheckAvailabilityResponse, error) {
+	shortages, err := G.app.Queries.CheckAvailability.Handle(ctx, query.CheckAvailability{
+		Items: convertor.NewItemWithQuantityConvertor().ProtosToEntities(request.Items),
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	resp := &stockpb.CheckAvailabilityResponse{Available: len(shortages) == 0}
+	for _, s := range shortages {
+		resp.Shortages = append(resp.Shortages, &stockpb.Shortage{
+			ProductId: s.ID,
+			Want:      s.Want,
+			Have:      s.Have,
+		})
+	}
+
+	return resp, nil
+}
+
 func (G GRPCServer) ReserveStock(ctx context.Context, request *stockpb.ReserveStockRequest) (*stockpb.ReserveStockResponse, error) {
 	items, err := G.app.Commands.ReserveStock.Handle(
 		ctx,
@@ -58,3 +106,67 @@ func (G GRPCServer) ConfirmStockReservation(ctx context.Context, request *stockp
 
 	return &stockpb.ConfirmStockReservationResponse{}, nil
 }
+
+func (G GRPCServer) ReleaseStockReservation(ctx context.Context, request *stockpb.ReleaseStockReservationRequest) (*stockpb.ReleaseStockReservationResponse, error) {
+	_, err := G.app.Commands.ReleaseStockReservation.Handle(ctx, command.ReleaseStockReservation{
+		Items:        convertor.NewItemWithQuantityConvertor().ProtosToEntities(request.Items),
+		BackorderIDs: request.BackorderIds,
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &stockpb.ReleaseStockReservationResponse{}, nil
+}
+
+func (G GRPCServer) UpdateStockPolicy(ctx context.Context, request *stockpb.UpdateStockPolicyRequest) (*stockpb.UpdateStockPolicyResponse, error) {
+	policyType, err := domain.ParsePolicyType(request.Policy)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	var availableAt *time.Time
+	if request.AvailableAt != "" {
+		t, err := time.Parse(time.RFC3339, request.AvailableAt)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		availableAt = &t
+	}
+
+	policy, err := domain.NewPolicy(policyType, request.BackorderLimit, availableAt)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	_, err = G.app.Commands.UpdateStockPolicy.Handle(ctx, command.UpdateStockPolicy{
+		ProductID: request.ProductId,
+		Policy:    policy,
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &stockpb.UpdateStockPolicyResponse{}, nil
+}
+
+func (G GRPCServer) Restock(ctx context.Context, request *stockpb.RestockRequest) (*stockpb.RestockResponse, error) {
+	allocations, err := G.app.Commands.Restock.Handle(ctx, command.Restock{
+		ProductID: request.ProductId,
+		Quantity:  request.Quantity,
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	resp := &stockpb.RestockResponse{}
+	for _, a := range allocations {
+		resp.Allocations = append(resp.Allocations, &stockpb.BackorderAllocation{
+			BackorderId: a.BackorderID,
+			ProductId:   a.ProductID,
+			Quantity:    a.Quantity,
+		})
+	}
+
+	return resp, nil
+}
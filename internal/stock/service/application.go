@@ -3,33 +3,43 @@ package service
 import (
 	"context"
 
-	"github.com/furutachiKurea/gorder/common/metrics"
+	"github.com/furutachiKurea/gorder/common/broker"
+	"github.com/furutachiKurea/gorder/common/decorator"
+	"github.com/furutachiKurea/gorder/common/resilience"
 	"github.com/furutachiKurea/gorder/stock/adapter"
 	"github.com/furutachiKurea/gorder/stock/app"
 	"github.com/furutachiKurea/gorder/stock/app/command"
 	"github.com/furutachiKurea/gorder/stock/app/query"
+	domain "github.com/furutachiKurea/gorder/stock/domain/stock"
 	"github.com/furutachiKurea/gorder/stock/infrastructure/integration"
 	"github.com/furutachiKurea/gorder/stock/infrastructure/persistent"
-	"github.com/spf13/viper"
 
 	"github.com/rs/zerolog/log"
 )
 
-func NewApplication(_ context.Context) app.Application {
+func NewApplication(_ context.Context, publisher broker.Publisher, metricsClient decorator.MetricsClient) (app.Application, func()) {
 	db := persistent.NewMySQL()
 	stockRepo := adapter.NewStockRepositoryMySQL(db)
-	stripeAPI := integration.NewStripeAPI()
+	stripeCaller, err := resilience.NewStripeCallerFromConfig(metricsClient)
+	if err != nil {
+		log.Panic().Err(err).Msg("failed to create stripe caller")
+	}
+	stripeAPI := integration.NewStripeAPI(stripeCaller)
+	return newApplication(stockRepo, stripeAPI, publisher, metricsClient), func() {}
+}
+
+func newApplication(
+	stockRepo domain.Repository,
+	productProvider command.ProductProvider,
+	publisher broker.Publisher,
+	metricsClient decorator.MetricsClient,
+) app.Application {
 	logger := log.Logger
-	metricsClient := metrics.NewPrometheusMetricsClient(
-		&metrics.PrometheusMetricsClientConfig{
-			Host:        viper.GetString("stock.metrics-export-addr"),
-			ServiceName: viper.GetString("stock.service-name"),
-		})
 	return app.Application{
 		Commands: app.Commands{
 			ReserveStock: command.NewReserveStockHandler(
 				stockRepo,
-				stripeAPI,
+				productProvider,
 				logger,
 				metricsClient,
 			),
@@ -38,6 +48,23 @@ func NewApplication(_ context.Context) app.Application {
 				logger,
 				metricsClient,
 			),
+			ReleaseStockReservation: command.NewReleaseStockReservationHandler(
+				stockRepo,
+				publisher,
+				logger,
+				metricsClient,
+			),
+			UpdateStockPolicy: command.NewUpdateStockPolicyHandler(
+				stockRepo,
+				logger,
+				metricsClient,
+			),
+			Restock: command.NewRestockHandler(
+				stockRepo,
+				publisher,
+				logger,
+				metricsClient,
+			),
 		},
 		Queries: app.Queries{
 			GetItems: query.NewGetItemsHandler(
@@ -45,6 +72,16 @@ func NewApplication(_ context.Context) app.Application {
 				logger,
 				metricsClient,
 			),
+			GetStock: query.NewGetStockHandler(
+				stockRepo,
+				logger,
+				metricsClient,
+			),
+			CheckAvailability: query.NewCheckAvailabilityHandler(
+				stockRepo,
+				logger,
+				metricsClient,
+			),
 		},
 	}
 }
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/furutachiKurea/gorder/common/broker"
+	"github.com/furutachiKurea/gorder/common/entity"
+	"github.com/furutachiKurea/gorder/common/metrics"
+	"github.com/furutachiKurea/gorder/stock/app/command"
+	domain "github.com/furutachiKurea/gorder/stock/domain/stock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// restockRepository 补货时返回固定的分配结果，其他方法不会被调用
+type restockRepository struct {
+	domain.Repository
+	allocations []*domain.Allocation
+}
+
+func (r restockRepository) Restock(context.Context, string, int64) ([]*domain.Allocation, error) {
+	return r.allocations, nil
+}
+
+// noProducts 补货不会查询商品信息
+type noProducts struct {
+	command.ProductProvider
+}
+
+func TestApplication_RestockPublishesAllocations(t *testing.T) {
+	ctx := context.Background()
+	mb := broker.NewMemoryBroker()
+	defer mb.Close()
+
+	allocations := []*domain.Allocation{
+		{BackorderID: "1", ProductID: "item1", Quantity: 2},
+		{BackorderID: "2", ProductID: "item1", Quantity: 1},
+	}
+	application := newApplication(restockRepository{allocations: allocations}, noProducts{}, mb, metrics.TodoMetrics{})
+
+	// 没有服务订阅时分配结果已经提交，返回分配结果以及发布失败的错误
+	got, err := application.Commands.Restock.Handle(ctx, command.Restock{ProductID: "item1", Quantity: 3})
+	var returnErr broker.ReturnError
+	require.ErrorAs(t, err, &returnErr)
+	assert.Equal(t, allocations, got)
+
+	subCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	msgs, err := mb.Subscribe(subCtx, broker.Subscription{Queue: "test", Exchange: broker.EventStockBackorderAllocated})
+	require.NoError(t, err)
+
+	_, err = application.Commands.Restock.Handle(ctx, command.Restock{ProductID: "item1", Quantity: 3})
+	require.NoError(t, err)
+
+	for _, want := range allocations {
+		select {
+		case d := <-msgs:
+			var a entity.BackorderAllocation
+			require.NoError(t, json.Unmarshal(d.Body, &a))
+			assert.Equal(t, want.BackorderID, a.BackorderID)
+			assert.Equal(t, want.Quantity, a.Quantity)
+			require.NoError(t, d.Ack())
+		case <-time.After(time.Second):
+			require.FailNow(t, "backorder allocated event not published")
+		}
+	}
+}